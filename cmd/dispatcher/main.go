@@ -0,0 +1,340 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The dispatcher delivers the messages of a Subscription's stream to its
+// subscriber as HTTP POST requests. The body of a successful response is
+// published to the reply stream, when one is bound.
+//
+// With AtLeastOnce delivery a message is acknowledged once the subscriber
+// accepted it. Failed deliveries are retried with an exponential backoff,
+// forever unless an error policy limits the retries, in which case the
+// message is published to the dead letter stream, if any, and skipped. With
+// AtMostOnce delivery a message is acknowledged before it is delivered and
+// is never retried.
+//
+// Members of the consumer group share the partitions of the stream. A group
+// without a position starts from the earliest message, as the subscription
+// scales up from zero on the messages it has yet to deliver.
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/projectriff/system/pkg/gateway"
+)
+
+var log = ctrl.Log.WithName("dispatcher")
+
+func main() {
+	ctrl.SetLogger(zap.Logger(true))
+
+	d, err := newDispatcher()
+	if err != nil {
+		log.Error(err, "invalid configuration")
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-ctrl.SetupSignalHandler()
+		cancel()
+	}()
+	d.run(ctx)
+}
+
+// stream is a topic on a gateway
+type stream struct {
+	client *gateway.Client
+	topic  string
+}
+
+func newStream(address string) (*stream, error) {
+	gatewayAddress, topic, err := gateway.ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	client, err := gateway.NewClient(gatewayAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &stream{client: client, topic: topic}, nil
+}
+
+func (s *stream) publish(ctx context.Context, message *gateway.Message) error {
+	value, err := message.Marshal()
+	if err != nil {
+		return err
+	}
+	return s.client.Publish(ctx, s.topic, nil, value)
+}
+
+type dispatcher struct {
+	input      *stream
+	group      string
+	subscriber string
+	atMostOnce bool
+
+	reply            *stream
+	replyContentType string
+	deadLetter       *stream
+
+	// maxRetries is negative to retry forever
+	maxRetries   int
+	initialDelay time.Duration
+	maxDelay     time.Duration
+	multiplier   int
+
+	http *http.Client
+}
+
+func newDispatcher() (*dispatcher, error) {
+	d := &dispatcher{
+		group:        os.Getenv("GROUP"),
+		subscriber:   os.Getenv("SUBSCRIBER"),
+		atMostOnce:   os.Getenv("DELIVERY") == "AtMostOnce",
+		maxRetries:   -1,
+		initialDelay: time.Second,
+		maxDelay:     time.Minute,
+		multiplier:   2,
+		http:         &http.Client{Timeout: time.Minute},
+	}
+	if d.group == "" {
+		return nil, fmt.Errorf("missing GROUP environment variable")
+	}
+	if d.subscriber == "" {
+		return nil, fmt.Errorf("missing SUBSCRIBER environment variable")
+	}
+
+	var err error
+	if d.input, err = newStream(os.Getenv("INPUT")); err != nil {
+		return nil, fmt.Errorf("invalid INPUT environment variable: %v", err)
+	}
+	if address := os.Getenv("REPLY"); address != "" {
+		if d.reply, err = newStream(address); err != nil {
+			return nil, fmt.Errorf("invalid REPLY environment variable: %v", err)
+		}
+		d.replyContentType = os.Getenv("REPLY_CONTENT_TYPE")
+		if d.replyContentType == "" {
+			d.replyContentType = "application/octet-stream"
+		}
+	}
+	if address := os.Getenv("DEAD_LETTER"); address != "" {
+		if d.deadLetter, err = newStream(address); err != nil {
+			return nil, fmt.Errorf("invalid DEAD_LETTER environment variable: %v", err)
+		}
+	}
+
+	if v := os.Getenv("ERROR_MAX_RETRIES"); v != "" {
+		if d.maxRetries, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("invalid ERROR_MAX_RETRIES environment variable: %v", err)
+		}
+	}
+	if v := os.Getenv("ERROR_BACKOFF_INITIAL_DELAY"); v != "" {
+		if d.initialDelay, err = time.ParseDuration(v); err != nil {
+			return nil, fmt.Errorf("invalid ERROR_BACKOFF_INITIAL_DELAY environment variable: %v", err)
+		}
+	}
+	if v := os.Getenv("ERROR_BACKOFF_MAX_DELAY"); v != "" {
+		if d.maxDelay, err = time.ParseDuration(v); err != nil {
+			return nil, fmt.Errorf("invalid ERROR_BACKOFF_MAX_DELAY environment variable: %v", err)
+		}
+	}
+	if v := os.Getenv("ERROR_BACKOFF_MULTIPLIER"); v != "" {
+		if d.multiplier, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("invalid ERROR_BACKOFF_MULTIPLIER environment variable: %v", err)
+		}
+	}
+
+	return d, nil
+}
+
+// run consumes the input stream until the context is cancelled, joining the
+// consumer group again whenever the subscription ends
+func (d *dispatcher) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := d.subscribe(ctx); err != nil && ctx.Err() == nil {
+			log.Error(err, "subscription to the input stream failed", "topic", d.input.topic, "group", d.group)
+		}
+		sleep(ctx, time.Second)
+	}
+}
+
+// subscribe consumes each partition assigned to this member concurrently
+func (d *dispatcher) subscribe(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	assignments, err := d.input.client.Subscribe(ctx, d.input.topic, d.group, gateway.OffsetResetEarliest)
+	if err != nil {
+		return err
+	}
+	defer assignments.Close()
+	for {
+		assignment, err := assignments.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		log.Info("consuming partition", "partition", assignment.Partition)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := d.consume(ctx, assignment); err != nil && ctx.Err() == nil {
+				log.Error(err, "consuming partition failed", "partition", assignment.Partition)
+				// join again for a fresh assignment
+				cancel()
+			}
+		}()
+	}
+}
+
+// consume dispatches the records of a partition in order until it is revoked
+func (d *dispatcher) consume(ctx context.Context, assignment gateway.Assignment) error {
+	records, err := d.input.client.Receive(ctx, assignment)
+	if err != nil {
+		return err
+	}
+	defer records.Close()
+	for {
+		record, err := records.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err := d.dispatch(ctx, assignment, record); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *dispatcher) dispatch(ctx context.Context, assignment gateway.Assignment, record gateway.Record) error {
+	ack := func() error {
+		return d.input.client.Ack(ctx, d.input.topic, d.group, assignment, record.Offset)
+	}
+
+	message, err := gateway.UnmarshalMessage(record.Value)
+	if err != nil {
+		log.Error(err, "skipping malformed message", "partition", assignment.Partition, "offset", record.Offset)
+		return ack()
+	}
+
+	if d.atMostOnce {
+		if err := ack(); err != nil {
+			return err
+		}
+		if err := d.deliver(ctx, message); err != nil {
+			log.Error(err, "dropping message", "partition", assignment.Partition, "offset", record.Offset)
+		}
+		return nil
+	}
+
+	delay := d.initialDelay
+	for retries := 0; ; retries++ {
+		err := d.deliver(ctx, message)
+		if err == nil {
+			return ack()
+		}
+		if d.maxRetries >= 0 && retries >= d.maxRetries {
+			log.Error(err, "retries exhausted", "partition", assignment.Partition, "offset", record.Offset)
+			if d.deadLetter != nil {
+				if err := d.deadLetter.publish(ctx, message); err != nil {
+					return err
+				}
+			}
+			return ack()
+		}
+		log.Info("retrying message", "partition", assignment.Partition, "offset", record.Offset, "delay", delay.String(), "error", err.Error())
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+		delay *= time.Duration(d.multiplier)
+		if delay > d.maxDelay {
+			delay = d.maxDelay
+		}
+	}
+}
+
+// deliver posts the message to the subscriber and publishes its response to
+// the reply stream
+func (d *dispatcher) deliver(ctx context.Context, message *gateway.Message) error {
+	req, err := http.NewRequest(http.MethodPost, d.subscriber, bytes.NewReader(message.Payload))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range message.Headers {
+		req.Header.Set(k, v)
+	}
+	if message.ContentType != "" {
+		req.Header.Set("Content-Type", message.ContentType)
+	}
+	res, err := d.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("subscriber responded with status %d", res.StatusCode)
+	}
+
+	if d.reply == nil || len(body) == 0 {
+		// responses are discarded
+		return nil
+	}
+	contentType := res.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = d.replyContentType
+	}
+	if !gateway.ContentTypeAccepted(d.replyContentType, contentType) {
+		return fmt.Errorf("subscriber responded with content type %q, the reply stream accepts %q", contentType, d.replyContentType)
+	}
+	return d.reply.publish(ctx, &gateway.Message{Payload: body, ContentType: contentType})
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
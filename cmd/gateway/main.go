@@ -0,0 +1,64 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The gateway serves the streams of an InMemoryProvider over the liiklus API.
+// Records are kept in memory, and written through to STORAGE_PATH when it is
+// set, so they survive a restart of the gateway.
+package main
+
+import (
+	"net"
+	"os"
+
+	"google.golang.org/grpc"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/projectriff/system/pkg/gateway/server"
+	"github.com/projectriff/system/pkg/liiklus"
+)
+
+var log = ctrl.Log.WithName("gateway")
+
+func main() {
+	ctrl.SetLogger(zap.Logger(true))
+
+	storagePath := os.Getenv("STORAGE_PATH")
+	s, err := server.New(storagePath)
+	if err != nil {
+		log.Error(err, "unable to load records", "path", storagePath)
+		os.Exit(1)
+	}
+	defer s.Close()
+
+	listener, err := net.Listen("tcp", ":6565")
+	if err != nil {
+		log.Error(err, "unable to listen")
+		os.Exit(1)
+	}
+	grpcServer := grpc.NewServer()
+	liiklus.RegisterLiiklusServiceServer(grpcServer, s)
+	go func() {
+		<-ctrl.SetupSignalHandler()
+		// subscriptions never end on their own, waiting on them would only
+		// delay the shutdown
+		grpcServer.Stop()
+	}()
+	if err := grpcServer.Serve(listener); err != nil {
+		log.Error(err, "problem running gateway")
+		os.Exit(1)
+	}
+}
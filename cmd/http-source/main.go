@@ -0,0 +1,68 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The http source publishes the body of each POST request to the stream an
+// HTTPSource references.
+package main
+
+import (
+	"net/http"
+	"os"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/projectriff/system/pkg/gateway"
+	"github.com/projectriff/system/pkg/httpsource"
+)
+
+var log = ctrl.Log.WithName("http-source")
+
+func main() {
+	ctrl.SetLogger(zap.Logger(true))
+
+	gatewayAddress, topic, err := gateway.ParseAddress(os.Getenv("OUTPUT"))
+	if err != nil {
+		log.Error(err, "invalid OUTPUT environment variable")
+		os.Exit(1)
+	}
+	contentType := os.Getenv("OUTPUT_CONTENT_TYPE")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	client, err := gateway.NewClient(gatewayAddress)
+	if err != nil {
+		log.Error(err, "unable to create gateway client")
+		os.Exit(1)
+	}
+	defer client.Close()
+
+	http.Handle("/", &httpsource.Handler{
+		Client:      client,
+		Topic:       topic,
+		ContentType: contentType,
+		Log:         log,
+	})
+	if err := http.ListenAndServe(":"+port, nil); err != nil {
+		log.Error(err, "problem running http source")
+		os.Exit(1)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"flag"
 	"net/http"
 	"os"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -50,10 +51,12 @@ func main() {
 	var metricsAddr string
 	var probesAddr string
 	var enableLeaderElection bool
+	var containerPollInterval time.Duration
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probesAddr, "probes-addr", ":8081", "The address health probes bind to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&containerPollInterval, "container-poll-interval", 5*time.Minute, "The period between checks of a container repository for a new image.")
 	flag.Parse()
 
 	ctrl.SetLogger(zap.Logger(true))
@@ -82,10 +85,13 @@ func main() {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Application")
 		os.Exit(1)
 	}
+	containerControllerLogger := ctrl.Log.WithName("controllers").WithName("Container")
 	if err = (&controllers.ContainerReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Container"),
-		Scheme: mgr.GetScheme(),
+		Client:              mgr.GetClient(),
+		Log:                 containerControllerLogger,
+		Scheme:              mgr.GetScheme(),
+		ImageDigestResolver: controllers.NewImageDigestResolver(http.DefaultClient, containerControllerLogger),
+		PollInterval:        containerPollInterval,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Container")
 		os.Exit(1)
@@ -30,6 +30,7 @@ import (
 
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
 	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
 	controllers "github.com/projectriff/system/pkg/controllers/core"
 	"github.com/projectriff/system/pkg/tracker"
 	// +kubebuilder:scaffold:imports
@@ -39,6 +40,7 @@ var (
 	scheme     = runtime.NewScheme()
 	setupLog   = ctrl.Log.WithName("setup")
 	syncPeriod = 10 * time.Hour
+	namespace  = os.Getenv("SYSTEM_NAMESPACE")
 )
 
 func init() {
@@ -46,6 +48,7 @@ func init() {
 
 	_ = corev1alpha1.AddToScheme(scheme)
 	_ = buildv1alpha1.AddToScheme(scheme)
+	_ = streamingv1alpha1.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -75,10 +78,11 @@ func main() {
 	}
 
 	if err = (&controllers.DeployerReconciler{
-		Client:  mgr.GetClient(),
-		Log:     ctrl.Log.WithName("controllers").WithName("Deployer"),
-		Scheme:  mgr.GetScheme(),
-		Tracker: tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("Deployer").WithName("tracker")),
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("Deployer"),
+		Scheme:    mgr.GetScheme(),
+		Tracker:   tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("Deployer").WithName("tracker")),
+		Namespace: namespace,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Deployer")
 		os.Exit(1)
@@ -87,6 +91,20 @@ func main() {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Deployer")
 		os.Exit(1)
 	}
+	if err = (&controllers.ScheduleReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("Schedule"),
+		Scheme:    mgr.GetScheme(),
+		Tracker:   tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("Schedule").WithName("tracker")),
+		Namespace: namespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Schedule")
+		os.Exit(1)
+	}
+	if err = ctrl.NewWebhookManagedBy(mgr).For(&corev1alpha1.Schedule{}).Complete(); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "Schedule")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("default", func(_ *http.Request) error { return nil }); err != nil {
@@ -30,6 +30,7 @@ import (
 
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
 	knativev1alpha1 "github.com/projectriff/system/pkg/apis/knative/v1alpha1"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
 	servingv1 "github.com/projectriff/system/pkg/apis/thirdparty/knative/serving/v1"
 	controllers "github.com/projectriff/system/pkg/controllers/knative"
 	"github.com/projectriff/system/pkg/tracker"
@@ -47,6 +48,7 @@ func init() {
 
 	_ = knativev1alpha1.AddToScheme(scheme)
 	_ = buildv1alpha1.AddToScheme(scheme)
+	_ = streamingv1alpha1.AddToScheme(scheme)
 	_ = servingv1.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 }
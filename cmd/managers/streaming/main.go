@@ -31,6 +31,8 @@ import (
 	kedav1alpha1 "github.com/projectriff/system/pkg/apis/thirdparty/keda/v1alpha1"
 
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
+	knativev1alpha1 "github.com/projectriff/system/pkg/apis/knative/v1alpha1"
 	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
 	controllers "github.com/projectriff/system/pkg/controllers/streaming"
 	"github.com/projectriff/system/pkg/tracker"
@@ -50,6 +52,9 @@ func init() {
 	_ = kedav1alpha1.AddToScheme(scheme)
 
 	_ = streamingv1alpha1.AddToScheme(scheme)
+	// kinds of other runtimes that bind streams
+	_ = corev1alpha1.AddToScheme(scheme)
+	_ = knativev1alpha1.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -106,11 +111,43 @@ func main() {
 		setupLog.Error(err, "unable to create webhook", "webhook", "PulsarProvider")
 		os.Exit(1)
 	}
+	if err = (&controllers.InMemoryProviderReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("InMemoryProvider"),
+		Scheme:    mgr.GetScheme(),
+		Tracker:   tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("InMemoryProvider").WithName("tracker")),
+		Namespace: namespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "InMemoryProvider")
+		os.Exit(1)
+	}
+	if err = ctrl.NewWebhookManagedBy(mgr).For(&streamingv1alpha1.InMemoryProvider{}).Complete(); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "InMemoryProvider")
+		os.Exit(1)
+	}
+	if err = (&controllers.ProviderReconciler{
+		Client:  mgr.GetClient(),
+		Log:     ctrl.Log.WithName("controllers").WithName("Provider"),
+		Scheme:  mgr.GetScheme(),
+		Tracker: tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("Provider").WithName("tracker")),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Provider")
+		os.Exit(1)
+	}
+	if err = ctrl.NewWebhookManagedBy(mgr).For(&streamingv1alpha1.Provider{}).Complete(); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "Provider")
+		os.Exit(1)
+	}
+	if err = ctrl.NewWebhookManagedBy(mgr).For(&streamingv1alpha1.ProviderClass{}).Complete(); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ProviderClass")
+		os.Exit(1)
+	}
 	streamControllerLogger := ctrl.Log.WithName("controllers").WithName("Stream")
 	if err = (&controllers.StreamReconciler{
 		Client:                  mgr.GetClient(),
 		Log:                     streamControllerLogger,
 		Scheme:                  mgr.GetScheme(),
+		Tracker:                 tracker.New(syncPeriod, streamControllerLogger.WithName("tracker")),
 		StreamProvisionerClient: controllers.NewStreamProvisionerClient(http.DefaultClient, streamControllerLogger),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Stream")
@@ -120,12 +157,14 @@ func main() {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Stream")
 		os.Exit(1)
 	}
+	processorControllerLogger := ctrl.Log.WithName("controllers").WithName("Processor")
 	if err = (&controllers.ProcessorReconciler{
-		Client:    mgr.GetClient(),
-		Log:       ctrl.Log.WithName("controllers").WithName("Processor"),
-		Scheme:    mgr.GetScheme(),
-		Tracker:   tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("Processor").WithName("tracker")),
-		Namespace: namespace,
+		Client:                  mgr.GetClient(),
+		Log:                     processorControllerLogger,
+		Scheme:                  mgr.GetScheme(),
+		Tracker:                 tracker.New(syncPeriod, processorControllerLogger.WithName("tracker")),
+		Namespace:               namespace,
+		StreamProvisionerClient: controllers.NewStreamProvisionerClient(http.DefaultClient, processorControllerLogger),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Processor")
 		os.Exit(1)
@@ -134,6 +173,34 @@ func main() {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Processor")
 		os.Exit(1)
 	}
+	if err = (&controllers.HTTPSourceReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("HTTPSource"),
+		Scheme:    mgr.GetScheme(),
+		Tracker:   tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("HTTPSource").WithName("tracker")),
+		Namespace: namespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "HTTPSource")
+		os.Exit(1)
+	}
+	if err = ctrl.NewWebhookManagedBy(mgr).For(&streamingv1alpha1.HTTPSource{}).Complete(); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "HTTPSource")
+		os.Exit(1)
+	}
+	if err = (&controllers.SubscriptionReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("Subscription"),
+		Scheme:    mgr.GetScheme(),
+		Tracker:   tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("Subscription").WithName("tracker")),
+		Namespace: namespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Subscription")
+		os.Exit(1)
+	}
+	if err = ctrl.NewWebhookManagedBy(mgr).For(&streamingv1alpha1.Subscription{}).Complete(); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "Subscription")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("default", func(_ *http.Request) error { return nil }); err != nil {
@@ -0,0 +1,252 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The processor runs next to the function of a Processor. It consumes the
+// input streams, invokes the function over riff-rpc with each message and
+// publishes the results to the output streams before acknowledging the
+// message.
+//
+// Failed invocations are retried with an exponential backoff, forever unless
+// an error policy limits the retries, in which case the message is published
+// to the dead letter stream, if any, and skipped.
+//
+// In the jobs of a batch Processor the processor exits once it acknowledged
+// MAX_MESSAGES messages, stopping the function, which shares its process
+// namespace, so the job can complete.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/projectriff/system/pkg/gateway"
+	"github.com/projectriff/system/pkg/processor"
+	"github.com/projectriff/system/pkg/rpc"
+	"github.com/projectriff/system/pkg/workload"
+)
+
+// stopGracePeriod is how long the function may take to exit once stopped
+const stopGracePeriod = 20 * time.Second
+
+var log = ctrl.Log.WithName("processor")
+
+func main() {
+	ctrl.SetLogger(zap.Logger(true))
+
+	p, err := newProcessor()
+	if err != nil {
+		log.Error(err, "invalid configuration")
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-ctrl.SetupSignalHandler()
+		cancel()
+	}()
+	if err := p.Run(ctx); err != nil {
+		log.Error(err, "problem running processor")
+		os.Exit(1)
+	}
+	if p.MaxMessages > 0 && ctx.Err() == nil {
+		log.Info("processed the batch", "messages", p.MaxMessages)
+		if err := workload.Stop(log, stopGracePeriod); err != nil {
+			log.Error(err, "unable to stop the function")
+			os.Exit(1)
+		}
+	}
+}
+
+func newProcessor() (*processor.Processor, error) {
+	gateways := &gatewayClients{clients: map[string]*gateway.Client{}}
+	p := &processor.Processor{
+		ErrorPolicy: processor.DefaultErrorPolicy,
+		Log:         log,
+	}
+
+	group := os.Getenv("GROUP")
+	if group == "" {
+		return nil, fmt.Errorf("missing GROUP environment variable")
+	}
+	inputs := splitList(os.Getenv("INPUTS"))
+	inputNames := splitList(os.Getenv("INPUT_NAMES"))
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("missing INPUTS environment variable")
+	}
+	if len(inputNames) != len(inputs) {
+		return nil, fmt.Errorf("INPUT_NAMES has %d names for %d INPUTS", len(inputNames), len(inputs))
+	}
+	// groups and start positions are optional, an empty entry defaults
+	inputGroups := splitList(os.Getenv("INPUT_GROUPS"))
+	if inputGroups != nil && len(inputGroups) != len(inputs) {
+		return nil, fmt.Errorf("INPUT_GROUPS has %d groups for %d INPUTS", len(inputGroups), len(inputs))
+	}
+	startPositions := splitList(os.Getenv("INPUT_START_POSITIONS"))
+	if startPositions != nil && len(startPositions) != len(inputs) {
+		return nil, fmt.Errorf("INPUT_START_POSITIONS has %d positions for %d INPUTS", len(startPositions), len(inputs))
+	}
+	for i, address := range inputs {
+		stream, err := gateways.stream(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid INPUTS environment variable: %v", err)
+		}
+		input := processor.Input{
+			Stream: *stream,
+			Name:   inputNames[i],
+			Group:  group,
+			Reset:  gateway.OffsetResetEarliest,
+		}
+		if inputGroups != nil && inputGroups[i] != "" {
+			input.Group = inputGroups[i]
+		}
+		if startPositions != nil {
+			if err := parseStartPosition(&input, startPositions[i]); err != nil {
+				return nil, fmt.Errorf("invalid INPUT_START_POSITIONS environment variable: %v", err)
+			}
+		}
+		p.Inputs = append(p.Inputs, input)
+	}
+
+	outputs := splitList(os.Getenv("OUTPUTS"))
+	outputNames := splitList(os.Getenv("OUTPUT_NAMES"))
+	var contentTypes []string
+	if v := os.Getenv("OUTPUT_CONTENT_TYPES"); v != "" {
+		if err := json.Unmarshal([]byte(v), &contentTypes); err != nil {
+			return nil, fmt.Errorf("invalid OUTPUT_CONTENT_TYPES environment variable: %v", err)
+		}
+	}
+	if len(outputNames) != len(outputs) || len(contentTypes) != len(outputs) {
+		return nil, fmt.Errorf("OUTPUT_NAMES and OUTPUT_CONTENT_TYPES must match the %d OUTPUTS", len(outputs))
+	}
+	for i, address := range outputs {
+		stream, err := gateways.stream(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OUTPUTS environment variable: %v", err)
+		}
+		p.Outputs = append(p.Outputs, processor.Output{
+			Stream:      *stream,
+			Name:        outputNames[i],
+			ContentType: contentTypes[i],
+		})
+	}
+
+	function := os.Getenv("FUNCTION")
+	if function == "" {
+		return nil, fmt.Errorf("missing FUNCTION environment variable")
+	}
+	conn, err := grpc.Dial(function, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid FUNCTION environment variable: %v", err)
+	}
+	p.Function = rpc.NewRiffClient(conn)
+
+	if err := parseErrorPolicy(&p.ErrorPolicy, gateways); err != nil {
+		return nil, err
+	}
+	if v := os.Getenv("MAX_MESSAGES"); v != "" {
+		if p.MaxMessages, err = strconv.Atoi(v); err != nil || p.MaxMessages < 1 {
+			return nil, fmt.Errorf("invalid MAX_MESSAGES environment variable: %q", v)
+		}
+	}
+
+	return p, nil
+}
+
+// parseStartPosition reads earliest, latest or an RFC 3339 timestamp, empty
+// for earliest
+func parseStartPosition(input *processor.Input, position string) error {
+	switch position {
+	case "", "earliest":
+		input.Reset = gateway.OffsetResetEarliest
+	case "latest":
+		input.Reset = gateway.OffsetResetLatest
+	default:
+		since, err := time.Parse(time.RFC3339, position)
+		if err != nil {
+			return fmt.Errorf("position %q is not earliest, latest or an RFC 3339 timestamp", position)
+		}
+		input.Since = since
+	}
+	return nil
+}
+
+func parseErrorPolicy(policy *processor.ErrorPolicy, gateways *gatewayClients) error {
+	var err error
+	if v := os.Getenv("ERROR_MAX_RETRIES"); v != "" {
+		if policy.MaxRetries, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("invalid ERROR_MAX_RETRIES environment variable: %v", err)
+		}
+	}
+	if v := os.Getenv("ERROR_BACKOFF_INITIAL_DELAY"); v != "" {
+		if policy.InitialDelay, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("invalid ERROR_BACKOFF_INITIAL_DELAY environment variable: %v", err)
+		}
+	}
+	if v := os.Getenv("ERROR_BACKOFF_MAX_DELAY"); v != "" {
+		if policy.MaxDelay, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("invalid ERROR_BACKOFF_MAX_DELAY environment variable: %v", err)
+		}
+	}
+	if v := os.Getenv("ERROR_BACKOFF_MULTIPLIER"); v != "" {
+		if policy.Multiplier, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("invalid ERROR_BACKOFF_MULTIPLIER environment variable: %v", err)
+		}
+	}
+	if address := os.Getenv("DEAD_LETTER"); address != "" {
+		if policy.DeadLetter, err = gateways.stream(address); err != nil {
+			return fmt.Errorf("invalid DEAD_LETTER environment variable: %v", err)
+		}
+	}
+	return nil
+}
+
+// gatewayClients shares a client between the streams of a gateway
+type gatewayClients struct {
+	clients map[string]*gateway.Client
+}
+
+func (g *gatewayClients) stream(address string) (*processor.Stream, error) {
+	gatewayAddress, topic, err := gateway.ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	client, ok := g.clients[gatewayAddress]
+	if !ok {
+		if client, err = gateway.NewClient(gatewayAddress); err != nil {
+			return nil, err
+		}
+		g.clients[gatewayAddress] = client
+	}
+	return &processor.Stream{Client: client, Topic: topic}, nil
+}
+
+// splitList splits a comma separated list, empty for an empty string
+func splitList(v string) []string {
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The in-memory provisioner resolves streams for an InMemoryProvider. The
+// gateway creates topics on first use and keeps a single partition without
+// retention limits, so there is nothing to create or delete, provisioning
+// only hands out the address of the stream's topic.
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/projectriff/system/pkg/provisioner"
+)
+
+var log = ctrl.Log.WithName("provisioner")
+
+func main() {
+	ctrl.SetLogger(zap.Logger(true))
+
+	gateway := os.Getenv("GATEWAY")
+	if gateway == "" {
+		log.Info("missing GATEWAY environment variable")
+		os.Exit(1)
+	}
+
+	http.Handle("/", &provisioner.Handler{
+		Gateway: gateway,
+		Topics:  inMemoryTopics{},
+		Log:     log,
+	})
+	if err := http.ListenAndServe(":8080", nil); err != nil {
+		log.Error(err, "problem running provisioner")
+		os.Exit(1)
+	}
+}
+
+// inMemoryTopics leaves topics to the gateway
+type inMemoryTopics struct{}
+
+func (inMemoryTopics) Apply(ctx context.Context, topic string, settings provisioner.TopicSettings) error {
+	if settings != (provisioner.TopicSettings{}) {
+		log.Info("the in-memory gateway ignores topic settings", "topic", topic)
+	}
+	return nil
+}
+
+func (inMemoryTopics) Delete(ctx context.Context, topic string) error {
+	// the gateway keeps the messages until its storage is discarded
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The kafka provisioner creates, updates and deletes the topics of streams
+// for a KafkaProvider.
+package main
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/projectriff/system/pkg/provisioner"
+	"github.com/projectriff/system/pkg/provisioner/kafka"
+)
+
+var log = ctrl.Log.WithName("provisioner")
+
+func main() {
+	ctrl.SetLogger(zap.Logger(true))
+
+	gateway := os.Getenv("GATEWAY")
+	if gateway == "" {
+		log.Info("missing GATEWAY environment variable")
+		os.Exit(1)
+	}
+	brokers := os.Getenv("BROKER")
+	if brokers == "" {
+		log.Info("missing BROKER environment variable")
+		os.Exit(1)
+	}
+
+	config := kafka.Config{
+		Brokers:  strings.Split(brokers, ","),
+		ClientID: "riff-kafka-provisioner",
+	}
+	if os.Getenv("TLS_ENABLED") == "true" {
+		tlsConfig, err := provisioner.TLSConfig(os.Getenv("TLS_CA"), os.Getenv("TLS_CERT"), os.Getenv("TLS_KEY"))
+		if err != nil {
+			log.Error(err, "unable to load TLS settings")
+			os.Exit(1)
+		}
+		config.TLS = tlsConfig
+	}
+	if mechanism := os.Getenv("SASL_MECHANISM"); mechanism != "" {
+		config.SASL = &kafka.SASL{
+			Mechanism: mechanism,
+			Username:  os.Getenv("SASL_USERNAME"),
+			Password:  os.Getenv("SASL_PASSWORD"),
+		}
+	}
+
+	admin := kafka.NewAdmin(config)
+	http.Handle("/", &provisioner.Handler{
+		Gateway: gateway,
+		Topics:  admin,
+		Timeout: 30 * time.Second,
+		Log:     log,
+	})
+	if err := http.ListenAndServe(":8080", nil); err != nil {
+		log.Error(err, "problem running provisioner")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The pulsar provisioner creates, updates and deletes the topics of streams
+// for a PulsarProvider.
+package main
+
+import (
+	"net/http"
+	"os"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/projectriff/system/pkg/provisioner"
+	"github.com/projectriff/system/pkg/provisioner/pulsar"
+)
+
+var log = ctrl.Log.WithName("provisioner")
+
+func main() {
+	ctrl.SetLogger(zap.Logger(true))
+
+	gateway := os.Getenv("GATEWAY")
+	if gateway == "" {
+		log.Info("missing GATEWAY environment variable")
+		os.Exit(1)
+	}
+	adminURL := os.Getenv("ADMIN_URL")
+	if adminURL == "" {
+		log.Info("missing ADMIN_URL environment variable")
+		os.Exit(1)
+	}
+
+	tlsConfig, err := provisioner.TLSConfig(os.Getenv("TLS_CA"), os.Getenv("TLS_CERT"), os.Getenv("TLS_KEY"))
+	if err != nil {
+		log.Error(err, "unable to load TLS settings")
+		os.Exit(1)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	admin := pulsar.NewAdmin(pulsar.Config{
+		AdminURL: adminURL,
+		Token:    os.Getenv("AUTH_TOKEN"),
+	}, &http.Client{Transport: transport})
+	http.Handle("/", &provisioner.Handler{
+		Gateway: gateway,
+		Topics:  admin,
+		Timeout: 30 * time.Second,
+		Log:     log,
+	})
+	if err := http.ListenAndServe(":8080", nil); err != nil {
+		log.Error(err, "problem running provisioner")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The schedule trigger runs next to the workload in each job of a Schedule.
+// It invokes the workload once with the schedule's payload and publishes the
+// response to the output stream, when bound. The trigger then stops the
+// workload, which shares its process namespace, so the job can complete.
+//
+// The workload is stopped with SIGTERM, and killed if it is still running
+// after a grace period. The job only succeeds when the workload exits with
+// status 0 on SIGTERM, as servers with a graceful shutdown do.
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/projectriff/system/pkg/gateway"
+	"github.com/projectriff/system/pkg/workload"
+)
+
+const (
+	// startupTimeout bounds how long the workload may take to accept requests
+	startupTimeout = 5 * time.Minute
+	// stopGracePeriod is how long the workload may take to exit once stopped
+	stopGracePeriod = 20 * time.Second
+)
+
+var log = ctrl.Log.WithName("schedule-trigger")
+
+func main() {
+	ctrl.SetLogger(zap.Logger(true))
+
+	err := trigger(context.Background())
+	if err != nil {
+		log.Error(err, "unable to invoke the workload")
+	}
+	if stopErr := workload.Stop(log, stopGracePeriod); stopErr != nil {
+		log.Error(stopErr, "unable to stop the workload")
+		err = stopErr
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+func trigger(ctx context.Context) error {
+	target := os.Getenv("TARGET")
+	if target == "" {
+		return fmt.Errorf("missing TARGET environment variable")
+	}
+	contentType := os.Getenv("CONTENT_TYPE")
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+
+	body, responseType, err := invoke(ctx, target, []byte(os.Getenv("PAYLOAD")), contentType)
+	if err != nil {
+		return err
+	}
+	log.Info("invoked the workload")
+
+	gatewayAddress, topic := os.Getenv("STREAM_OUTPUT_GATEWAY"), os.Getenv("STREAM_OUTPUT_TOPIC")
+	if gatewayAddress == "" || topic == "" {
+		// responses are discarded without an output stream
+		return nil
+	}
+	value, err := (&gateway.Message{Payload: body, ContentType: responseType}).Marshal()
+	if err != nil {
+		return err
+	}
+	client, err := gateway.NewClient(gatewayAddress)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if err := client.Publish(ctx, topic, nil, value); err != nil {
+		return fmt.Errorf("unable to publish the response: %v", err)
+	}
+	return nil
+}
+
+// invoke posts the payload to the workload, retrying while the workload is
+// not yet accepting connections
+func invoke(ctx context.Context, target string, payload []byte, contentType string) ([]byte, string, error) {
+	client := &http.Client{Timeout: time.Minute}
+	deadline := time.Now().Add(startupTimeout)
+	for {
+		req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(payload))
+		if err != nil {
+			return nil, "", err
+		}
+		req = req.WithContext(ctx)
+		req.Header.Set("Content-Type", contentType)
+		res, err := client.Do(req)
+		if err != nil {
+			if time.Now().After(deadline) {
+				return nil, "", fmt.Errorf("workload did not accept requests within %s: %v", startupTimeout, err)
+			}
+			time.Sleep(time.Second)
+			continue
+		}
+		defer res.Body.Close()
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return nil, "", err
+		}
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return nil, "", fmt.Errorf("workload responded with status %d: %s", res.StatusCode, body)
+		}
+		return body, res.Header.Get("Content-Type"), nil
+	}
+}
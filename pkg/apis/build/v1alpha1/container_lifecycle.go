@@ -57,6 +57,10 @@ func (cs *ContainerStatus) MarkImageInvalid(message string) {
 	containerCondSet.Manage(cs).MarkFalse(ContainerConditionImageResolved, "ImageInvalid", message)
 }
 
+func (cs *ContainerStatus) MarkImageDigestUnresolved(message string) {
+	containerCondSet.Manage(cs).MarkFalse(ContainerConditionImageResolved, "DigestUnresolved", message)
+}
+
 func (cs *ContainerStatus) MarkImageResolved() {
 	containerCondSet.Manage(cs).MarkTrue(ContainerConditionImageResolved)
 }
@@ -58,4 +58,46 @@ func (s *DeployerSpec) Default() {
 	if s.IngressPolicy == "" {
 		s.IngressPolicy = IngressPolicyExternal
 	}
+	if s.Ingress != nil {
+		s.Ingress.Default()
+	}
+	if s.Scale != nil {
+		s.Scale.Default()
+	}
+	for i := range s.Streams {
+		s.Streams[i].Default()
+	}
+}
+
+func (i *Ingress) Default() {
+	if i.Path == "" {
+		i.Path = "/"
+	}
+	if i.TLS != nil && i.TLS.IssuerRef != nil {
+		if i.TLS.IssuerRef.Kind == "" {
+			i.TLS.IssuerRef.Kind = ClusterIssuerKind
+		}
+	}
+}
+
+func (s *Scale) Default() {
+	if s.Min == nil {
+		s.Min = int32Ptr(1)
+	}
+	if s.TargetCPUUtilization == nil {
+		s.TargetCPUUtilization = int32Ptr(80)
+	}
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func (b *StreamBinding) Default() {
+	if b.Alias == "" {
+		b.Alias = b.Stream
+	}
+	if b.Mode == "" {
+		b.Mode = StreamBindingModePublish
+	}
 }
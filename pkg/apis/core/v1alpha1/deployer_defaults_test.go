@@ -142,6 +142,116 @@ func TestDeployerSpecDefault(t *testing.T) {
 			},
 			IngressPolicy: IngressPolicyClusterLocal,
 		},
+	}, {
+		name: "ingress",
+		in: &DeployerSpec{
+			Ingress: &Ingress{
+				TLS: &IngressTLS{
+					IssuerRef: &IssuerReference{
+						Name: "letsencrypt",
+					},
+				},
+			},
+		},
+		want: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "handler",
+						Ports: []corev1.ContainerPort{
+							{Name: "http", Protocol: corev1.ProtocolTCP, ContainerPort: 8080},
+						},
+					},
+				},
+			},
+			IngressPolicy: IngressPolicyExternal,
+			Ingress: &Ingress{
+				Path: "/",
+				TLS: &IngressTLS{
+					IssuerRef: &IssuerReference{
+						Name: "letsencrypt",
+						Kind: ClusterIssuerKind,
+					},
+				},
+			},
+		},
+	}, {
+		name: "scale",
+		in: &DeployerSpec{
+			Scale: &Scale{
+				Max: int32Ptr(10),
+			},
+		},
+		want: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "handler",
+						Ports: []corev1.ContainerPort{
+							{Name: "http", Protocol: corev1.ProtocolTCP, ContainerPort: 8080},
+						},
+					},
+				},
+			},
+			IngressPolicy: IngressPolicyExternal,
+			Scale: &Scale{
+				Min:                  int32Ptr(1),
+				Max:                  int32Ptr(10),
+				TargetCPUUtilization: int32Ptr(80),
+			},
+		},
+	}, {
+		name: "scale preserves cpu target",
+		in: &DeployerSpec{
+			Scale: &Scale{
+				Min:                  int32Ptr(2),
+				Max:                  int32Ptr(10),
+				TargetCPUUtilization: int32Ptr(50),
+			},
+		},
+		want: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "handler",
+						Ports: []corev1.ContainerPort{
+							{Name: "http", Protocol: corev1.ProtocolTCP, ContainerPort: 8080},
+						},
+					},
+				},
+			},
+			IngressPolicy: IngressPolicyExternal,
+			Scale: &Scale{
+				Min:                  int32Ptr(2),
+				Max:                  int32Ptr(10),
+				TargetCPUUtilization: int32Ptr(50),
+			},
+		},
+	}, {
+		name: "streams",
+		in: &DeployerSpec{
+			Streams: []StreamBinding{
+				{Stream: "orders"},
+				{Stream: "payments", Alias: "in", Mode: StreamBindingModeSubscribe},
+			},
+		},
+		want: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "handler",
+						Ports: []corev1.ContainerPort{
+							{Name: "http", Protocol: corev1.ProtocolTCP, ContainerPort: 8080},
+						},
+					},
+				},
+			},
+			IngressPolicy: IngressPolicyExternal,
+			Streams: []StreamBinding{
+				{Stream: "orders", Alias: "orders", Mode: StreamBindingModePublish},
+				{Stream: "payments", Alias: "in", Mode: StreamBindingModeSubscribe},
+			},
+		},
 	}}
 
 	for _, test := range tests {
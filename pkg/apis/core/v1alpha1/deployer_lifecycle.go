@@ -17,9 +17,13 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"time"
+
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apis "github.com/projectriff/system/pkg/apis"
 )
@@ -29,11 +33,16 @@ const (
 	DeployerConditionDeploymentReady apis.ConditionType = "DeploymentReady"
 	DeployerConditionServiceReady    apis.ConditionType = "ServiceReady"
 	DeployerConditionIngressReady    apis.ConditionType = "IngressReady"
+	DeployerConditionAutoscalerReady apis.ConditionType = "AutoscalerReady"
+	DeployerConditionRolloutReady    apis.ConditionType = "RolloutReady"
+	DeployerConditionStreamsReady    apis.ConditionType = "StreamsReady"
 )
 
 var deployerCondSet = apis.NewLivingConditionSet(
 	DeployerConditionDeploymentReady,
 	DeployerConditionServiceReady,
+	DeployerConditionAutoscalerReady,
+	DeployerConditionStreamsReady,
 )
 
 func (ds *DeployerStatus) GetObservedGeneration() int64 {
@@ -93,6 +102,10 @@ func (ds *DeployerStatus) MarkIngressNotRequired() {
 	deployerCondSet.Manage(ds).MarkFalse(DeployerConditionIngressReady, "IngressNotRequired", "Ingress resource is not required.")
 }
 
+func (ds *DeployerStatus) MarkIngressDomainMissing(message string) {
+	deployerCondSet.Manage(ds).MarkFalse(DeployerConditionIngressReady, "DomainMissing", message)
+}
+
 // PropagateIngressStatus update DeployerConditionIngressReady condition
 // in DeployerStatus according to IngressStatus.
 func (ds *DeployerStatus) PropagateIngressStatus(is *networkingv1beta1.IngressStatus) {
@@ -102,3 +115,160 @@ func (ds *DeployerStatus) PropagateIngressStatus(is *networkingv1beta1.IngressSt
 		deployerCondSet.Manage(ds).MarkTrue(DeployerConditionIngressReady)
 	}
 }
+
+// MarkAutoscalerNotRequired is used by deployers without a scale, which run
+// a fixed number of replicas.
+func (ds *DeployerStatus) MarkAutoscalerNotRequired() {
+	deployerCondSet.Manage(ds).MarkTrue(DeployerConditionAutoscalerReady)
+}
+
+// PropagateAutoscalerStatus update DeployerConditionAutoscalerReady condition
+// in DeployerStatus according to HorizontalPodAutoscalerStatus.
+func (ds *DeployerStatus) PropagateAutoscalerStatus(hs *autoscalingv2beta2.HorizontalPodAutoscalerStatus) {
+	var able, active *autoscalingv2beta2.HorizontalPodAutoscalerCondition
+	for i := range hs.Conditions {
+		switch hs.Conditions[i].Type {
+		case autoscalingv2beta2.AbleToScale:
+			able = &hs.Conditions[i]
+		case autoscalingv2beta2.ScalingActive:
+			active = &hs.Conditions[i]
+		}
+	}
+	switch {
+	case able == nil || active == nil:
+		deployerCondSet.Manage(ds).MarkUnknown(DeployerConditionAutoscalerReady, "AutoscalerNotConfigured", "Autoscaler has not yet been reconciled.")
+	case able.Status == corev1.ConditionFalse:
+		deployerCondSet.Manage(ds).MarkFalse(DeployerConditionAutoscalerReady, able.Reason, able.Message)
+	case active.Status == corev1.ConditionFalse:
+		deployerCondSet.Manage(ds).MarkFalse(DeployerConditionAutoscalerReady, active.Reason, active.Message)
+	case able.Status == corev1.ConditionTrue && active.Status == corev1.ConditionTrue:
+		deployerCondSet.Manage(ds).MarkTrue(DeployerConditionAutoscalerReady)
+	default:
+		deployerCondSet.Manage(ds).MarkUnknown(DeployerConditionAutoscalerReady, active.Reason, active.Message)
+	}
+}
+
+func (ds *DeployerStatus) MarkRolloutNotRequired() {
+	deployerCondSet.Manage(ds).MarkFalse(DeployerConditionRolloutReady, "RolloutNotRequired", "Rollout is not required.")
+}
+
+func (ds *DeployerStatus) MarkRolloutWaiting(revision string) {
+	deployerCondSet.Manage(ds).MarkUnknown(DeployerConditionRolloutReady, "RolloutWaiting", "waiting for revision %q to become available", revision)
+}
+
+func (ds *DeployerStatus) MarkRolloutProgressing(revision string, step, steps int, percent int64) {
+	deployerCondSet.Manage(ds).MarkUnknown(DeployerConditionRolloutReady, "RolloutProgressing", "step %d of %d, routing %d%% of requests to revision %q", step+1, steps, percent, revision)
+}
+
+func (ds *DeployerStatus) MarkRolloutComplete() {
+	deployerCondSet.Manage(ds).MarkTrue(DeployerConditionRolloutReady)
+}
+
+func (ds *DeployerStatus) MarkRolledBack(revision, message string) {
+	deployerCondSet.Manage(ds).MarkFalse(DeployerConditionRolloutReady, "RolledBack", "revision %q was rolled back: %s", revision, message)
+}
+
+// ProgressRollout moves the rollout of the candidate revision forward given
+// whether the candidate is available at now. The step of an available
+// candidate advances once its pause has elapsed, a failed candidate is rolled
+// back and otherwise the current step holds with its pause timer stopped. The
+// stable revision must be resolved before calling. The returned duration is
+// the time until the current step completes, or zero when no step is running.
+func (ds *DeployerStatus) ProgressRollout(rollout *Rollout, candidate string, available corev1.ConditionStatus, message string, now time.Time) time.Duration {
+	rs := ds.Rollout
+	if candidate == "" || candidate == rs.StableRevisionName || candidate == rs.FailedRevisionName {
+		// nothing to roll out
+		rs.CandidateRevisionName = ""
+		rs.resetStep()
+		if candidate != rs.FailedRevisionName {
+			ds.MarkRolloutComplete()
+		}
+		return 0
+	}
+
+	if rs.CandidateRevisionName != candidate {
+		rs.CandidateRevisionName = candidate
+		rs.resetStep()
+	}
+
+	switch available {
+	case corev1.ConditionTrue:
+		// keep rolling out
+	case corev1.ConditionFalse:
+		rs.FailedRevisionName = candidate
+		rs.CandidateRevisionName = ""
+		rs.resetStep()
+		ds.MarkRolledBack(candidate, message)
+		return 0
+	default:
+		// hold the current step and stop its pause until the candidate is
+		// available again
+		if rs.StepStartTime != nil {
+			rs.StepElapsed = &metav1.Duration{Duration: rs.stepElapsed(now)}
+			rs.StepStartTime = nil
+		}
+		ds.MarkRolloutWaiting(candidate)
+		return 0
+	}
+
+	if rs.StepStartTime == nil {
+		// start or resume the current step
+		rs.StepStartTime = &metav1.Time{Time: now}
+	} else if int(rs.Step) < len(rollout.Steps) && rs.stepElapsed(now) >= rollout.Steps[rs.Step].Pause.Duration {
+		rs.Step++
+		rs.StepStartTime = &metav1.Time{Time: now}
+		rs.StepElapsed = nil
+	}
+
+	if int(rs.Step) >= len(rollout.Steps) {
+		rs.StableRevisionName = candidate
+		rs.CandidateRevisionName = ""
+		rs.resetStep()
+		ds.MarkRolloutComplete()
+		return 0
+	}
+
+	step := rollout.Steps[rs.Step]
+	ds.MarkRolloutProgressing(candidate, int(rs.Step), len(rollout.Steps), step.Percent)
+	remaining := step.Pause.Duration - rs.stepElapsed(now)
+	if remaining <= 0 {
+		// advance to the next step promptly
+		remaining = time.Second
+	}
+	return remaining
+}
+
+func (ds *DeployerStatus) MarkStreamsReady() {
+	deployerCondSet.Manage(ds).MarkTrue(DeployerConditionStreamsReady)
+}
+
+func (ds *DeployerStatus) MarkStreamsNotReady(message string) {
+	deployerCondSet.Manage(ds).MarkFalse(DeployerConditionStreamsReady, "StreamNotReady", message)
+}
+
+// CandidatePercent is the percent of requests the current step routes to the
+// candidate revision. The canary runs at this share from the first step, even
+// before the candidate is available, as requests only reach ready pods.
+func (rs *RolloutStatus) CandidatePercent(rollout *Rollout) int64 {
+	if rs.CandidateRevisionName == "" || int(rs.Step) >= len(rollout.Steps) {
+		return 0
+	}
+	return rollout.Steps[rs.Step].Percent
+}
+
+func (rs *RolloutStatus) resetStep() {
+	rs.Step = 0
+	rs.StepStartTime = nil
+	rs.StepElapsed = nil
+}
+
+func (rs *RolloutStatus) stepElapsed(now time.Time) time.Duration {
+	var elapsed time.Duration
+	if rs.StepElapsed != nil {
+		elapsed = rs.StepElapsed.Duration
+	}
+	if rs.StepStartTime != nil {
+		elapsed += now.Sub(rs.StepStartTime.Time)
+	}
+	return elapsed
+}
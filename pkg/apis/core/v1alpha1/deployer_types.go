@@ -28,7 +28,9 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 var (
-	DeployerLabelKey = GroupVersion.Group + "/deployer"
+	DeployerLabelKey         = GroupVersion.Group + "/deployer"
+	DeployerCanaryLabelKey   = GroupVersion.Group + "/deployer-canary"
+	DeployerRevisionLabelKey = GroupVersion.Group + "/deployer-revision"
 )
 
 var (
@@ -51,6 +53,27 @@ type DeployerSpec struct {
 	// IngressPolicy defines whether the workload should be reachable from
 	// outside the cluster
 	IngressPolicy IngressPolicy `json:"ingressPolicy,omitempty"`
+
+	// Ingress customizes how the workload is exposed outside the cluster.
+	// Only allowed with the External ingress policy.
+	Ingress *Ingress `json:"ingress,omitempty"`
+
+	// Scale configures horizontal autoscaling of the workload. When not
+	// specified, the number of replicas is not managed. Autoscaling is
+	// suspended while a rollout runs.
+	Scale *Scale `json:"scale,omitempty"`
+
+	// Rollout progressively shifts requests to new revisions of the pod
+	// template while they remain available. A canary Deployment runs the new
+	// revision behind the same Service, with replicas sized to receive the
+	// percent of requests for the current step. When not specified, the
+	// Deployment is updated in place.
+	Rollout *Rollout `json:"rollout,omitempty"`
+
+	// Streams binds streams to the workload. The gateway and topic of each
+	// stream are exposed to the first container as environment variables
+	// and as files.
+	Streams []StreamBinding `json:"streams,omitempty"`
 }
 
 type Build struct {
@@ -75,6 +98,104 @@ const (
 	IngressPolicyExternal     IngressPolicy = "External"
 )
 
+type Ingress struct {
+	// Host name to route to the workload. Defaults to
+	// `<service>.<namespace>.<domain>` with the domain configured for the
+	// cluster.
+	Host string `json:"host,omitempty"`
+
+	// Path prefix to route to the workload. Defaults to `/`.
+	Path string `json:"path,omitempty"`
+
+	// Class of the ingress controller that should satisfy the ingress.
+	Class string `json:"class,omitempty"`
+
+	// TLS terminates https requests for the host.
+	TLS *IngressTLS `json:"tls,omitempty"`
+}
+
+type IngressTLS struct {
+	// SecretName references a secret in this namespace holding the
+	// certificate for the host. Defaults to `<deployer>-deployer-tls`, or
+	// `<source>-http-source-tls` for an HTTPSource, when an issuer is
+	// specified.
+	SecretName string `json:"secretName,omitempty"`
+
+	// IssuerRef references a cert-manager issuer that will provide the
+	// certificate for the host.
+	IssuerRef *IssuerReference `json:"issuerRef,omitempty"`
+}
+
+type IssuerReference struct {
+	// Name of the issuer
+	Name string `json:"name"`
+
+	// Kind of the issuer, either Issuer or ClusterIssuer. Defaults to
+	// ClusterIssuer.
+	Kind string `json:"kind,omitempty"`
+}
+
+const (
+	IssuerKind        = "Issuer"
+	ClusterIssuerKind = "ClusterIssuer"
+)
+
+type Scale struct {
+	// Min replicas for the workload. Defaults to 1.
+	Min *int32 `json:"min,omitempty"`
+
+	// Max replicas for the workload.
+	Max *int32 `json:"max"`
+
+	// TargetCPUUtilization is the average percentage of requested cpu to
+	// target across replicas. Defaults to 80.
+	TargetCPUUtilization *int32 `json:"targetCPUUtilization,omitempty"`
+}
+
+type Rollout struct {
+	// Steps of requests shifted to a new revision. After the final step the
+	// new revision replaces the stable revision.
+	Steps []RolloutStep `json:"steps"`
+}
+
+type RolloutStep struct {
+	// Percent of requests routed to the new revision during this step. The
+	// replicas of the stable revision when the rollout started are divided
+	// between the canary and stable revisions by this ratio, so the split is
+	// approximate for workloads with few replicas. Both revisions keep a
+	// replica until a step of 100 percent.
+	Percent int64 `json:"percent"`
+
+	// Pause before advancing to the next step, e.g. `5m`
+	Pause metav1.Duration `json:"pause,omitempty"`
+}
+
+// StreamBinding exposes a stream to the workload under an alias. The
+// container receives the environment variables STREAM_<ALIAS>_GATEWAY and
+// STREAM_<ALIAS>_TOPIC, with the alias upper cased and dashes replaced by
+// underscores, and the files gateway and topic in
+// /var/riff/streams/<alias>. Subscribers also receive
+// STREAM_<ALIAS>_GROUP naming their consumer group, core-deployer-<name>.
+type StreamBinding struct {
+	// Stream name, from this namespace, to be bound to the workload
+	Stream string `json:"stream"`
+
+	// Alias exposes the stream under another name within the workload.
+	// Defaults to the stream name.
+	Alias string `json:"alias,omitempty"`
+
+	// Mode is how the workload uses the stream, either Publish or Subscribe.
+	// Defaults to Publish.
+	Mode StreamBindingMode `json:"mode,omitempty"`
+}
+
+type StreamBindingMode string
+
+const (
+	StreamBindingModePublish   StreamBindingMode = "Publish"
+	StreamBindingModeSubscribe StreamBindingMode = "Subscribe"
+)
+
 // DeployerStatus defines the observed state of Deployer
 type DeployerStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -88,12 +209,50 @@ type DeployerStatus struct {
 	DeploymentName string `json:"deploymentName,omitempty"`
 	ServiceName    string `json:"serviceName,omitempty"`
 	IngressName    string `json:"ingressName,omitempty"`
+	AutoscalerName string `json:"autoscalerName,omitempty"`
 
 	// Address to target this deployer internally
 	Address *apis.Addressable `json:"address,omitempty"`
 
 	// URL to target this deployer publicly
 	URL string `json:"url,omitempty"`
+
+	// Rollout is the progress of shifting requests to a new revision
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+}
+
+// RolloutStatus records revisions of the pod template by a hash of the
+// template.
+type RolloutStatus struct {
+	// StableRevisionName is the most recent revision to complete a rollout
+	StableRevisionName string `json:"stableRevisionName,omitempty"`
+
+	// CandidateRevisionName is the revision being rolled out
+	CandidateRevisionName string `json:"candidateRevisionName,omitempty"`
+
+	// FailedRevisionName is the most recent revision to be rolled back. It
+	// will not be rolled out again.
+	FailedRevisionName string `json:"failedRevisionName,omitempty"`
+
+	// CanaryDeploymentName is the Deployment running the candidate revision
+	CanaryDeploymentName string `json:"canaryDeploymentName,omitempty"`
+
+	// Step is the index of the rollout step currently applied
+	Step int32 `json:"step,omitempty"`
+
+	// StepStartTime is when the candidate revision last became available
+	// during the current step. It is cleared while the revision is not
+	// available.
+	StepStartTime *metav1.Time `json:"stepStartTime,omitempty"`
+
+	// StepElapsed is how long the current step ran before StepStartTime.
+	// The pause of a step only counts time while the revision is available.
+	StepElapsed *metav1.Duration `json:"stepElapsed,omitempty"`
+
+	// Replicas of the stable revision when the rollout started. They are
+	// divided between the canary and stable Deployments until the rollout
+	// ends, then given back to the stable Deployment.
+	Replicas int32 `json:"replicas,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -18,11 +18,13 @@ package v1alpha1
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/projectriff/system/pkg/validation"
@@ -94,6 +96,66 @@ func (s *DeployerSpec) Validate() validation.FieldErrors {
 		errs = errs.Also(validation.ErrInvalidValue(s.IngressPolicy, "ingressPolicy"))
 	}
 
+	if s.Ingress != nil {
+		if s.IngressPolicy == IngressPolicyClusterLocal {
+			errs = errs.Also(validation.ErrDisallowedFields("ingress", "not allowed for ClusterLocal ingress policy"))
+		} else {
+			errs = errs.Also(s.Ingress.Validate().ViaField("ingress"))
+		}
+	}
+
+	if s.Scale != nil {
+		errs = errs.Also(s.Scale.Validate().ViaField("scale"))
+	}
+
+	if s.Rollout != nil {
+		errs = errs.Also(s.Rollout.Validate().ViaField("rollout"))
+	}
+
+	aliases := map[string]bool{}
+	for i, binding := range s.Streams {
+		errs = errs.Also(binding.Validate().ViaFieldIndex("streams", i))
+		if aliases[binding.Alias] {
+			errs = errs.Also(validation.ErrInvalidValue(binding.Alias, "alias").ViaFieldIndex("streams", i))
+		}
+		aliases[binding.Alias] = true
+	}
+
+	return errs
+}
+
+func (i *Ingress) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if i.Host != "" && len(utilvalidation.IsDNS1123Subdomain(i.Host)) != 0 {
+		errs = errs.Also(validation.ErrInvalidValue(i.Host, "host"))
+	}
+	if i.Path != "" && !strings.HasPrefix(i.Path, "/") {
+		errs = errs.Also(validation.ErrInvalidValue(i.Path, "path"))
+	}
+	if i.TLS != nil {
+		errs = errs.Also(i.TLS.Validate().ViaField("tls"))
+	}
+
+	return errs
+}
+
+func (t *IngressTLS) Validate() validation.FieldErrors {
+	if equality.Semantic.DeepEqual(t, &IngressTLS{}) {
+		return validation.ErrMissingOneOf("secretName", "issuerRef")
+	}
+
+	errs := validation.FieldErrors{}
+
+	if t.IssuerRef != nil {
+		if t.IssuerRef.Name == "" {
+			errs = errs.Also(validation.ErrMissingField("issuerRef.name"))
+		}
+		if t.IssuerRef.Kind != "" && t.IssuerRef.Kind != IssuerKind && t.IssuerRef.Kind != ClusterIssuerKind {
+			errs = errs.Also(validation.ErrInvalidValue(t.IssuerRef.Kind, "issuerRef.kind"))
+		}
+	}
+
 	return errs
 }
 
@@ -142,3 +204,60 @@ func filterInvalidVolumes(volumes []corev1.Volume) []corev1.Volume {
 	// TODO remove unsupported fields
 	return volumes
 }
+
+func (s *Scale) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if s.Min != nil && *s.Min < 1 {
+		errs = errs.Also(validation.ErrInvalidValue(*s.Min, "min"))
+	}
+	if s.Max == nil {
+		errs = errs.Also(validation.ErrMissingField("max"))
+	} else if *s.Max < 1 || (s.Min != nil && *s.Max < *s.Min) {
+		errs = errs.Also(validation.ErrInvalidValue(*s.Max, "max"))
+	}
+	if s.TargetCPUUtilization != nil && (*s.TargetCPUUtilization < 1 || *s.TargetCPUUtilization > 100) {
+		errs = errs.Also(validation.ErrInvalidValue(*s.TargetCPUUtilization, "targetCPUUtilization"))
+	}
+
+	return errs
+}
+
+func (r *Rollout) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if len(r.Steps) == 0 {
+		errs = errs.Also(validation.ErrMissingField("steps"))
+	}
+	var previous int64
+	for i, step := range r.Steps {
+		// each step must route more requests to the new revision than the last
+		if step.Percent <= previous || step.Percent > 100 {
+			errs = errs.Also(validation.ErrInvalidValue(step.Percent, "percent").ViaFieldIndex("steps", i))
+		}
+		if step.Pause.Duration < 0 {
+			errs = errs.Also(validation.ErrInvalidValue(step.Pause.Duration.String(), "pause").ViaFieldIndex("steps", i))
+		}
+		previous = step.Percent
+	}
+
+	return errs
+}
+
+func (b *StreamBinding) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if b.Stream == "" {
+		errs = errs.Also(validation.ErrMissingField("stream"))
+	}
+	if b.Alias == "" {
+		errs = errs.Also(validation.ErrMissingField("alias"))
+	} else if len(utilvalidation.IsDNS1123Label(b.Alias)) != 0 {
+		errs = errs.Also(validation.ErrInvalidValue(b.Alias, "alias"))
+	}
+	if b.Mode != StreamBindingModePublish && b.Mode != StreamBindingModeSubscribe {
+		errs = errs.Also(validation.ErrInvalidValue(b.Mode, "mode"))
+	}
+
+	return errs
+}
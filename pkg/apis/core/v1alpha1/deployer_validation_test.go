@@ -18,9 +18,11 @@ package v1alpha1
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/projectriff/system/pkg/validation"
 )
@@ -131,6 +133,264 @@ func TestValidateDeployerSpec(t *testing.T) {
 			IngressPolicy: "bogus",
 		},
 		expected: validation.ErrInvalidValue(IngressPolicy("bogus"), "ingressPolicy"),
+	}, {
+		name: "valid, ingress",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-iamge"},
+				},
+			},
+			IngressPolicy: IngressPolicyExternal,
+			Ingress: &Ingress{
+				Host:  "my-deployer.example.com",
+				Path:  "/api",
+				Class: "nginx",
+				TLS: &IngressTLS{
+					IssuerRef: &IssuerReference{
+						Name: "letsencrypt",
+						Kind: ClusterIssuerKind,
+					},
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid, ingress with cluster local policy",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-iamge"},
+				},
+			},
+			IngressPolicy: IngressPolicyClusterLocal,
+			Ingress:       &Ingress{},
+		},
+		expected: validation.ErrDisallowedFields("ingress", "not allowed for ClusterLocal ingress policy"),
+	}, {
+		name: "invalid, ingress host",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-iamge"},
+				},
+			},
+			IngressPolicy: IngressPolicyExternal,
+			Ingress: &Ingress{
+				Host: "Not_A_Host",
+			},
+		},
+		expected: validation.ErrInvalidValue("Not_A_Host", "ingress.host"),
+	}, {
+		name: "invalid, ingress path",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-iamge"},
+				},
+			},
+			IngressPolicy: IngressPolicyExternal,
+			Ingress: &Ingress{
+				Path: "api",
+			},
+		},
+		expected: validation.ErrInvalidValue("api", "ingress.path"),
+	}, {
+		name: "invalid, empty ingress tls",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-iamge"},
+				},
+			},
+			IngressPolicy: IngressPolicyExternal,
+			Ingress: &Ingress{
+				TLS: &IngressTLS{},
+			},
+		},
+		expected: validation.ErrMissingOneOf("secretName", "issuerRef").ViaField("tls").ViaField("ingress"),
+	}, {
+		name: "invalid, ingress tls issuer",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-iamge"},
+				},
+			},
+			IngressPolicy: IngressPolicyExternal,
+			Ingress: &Ingress{
+				TLS: &IngressTLS{
+					IssuerRef: &IssuerReference{
+						Kind: "bogus",
+					},
+				},
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrMissingField("ingress.tls.issuerRef.name"),
+			validation.ErrInvalidValue("bogus", "ingress.tls.issuerRef.kind"),
+		),
+	}, {
+		name: "valid, scale",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-iamge"},
+				},
+			},
+			IngressPolicy: IngressPolicyExternal,
+			Scale: &Scale{
+				Min:                  int32Ptr(1),
+				Max:                  int32Ptr(10),
+				TargetCPUUtilization: int32Ptr(50),
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid, scale max required",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-iamge"},
+				},
+			},
+			IngressPolicy: IngressPolicyExternal,
+			Scale:         &Scale{},
+		},
+		expected: validation.ErrMissingField("scale.max"),
+	}, {
+		name: "invalid, scale max less than min",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-iamge"},
+				},
+			},
+			IngressPolicy: IngressPolicyExternal,
+			Scale: &Scale{
+				Min: int32Ptr(3),
+				Max: int32Ptr(2),
+			},
+		},
+		expected: validation.ErrInvalidValue(int32(2), "scale.max"),
+	}, {
+		name: "invalid, scale values",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-iamge"},
+				},
+			},
+			IngressPolicy: IngressPolicyExternal,
+			Scale: &Scale{
+				Min:                  int32Ptr(0),
+				Max:                  int32Ptr(1),
+				TargetCPUUtilization: int32Ptr(101),
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrInvalidValue(int32(0), "scale.min"),
+			validation.ErrInvalidValue(int32(101), "scale.targetCPUUtilization"),
+		),
+	}, {
+		name: "valid, rollout",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			Rollout: &Rollout{
+				Steps: []RolloutStep{
+					{Percent: 10, Pause: metav1.Duration{Duration: 5 * time.Minute}},
+					{Percent: 50, Pause: metav1.Duration{Duration: 5 * time.Minute}},
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid, rollout without steps",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			Rollout: &Rollout{},
+		},
+		expected: validation.ErrMissingField("rollout.steps"),
+	}, {
+		name: "invalid, rollout steps",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			Rollout: &Rollout{
+				Steps: []RolloutStep{
+					{Percent: 50},
+					{Percent: 20},
+					{Percent: 101, Pause: metav1.Duration{Duration: -time.Minute}},
+				},
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrInvalidValue(int64(20), "rollout.steps[1].percent"),
+			validation.ErrInvalidValue(int64(101), "rollout.steps[2].percent"),
+			validation.ErrInvalidValue("-1m0s", "rollout.steps[2].pause"),
+		),
+	}, {
+		name: "valid, streams",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			IngressPolicy: IngressPolicyExternal,
+			Streams: []StreamBinding{
+				{Stream: "orders", Alias: "orders", Mode: StreamBindingModePublish},
+				{Stream: "payments", Alias: "in", Mode: StreamBindingModeSubscribe},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid, streams",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			IngressPolicy: IngressPolicyExternal,
+			Streams: []StreamBinding{
+				{},
+				{Stream: "orders", Alias: "Not_A_Label", Mode: "bogus"},
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrMissingField("streams[0].stream"),
+			validation.ErrMissingField("streams[0].alias"),
+			validation.ErrInvalidValue(StreamBindingMode(""), "streams[0].mode"),
+			validation.ErrInvalidValue("Not_A_Label", "streams[1].alias"),
+			validation.ErrInvalidValue(StreamBindingMode("bogus"), "streams[1].mode"),
+		),
+	}, {
+		name: "invalid, duplicate stream alias",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			IngressPolicy: IngressPolicyExternal,
+			Streams: []StreamBinding{
+				{Stream: "orders", Alias: "orders", Mode: StreamBindingModePublish},
+				{Stream: "other-orders", Alias: "orders", Mode: StreamBindingModeSubscribe},
+			},
+		},
+		expected: validation.ErrInvalidValue("orders", "streams[1].alias"),
 	}} {
 		t.Run(c.name, func(t *testing.T) {
 			actual := c.target.Validate()
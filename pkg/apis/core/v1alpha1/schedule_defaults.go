@@ -0,0 +1,65 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// +kubebuilder:webhook:path=/mutate-core-projectriff-io-v1alpha1-schedule,mutating=true,failurePolicy=fail,groups=core.projectriff.io,resources=schedules,verbs=create;update,versions=v1alpha1,name=schedules.core.projectriff.io
+
+var _ webhook.Defaulter = &Schedule{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *Schedule) Default() {
+	r.Spec.Default()
+}
+
+func (s *ScheduleSpec) Default() {
+	if s.Template == nil {
+		s.Template = &corev1.PodSpec{}
+	}
+	if len(s.Template.Containers) == 0 {
+		s.Template.Containers = append(s.Template.Containers, corev1.Container{})
+	}
+	if s.Template.Containers[0].Name == "" {
+		s.Template.Containers[0].Name = "handler"
+	}
+	if s.Template.Containers[0].Ports == nil {
+		s.Template.Containers[0].Ports = []corev1.ContainerPort{}
+	}
+	if len(s.Template.Containers[0].Ports) == 0 {
+		s.Template.Containers[0].Ports = append(s.Template.Containers[0].Ports, corev1.ContainerPort{})
+	}
+	if s.Template.Containers[0].Ports[0].Name == "" {
+		s.Template.Containers[0].Ports[0].Name = "http"
+	}
+	if s.Template.Containers[0].Ports[0].Protocol == "" {
+		s.Template.Containers[0].Ports[0].Protocol = corev1.ProtocolTCP
+	}
+	if s.Template.Containers[0].Ports[0].ContainerPort == 0 {
+		s.Template.Containers[0].Ports[0].ContainerPort = 8080
+	}
+	if s.ContentType == "" {
+		s.ContentType = "text/plain"
+	}
+	if s.ConcurrencyPolicy == "" {
+		s.ConcurrencyPolicy = batchv1beta1.ForbidConcurrent
+	}
+}
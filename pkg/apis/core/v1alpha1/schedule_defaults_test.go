@@ -0,0 +1,110 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestScheduleDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *Schedule
+		want *Schedule
+	}{{
+		name: "empty",
+		in:   &Schedule{},
+		want: &Schedule{
+			Spec: ScheduleSpec{
+				Template: &corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "handler",
+							Ports: []corev1.ContainerPort{
+								{Name: "http", Protocol: corev1.ProtocolTCP, ContainerPort: 8080},
+							},
+						},
+					},
+				},
+				ContentType:       "text/plain",
+				ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+			},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.in
+			got.Default()
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Default (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestScheduleSpecDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *ScheduleSpec
+		want *ScheduleSpec
+	}{{
+		name: "preserves content type and concurrency policy",
+		in: &ScheduleSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "my-container",
+						Ports: []corev1.ContainerPort{
+							{Name: "web", Protocol: corev1.ProtocolTCP, ContainerPort: 80},
+						},
+					},
+				},
+			},
+			ContentType:       "application/json",
+			ConcurrencyPolicy: batchv1beta1.AllowConcurrent,
+		},
+		want: &ScheduleSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "my-container",
+						Ports: []corev1.ContainerPort{
+							{Name: "web", Protocol: corev1.ProtocolTCP, ContainerPort: 80},
+						},
+					},
+				},
+			},
+			ContentType:       "application/json",
+			ConcurrencyPolicy: batchv1beta1.AllowConcurrent,
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.in
+			got.Default()
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Default (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
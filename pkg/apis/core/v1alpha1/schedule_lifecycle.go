@@ -0,0 +1,94 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+
+	apis "github.com/projectriff/system/pkg/apis"
+)
+
+const (
+	ScheduleConditionReady                               = apis.ConditionReady
+	ScheduleConditionCronJobReady     apis.ConditionType = "CronJobReady"
+	ScheduleConditionStreamsReady     apis.ConditionType = "StreamsReady"
+	ScheduleConditionLastRunSucceeded apis.ConditionType = "LastRunSucceeded"
+)
+
+var scheduleCondSet = apis.NewLivingConditionSet(
+	ScheduleConditionCronJobReady,
+	ScheduleConditionStreamsReady,
+)
+
+func (ss *ScheduleStatus) GetObservedGeneration() int64 {
+	return ss.ObservedGeneration
+}
+
+func (ss *ScheduleStatus) IsReady() bool {
+	return scheduleCondSet.Manage(ss).IsHappy()
+}
+
+func (*ScheduleStatus) GetReadyConditionType() apis.ConditionType {
+	return ScheduleConditionReady
+}
+
+func (ss *ScheduleStatus) GetCondition(t apis.ConditionType) *apis.Condition {
+	return scheduleCondSet.Manage(ss).GetCondition(t)
+}
+
+func (ss *ScheduleStatus) InitializeConditions() {
+	scheduleCondSet.Manage(ss).InitializeConditions()
+}
+
+func (ss *ScheduleStatus) PropagateCronJobStatus(cs *batchv1beta1.CronJobStatus) {
+	// cron jobs don't have meaningful status
+	scheduleCondSet.Manage(ss).MarkTrue(ScheduleConditionCronJobReady)
+	ss.LastScheduleTime = cs.LastScheduleTime
+}
+
+func (ss *ScheduleStatus) MarkStreamsReady() {
+	scheduleCondSet.Manage(ss).MarkTrue(ScheduleConditionStreamsReady)
+}
+
+func (ss *ScheduleStatus) MarkStreamsNotReady(message string) {
+	scheduleCondSet.Manage(ss).MarkFalse(ScheduleConditionStreamsReady, "StreamNotReady", message)
+}
+
+func (ss *ScheduleStatus) MarkNoRuns() {
+	scheduleCondSet.Manage(ss).MarkUnknown(ScheduleConditionLastRunSucceeded, "NoRuns", "The workload has not yet been invoked.")
+}
+
+// PropagateLastJobStatus update ScheduleConditionLastRunSucceeded condition
+// in ScheduleStatus according to the status of the most recently finished
+// job.
+func (ss *ScheduleStatus) PropagateLastJobStatus(js *batchv1.JobStatus) {
+	for _, c := range js.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			scheduleCondSet.Manage(ss).MarkTrue(ScheduleConditionLastRunSucceeded)
+			return
+		case batchv1.JobFailed:
+			scheduleCondSet.Manage(ss).MarkFalse(ScheduleConditionLastRunSucceeded, c.Reason, c.Message)
+			return
+		}
+	}
+}
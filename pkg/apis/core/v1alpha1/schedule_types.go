@@ -0,0 +1,121 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	apis "github.com/projectriff/system/pkg/apis"
+)
+
+var (
+	ScheduleLabelKey = GroupVersion.Group + "/schedule"
+)
+
+var (
+	_ apis.Resource = (*Schedule)(nil)
+)
+
+// ScheduleSpec defines the desired state of Schedule
+type ScheduleSpec struct {
+	// Schedule in cron format, see https://en.wikipedia.org/wiki/Cron
+	Schedule string `json:"schedule"`
+
+	// Build resolves the image from a build resource. As the target build
+	// produces new images, they will be used for subsequent invocations.
+	Build *Build `json:"build,omitempty"`
+
+	// Template pod
+	Template *corev1.PodSpec `json:"template,omitempty"`
+
+	// Payload is the body of the request sent to the workload on each
+	// invocation. Defaults to an empty body.
+	Payload string `json:"payload,omitempty"`
+
+	// ContentType of the payload. Defaults to `text/plain`.
+	ContentType string `json:"contentType,omitempty"`
+
+	// Output is the name of a stream, from this namespace, the result of
+	// each invocation is published to. When not specified, the result is
+	// discarded.
+	Output string `json:"output,omitempty"`
+
+	// ConcurrencyPolicy specifies how to treat concurrent invocations,
+	// either Allow, Forbid or Replace. Defaults to Forbid.
+	ConcurrencyPolicy batchv1beta1.ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+}
+
+// ScheduleStatus defines the observed state of Schedule
+type ScheduleStatus struct {
+	apis.Status `json:",inline"`
+
+	// LatestImage is the most recent image resolved from the build
+	LatestImage string `json:"latestImage,omitempty"`
+
+	CronJobName string `json:"cronJobName,omitempty"`
+
+	// LastScheduleTime is when the workload was last invoked
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSuccessfulTime is when the latest successful invocation completed
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+
+	// LastFailureTime is when the latest failed invocation gave up
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:categories="riff"
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="Last Run",type=date,JSONPath=`.status.lastScheduleTime`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+// +genclient
+
+// Schedule is the Schema for the schedules API
+type Schedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScheduleSpec   `json:"spec,omitempty"`
+	Status ScheduleStatus `json:"status,omitempty"`
+}
+
+func (*Schedule) GetGroupVersionKind() schema.GroupVersionKind {
+	return SchemeGroupVersion.WithKind("Schedule")
+}
+
+func (s *Schedule) GetStatus() apis.ResourceStatus {
+	return &s.Status
+}
+
+// +kubebuilder:object:root=true
+
+// ScheduleList contains a list of Schedule
+type ScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Schedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Schedule{}, &ScheduleList{})
+}
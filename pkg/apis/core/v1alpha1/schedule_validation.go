@@ -0,0 +1,122 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/projectriff/system/pkg/validation"
+)
+
+// +kubebuilder:webhook:path=/validate-core-projectriff-io-v1alpha1-schedule,mutating=false,failurePolicy=fail,groups=core.projectriff.io,resources=schedules,verbs=create;update,versions=v1alpha1,name=schedules.core.projectriff.io
+
+var (
+	_ webhook.Validator         = &Schedule{}
+	_ validation.FieldValidator = &Schedule{}
+)
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Schedule) ValidateCreate() error {
+	return r.Validate().ToAggregate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Schedule) ValidateUpdate(old runtime.Object) error {
+	// TODO check for immutable fields
+	return r.Validate().ToAggregate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Schedule) ValidateDelete() error {
+	return nil
+}
+
+func (r *Schedule) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	errs = errs.Also(r.Spec.Validate().ViaField("spec"))
+
+	return errs
+}
+
+func (s *ScheduleSpec) Validate() validation.FieldErrors {
+	if equality.Semantic.DeepEqual(s, &ScheduleSpec{}) {
+		return validation.ErrMissingField(validation.CurrentField)
+	}
+
+	errs := validation.FieldErrors{}
+
+	if s.Schedule == "" {
+		errs = errs.Also(validation.ErrMissingField("schedule"))
+	} else if !isCronSchedule(s.Schedule) {
+		errs = errs.Also(validation.ErrInvalidValue(s.Schedule, "schedule"))
+	}
+
+	if diff := cmp.Diff(&corev1.PodSpec{
+		// add supported PodSpec fields here, otherwise their usage will be rejected
+		ServiceAccountName: s.Template.ServiceAccountName,
+		// the defaulter guarantees at least one container
+		Containers: filterInvalidContainers(s.Template.Containers[:1]),
+		Volumes:    filterInvalidVolumes(s.Template.Volumes),
+	}, s.Template); diff != "" {
+		errs = errs.Also(validation.ErrDisallowedFields(validation.CurrentField, fmt.Sprintf("limited Template fields may be set (-want, +got) = %v", diff)))
+	}
+
+	for i, env := range s.Template.Containers[0].Env {
+		if env.Name == "PORT" {
+			errs = errs.Also(validation.ErrDisallowedFields(fmt.Sprintf("template.containers[0].env[%d]", i), "PORT is not allowed"))
+		}
+	}
+
+	if s.Build == nil && s.Template.Containers[0].Image == "" {
+		errs = errs.Also(validation.ErrMissingOneOf("build", "template.containers[0].image"))
+	} else if s.Build != nil && s.Template.Containers[0].Image != "" {
+		errs = errs.Also(validation.ErrMultipleOneOf("build", "template.containers[0].image"))
+	} else if s.Build != nil {
+		errs = errs.Also(s.Build.Validate().ViaField("build"))
+	}
+
+	if s.ContentType == "" {
+		errs = errs.Also(validation.ErrMissingField("contentType"))
+	}
+
+	switch s.ConcurrencyPolicy {
+	case batchv1beta1.AllowConcurrent, batchv1beta1.ForbidConcurrent, batchv1beta1.ReplaceConcurrent:
+	default:
+		errs = errs.Also(validation.ErrInvalidValue(s.ConcurrencyPolicy, "concurrencyPolicy"))
+	}
+
+	return errs
+}
+
+// isCronSchedule checks the shape of a schedule, either five fields or a
+// predefined macro like @hourly. The cron job controller is left to reject
+// malformed fields.
+func isCronSchedule(schedule string) bool {
+	if strings.HasPrefix(schedule, "@") {
+		return !strings.ContainsAny(schedule, " \t")
+	}
+	return len(strings.Fields(schedule)) == 5
+}
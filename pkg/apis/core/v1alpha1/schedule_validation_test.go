@@ -0,0 +1,185 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/projectriff/system/pkg/validation"
+)
+
+func TestValidateSchedule(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *Schedule
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &Schedule{},
+		expected: validation.ErrMissingField("spec"),
+	}, {
+		name: "valid",
+		target: &Schedule{
+			Spec: ScheduleSpec{
+				Schedule: "*/5 * * * *",
+				Build: &Build{
+					FunctionRef: "my-function",
+				},
+				Template: &corev1.PodSpec{
+					Containers: []corev1.Container{
+						{},
+					},
+				},
+				ContentType:       "text/plain",
+				ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+			},
+		},
+		expected: validation.FieldErrors{},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateSchedule(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
+
+func TestValidateScheduleSpec(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *ScheduleSpec
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &ScheduleSpec{},
+		expected: validation.ErrMissingField(validation.CurrentField),
+	}, {
+		name: "valid, build",
+		target: &ScheduleSpec{
+			Schedule: "0 * * * *",
+			Build: &Build{
+				FunctionRef: "my-function",
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{},
+				},
+			},
+			ContentType:       "text/plain",
+			ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "valid, container image and macro",
+		target: &ScheduleSpec{
+			Schedule: "@hourly",
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			Output:            "my-stream",
+			ContentType:       "application/json",
+			ConcurrencyPolicy: batchv1beta1.ReplaceConcurrent,
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid, missing schedule",
+		target: &ScheduleSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			ContentType:       "text/plain",
+			ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+		},
+		expected: validation.ErrMissingField("schedule"),
+	}, {
+		name: "invalid, schedule",
+		target: &ScheduleSpec{
+			Schedule: "* * *",
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			ContentType:       "text/plain",
+			ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+		},
+		expected: validation.ErrInvalidValue("* * *", "schedule"),
+	}, {
+		name: "invalid, build and container image",
+		target: &ScheduleSpec{
+			Schedule: "0 * * * *",
+			Build: &Build{
+				FunctionRef: "my-function",
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			ContentType:       "text/plain",
+			ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+		},
+		expected: validation.ErrMultipleOneOf("build", "template.containers[0].image"),
+	}, {
+		name: "no PORT env",
+		target: &ScheduleSpec{
+			Schedule: "0 * * * *",
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Image: "my-image",
+						Env: []corev1.EnvVar{
+							{Name: "PORT", Value: "8080"},
+						},
+					},
+				},
+			},
+			ContentType:       "text/plain",
+			ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+		},
+		expected: validation.ErrDisallowedFields("template.containers[0].env[0]", "PORT is not allowed"),
+	}, {
+		name: "invalid, concurrency policy",
+		target: &ScheduleSpec{
+			Schedule: "0 * * * *",
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			ContentType:       "text/plain",
+			ConcurrencyPolicy: "bogus",
+		},
+		expected: validation.ErrInvalidValue(batchv1beta1.ConcurrencyPolicy("bogus"), "concurrencyPolicy"),
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateScheduleSpec(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
@@ -40,4 +40,16 @@ func (s *DeployerSpec) Default() {
 	if s.IngressPolicy == "" {
 		s.IngressPolicy = IngressPolicyExternal
 	}
+	for i := range s.Streams {
+		s.Streams[i].Default()
+	}
+}
+
+func (b *StreamBinding) Default() {
+	if b.Alias == "" {
+		b.Alias = b.Stream
+	}
+	if b.Mode == "" {
+		b.Mode = StreamBindingModePublish
+	}
 }
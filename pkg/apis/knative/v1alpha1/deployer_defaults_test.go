@@ -70,6 +70,26 @@ func TestDeployerSpecDefault(t *testing.T) {
 			},
 			IngressPolicy: IngressPolicyExternal,
 		},
+	}, {
+		name: "streams",
+		in: &DeployerSpec{
+			Streams: []StreamBinding{
+				{Stream: "orders"},
+				{Stream: "payments", Alias: "in", Mode: StreamBindingModeSubscribe},
+			},
+		},
+		want: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{},
+				},
+			},
+			IngressPolicy: IngressPolicyExternal,
+			Streams: []StreamBinding{
+				{Stream: "orders", Alias: "orders", Mode: StreamBindingModePublish},
+				{Stream: "payments", Alias: "in", Mode: StreamBindingModeSubscribe},
+			},
+		},
 	}}
 
 	for _, test := range tests {
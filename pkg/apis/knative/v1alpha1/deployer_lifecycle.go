@@ -17,7 +17,10 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apis "github.com/projectriff/system/pkg/apis"
 	servingv1 "github.com/projectriff/system/pkg/apis/thirdparty/knative/serving/v1"
@@ -27,11 +30,14 @@ const (
 	DeployerConditionReady                                 = apis.ConditionReady
 	DeployerConditionConfigurationReady apis.ConditionType = "ConfigurationReady"
 	DeployerConditionRouteReady         apis.ConditionType = "RouteReady"
+	DeployerConditionRolloutReady       apis.ConditionType = "RolloutReady"
+	DeployerConditionStreamsReady       apis.ConditionType = "StreamsReady"
 )
 
 var deployerCondSet = apis.NewLivingConditionSet(
 	DeployerConditionConfigurationReady,
 	DeployerConditionRouteReady,
+	DeployerConditionStreamsReady,
 )
 
 func (ds *DeployerStatus) GetObservedGeneration() int64 {
@@ -72,6 +78,16 @@ func (ds *DeployerStatus) PropagateConfigurationStatus(kcs *servingv1.Configurat
 func (ds *DeployerStatus) PropagateRouteStatus(rs *servingv1.RouteStatus) {
 	ds.Address = rs.Address
 	ds.URL = rs.URL
+	ds.Traffic = nil
+	for _, t := range rs.Traffic {
+		ds.Traffic = append(ds.Traffic, TrafficTargetStatus{
+			Tag:            t.Tag,
+			RevisionName:   t.RevisionName,
+			LatestRevision: t.LatestRevision,
+			Percent:        t.Percent,
+			URL:            t.URL,
+		})
+	}
 
 	sc := rs.GetCondition(servingv1.RouteConditionReady)
 	if sc == nil {
@@ -86,3 +102,131 @@ func (ds *DeployerStatus) PropagateRouteStatus(rs *servingv1.RouteStatus) {
 		deployerCondSet.Manage(ds).MarkFalse(DeployerConditionRouteReady, sc.Reason, sc.Message)
 	}
 }
+
+func (ds *DeployerStatus) MarkRolloutNotRequired() {
+	deployerCondSet.Manage(ds).MarkFalse(DeployerConditionRolloutReady, "RolloutNotRequired", "Rollout is not required.")
+}
+
+func (ds *DeployerStatus) MarkRolloutWaiting(revision string) {
+	deployerCondSet.Manage(ds).MarkUnknown(DeployerConditionRolloutReady, "RolloutWaiting", "waiting for revision %q to become ready", revision)
+}
+
+func (ds *DeployerStatus) MarkRolloutProgressing(revision string, step, steps int, percent int64) {
+	deployerCondSet.Manage(ds).MarkUnknown(DeployerConditionRolloutReady, "RolloutProgressing", "step %d of %d, routing %d%% of traffic to revision %q", step+1, steps, percent, revision)
+}
+
+func (ds *DeployerStatus) MarkRolloutComplete() {
+	deployerCondSet.Manage(ds).MarkTrue(DeployerConditionRolloutReady)
+}
+
+func (ds *DeployerStatus) MarkRolledBack(revision, message string) {
+	deployerCondSet.Manage(ds).MarkFalse(DeployerConditionRolloutReady, "RolledBack", "revision %q was rolled back: %s", revision, message)
+}
+
+// ProgressRollout moves the rollout of the candidate revision forward given
+// the status of the candidate's ready condition at now. The step of a ready
+// candidate advances once its pause has elapsed, a failed candidate is rolled
+// back and otherwise the current step holds with its pause timer stopped. The
+// stable revision must be resolved before calling. The returned duration is
+// the time until the current step completes, or zero when no step is running.
+func (ds *DeployerStatus) ProgressRollout(rollout *Rollout, candidate string, ready corev1.ConditionStatus, message string, now time.Time) time.Duration {
+	rs := ds.Rollout
+	if candidate == "" || candidate == rs.StableRevisionName || candidate == rs.FailedRevisionName {
+		// nothing to roll out
+		rs.CandidateRevisionName = ""
+		rs.resetStep()
+		if candidate != rs.FailedRevisionName {
+			ds.MarkRolloutComplete()
+		}
+		return 0
+	}
+
+	if rs.CandidateRevisionName != candidate {
+		rs.CandidateRevisionName = candidate
+		rs.resetStep()
+	}
+
+	switch ready {
+	case corev1.ConditionTrue:
+		// keep rolling out
+	case corev1.ConditionFalse:
+		rs.FailedRevisionName = candidate
+		rs.CandidateRevisionName = ""
+		rs.resetStep()
+		ds.MarkRolledBack(candidate, message)
+		return 0
+	default:
+		// hold the current step and stop its pause until the candidate is
+		// ready again
+		if rs.StepStartTime != nil {
+			rs.StepElapsed = &metav1.Duration{Duration: rs.stepElapsed(now)}
+			rs.StepStartTime = nil
+		}
+		ds.MarkRolloutWaiting(candidate)
+		return 0
+	}
+
+	if rs.StepStartTime == nil {
+		// start or resume the current step
+		rs.StepStartTime = &metav1.Time{Time: now}
+	} else if int(rs.Step) < len(rollout.Steps) && rs.stepElapsed(now) >= rollout.Steps[rs.Step].Pause.Duration {
+		rs.Step++
+		rs.StepStartTime = &metav1.Time{Time: now}
+		rs.StepElapsed = nil
+	}
+
+	if int(rs.Step) >= len(rollout.Steps) {
+		rs.StableRevisionName = candidate
+		rs.CandidateRevisionName = ""
+		rs.resetStep()
+		ds.MarkRolloutComplete()
+		return 0
+	}
+
+	step := rollout.Steps[rs.Step]
+	ds.MarkRolloutProgressing(candidate, int(rs.Step), len(rollout.Steps), step.Percent)
+	remaining := step.Pause.Duration - rs.stepElapsed(now)
+	if remaining <= 0 {
+		// advance to the next step promptly
+		remaining = time.Second
+	}
+	return remaining
+}
+
+func (ds *DeployerStatus) MarkStreamsReady() {
+	deployerCondSet.Manage(ds).MarkTrue(DeployerConditionStreamsReady)
+}
+
+func (ds *DeployerStatus) MarkStreamsNotReady(message string) {
+	deployerCondSet.Manage(ds).MarkFalse(DeployerConditionStreamsReady, "StreamNotReady", message)
+}
+
+// CandidatePercent is the percent of traffic the current step routes to the
+// candidate revision. No traffic is routed to a candidate that has yet to
+// start its first step.
+func (rs *RolloutStatus) CandidatePercent(rollout *Rollout) int64 {
+	if rs.CandidateRevisionName == "" || int(rs.Step) >= len(rollout.Steps) {
+		return 0
+	}
+	if rs.Step == 0 && rs.StepStartTime == nil && rs.StepElapsed == nil {
+		return 0
+	}
+	return rollout.Steps[rs.Step].Percent
+}
+
+func (rs *RolloutStatus) resetStep() {
+	rs.Step = 0
+	rs.StepStartTime = nil
+	rs.StepElapsed = nil
+}
+
+func (rs *RolloutStatus) stepElapsed(now time.Time) time.Duration {
+	var elapsed time.Duration
+	if rs.StepElapsed != nil {
+		elapsed = rs.StepElapsed.Duration
+	}
+	if rs.StepStartTime != nil {
+		elapsed += now.Sub(rs.StepStartTime.Time)
+	}
+	return elapsed
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apis "github.com/projectriff/system/pkg/apis"
+)
+
+func TestDeployerStatusProgressRollout(t *testing.T) {
+	now := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *metav1.Time {
+		return &metav1.Time{Time: now.Add(d)}
+	}
+	elapsed := func(d time.Duration) *metav1.Duration {
+		return &metav1.Duration{Duration: d}
+	}
+	rollout := &Rollout{
+		Steps: []RolloutStep{
+			{Percent: 20, Pause: metav1.Duration{Duration: 5 * time.Minute}},
+			{Percent: 50, Pause: metav1.Duration{Duration: 10 * time.Minute}},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		in            *RolloutStatus
+		candidate     string
+		ready         corev1.ConditionStatus
+		want          *RolloutStatus
+		wantRequeue   time.Duration
+		wantPercent   int64
+		wantCondition corev1.ConditionStatus
+		wantReason    string
+	}{{
+		name:          "no candidate",
+		in:            &RolloutStatus{StableRevisionName: "rev-1"},
+		candidate:     "rev-1",
+		ready:         corev1.ConditionTrue,
+		want:          &RolloutStatus{StableRevisionName: "rev-1"},
+		wantCondition: corev1.ConditionTrue,
+	}, {
+		name:          "failed candidate is not retried",
+		in:            &RolloutStatus{StableRevisionName: "rev-1", FailedRevisionName: "rev-2"},
+		candidate:     "rev-2",
+		ready:         corev1.ConditionTrue,
+		want:          &RolloutStatus{StableRevisionName: "rev-1", FailedRevisionName: "rev-2"},
+		wantCondition: corev1.ConditionUnknown,
+	}, {
+		name:          "wait for new candidate",
+		in:            &RolloutStatus{StableRevisionName: "rev-1"},
+		candidate:     "rev-2",
+		ready:         corev1.ConditionUnknown,
+		want:          &RolloutStatus{StableRevisionName: "rev-1", CandidateRevisionName: "rev-2"},
+		wantCondition: corev1.ConditionUnknown,
+		wantReason:    "RolloutWaiting",
+	}, {
+		name:      "start first step",
+		in:        &RolloutStatus{StableRevisionName: "rev-1", CandidateRevisionName: "rev-2"},
+		candidate: "rev-2",
+		ready:     corev1.ConditionTrue,
+		want: &RolloutStatus{
+			StableRevisionName:    "rev-1",
+			CandidateRevisionName: "rev-2",
+			StepStartTime:         at(0),
+		},
+		wantRequeue:   5 * time.Minute,
+		wantPercent:   20,
+		wantCondition: corev1.ConditionUnknown,
+		wantReason:    "RolloutProgressing",
+	}, {
+		name: "hold step until pause elapses",
+		in: &RolloutStatus{
+			StableRevisionName:    "rev-1",
+			CandidateRevisionName: "rev-2",
+			StepStartTime:         at(-2 * time.Minute),
+		},
+		candidate: "rev-2",
+		ready:     corev1.ConditionTrue,
+		want: &RolloutStatus{
+			StableRevisionName:    "rev-1",
+			CandidateRevisionName: "rev-2",
+			StepStartTime:         at(-2 * time.Minute),
+		},
+		wantRequeue:   3 * time.Minute,
+		wantPercent:   20,
+		wantCondition: corev1.ConditionUnknown,
+		wantReason:    "RolloutProgressing",
+	}, {
+		name: "advance after pause",
+		in: &RolloutStatus{
+			StableRevisionName:    "rev-1",
+			CandidateRevisionName: "rev-2",
+			StepStartTime:         at(-5 * time.Minute),
+		},
+		candidate: "rev-2",
+		ready:     corev1.ConditionTrue,
+		want: &RolloutStatus{
+			StableRevisionName:    "rev-1",
+			CandidateRevisionName: "rev-2",
+			Step:                  1,
+			StepStartTime:         at(0),
+		},
+		wantRequeue:   10 * time.Minute,
+		wantPercent:   50,
+		wantCondition: corev1.ConditionUnknown,
+		wantReason:    "RolloutProgressing",
+	}, {
+		name: "stop pause while not ready",
+		in: &RolloutStatus{
+			StableRevisionName:    "rev-1",
+			CandidateRevisionName: "rev-2",
+			Step:                  1,
+			StepStartTime:         at(-4 * time.Minute),
+			StepElapsed:           elapsed(time.Minute),
+		},
+		candidate: "rev-2",
+		ready:     corev1.ConditionUnknown,
+		want: &RolloutStatus{
+			StableRevisionName:    "rev-1",
+			CandidateRevisionName: "rev-2",
+			Step:                  1,
+			StepElapsed:           elapsed(5 * time.Minute),
+		},
+		wantPercent:   50,
+		wantCondition: corev1.ConditionUnknown,
+		wantReason:    "RolloutWaiting",
+	}, {
+		name: "resume pause when ready again",
+		in: &RolloutStatus{
+			StableRevisionName:    "rev-1",
+			CandidateRevisionName: "rev-2",
+			Step:                  1,
+			StepElapsed:           elapsed(5 * time.Minute),
+		},
+		candidate: "rev-2",
+		ready:     corev1.ConditionTrue,
+		want: &RolloutStatus{
+			StableRevisionName:    "rev-1",
+			CandidateRevisionName: "rev-2",
+			Step:                  1,
+			StepStartTime:         at(0),
+			StepElapsed:           elapsed(5 * time.Minute),
+		},
+		wantRequeue:   5 * time.Minute,
+		wantPercent:   50,
+		wantCondition: corev1.ConditionUnknown,
+		wantReason:    "RolloutProgressing",
+	}, {
+		name: "complete after last step",
+		in: &RolloutStatus{
+			StableRevisionName:    "rev-1",
+			CandidateRevisionName: "rev-2",
+			Step:                  1,
+			StepStartTime:         at(-6 * time.Minute),
+			StepElapsed:           elapsed(5 * time.Minute),
+		},
+		candidate:     "rev-2",
+		ready:         corev1.ConditionTrue,
+		want:          &RolloutStatus{StableRevisionName: "rev-2"},
+		wantCondition: corev1.ConditionTrue,
+	}, {
+		name: "roll back failed candidate",
+		in: &RolloutStatus{
+			StableRevisionName:    "rev-1",
+			CandidateRevisionName: "rev-2",
+			Step:                  1,
+			StepStartTime:         at(-time.Minute),
+		},
+		candidate:     "rev-2",
+		ready:         corev1.ConditionFalse,
+		want:          &RolloutStatus{StableRevisionName: "rev-1", FailedRevisionName: "rev-2"},
+		wantCondition: corev1.ConditionFalse,
+		wantReason:    "RolledBack",
+	}, {
+		name: "restart for newer candidate",
+		in: &RolloutStatus{
+			StableRevisionName:    "rev-1",
+			CandidateRevisionName: "rev-2",
+			Step:                  1,
+			StepStartTime:         at(-time.Minute),
+		},
+		candidate: "rev-3",
+		ready:     corev1.ConditionTrue,
+		want: &RolloutStatus{
+			StableRevisionName:    "rev-1",
+			CandidateRevisionName: "rev-3",
+			StepStartTime:         at(0),
+		},
+		wantRequeue:   5 * time.Minute,
+		wantPercent:   20,
+		wantCondition: corev1.ConditionUnknown,
+		wantReason:    "RolloutProgressing",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status := &DeployerStatus{Rollout: test.in}
+			status.InitializeConditions()
+			requeue := status.ProgressRollout(rollout, test.candidate, test.ready, "", now)
+			if diff := cmp.Diff(test.want, status.Rollout); diff != "" {
+				t.Errorf("ProgressRollout() (-want, +got) = %v", diff)
+			}
+			if requeue != test.wantRequeue {
+				t.Errorf("ProgressRollout() requeue = %v, want %v", requeue, test.wantRequeue)
+			}
+			if percent := status.Rollout.CandidatePercent(rollout); percent != test.wantPercent {
+				t.Errorf("CandidatePercent() = %d, want %d", percent, test.wantPercent)
+			}
+			cond := status.GetCondition(DeployerConditionRolloutReady)
+			if cond == nil {
+				cond = &apis.Condition{Status: corev1.ConditionUnknown}
+			}
+			if cond.Status != test.wantCondition || cond.Reason != test.wantReason {
+				t.Errorf("RolloutReady = %s/%s, want %s/%s", cond.Status, cond.Reason, test.wantCondition, test.wantReason)
+			}
+		})
+	}
+}
@@ -51,6 +51,20 @@ type DeployerSpec struct {
 	// IngressPolicy defines whether the workload should be reachable from
 	// outside the cluster
 	IngressPolicy IngressPolicy `json:"ingressPolicy,omitempty"`
+
+	// Traffic splits requests across revisions of the deployer. Defaults to
+	// routing all traffic to the latest ready revision.
+	Traffic []TrafficTarget `json:"traffic,omitempty"`
+
+	// Rollout progressively shifts traffic to new revisions while they
+	// remain ready. When not specified, all traffic shifts to a new revision
+	// once it is ready. Not allowed with explicit traffic targets.
+	Rollout *Rollout `json:"rollout,omitempty"`
+
+	// Streams binds streams to the workload. The gateway and topic of each
+	// stream are exposed to the first container as environment variables
+	// and as files.
+	Streams []StreamBinding `json:"streams,omitempty"`
 }
 
 // IngressPolicy describes whether the container should be exposed via
@@ -64,6 +78,63 @@ const (
 	IngressPolicyExternal     IngressPolicy = "External"
 )
 
+// LatestRevisionName references the latest ready revision of the deployer in
+// a traffic target.
+const LatestRevisionName = "latest"
+
+type TrafficTarget struct {
+	// RevisionName of the revision to route to, or `latest` for the latest
+	// ready revision of the deployer.
+	RevisionName string `json:"revisionName"`
+
+	// Percent of traffic routed to the revision. The percentages of all
+	// traffic targets must sum to 100.
+	Percent *int64 `json:"percent,omitempty"`
+
+	// Tag exposes a dedicated url for routing exclusively to the revision.
+	Tag string `json:"tag,omitempty"`
+}
+
+type Rollout struct {
+	// Steps of traffic shifted to a new revision. After the final step all
+	// traffic is routed to the new revision.
+	Steps []RolloutStep `json:"steps"`
+}
+
+type RolloutStep struct {
+	// Percent of traffic routed to the new revision during this step
+	Percent int64 `json:"percent"`
+
+	// Pause before advancing to the next step, e.g. `5m`
+	Pause metav1.Duration `json:"pause,omitempty"`
+}
+
+// StreamBinding exposes a stream to the workload under an alias. The
+// container receives the environment variables STREAM_<ALIAS>_GATEWAY and
+// STREAM_<ALIAS>_TOPIC, with the alias upper cased and dashes replaced by
+// underscores, and the files gateway and topic in
+// /var/riff/streams/<alias>. Subscribers also receive
+// STREAM_<ALIAS>_GROUP naming their consumer group, knative-deployer-<name>.
+type StreamBinding struct {
+	// Stream name, from this namespace, to be bound to the workload
+	Stream string `json:"stream"`
+
+	// Alias exposes the stream under another name within the workload.
+	// Defaults to the stream name.
+	Alias string `json:"alias,omitempty"`
+
+	// Mode is how the workload uses the stream, either Publish or Subscribe.
+	// Defaults to Publish.
+	Mode StreamBindingMode `json:"mode,omitempty"`
+}
+
+type StreamBindingMode string
+
+const (
+	StreamBindingModePublish   StreamBindingMode = "Publish"
+	StreamBindingModeSubscribe StreamBindingMode = "Subscribe"
+)
+
 // DeployerStatus defines the observed state of Deployer
 type DeployerStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -87,6 +158,54 @@ type DeployerStatus struct {
 
 	// URL to target this deployer publicly
 	URL string `json:"url,omitempty"`
+
+	// Traffic is the observed distribution of requests across revisions,
+	// including the url for each tagged target
+	Traffic []TrafficTargetStatus `json:"traffic,omitempty"`
+
+	// Rollout is the progress of shifting traffic to a new revision
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+}
+
+type RolloutStatus struct {
+	// StableRevisionName is the most recent revision to complete a rollout
+	StableRevisionName string `json:"stableRevisionName,omitempty"`
+
+	// CandidateRevisionName is the revision being rolled out
+	CandidateRevisionName string `json:"candidateRevisionName,omitempty"`
+
+	// FailedRevisionName is the most recent revision to be rolled back. It
+	// will not be rolled out again.
+	FailedRevisionName string `json:"failedRevisionName,omitempty"`
+
+	// Step is the index of the rollout step currently applied
+	Step int32 `json:"step,omitempty"`
+
+	// StepStartTime is when the candidate revision last became ready during
+	// the current step. It is cleared while the revision is not ready.
+	StepStartTime *metav1.Time `json:"stepStartTime,omitempty"`
+
+	// StepElapsed is how long the current step ran before StepStartTime.
+	// The pause of a step only counts time while the revision is ready.
+	StepElapsed *metav1.Duration `json:"stepElapsed,omitempty"`
+}
+
+type TrafficTargetStatus struct {
+	// Tag of the traffic target
+	Tag string `json:"tag,omitempty"`
+
+	// RevisionName of the revision traffic is routed to
+	RevisionName string `json:"revisionName,omitempty"`
+
+	// LatestRevision is true when the target tracks the latest ready
+	// revision
+	LatestRevision *bool `json:"latestRevision,omitempty"`
+
+	// Percent of traffic routed to the revision
+	Percent *int64 `json:"percent,omitempty"`
+
+	// URL to target this revision exclusively, only set for tagged targets
+	URL string `json:"url,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -23,6 +23,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/projectriff/system/pkg/validation"
@@ -88,6 +90,82 @@ func (s DeployerSpec) Validate() validation.FieldErrors {
 		errs = errs.Also(validation.ErrInvalidValue(s.IngressPolicy, "ingressPolicy"))
 	}
 
+	if len(s.Traffic) != 0 {
+		var total int64
+		tags := map[string]int{}
+		for i, t := range s.Traffic {
+			errs = errs.Also(t.Validate().ViaFieldIndex("traffic", i))
+			if t.Percent != nil {
+				total += *t.Percent
+			}
+			if t.Tag == "" {
+				continue
+			}
+			if _, ok := tags[t.Tag]; ok {
+				errs = errs.Also(validation.FieldErrors{
+					field.Duplicate(field.NewPath("traffic").Index(i).Child("tag"), t.Tag),
+				})
+			}
+			tags[t.Tag] = i
+		}
+		if total != 100 {
+			errs = errs.Also(validation.FieldErrors{
+				field.Invalid(field.NewPath("traffic"), total, "traffic percentages must sum to 100"),
+			})
+		}
+	}
+
+	if s.Rollout != nil {
+		if len(s.Traffic) != 0 {
+			errs = errs.Also(validation.ErrMultipleOneOf("traffic", "rollout"))
+		} else {
+			errs = errs.Also(s.Rollout.Validate().ViaField("rollout"))
+		}
+	}
+
+	aliases := map[string]bool{}
+	for i, binding := range s.Streams {
+		errs = errs.Also(binding.Validate().ViaFieldIndex("streams", i))
+		if aliases[binding.Alias] {
+			errs = errs.Also(validation.ErrInvalidValue(binding.Alias, "alias").ViaFieldIndex("streams", i))
+		}
+		aliases[binding.Alias] = true
+	}
+
+	return errs
+}
+
+func (t TrafficTarget) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if t.RevisionName == "" {
+		errs = errs.Also(validation.ErrMissingField("revisionName"))
+	}
+	if t.Percent != nil && (*t.Percent < 0 || *t.Percent > 100) {
+		errs = errs.Also(validation.ErrInvalidValue(*t.Percent, "percent"))
+	}
+	if t.Tag != "" && len(utilvalidation.IsDNS1123Label(t.Tag)) != 0 {
+		errs = errs.Also(validation.ErrInvalidValue(t.Tag, "tag"))
+	}
+
+	return errs
+}
+
+func (b StreamBinding) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if b.Stream == "" {
+		errs = errs.Also(validation.ErrMissingField("stream"))
+	}
+	if b.Alias == "" {
+		errs = errs.Also(validation.ErrMissingField("alias"))
+	} else if len(utilvalidation.IsDNS1123Label(b.Alias)) != 0 {
+		errs = errs.Also(validation.ErrInvalidValue(b.Alias, "alias"))
+	}
+	if b.Mode != StreamBindingModePublish && b.Mode != StreamBindingModeSubscribe {
+		errs = errs.Also(validation.ErrInvalidValue(b.Mode, "mode"))
+	}
+
 	return errs
 }
 
@@ -100,3 +178,24 @@ func filterInvalidVolumes(volumes []corev1.Volume) []corev1.Volume {
 	// TODO remove unsupported fields
 	return volumes
 }
+
+func (r *Rollout) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if len(r.Steps) == 0 {
+		errs = errs.Also(validation.ErrMissingField("steps"))
+	}
+	var previous int64
+	for i, step := range r.Steps {
+		// each step must route more traffic to the new revision than the last
+		if step.Percent <= previous || step.Percent > 100 {
+			errs = errs.Also(validation.ErrInvalidValue(step.Percent, "percent").ViaFieldIndex("steps", i))
+		}
+		if step.Pause.Duration < 0 {
+			errs = errs.Also(validation.ErrInvalidValue(step.Pause.Duration.String(), "pause").ViaFieldIndex("steps", i))
+		}
+		previous = step.Percent
+	}
+
+	return errs
+}
@@ -18,9 +18,12 @@ package v1alpha1
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/projectriff/system/pkg/validation"
 )
@@ -116,6 +119,200 @@ func TestValidateDeployerSpec(t *testing.T) {
 			IngressPolicy: "bogus",
 		},
 		expected: validation.ErrInvalidValue(IngressPolicy("bogus"), "ingressPolicy"),
+	}, {
+		name: "valid, traffic",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			Traffic: []TrafficTarget{
+				{RevisionName: LatestRevisionName, Percent: int64Ptr(10), Tag: "canary"},
+				{RevisionName: "my-deployer-deployer-abcde-1", Percent: int64Ptr(90), Tag: "stable"},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid, traffic target",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			Traffic: []TrafficTarget{
+				{Percent: int64Ptr(100), Tag: "Not_A_Tag"},
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrMissingField("traffic[0].revisionName"),
+			validation.ErrInvalidValue("Not_A_Tag", "traffic[0].tag"),
+		),
+	}, {
+		name: "invalid, traffic percent",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			Traffic: []TrafficTarget{
+				{RevisionName: LatestRevisionName, Percent: int64Ptr(101)},
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrInvalidValue(int64(101), "traffic[0].percent"),
+			validation.FieldErrors{
+				field.Invalid(field.NewPath("traffic"), int64(101), "traffic percentages must sum to 100"),
+			},
+		),
+	}, {
+		name: "invalid, traffic percent sum",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			Traffic: []TrafficTarget{
+				{RevisionName: LatestRevisionName, Percent: int64Ptr(50)},
+				{RevisionName: "my-deployer-deployer-abcde-1", Percent: int64Ptr(40)},
+			},
+		},
+		expected: validation.FieldErrors{
+			field.Invalid(field.NewPath("traffic"), int64(90), "traffic percentages must sum to 100"),
+		},
+	}, {
+		name: "invalid, duplicate traffic tag",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			Traffic: []TrafficTarget{
+				{RevisionName: LatestRevisionName, Percent: int64Ptr(50), Tag: "blue"},
+				{RevisionName: "my-deployer-deployer-abcde-1", Percent: int64Ptr(50), Tag: "blue"},
+			},
+		},
+		expected: validation.FieldErrors{
+			field.Duplicate(field.NewPath("traffic").Index(1).Child("tag"), "blue"),
+		},
+	}, {
+		name: "valid, rollout",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			Rollout: &Rollout{
+				Steps: []RolloutStep{
+					{Percent: 10, Pause: metav1.Duration{Duration: 5 * time.Minute}},
+					{Percent: 50, Pause: metav1.Duration{Duration: 5 * time.Minute}},
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid, rollout without steps",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			Rollout: &Rollout{},
+		},
+		expected: validation.ErrMissingField("rollout.steps"),
+	}, {
+		name: "invalid, rollout steps",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			Rollout: &Rollout{
+				Steps: []RolloutStep{
+					{Percent: 50},
+					{Percent: 20},
+					{Percent: 101, Pause: metav1.Duration{Duration: -time.Minute}},
+				},
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrInvalidValue(int64(20), "rollout.steps[1].percent"),
+			validation.ErrInvalidValue(int64(101), "rollout.steps[2].percent"),
+			validation.ErrInvalidValue("-1m0s", "rollout.steps[2].pause"),
+		),
+	}, {
+		name: "invalid, rollout and traffic",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			Traffic: []TrafficTarget{
+				{RevisionName: LatestRevisionName, Percent: int64Ptr(100)},
+			},
+			Rollout: &Rollout{
+				Steps: []RolloutStep{
+					{Percent: 50},
+				},
+			},
+		},
+		expected: validation.ErrMultipleOneOf("traffic", "rollout"),
+	}, {
+		name: "valid, streams",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			Streams: []StreamBinding{
+				{Stream: "orders", Alias: "orders", Mode: StreamBindingModePublish},
+				{Stream: "payments", Alias: "in", Mode: StreamBindingModeSubscribe},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid, streams",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			Streams: []StreamBinding{
+				{},
+				{Stream: "orders", Alias: "Not_A_Label", Mode: "bogus"},
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrMissingField("streams[0].stream"),
+			validation.ErrMissingField("streams[0].alias"),
+			validation.ErrInvalidValue(StreamBindingMode(""), "streams[0].mode"),
+			validation.ErrInvalidValue("Not_A_Label", "streams[1].alias"),
+			validation.ErrInvalidValue(StreamBindingMode("bogus"), "streams[1].mode"),
+		),
+	}, {
+		name: "invalid, duplicate stream alias",
+		target: &DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Image: "my-image"},
+				},
+			},
+			Streams: []StreamBinding{
+				{Stream: "orders", Alias: "orders", Mode: StreamBindingModePublish},
+				{Stream: "other-orders", Alias: "orders", Mode: StreamBindingModeSubscribe},
+			},
+		},
+		expected: validation.ErrInvalidValue("orders", "streams[1].alias"),
 	}} {
 		t.Run(c.name, func(t *testing.T) {
 			actual := c.target.Validate()
@@ -125,3 +322,7 @@ func TestValidateDeployerSpec(t *testing.T) {
 		})
 	}
 }
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/projectriff/system/pkg/apis"
+	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+var (
+	HTTPSourceLabelKey = GroupVersion.Group + "/http-source"
+)
+
+var (
+	_ apis.Resource = (*HTTPSource)(nil)
+)
+
+// HTTPSourceSpec defines the desired state of HTTPSource
+type HTTPSourceSpec struct {
+	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// Stream name, from this namespace, to publish the body of each POST
+	// request to. Requests are rejected unless their Content-Type is
+	// compatible with the stream's content type.
+	Stream string `json:"stream"`
+
+	// IngressPolicy defines whether the source should be reachable from
+	// outside the cluster. Defaults to ClusterLocal.
+	// +optional
+	IngressPolicy IngressPolicy `json:"ingressPolicy,omitempty"`
+
+	// Ingress customizes how the source is exposed outside the cluster, the
+	// same way as for Deployers. The host defaults to the domain configured
+	// for the core runtime.
+	// +optional
+	Ingress *corev1alpha1.Ingress `json:"ingress,omitempty"`
+}
+
+// IngressPolicy describes whether the source should be exposed via an
+// ingress outside the cluster, or only reachable from within the cluster.
+// Allowed values are IngressPolicyClusterLocal and IngressPolicyExternal,
+// matching the values used by Deployers.
+type IngressPolicy string
+
+const (
+	IngressPolicyClusterLocal IngressPolicy = "ClusterLocal"
+	IngressPolicyExternal     IngressPolicy = "External"
+)
+
+// HTTPSourceStatus defines the observed state of HTTPSource
+type HTTPSourceStatus struct {
+	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	apis.Status `json:",inline"`
+
+	DeploymentName string `json:"deploymentName,omitempty"`
+	ServiceName    string `json:"serviceName,omitempty"`
+	IngressName    string `json:"ingressName,omitempty"`
+
+	// Address to post messages to from within the cluster
+	Address *apis.Addressable `json:"address,omitempty"`
+
+	// URL to post messages to from outside the cluster
+	URL string `json:"url,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:categories="riff"
+// +kubebuilder:printcolumn:name="Stream",type=string,JSONPath=`.spec.stream`
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.status.url`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+// +genclient
+
+// HTTPSource is the Schema for the httpsources API. It accepts messages
+// over HTTP and publishes them to a stream, so that systems outside of riff
+// can produce events without speaking to the stream gateway.
+type HTTPSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HTTPSourceSpec   `json:"spec,omitempty"`
+	Status HTTPSourceStatus `json:"status,omitempty"`
+}
+
+func (*HTTPSource) GetGroupVersionKind() schema.GroupVersionKind {
+	return SchemeGroupVersion.WithKind("HTTPSource")
+}
+
+func (s *HTTPSource) GetStatus() apis.ResourceStatus {
+	return &s.Status
+}
+
+// +kubebuilder:object:root=true
+
+// HTTPSourceList contains a list of HTTPSource
+type HTTPSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HTTPSource `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HTTPSource{}, &HTTPSourceList{})
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
+	"github.com/projectriff/system/pkg/validation"
+)
+
+func TestValidateHTTPSource(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *HTTPSource
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &HTTPSource{},
+		expected: validation.ErrMissingField("spec"),
+	}, {
+		name: "valid",
+		target: &HTTPSource{
+			Spec: HTTPSourceSpec{
+				Stream:        "my-stream",
+				IngressPolicy: IngressPolicyClusterLocal,
+			},
+		},
+		expected: validation.FieldErrors{},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateHTTPSource(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
+
+func TestValidateHTTPSourceSpec(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *HTTPSourceSpec
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &HTTPSourceSpec{},
+		expected: validation.ErrMissingField(validation.CurrentField),
+	}, {
+		name: "valid, external",
+		target: &HTTPSourceSpec{
+			Stream:        "my-stream",
+			IngressPolicy: IngressPolicyExternal,
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "requires stream",
+		target: &HTTPSourceSpec{
+			IngressPolicy: IngressPolicyClusterLocal,
+		},
+		expected: validation.ErrMissingField("stream"),
+	}, {
+		name: "invalid ingress policy",
+		target: &HTTPSourceSpec{
+			Stream:        "my-stream",
+			IngressPolicy: IngressPolicy("bogus"),
+		},
+		expected: validation.ErrInvalidValue(IngressPolicy("bogus"), "ingressPolicy"),
+	}, {
+		name: "valid ingress",
+		target: &HTTPSourceSpec{
+			Stream:        "my-stream",
+			IngressPolicy: IngressPolicyExternal,
+			Ingress: &corev1alpha1.Ingress{
+				Host:  "events.example.com",
+				Class: "contour",
+				TLS: &corev1alpha1.IngressTLS{
+					IssuerRef: &corev1alpha1.IssuerReference{Name: "letsencrypt"},
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid ingress",
+		target: &HTTPSourceSpec{
+			Stream:        "my-stream",
+			IngressPolicy: IngressPolicyExternal,
+			Ingress: &corev1alpha1.Ingress{
+				Path: "events",
+			},
+		},
+		expected: validation.ErrInvalidValue("events", "ingress.path"),
+	}, {
+		name: "ingress for cluster local",
+		target: &HTTPSourceSpec{
+			Stream:        "my-stream",
+			IngressPolicy: IngressPolicyClusterLocal,
+			Ingress:       &corev1alpha1.Ingress{},
+		},
+		expected: validation.ErrDisallowedFields("ingress", "not allowed for ClusterLocal ingress policy"),
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateHTTPSourceSpec(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/projectriff/system/pkg/apis"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+var (
+	InMemoryProviderLabelKey            = GroupVersion.Group + "/inmemory-provider"             // Identifies all resources originating from a provider
+	InMemoryProviderGatewayLabelKey     = GroupVersion.Group + "/inmemory-provider-gateway"     // Used as a selector
+	InMemoryProviderProvisionerLabelKey = GroupVersion.Group + "/inmemory-provider-provisioner" // Used as a selector
+	InMemoryProvisioner                 = "inmemory-provisioner"
+)
+
+var (
+	_ apis.Resource = (*InMemoryProvider)(nil)
+)
+
+// InMemoryProviderSpec defines the desired state of InMemoryProvider. The
+// gateway keeps messages in memory, they are lost when the gateway restarts
+// unless they are also stored on disk.
+type InMemoryProviderSpec struct {
+	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// Storage configures where the gateway keeps messages and consumer
+	// positions. Defaults to Memory.
+	// +optional
+	Storage *InMemoryStorage `json:"storage,omitempty"`
+
+	// AllowedNamespaces may use the provider for their streams, along with
+	// the provider's own namespace. Defaults to the provider's namespace only.
+	// +optional
+	AllowedNamespaces *AllowedNamespaces `json:"allowedNamespaces,omitempty"`
+}
+
+// InMemoryStorage configures where the gateway keeps messages and consumer
+// positions. On disk they survive a restart of the gateway.
+type InMemoryStorage struct {
+	// Type of storage, either Memory or Disk. Defaults to Memory.
+	// +optional
+	Type InMemoryStorageType `json:"type,omitempty"`
+
+	// Disk holding messages and consumer positions, required when the type
+	// is Disk.
+	// +optional
+	Disk *InMemoryDiskStorage `json:"disk,omitempty"`
+}
+
+// InMemoryStorageType describes the kind of storage used by the gateway.
+type InMemoryStorageType string
+
+const (
+	// InMemoryStorageTypeMemory keeps messages within the gateway process
+	InMemoryStorageTypeMemory InMemoryStorageType = "Memory"
+	// InMemoryStorageTypeDisk writes messages through to a volume
+	InMemoryStorageTypeDisk InMemoryStorageType = "Disk"
+)
+
+// InMemoryDiskStorage locates the volume mounted by the gateway. Only one
+// gateway runs at a time, so the claim may be ReadWriteOnce.
+type InMemoryDiskStorage struct {
+	// ClaimName of a PersistentVolumeClaim in the provider's namespace
+	ClaimName string `json:"claimName"`
+}
+
+// InMemoryProviderStatus defines the observed state of InMemoryProvider
+type InMemoryProviderStatus struct {
+	ProviderStatus `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:categories="riff"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+// +genclient
+
+// InMemoryProvider is the Schema for the providers API. It runs a
+// self-contained gateway without a broker, for development and tests.
+type InMemoryProvider struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   InMemoryProviderSpec   `json:"spec,omitempty"`
+	Status InMemoryProviderStatus `json:"status,omitempty"`
+}
+
+func (*InMemoryProvider) GetGroupVersionKind() schema.GroupVersionKind {
+	return SchemeGroupVersion.WithKind("InMemoryProvider")
+}
+
+func (p *InMemoryProvider) GetStatus() apis.ResourceStatus {
+	return &p.Status
+}
+
+// +kubebuilder:object:root=true
+
+// InMemoryProviderList contains a list of InMemoryProvider
+type InMemoryProviderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []InMemoryProvider `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&InMemoryProvider{}, &InMemoryProviderList{})
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/projectriff/system/pkg/validation"
+)
+
+// +kubebuilder:webhook:path=/validate-streaming-projectriff-io-v1alpha1-inmemoryprovider,mutating=false,failurePolicy=fail,groups=streaming.projectriff.io,resources=inmemoryproviders,verbs=create;update,versions=v1alpha1,name=inmemoryproviders.streaming.projectriff.io
+
+var (
+	_ webhook.Validator         = &InMemoryProvider{}
+	_ validation.FieldValidator = &InMemoryProvider{}
+)
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *InMemoryProvider) ValidateCreate() error {
+	return r.Validate().ToAggregate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *InMemoryProvider) ValidateUpdate(old runtime.Object) error {
+	// TODO check for immutable fields
+	return r.Validate().ToAggregate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *InMemoryProvider) ValidateDelete() error {
+	return nil
+}
+
+func (r *InMemoryProvider) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	errs = errs.Also(r.Spec.Validate().ViaField("spec"))
+
+	return errs
+}
+
+func (s *InMemoryProviderSpec) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if s.Storage != nil {
+		errs = errs.Also(s.Storage.Validate().ViaField("storage"))
+	}
+
+	if s.AllowedNamespaces != nil {
+		errs = errs.Also(s.AllowedNamespaces.Validate().ViaField("allowedNamespaces"))
+	}
+
+	return errs
+}
+
+func (s *InMemoryStorage) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	switch s.Type {
+	case InMemoryStorageTypeMemory:
+		if s.Disk != nil {
+			errs = errs.Also(validation.ErrDisallowedFields("disk", "only allowed for Disk storage"))
+		}
+	case InMemoryStorageTypeDisk:
+		if s.Disk == nil {
+			errs = errs.Also(validation.ErrMissingField("disk"))
+		} else if s.Disk.ClaimName == "" {
+			errs = errs.Also(validation.ErrMissingField("disk.claimName"))
+		}
+	default:
+		errs = errs.Also(validation.ErrInvalidValue(s.Type, "type"))
+	}
+
+	return errs
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/projectriff/system/pkg/validation"
+)
+
+func TestValidateInMemoryProvider(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *InMemoryProvider
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &InMemoryProvider{},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "valid",
+		target: &InMemoryProvider{
+			Spec: InMemoryProviderSpec{
+				Storage: &InMemoryStorage{
+					Type: InMemoryStorageTypeMemory,
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateInMemoryProvider(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
+
+func TestValidateInMemoryProviderSpec(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *InMemoryProviderSpec
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &InMemoryProviderSpec{},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "disk storage",
+		target: &InMemoryProviderSpec{
+			Storage: &InMemoryStorage{
+				Type: InMemoryStorageTypeDisk,
+				Disk: &InMemoryDiskStorage{
+					ClaimName: "streams",
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "missing disk",
+		target: &InMemoryProviderSpec{
+			Storage: &InMemoryStorage{
+				Type: InMemoryStorageTypeDisk,
+			},
+		},
+		expected: validation.ErrMissingField("storage.disk"),
+	}, {
+		name: "missing claim name",
+		target: &InMemoryProviderSpec{
+			Storage: &InMemoryStorage{
+				Type: InMemoryStorageTypeDisk,
+				Disk: &InMemoryDiskStorage{},
+			},
+		},
+		expected: validation.ErrMissingField("storage.disk.claimName"),
+	}, {
+		name: "disk for memory storage",
+		target: &InMemoryProviderSpec{
+			Storage: &InMemoryStorage{
+				Type: InMemoryStorageTypeMemory,
+				Disk: &InMemoryDiskStorage{
+					ClaimName: "streams",
+				},
+			},
+		},
+		expected: validation.ErrDisallowedFields("storage.disk", "only allowed for Disk storage"),
+	}, {
+		name: "invalid storage type",
+		target: &InMemoryProviderSpec{
+			Storage: &InMemoryStorage{
+				Type: "Redis",
+			},
+		},
+		expected: validation.ErrInvalidValue(InMemoryStorageType("Redis"), "storage.type"),
+	}, {
+		name: "allowed namespaces",
+		target: &InMemoryProviderSpec{
+			AllowedNamespaces: &AllowedNamespaces{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"env": "dev"},
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid allowed namespaces",
+		target: &InMemoryProviderSpec{
+			AllowedNamespaces: &AllowedNamespaces{
+				Names: []string{"dev", ""},
+			},
+		},
+		expected: validation.ErrInvalidArrayValue("", "allowedNamespaces.names", 1),
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateInMemoryProviderSpec(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
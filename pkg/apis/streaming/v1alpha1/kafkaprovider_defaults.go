@@ -27,4 +27,11 @@ func (r *KafkaProvider) Default() {
 }
 
 func (s *KafkaProviderSpec) Default() {
+	if s.PositionStorage == nil {
+		s.PositionStorage = &PositionStorage{}
+	}
+	s.PositionStorage.Default()
+	if s.SASL != nil && s.SASL.Mechanism == "" {
+		s.SASL.Mechanism = KafkaSASLMechanismPlain
+	}
 }
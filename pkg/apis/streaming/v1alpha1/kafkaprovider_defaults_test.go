@@ -0,0 +1,113 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestKafkaProviderDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *KafkaProvider
+		want *KafkaProvider
+	}{{
+		name: "empty",
+		in:   &KafkaProvider{},
+		want: &KafkaProvider{
+			Spec: KafkaProviderSpec{
+				PositionStorage: &PositionStorage{
+					Type: PositionStorageTypeMemory,
+				},
+			},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.in
+			got.Default()
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Default (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestKafkaProviderSpecDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *KafkaProviderSpec
+		want *KafkaProviderSpec
+	}{{
+		name: "empty",
+		in:   &KafkaProviderSpec{},
+		want: &KafkaProviderSpec{
+			PositionStorage: &PositionStorage{
+				Type: PositionStorageTypeMemory,
+			},
+		},
+	}, {
+		name: "sasl default mechanism",
+		in: &KafkaProviderSpec{
+			SASL: &KafkaSASL{
+				SecretRef: corev1.LocalObjectReference{Name: "kafka-credentials"},
+			},
+		},
+		want: &KafkaProviderSpec{
+			SASL: &KafkaSASL{
+				Mechanism: KafkaSASLMechanismPlain,
+				SecretRef: corev1.LocalObjectReference{Name: "kafka-credentials"},
+			},
+			PositionStorage: &PositionStorage{
+				Type: PositionStorageTypeMemory,
+			},
+		},
+	}, {
+		name: "redis default port",
+		in: &KafkaProviderSpec{
+			PositionStorage: &PositionStorage{
+				Type: PositionStorageTypeRedis,
+				Redis: &RedisPositionStorage{
+					Host: "redis",
+				},
+			},
+		},
+		want: &KafkaProviderSpec{
+			PositionStorage: &PositionStorage{
+				Type: PositionStorageTypeRedis,
+				Redis: &RedisPositionStorage{
+					Host: "redis",
+					Port: 6379,
+				},
+			},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.in
+			got.Default()
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Default (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
@@ -48,18 +49,53 @@ type KafkaProviderSpec struct {
 	//
 	// A host and port pair uses `:` as the separator.
 	BootstrapServers string `json:"bootstrapServers"`
+
+	// SASL authenticates the provisioner's connections to the brokers. The
+	// liiklus gateway only binds the bootstrap servers, it must reach the
+	// brokers through a listener without authentication.
+	// +optional
+	SASL *KafkaSASL `json:"sasl,omitempty"`
+
+	// TLS secures the provisioner's connections to the brokers. Like SASL,
+	// it does not apply to the gateway.
+	// +optional
+	TLS *ProviderTLS `json:"tls,omitempty"`
+
+	// PositionStorage configures where the gateway persists consumer
+	// positions. Defaults to Memory.
+	// +optional
+	PositionStorage *PositionStorage `json:"positionStorage,omitempty"`
+
+	// AllowedNamespaces may use the provider for their streams, along with
+	// the provider's own namespace. Defaults to the provider's namespace only.
+	// +optional
+	AllowedNamespaces *AllowedNamespaces `json:"allowedNamespaces,omitempty"`
 }
 
+// KafkaSASL authenticates with the brokers using a username and password
+type KafkaSASL struct {
+	// Mechanism used to authenticate, one of PLAIN, SCRAM-SHA-256 or
+	// SCRAM-SHA-512. Defaults to PLAIN.
+	// +optional
+	Mechanism KafkaSASLMechanism `json:"mechanism,omitempty"`
+
+	// SecretRef references a Secret with the credentials, under the keys
+	// username and password
+	SecretRef corev1.LocalObjectReference `json:"secretRef"`
+}
+
+// KafkaSASLMechanism describes how credentials are exchanged with the brokers
+type KafkaSASLMechanism string
+
+const (
+	KafkaSASLMechanismPlain       KafkaSASLMechanism = "PLAIN"
+	KafkaSASLMechanismScramSHA256 KafkaSASLMechanism = "SCRAM-SHA-256"
+	KafkaSASLMechanismScramSHA512 KafkaSASLMechanism = "SCRAM-SHA-512"
+)
+
 // KafkaProviderStatus defines the observed state of KafkaProvider
 type KafkaProviderStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
-
-	apis.Status               `json:",inline"`
-	GatewayDeploymentName     string `json:"gatewayDeploymentName,omitempty"`
-	GatewayServiceName        string `json:"gatewayServiceName,omitempty"`
-	ProvisionerDeploymentName string `json:"provisionerDeploymentName,omitempty"`
-	ProvisionerServiceName    string `json:"provisionerServiceName,omitempty"`
+	ProviderStatus `json:",inline"`
 }
 
 // +kubebuilder:object:root=true
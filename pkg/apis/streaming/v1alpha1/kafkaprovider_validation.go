@@ -66,5 +66,36 @@ func (s *KafkaProviderSpec) Validate() validation.FieldErrors {
 		errs = errs.Also(validation.ErrMissingField("bootstrapServers"))
 	}
 
+	if s.SASL != nil {
+		errs = errs.Also(s.SASL.Validate().ViaField("sasl"))
+	}
+
+	if s.TLS != nil {
+		errs = errs.Also(s.TLS.Validate().ViaField("tls"))
+	}
+
+	if s.PositionStorage != nil {
+		errs = errs.Also(s.PositionStorage.Validate().ViaField("positionStorage"))
+	}
+
+	if s.AllowedNamespaces != nil {
+		errs = errs.Also(s.AllowedNamespaces.Validate().ViaField("allowedNamespaces"))
+	}
+
+	return errs
+}
+
+func (s *KafkaSASL) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	switch s.Mechanism {
+	case KafkaSASLMechanismPlain, KafkaSASLMechanismScramSHA256, KafkaSASLMechanismScramSHA512:
+	default:
+		errs = errs.Also(validation.ErrInvalidValue(s.Mechanism, "mechanism"))
+	}
+	if s.SecretRef.Name == "" {
+		errs = errs.Also(validation.ErrMissingField("secretRef.name"))
+	}
+
 	return errs
 }
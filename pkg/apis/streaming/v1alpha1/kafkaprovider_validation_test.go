@@ -20,6 +20,8 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/projectriff/system/pkg/validation"
 )
@@ -66,6 +68,148 @@ func TestValidateKafkaProviderSpec(t *testing.T) {
 			BootstrapServers: "localhost:9092",
 		},
 		expected: validation.FieldErrors{},
+	}, {
+		name: "sasl",
+		target: &KafkaProviderSpec{
+			BootstrapServers: "localhost:9092",
+			SASL: &KafkaSASL{
+				Mechanism: KafkaSASLMechanismScramSHA512,
+				SecretRef: corev1.LocalObjectReference{Name: "kafka-credentials"},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid sasl",
+		target: &KafkaProviderSpec{
+			BootstrapServers: "localhost:9092",
+			SASL: &KafkaSASL{
+				Mechanism: "GSSAPI",
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrInvalidValue(KafkaSASLMechanism("GSSAPI"), "sasl.mechanism"),
+			validation.ErrMissingField("sasl.secretRef.name"),
+		),
+	}, {
+		name: "tls",
+		target: &KafkaProviderSpec{
+			BootstrapServers: "localhost:9093",
+			TLS: &ProviderTLS{
+				CASecretRef:   &corev1.LocalObjectReference{Name: "kafka-ca"},
+				CertSecretRef: &corev1.LocalObjectReference{Name: "kafka-client"},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "tls with system trust store",
+		target: &KafkaProviderSpec{
+			BootstrapServers: "localhost:9093",
+			TLS:              &ProviderTLS{},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid tls",
+		target: &KafkaProviderSpec{
+			BootstrapServers: "localhost:9093",
+			TLS: &ProviderTLS{
+				CASecretRef:   &corev1.LocalObjectReference{},
+				CertSecretRef: &corev1.LocalObjectReference{},
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrMissingField("tls.caSecretRef.name"),
+			validation.ErrMissingField("tls.certSecretRef.name"),
+		),
+	}, {
+		name: "allowed namespaces",
+		target: &KafkaProviderSpec{
+			BootstrapServers: "localhost:9092",
+			AllowedNamespaces: &AllowedNamespaces{
+				Names: []string{"team-a", "team-b"},
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"streaming": "enabled"},
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid allowed namespace name",
+		target: &KafkaProviderSpec{
+			BootstrapServers: "localhost:9092",
+			AllowedNamespaces: &AllowedNamespaces{
+				Names: []string{"team-a", "Team_B"},
+			},
+		},
+		expected: validation.ErrInvalidArrayValue("Team_B", "allowedNamespaces.names", 1),
+	}, {
+		name: "redis position storage",
+		target: &KafkaProviderSpec{
+			BootstrapServers: "localhost:9092",
+			PositionStorage: &PositionStorage{
+				Type: PositionStorageTypeRedis,
+				Redis: &RedisPositionStorage{
+					Host: "redis",
+					Port: 6379,
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid position storage type",
+		target: &KafkaProviderSpec{
+			BootstrapServers: "localhost:9092",
+			PositionStorage: &PositionStorage{
+				Type: "Disk",
+			},
+		},
+		expected: validation.ErrInvalidValue(PositionStorageType("Disk"), "positionStorage.type"),
+	}, {
+		name: "redis position storage missing redis",
+		target: &KafkaProviderSpec{
+			BootstrapServers: "localhost:9092",
+			PositionStorage: &PositionStorage{
+				Type: PositionStorageTypeRedis,
+			},
+		},
+		expected: validation.ErrMissingField("positionStorage.redis"),
+	}, {
+		name: "redis position storage missing host",
+		target: &KafkaProviderSpec{
+			BootstrapServers: "localhost:9092",
+			PositionStorage: &PositionStorage{
+				Type: PositionStorageTypeRedis,
+				Redis: &RedisPositionStorage{
+					Port: 6379,
+				},
+			},
+		},
+		expected: validation.ErrMissingField("positionStorage.redis.host"),
+	}, {
+		name: "redis position storage invalid port",
+		target: &KafkaProviderSpec{
+			BootstrapServers: "localhost:9092",
+			PositionStorage: &PositionStorage{
+				Type: PositionStorageTypeRedis,
+				Redis: &RedisPositionStorage{
+					Host: "redis",
+					Port: 70000,
+				},
+			},
+		},
+		expected: validation.ErrInvalidValue(int32(70000), "positionStorage.redis.port"),
+	}, {
+		name: "memory position storage with redis",
+		target: &KafkaProviderSpec{
+			BootstrapServers: "localhost:9092",
+			PositionStorage: &PositionStorage{
+				Type: PositionStorageTypeMemory,
+				Redis: &RedisPositionStorage{
+					Host: "redis",
+					Port: 6379,
+				},
+			},
+		},
+		expected: validation.ErrDisallowedFields("positionStorage.redis", "only allowed for Redis storage"),
 	}} {
 		t.Run(c.name, func(t *testing.T) {
 			actual := c.target.Validate()
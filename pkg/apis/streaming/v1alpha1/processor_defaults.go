@@ -16,7 +16,10 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
@@ -57,4 +60,83 @@ func (s *ProcessorSpec) Default() {
 	if s.Template.Containers[0].Name == "" {
 		s.Template.Containers[0].Name = "function"
 	}
+
+	if s.Scaling == nil {
+		s.Scaling = &Scaling{}
+	}
+	s.Scaling.Default()
+
+	if s.ErrorPolicy != nil {
+		s.ErrorPolicy.Default()
+	}
+
+	if s.Batch != nil {
+		s.Batch.Default()
+	}
+
+	if s.Replay != nil {
+		s.Replay.Default()
+	}
+}
+
+func (s *Scaling) Default() {
+	if s.MinReplicas == nil {
+		s.MinReplicas = int32Ptr(0)
+	}
+	if s.MaxReplicas == nil {
+		s.MaxReplicas = int32Ptr(30)
+	}
+	if s.CooldownPeriod == nil {
+		s.CooldownPeriod = int32Ptr(30)
+	}
+	if s.PollingInterval == nil {
+		s.PollingInterval = int32Ptr(1)
+	}
+}
+
+func (p *ErrorPolicy) Default() {
+	if p.MaxRetries == nil {
+		p.MaxRetries = int32Ptr(3)
+	}
+	if p.Backoff == nil {
+		p.Backoff = &Backoff{}
+	}
+	p.Backoff.Default()
+}
+
+func (b *Backoff) Default() {
+	if b.InitialDelay == nil {
+		b.InitialDelay = &metav1.Duration{Duration: time.Second}
+	}
+	if b.MaxDelay == nil {
+		b.MaxDelay = &metav1.Duration{Duration: time.Minute}
+	}
+	if b.Multiplier == nil {
+		b.Multiplier = int32Ptr(2)
+	}
+}
+
+func (b *Batch) Default() {
+	if b.MaxMessages == nil {
+		b.MaxMessages = int32Ptr(100)
+	}
+	if b.BackoffLimit == nil {
+		b.BackoffLimit = int32Ptr(3)
+	}
+	if b.SuccessfulJobsHistoryLimit == nil {
+		b.SuccessfulJobsHistoryLimit = int32Ptr(3)
+	}
+	if b.FailedJobsHistoryLimit == nil {
+		b.FailedJobsHistoryLimit = int32Ptr(1)
+	}
+}
+
+func (r *Replay) Default() {
+	if r.Position == "" {
+		r.Position = StartPositionEarliest
+	}
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
 }
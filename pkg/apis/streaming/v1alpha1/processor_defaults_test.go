@@ -18,9 +18,11 @@ package v1alpha1
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestProcessorDefault(t *testing.T) {
@@ -40,6 +42,12 @@ func TestProcessorDefault(t *testing.T) {
 						{Name: "function"},
 					},
 				},
+				Scaling: &Scaling{
+					MinReplicas:     int32Ptr(0),
+					MaxReplicas:     int32Ptr(30),
+					CooldownPeriod:  int32Ptr(30),
+					PollingInterval: int32Ptr(1),
+				},
 			},
 		},
 	}}
@@ -71,6 +79,12 @@ func TestProcessorSpecDefault(t *testing.T) {
 					{Name: "function"},
 				},
 			},
+			Scaling: &Scaling{
+				MinReplicas:     int32Ptr(0),
+				MaxReplicas:     int32Ptr(30),
+				CooldownPeriod:  int32Ptr(30),
+				PollingInterval: int32Ptr(1),
+			},
 		},
 	}, {
 		name: "add alias",
@@ -93,6 +107,12 @@ func TestProcessorSpecDefault(t *testing.T) {
 					{Name: "function"},
 				},
 			},
+			Scaling: &Scaling{
+				MinReplicas:     int32Ptr(0),
+				MaxReplicas:     int32Ptr(30),
+				CooldownPeriod:  int32Ptr(30),
+				PollingInterval: int32Ptr(1),
+			},
 		},
 	}, {
 		name: "preserves alias",
@@ -115,6 +135,12 @@ func TestProcessorSpecDefault(t *testing.T) {
 					{Name: "function"},
 				},
 			},
+			Scaling: &Scaling{
+				MinReplicas:     int32Ptr(0),
+				MaxReplicas:     int32Ptr(30),
+				CooldownPeriod:  int32Ptr(30),
+				PollingInterval: int32Ptr(1),
+			},
 		},
 	}, {
 		name: "add container name",
@@ -133,6 +159,12 @@ func TestProcessorSpecDefault(t *testing.T) {
 					{Name: "function"},
 				},
 			},
+			Scaling: &Scaling{
+				MinReplicas:     int32Ptr(0),
+				MaxReplicas:     int32Ptr(30),
+				CooldownPeriod:  int32Ptr(30),
+				PollingInterval: int32Ptr(1),
+			},
 		},
 	}, {
 		name: "preserves container",
@@ -161,6 +193,116 @@ func TestProcessorSpecDefault(t *testing.T) {
 					},
 				},
 			},
+			Scaling: &Scaling{
+				MinReplicas:     int32Ptr(0),
+				MaxReplicas:     int32Ptr(30),
+				CooldownPeriod:  int32Ptr(30),
+				PollingInterval: int32Ptr(1),
+			},
+		},
+	}, {
+		name: "preserves scaling",
+		in: &ProcessorSpec{
+			Scaling: &Scaling{
+				MinReplicas: int32Ptr(1),
+				MaxReplicas: int32Ptr(5),
+			},
+		},
+		want: &ProcessorSpec{
+			Inputs:  []StreamBinding{},
+			Outputs: []StreamBinding{},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+			Scaling: &Scaling{
+				MinReplicas:     int32Ptr(1),
+				MaxReplicas:     int32Ptr(5),
+				CooldownPeriod:  int32Ptr(30),
+				PollingInterval: int32Ptr(1),
+			},
+		},
+	}, {
+		name: "error policy",
+		in: &ProcessorSpec{
+			ErrorPolicy: &ErrorPolicy{
+				DeadLetterStream: "my-dead-letters",
+			},
+		},
+		want: &ProcessorSpec{
+			Inputs:  []StreamBinding{},
+			Outputs: []StreamBinding{},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+			Scaling: &Scaling{
+				MinReplicas:     int32Ptr(0),
+				MaxReplicas:     int32Ptr(30),
+				CooldownPeriod:  int32Ptr(30),
+				PollingInterval: int32Ptr(1),
+			},
+			ErrorPolicy: &ErrorPolicy{
+				MaxRetries: int32Ptr(3),
+				Backoff: &Backoff{
+					InitialDelay: &metav1.Duration{Duration: time.Second},
+					MaxDelay:     &metav1.Duration{Duration: time.Minute},
+					Multiplier:   int32Ptr(2),
+				},
+				DeadLetterStream: "my-dead-letters",
+			},
+		},
+	}, {
+		name: "batch",
+		in: &ProcessorSpec{
+			Batch: &Batch{},
+		},
+		want: &ProcessorSpec{
+			Inputs:  []StreamBinding{},
+			Outputs: []StreamBinding{},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+			Scaling: &Scaling{
+				MinReplicas:     int32Ptr(0),
+				MaxReplicas:     int32Ptr(30),
+				CooldownPeriod:  int32Ptr(30),
+				PollingInterval: int32Ptr(1),
+			},
+			Batch: &Batch{
+				MaxMessages:                int32Ptr(100),
+				BackoffLimit:               int32Ptr(3),
+				SuccessfulJobsHistoryLimit: int32Ptr(3),
+				FailedJobsHistoryLimit:     int32Ptr(1),
+			},
+		},
+	}, {
+		name: "replay",
+		in: &ProcessorSpec{
+			Replay: &Replay{Token: "1"},
+		},
+		want: &ProcessorSpec{
+			Inputs:  []StreamBinding{},
+			Outputs: []StreamBinding{},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+			Scaling: &Scaling{
+				MinReplicas:     int32Ptr(0),
+				MaxReplicas:     int32Ptr(30),
+				CooldownPeriod:  int32Ptr(30),
+				PollingInterval: int32Ptr(1),
+			},
+			Replay: &Replay{
+				Token:    "1",
+				Position: "earliest",
+			},
 		},
 	}}
 
@@ -17,6 +17,7 @@ package v1alpha1
 
 import (
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 
 	kedav1alpha1 "github.com/projectriff/system/pkg/apis/thirdparty/keda/v1alpha1"
@@ -31,6 +32,9 @@ const (
 	ProcessorConditionStreamsReady      apis.ConditionType = "StreamsReady"
 	ProcessorConditionDeploymentReady   apis.ConditionType = "DeploymentReady"
 	ProcessorConditionScaledObjectReady apis.ConditionType = "ScaledObjectReady"
+	ProcessorConditionLastJobSucceeded  apis.ConditionType = "LastJobSucceeded"
+	ProcessorConditionSuspended         apis.ConditionType = "Suspended"
+	ProcessorConditionReplayed          apis.ConditionType = "Replayed"
 )
 
 var processorCondSet = apis.NewLivingConditionSet(
@@ -95,6 +99,60 @@ func (ps *ProcessorStatus) PropagateDeploymentStatus(ds *appsv1.DeploymentStatus
 	}
 }
 
+// MarkDeploymentNotRequired is used by batch processors, which run as jobs
+// rather than a deployment.
+func (ps *ProcessorStatus) MarkDeploymentNotRequired() {
+	processorCondSet.Manage(ps).MarkTrue(ProcessorConditionDeploymentReady)
+}
+
+func (ps *ProcessorStatus) MarkNoJobs() {
+	processorCondSet.Manage(ps).MarkUnknown(ProcessorConditionLastJobSucceeded, "NoJobs", "No job has finished yet.")
+}
+
+// PropagateLastJobStatus update ProcessorConditionLastJobSucceeded condition
+// in ProcessorStatus according to the status of the most recently finished
+// job.
+func (ps *ProcessorStatus) PropagateLastJobStatus(js *batchv1.JobStatus) {
+	for _, c := range js.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			processorCondSet.Manage(ps).MarkTrue(ProcessorConditionLastJobSucceeded)
+			return
+		case batchv1.JobFailed:
+			processorCondSet.Manage(ps).MarkFalse(ProcessorConditionLastJobSucceeded, c.Reason, c.Message)
+			return
+		}
+	}
+}
+
+func (ps *ProcessorStatus) MarkSuspended() {
+	processorCondSet.Manage(ps).MarkTrue(ProcessorConditionSuspended)
+}
+
+func (ps *ProcessorStatus) MarkResumed() {
+	// the condition is not terminal, so clearing it cannot fail
+	_ = processorCondSet.Manage(ps).ClearCondition(ProcessorConditionSuspended)
+}
+
+func (ps *ProcessorStatus) MarkReplayPending(message string) {
+	processorCondSet.Manage(ps).MarkUnknown(ProcessorConditionReplayed, "ReplayPending", message)
+}
+
+func (ps *ProcessorStatus) MarkReplayFailed(message string) {
+	processorCondSet.Manage(ps).MarkFalse(ProcessorConditionReplayed, "ReplayFailed", message)
+}
+
+func (ps *ProcessorStatus) MarkReplayNotSupported(message string) {
+	processorCondSet.Manage(ps).MarkFalse(ProcessorConditionReplayed, "ReplayNotSupported", message)
+}
+
+func (ps *ProcessorStatus) MarkReplayed() {
+	processorCondSet.Manage(ps).MarkTrue(ProcessorConditionReplayed)
+}
+
 func (ps *ProcessorStatus) PropagateScaledObjectStatus(sos *kedav1alpha1.ScaledObjectStatus) {
 	// TODO: ScaledObject does not report much atm
 	processorCondSet.Manage(ps).MarkTrue(ProcessorConditionScaledObjectReady)
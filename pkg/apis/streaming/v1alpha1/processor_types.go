@@ -55,6 +55,62 @@ type ProcessorSpec struct {
 	// Template pod
 	// +optional
 	Template *corev1.PodSpec `json:"template,omitempty"`
+
+	// Sidecar configures the processor container injected into the pod next
+	// to the function container
+	// +optional
+	Sidecar *Sidecar `json:"sidecar,omitempty"`
+
+	// Scaling bounds and tunes the autoscaling of the processor
+	// +optional
+	Scaling *Scaling `json:"scaling,omitempty"`
+
+	// ErrorPolicy controls how messages the function fails to process are
+	// retried and where they end up once retries are exhausted
+	// +optional
+	ErrorPolicy *ErrorPolicy `json:"errorPolicy,omitempty"`
+
+	// Batch runs the processor as jobs, sized by the lag of its inputs, that
+	// each drain a bounded number of messages and exit. When not specified,
+	// the processor runs continuously as a deployment.
+	// +optional
+	Batch *Batch `json:"batch,omitempty"`
+
+	// Suspend scales the processor to zero while set, leaving its consumer
+	// group, and therefore its position on the inputs, intact. Resuming
+	// continues from where the processor left off.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Replay resets the position of the processor on its inputs. The
+	// processor is scaled to zero while its consumer groups are reset and
+	// resumes from the new position. Replay is not supported for streams of
+	// a generic Provider, whose provisioner may not reset consumer groups.
+	// +optional
+	Replay *Replay `json:"replay,omitempty"`
+}
+
+type Sidecar struct {
+	// Resources are the compute resources required by the processor
+	// container
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+type Replay struct {
+	// Token identifies the replay request. Each token is replayed once,
+	// setting a new token requests another replay.
+	Token string `json:"token"`
+
+	// Inputs are the aliases of the inputs to replay. Defaults to all
+	// inputs.
+	// +optional
+	Inputs []string `json:"inputs,omitempty"`
+
+	// Position to reset the inputs to, either `earliest`, `latest` or an
+	// RFC 3339 timestamp. Defaults to `earliest`.
+	// +optional
+	Position string `json:"position,omitempty"`
 }
 
 type Build struct {
@@ -72,6 +128,109 @@ type StreamBinding struct {
 	// Alias exposes the stream under another name within the processor
 	// +optional
 	Alias string `json:"alias,omitempty"`
+
+	// LagThreshold is the number of pending messages on an input stream that
+	// warrants another replica. Only allowed on inputs.
+	// +optional
+	LagThreshold *int32 `json:"lagThreshold,omitempty"`
+
+	// Group is the consumer group reading the input. Defaults to the name of
+	// the processor. Setting it keeps the position on the input when the
+	// processor is renamed. Only allowed on inputs.
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// StartPosition is where a new consumer group starts reading the input,
+	// either `earliest`, `latest` or an RFC 3339 timestamp. It has no effect
+	// once the group has a position on the input. When not specified, new
+	// groups start from the earliest message. Only allowed on inputs.
+	// +optional
+	StartPosition string `json:"startPosition,omitempty"`
+}
+
+const (
+	StartPositionEarliest = "earliest"
+	StartPositionLatest   = "latest"
+)
+
+type Scaling struct {
+	// MinReplicas is the lower bound for the number of replicas. Defaults to
+	// 0, allowing the processor to scale to zero while its inputs are idle.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper bound for the number of replicas. Defaults to
+	// 30.
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+
+	// CooldownPeriod is the number of seconds to wait after the inputs become
+	// idle before scaling to zero. Defaults to 30.
+	// +optional
+	CooldownPeriod *int32 `json:"cooldownPeriod,omitempty"`
+
+	// PollingInterval is the number of seconds between checks of the inputs
+	// for pending messages. Defaults to 1.
+	// +optional
+	PollingInterval *int32 `json:"pollingInterval,omitempty"`
+}
+
+type ErrorPolicy struct {
+	// MaxRetries is the number of times a failed message is retried before
+	// giving up on it. Defaults to 3.
+	// +optional
+	MaxRetries *int32 `json:"maxRetries,omitempty"`
+
+	// Backoff between retries of a failed message
+	// +optional
+	Backoff *Backoff `json:"backoff,omitempty"`
+
+	// DeadLetterStream name, from this namespace, that receives messages
+	// once their retries are exhausted. When not specified, those messages
+	// are dropped.
+	// +optional
+	DeadLetterStream string `json:"deadLetterStream,omitempty"`
+}
+
+type Backoff struct {
+	// InitialDelay before the first retry. Defaults to 1s.
+	// +optional
+	InitialDelay *metav1.Duration `json:"initialDelay,omitempty"`
+
+	// MaxDelay between retries. Defaults to 1m.
+	// +optional
+	MaxDelay *metav1.Duration `json:"maxDelay,omitempty"`
+
+	// Multiplier applied to the delay after each retry. Defaults to 2.
+	// +optional
+	Multiplier *int32 `json:"multiplier,omitempty"`
+}
+
+type Batch struct {
+	// MaxMessages is the number of messages each job consumes from its
+	// inputs before exiting. Defaults to 100.
+	// +optional
+	MaxMessages *int32 `json:"maxMessages,omitempty"`
+
+	// BackoffLimit is the number of times a job is retried before it is
+	// considered failed. Defaults to 3.
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+
+	// ActiveDeadlineSeconds bounds how long each job may run before it is
+	// terminated and considered failed.
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// SuccessfulJobsHistoryLimit is the number of completed jobs to retain.
+	// Defaults to 3.
+	// +optional
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+
+	// FailedJobsHistoryLimit is the number of failed jobs to retain.
+	// Defaults to 1.
+	// +optional
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
 }
 
 // ProcessorStatus defines the observed state of Processor
@@ -84,14 +243,66 @@ type ProcessorStatus struct {
 	InputAddresses     []string `json:"inputAddresses,omitempty"`
 	OutputAddresses    []string `json:"outputAddresses,omitempty"`
 	OutputContentTypes []string `json:"outputContentTypes,omitempty"`
+	DeadLetterAddress  string   `json:"deadLetterAddress,omitempty"`
 	DeploymentName     string   `json:"deploymentName,omitempty"`
 	ScaledObjectName   string   `json:"scaledObjectName,omitempty"`
 	LatestImage        string   `json:"latestImage,omitempty"`
+
+	// Batch reports the jobs of a batch processor
+	Batch *BatchStatus `json:"batch,omitempty"`
+
+	// Replay reports the progress of the latest replay request
+	Replay *ReplayStatus `json:"replay,omitempty"`
+
+	// ReplayedGroups are the consumer groups handed out by provisioners in
+	// place of an input's group when replaying it
+	ReplayedGroups []ReplayedGroup `json:"replayedGroups,omitempty"`
+}
+
+type BatchStatus struct {
+	// Active is the number of jobs that are running
+	Active int32 `json:"active,omitempty"`
+
+	// Succeeded is the number of retained jobs that completed
+	Succeeded int32 `json:"succeeded,omitempty"`
+
+	// Failed is the number of retained jobs that failed
+	Failed int32 `json:"failed,omitempty"`
+
+	// LastSuccessfulTime is when the latest successful job completed
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+
+	// LastFailureTime is when the latest failed job gave up
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+}
+
+type ReplayedGroup struct {
+	// Alias of the replayed input
+	Alias string `json:"alias"`
+
+	// Group of the input that was replayed
+	Group string `json:"group"`
+
+	// ConsumerGroup the input consumes with since the replay
+	ConsumerGroup string `json:"consumerGroup"`
+
+	// StartPosition of the consumer group
+	StartPosition string `json:"startPosition,omitempty"`
+}
+
+type ReplayStatus struct {
+	// Token of the latest replay request
+	Token string `json:"token,omitempty"`
+
+	// CompletionTime is when the inputs were reset, empty while the replay
+	// is in progress
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:categories="riff"
+// +kubebuilder:printcolumn:name="Suspended",type=boolean,JSONPath=`.spec.suspend`
 // +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 // +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
 // +genclient
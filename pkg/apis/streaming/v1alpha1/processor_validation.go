@@ -18,14 +18,14 @@ package v1alpha1
 
 import (
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
-	"github.com/google/go-cmp/cmp"
-
 	"github.com/projectriff/system/pkg/validation"
 )
 
@@ -67,18 +67,7 @@ func (s *ProcessorSpec) Validate() validation.FieldErrors {
 
 	errs := validation.FieldErrors{}
 
-	if diff := cmp.Diff(&corev1.PodSpec{
-		// add supported PodSpec fields here, otherwise their usage will be rejected
-		ServiceAccountName: s.Template.ServiceAccountName,
-		// the defaulter guarantees at least one container
-		Containers: filterInvalidContainers(s.Template.Containers[:1]),
-		Volumes:    filterInvalidVolumes(s.Template.Volumes),
-	}, s.Template); diff != "" {
-		errs = errs.Also(validation.ErrDisallowedFields("template", fmt.Sprintf("limited Template fields may be set (-want, +got) = %v", diff)))
-	}
-	if s.Template.Containers[0].Name != "function" {
-		errs = errs.Also(validation.ErrInvalidValue(s.Template.Containers[0].Name, "template.containers[0].name"))
-	}
+	errs = errs.Also(validateProcessorTemplate(s.Template).ViaField("template"))
 
 	if s.Build == nil && s.Template.Containers[0].Image == "" {
 		errs = errs.Also(validation.ErrMissingOneOf("build", "template.containers[0].image"))
@@ -99,6 +88,15 @@ func (s *ProcessorSpec) Validate() validation.FieldErrors {
 		if input.Alias == "" {
 			errs = errs.Also(validation.ErrMissingField("alias").ViaFieldIndex("inputs", i))
 		}
+		if input.LagThreshold != nil && *input.LagThreshold < 1 {
+			errs = errs.Also(validation.ErrInvalidValue(*input.LagThreshold, "lagThreshold").ViaFieldIndex("inputs", i))
+		}
+		if input.Group != "" && len(utilvalidation.IsDNS1123Subdomain(input.Group)) != 0 {
+			errs = errs.Also(validation.ErrInvalidValue(input.Group, "group").ViaFieldIndex("inputs", i))
+		}
+		if input.StartPosition != "" && !isStartPosition(input.StartPosition) {
+			errs = errs.Also(validation.ErrInvalidValue(input.StartPosition, "startPosition").ViaFieldIndex("inputs", i))
+		}
 	}
 
 	// outputs are optional
@@ -109,6 +107,152 @@ func (s *ProcessorSpec) Validate() validation.FieldErrors {
 		if output.Alias == "" {
 			errs = errs.Also(validation.ErrMissingField("alias").ViaFieldIndex("outputs", i))
 		}
+		if output.LagThreshold != nil {
+			errs = errs.Also(validation.ErrDisallowedFields("lagThreshold", "only allowed for inputs").ViaFieldIndex("outputs", i))
+		}
+		if output.Group != "" {
+			errs = errs.Also(validation.ErrDisallowedFields("group", "only allowed for inputs").ViaFieldIndex("outputs", i))
+		}
+		if output.StartPosition != "" {
+			errs = errs.Also(validation.ErrDisallowedFields("startPosition", "only allowed for inputs").ViaFieldIndex("outputs", i))
+		}
+	}
+
+	if s.Sidecar != nil {
+		errs = errs.Also(s.Sidecar.Validate().ViaField("sidecar"))
+	}
+
+	if s.Scaling != nil {
+		errs = errs.Also(s.Scaling.Validate().ViaField("scaling"))
+	}
+
+	if s.ErrorPolicy != nil {
+		errs = errs.Also(s.ErrorPolicy.Validate().ViaField("errorPolicy"))
+		for _, input := range s.Inputs {
+			if s.ErrorPolicy.DeadLetterStream != "" && s.ErrorPolicy.DeadLetterStream == input.Stream {
+				// dead letters would be consumed again by the processor
+				errs = errs.Also(validation.ErrDisallowedFields("errorPolicy.deadLetterStream", "must not be an input stream"))
+				break
+			}
+		}
+	}
+
+	if s.Batch != nil {
+		errs = errs.Also(s.Batch.Validate().ViaField("batch"))
+	}
+
+	if s.Replay != nil {
+		errs = errs.Also(s.Replay.Validate().ViaField("replay"))
+		aliases := map[string]bool{}
+		for _, input := range s.Inputs {
+			aliases[input.Alias] = true
+		}
+		for i, alias := range s.Replay.Inputs {
+			if !aliases[alias] {
+				errs = errs.Also(validation.ErrInvalidValue(alias, fmt.Sprintf("replay.inputs[%d]", i)))
+			}
+		}
+	}
+
+	return errs
+}
+
+func (r *Replay) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if r.Token == "" {
+		errs = errs.Also(validation.ErrMissingField("token"))
+	}
+	if r.Position == "" {
+		errs = errs.Also(validation.ErrMissingField("position"))
+	} else if !isStartPosition(r.Position) {
+		errs = errs.Also(validation.ErrInvalidValue(r.Position, "position"))
+	}
+
+	return errs
+}
+
+func isStartPosition(position string) bool {
+	if position == StartPositionEarliest || position == StartPositionLatest {
+		return true
+	}
+	_, err := time.Parse(time.RFC3339, position)
+	return err == nil
+}
+
+func (p *ErrorPolicy) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if p.MaxRetries != nil && *p.MaxRetries < 0 {
+		errs = errs.Also(validation.ErrInvalidValue(*p.MaxRetries, "maxRetries"))
+	}
+	if p.Backoff != nil {
+		errs = errs.Also(p.Backoff.Validate().ViaField("backoff"))
+	}
+
+	return errs
+}
+
+func (b *Backoff) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if b.InitialDelay != nil && b.InitialDelay.Duration < 0 {
+		errs = errs.Also(validation.ErrInvalidValue(b.InitialDelay.Duration.String(), "initialDelay"))
+	}
+	if b.MaxDelay != nil {
+		if b.MaxDelay.Duration < 0 {
+			errs = errs.Also(validation.ErrInvalidValue(b.MaxDelay.Duration.String(), "maxDelay"))
+		} else if b.InitialDelay != nil && b.MaxDelay.Duration < b.InitialDelay.Duration {
+			errs = errs.Also(validation.ErrInvalidValue(b.MaxDelay.Duration.String(), "maxDelay"))
+		}
+	}
+	if b.Multiplier != nil && *b.Multiplier < 1 {
+		errs = errs.Also(validation.ErrInvalidValue(*b.Multiplier, "multiplier"))
+	}
+
+	return errs
+}
+
+func (b *Batch) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if b.MaxMessages != nil && *b.MaxMessages < 1 {
+		errs = errs.Also(validation.ErrInvalidValue(*b.MaxMessages, "maxMessages"))
+	}
+	if b.BackoffLimit != nil && *b.BackoffLimit < 0 {
+		errs = errs.Also(validation.ErrInvalidValue(*b.BackoffLimit, "backoffLimit"))
+	}
+	if b.ActiveDeadlineSeconds != nil && *b.ActiveDeadlineSeconds < 1 {
+		errs = errs.Also(validation.ErrInvalidValue(*b.ActiveDeadlineSeconds, "activeDeadlineSeconds"))
+	}
+	if b.SuccessfulJobsHistoryLimit != nil && *b.SuccessfulJobsHistoryLimit < 0 {
+		errs = errs.Also(validation.ErrInvalidValue(*b.SuccessfulJobsHistoryLimit, "successfulJobsHistoryLimit"))
+	}
+	if b.FailedJobsHistoryLimit != nil && *b.FailedJobsHistoryLimit < 0 {
+		errs = errs.Also(validation.ErrInvalidValue(*b.FailedJobsHistoryLimit, "failedJobsHistoryLimit"))
+	}
+
+	return errs
+}
+
+func (s *Scaling) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if s.MinReplicas != nil && *s.MinReplicas < 0 {
+		errs = errs.Also(validation.ErrInvalidValue(*s.MinReplicas, "minReplicas"))
+	}
+	if s.MaxReplicas != nil {
+		if *s.MaxReplicas < 1 {
+			errs = errs.Also(validation.ErrInvalidValue(*s.MaxReplicas, "maxReplicas"))
+		} else if s.MinReplicas != nil && *s.MinReplicas > *s.MaxReplicas {
+			errs = errs.Also(validation.ErrInvalidValue(*s.MaxReplicas, "maxReplicas"))
+		}
+	}
+	if s.CooldownPeriod != nil && *s.CooldownPeriod < 0 {
+		errs = errs.Also(validation.ErrInvalidValue(*s.CooldownPeriod, "cooldownPeriod"))
+	}
+	if s.PollingInterval != nil && *s.PollingInterval < 1 {
+		errs = errs.Also(validation.ErrInvalidValue(*s.PollingInterval, "pollingInterval"))
 	}
 
 	return errs
@@ -144,12 +288,68 @@ func (b *Build) Validate() validation.FieldErrors {
 	return errs
 }
 
-func filterInvalidContainers(containers []corev1.Container) []corev1.Container {
-	// TODO remove unsupported fields
-	return containers
+// validateProcessorTemplate checks the fields of the pod template that are
+// merged with the values controlled by the processor. The first container is
+// the function, the processor container is injected next to it.
+func validateProcessorTemplate(template *corev1.PodSpec) validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	// the defaulter guarantees at least one container
+	function := template.Containers[0]
+	if function.Name != "function" {
+		errs = errs.Also(validation.ErrInvalidValue(function.Name, "containers[0].name"))
+	}
+	if len(function.Ports) != 0 {
+		errs = errs.Also(validation.ErrDisallowedFields("containers[0].ports", "the function port is controlled by the processor"))
+	}
+	if template.RestartPolicy != "" {
+		errs = errs.Also(validation.ErrDisallowedFields("restartPolicy", "controlled by the processor"))
+	}
+
+	names := map[string]bool{function.Name: true}
+	for i, container := range template.InitContainers {
+		errs = errs.Also(validateProcessorTemplateContainer(container, names).ViaFieldIndex("initContainers", i))
+	}
+	for i, container := range template.Containers[1:] {
+		errs = errs.Also(validateProcessorTemplateContainer(container, names).ViaFieldIndex("containers", i+1))
+	}
+
+	return errs
+}
+
+func validateProcessorTemplateContainer(container corev1.Container, names map[string]bool) validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if container.Name == "" {
+		errs = errs.Also(validation.ErrMissingField("name"))
+	} else if container.Name == "processor" {
+		errs = errs.Also(validation.ErrDisallowedFields("name", "reserved for the processor container"))
+	} else if names[container.Name] {
+		errs = errs.Also(validation.ErrDisallowedFields("name", fmt.Sprintf("duplicate container name %q", container.Name)))
+	}
+	names[container.Name] = true
+	if container.Image == "" {
+		errs = errs.Also(validation.ErrMissingField("image"))
+	}
+
+	return errs
 }
 
-func filterInvalidVolumes(volumes []corev1.Volume) []corev1.Volume {
-	// TODO remove unsupported fields
-	return volumes
+func (s *Sidecar) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	for name, quantity := range s.Resources.Requests {
+		if quantity.Sign() < 0 {
+			errs = errs.Also(validation.ErrInvalidValue(quantity.String(), fmt.Sprintf("resources.requests[%s]", name)))
+		}
+	}
+	for name, limit := range s.Resources.Limits {
+		if limit.Sign() < 0 {
+			errs = errs.Also(validation.ErrInvalidValue(limit.String(), fmt.Sprintf("resources.limits[%s]", name)))
+		} else if request, ok := s.Resources.Requests[name]; ok && request.Cmp(limit) > 0 {
+			errs = errs.Also(validation.ErrInvalidValue(request.String(), fmt.Sprintf("resources.requests[%s]", name)))
+		}
+	}
+
+	return errs
 }
@@ -18,9 +18,12 @@ package v1alpha1
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/projectriff/system/pkg/validation"
 )
@@ -225,6 +228,440 @@ func TestValidateProcessorSpec(t *testing.T) {
 			},
 		},
 		expected: validation.ErrInvalidValue("processor", "template.containers[0].name"),
+	}, {
+		name: "rich template",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in"},
+			},
+			Template: &corev1.PodSpec{
+				ServiceAccountName: "my-sa",
+				NodeSelector: map[string]string{
+					"disktype": "ssd",
+				},
+				InitContainers: []corev1.Container{
+					{Name: "init", Image: "busybox"},
+				},
+				Containers: []corev1.Container{
+					{
+						Name: "function",
+						Resources: corev1.ResourceRequirements{
+							Limits: corev1.ResourceList{
+								corev1.ResourceMemory: resource.MustParse("256Mi"),
+							},
+						},
+						EnvFrom: []corev1.EnvFromSource{
+							{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "my-secret"}}},
+						},
+					},
+					{Name: "proxy", Image: "envoyproxy/envoy"},
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "controlled template fields",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in"},
+			},
+			Template: &corev1.PodSpec{
+				RestartPolicy: corev1.RestartPolicyOnFailure,
+				Containers: []corev1.Container{
+					{
+						Name: "function",
+						Ports: []corev1.ContainerPort{
+							{ContainerPort: 8080},
+						},
+					},
+				},
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrDisallowedFields("template.containers[0].ports", "the function port is controlled by the processor"),
+			validation.ErrDisallowedFields("template.restartPolicy", "controlled by the processor"),
+		),
+	}, {
+		name: "invalid template containers",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in"},
+			},
+			Template: &corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					{Name: "function", Image: "busybox"},
+				},
+				Containers: []corev1.Container{
+					{Name: "function"},
+					{Name: "processor", Image: "my-processor"},
+					{Image: "envoyproxy/envoy"},
+					{Name: "proxy"},
+				},
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrDisallowedFields("template.initContainers[0].name", `duplicate container name "function"`),
+			validation.ErrDisallowedFields("template.containers[1].name", "reserved for the processor container"),
+			validation.ErrMissingField("template.containers[2].name"),
+			validation.ErrMissingField("template.containers[3].image"),
+		),
+	}, {
+		name: "valid lag threshold",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in", LagThreshold: int32Ptr(100)},
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid lag threshold",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in", LagThreshold: int32Ptr(0)},
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+		},
+		expected: validation.ErrInvalidValue(int32(0), "inputs[0].lagThreshold"),
+	}, {
+		name: "output lag threshold",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in"},
+			},
+			Outputs: []StreamBinding{
+				{Stream: "my-output", Alias: "out", LagThreshold: int32Ptr(100)},
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+		},
+		expected: validation.ErrDisallowedFields("outputs[0].lagThreshold", "only allowed for inputs"),
+	}, {
+		name: "valid group and start positions",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in", Group: "my-group", StartPosition: "latest"},
+				{Stream: "my-other-stream", Alias: "other", StartPosition: "2019-12-01T10:00:00Z"},
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid group and start position",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in", Group: "my,group", StartPosition: "yesterday"},
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrInvalidValue("my,group", "inputs[0].group"),
+			validation.ErrInvalidValue("yesterday", "inputs[0].startPosition"),
+		),
+	}, {
+		name: "output group and start position",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in"},
+			},
+			Outputs: []StreamBinding{
+				{Stream: "my-output", Alias: "out", Group: "my-group", StartPosition: "earliest"},
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrDisallowedFields("outputs[0].group", "only allowed for inputs"),
+			validation.ErrDisallowedFields("outputs[0].startPosition", "only allowed for inputs"),
+		),
+	}, {
+		name: "valid scaling",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in"},
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+			Scaling: &Scaling{
+				MinReplicas:     int32Ptr(1),
+				MaxReplicas:     int32Ptr(5),
+				CooldownPeriod:  int32Ptr(300),
+				PollingInterval: int32Ptr(10),
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid scaling",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in"},
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+			Scaling: &Scaling{
+				MinReplicas:     int32Ptr(-1),
+				MaxReplicas:     int32Ptr(0),
+				CooldownPeriod:  int32Ptr(-1),
+				PollingInterval: int32Ptr(0),
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrInvalidValue(int32(-1), "scaling.minReplicas"),
+			validation.ErrInvalidValue(int32(0), "scaling.maxReplicas"),
+			validation.ErrInvalidValue(int32(-1), "scaling.cooldownPeriod"),
+			validation.ErrInvalidValue(int32(0), "scaling.pollingInterval"),
+		),
+	}, {
+		name: "min replicas above max replicas",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in"},
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+			Scaling: &Scaling{
+				MinReplicas: int32Ptr(10),
+				MaxReplicas: int32Ptr(5),
+			},
+		},
+		expected: validation.ErrInvalidValue(int32(5), "scaling.maxReplicas"),
+	}, {
+		name: "valid error policy",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in"},
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+			ErrorPolicy: &ErrorPolicy{
+				MaxRetries: int32Ptr(5),
+				Backoff: &Backoff{
+					InitialDelay: &metav1.Duration{Duration: time.Second},
+					MaxDelay:     &metav1.Duration{Duration: time.Minute},
+					Multiplier:   int32Ptr(2),
+				},
+				DeadLetterStream: "my-dead-letters",
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid error policy",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in"},
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+			ErrorPolicy: &ErrorPolicy{
+				MaxRetries: int32Ptr(-1),
+				Backoff: &Backoff{
+					InitialDelay: &metav1.Duration{Duration: time.Minute},
+					MaxDelay:     &metav1.Duration{Duration: time.Second},
+					Multiplier:   int32Ptr(0),
+				},
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrInvalidValue(int32(-1), "errorPolicy.maxRetries"),
+			validation.ErrInvalidValue("1s", "errorPolicy.backoff.maxDelay"),
+			validation.ErrInvalidValue(int32(0), "errorPolicy.backoff.multiplier"),
+		),
+	}, {
+		name: "dead letter stream is an input",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in"},
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+			ErrorPolicy: &ErrorPolicy{
+				DeadLetterStream: "my-stream",
+			},
+		},
+		expected: validation.ErrDisallowedFields("errorPolicy.deadLetterStream", "must not be an input stream"),
+	}, {
+		name: "valid batch",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in"},
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+			Batch: &Batch{
+				MaxMessages:                int32Ptr(10),
+				BackoffLimit:               int32Ptr(0),
+				ActiveDeadlineSeconds:      int64Ptr(600),
+				SuccessfulJobsHistoryLimit: int32Ptr(0),
+				FailedJobsHistoryLimit:     int32Ptr(0),
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid batch",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in"},
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+			Batch: &Batch{
+				MaxMessages:                int32Ptr(0),
+				BackoffLimit:               int32Ptr(-1),
+				ActiveDeadlineSeconds:      int64Ptr(0),
+				SuccessfulJobsHistoryLimit: int32Ptr(-1),
+				FailedJobsHistoryLimit:     int32Ptr(-1),
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrInvalidValue(int32(0), "batch.maxMessages"),
+			validation.ErrInvalidValue(int32(-1), "batch.backoffLimit"),
+			validation.ErrInvalidValue(int64(0), "batch.activeDeadlineSeconds"),
+			validation.ErrInvalidValue(int32(-1), "batch.successfulJobsHistoryLimit"),
+			validation.ErrInvalidValue(int32(-1), "batch.failedJobsHistoryLimit"),
+		),
+	}, {
+		name: "valid replay",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in"},
+				{Stream: "my-other-stream", Alias: "other"},
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+			Replay: &Replay{
+				Token:    "2019-12-01",
+				Inputs:   []string{"other"},
+				Position: "2019-12-01T10:00:00Z",
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid replay",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in"},
+			},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "function"},
+				},
+			},
+			Replay: &Replay{
+				Inputs:   []string{"out"},
+				Position: "yesterday",
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrMissingField("replay.token"),
+			validation.ErrInvalidValue("yesterday", "replay.position"),
+			validation.ErrInvalidValue("out", "replay.inputs[0]"),
+		),
 	}} {
 		t.Run(c.name, func(t *testing.T) {
 			actual := c.target.Validate()
@@ -272,3 +709,59 @@ func TestValidateBuild(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateSidecar(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *Sidecar
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &Sidecar{},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "valid resources",
+		target: &Sidecar{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("100m"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("500m"),
+					corev1.ResourceMemory: resource.MustParse("128Mi"),
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "negative request",
+		target: &Sidecar{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("-128Mi"),
+				},
+			},
+		},
+		expected: validation.ErrInvalidValue("-128Mi", "resources.requests[memory]"),
+	}, {
+		name: "request above limit",
+		target: &Sidecar{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("1"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("500m"),
+				},
+			},
+		},
+		expected: validation.ErrInvalidValue("1", "resources.requests[cpu]"),
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateSidecar(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/projectriff/system/pkg/apis"
+)
+
+const (
+	ProviderConditionReady                                         = apis.ConditionReady
+	ProviderConditionClassReady                 apis.ConditionType = "ClassReady"
+	ProviderConditionGatewayDeploymentReady     apis.ConditionType = "GatewayDeploymentReady"
+	ProviderConditionGatewayServiceReady        apis.ConditionType = "GatewayServiceReady"
+	ProviderConditionProvisionerDeploymentReady apis.ConditionType = "ProvisionerDeploymentReady"
+	ProviderConditionProvisionerServiceReady    apis.ConditionType = "ProvisionerServiceReady"
+)
+
+var providerCondSet = apis.NewLivingConditionSet(
+	ProviderConditionClassReady,
+	ProviderConditionGatewayDeploymentReady,
+	ProviderConditionGatewayServiceReady,
+	ProviderConditionProvisionerDeploymentReady,
+	ProviderConditionProvisionerServiceReady,
+)
+
+func (ps *ProviderStatus) GetObservedGeneration() int64 {
+	return ps.ObservedGeneration
+}
+
+func (ps *ProviderStatus) IsReady() bool {
+	return providerCondSet.Manage(ps).IsHappy()
+}
+
+func (*ProviderStatus) GetReadyConditionType() apis.ConditionType {
+	return ProviderConditionReady
+}
+
+func (ps *ProviderStatus) GetCondition(t apis.ConditionType) *apis.Condition {
+	return providerCondSet.Manage(ps).GetCondition(t)
+}
+
+func (ps *ProviderStatus) InitializeConditions() {
+	providerCondSet.Manage(ps).InitializeConditions()
+}
+
+func (ps *ProviderStatus) MarkClassReady() {
+	providerCondSet.Manage(ps).MarkTrue(ProviderConditionClassReady)
+}
+
+func (ps *ProviderStatus) MarkClassNotFound(name string) {
+	providerCondSet.Manage(ps).MarkFalse(ProviderConditionClassReady, "ClassNotFound", "ProviderClass %q not found", name)
+}
+
+func (ps *ProviderStatus) PropagateGatewayDeploymentStatus(cds *appsv1.DeploymentStatus) {
+	var available, progressing *appsv1.DeploymentCondition
+	for i := range cds.Conditions {
+		switch cds.Conditions[i].Type {
+		case appsv1.DeploymentAvailable:
+			available = &cds.Conditions[i]
+		case appsv1.DeploymentProgressing:
+			progressing = &cds.Conditions[i]
+		}
+	}
+	if available == nil || progressing == nil {
+		return
+	}
+	if progressing.Status == corev1.ConditionTrue && available.Status == corev1.ConditionFalse {
+		// DeploymentAvailable is False while progressing, avoid reporting ProviderConditionReady as False
+		providerCondSet.Manage(ps).MarkUnknown(ProviderConditionGatewayDeploymentReady, progressing.Reason, progressing.Message)
+		return
+	}
+	switch {
+	case available.Status == corev1.ConditionUnknown:
+		providerCondSet.Manage(ps).MarkUnknown(ProviderConditionGatewayDeploymentReady, available.Reason, available.Message)
+	case available.Status == corev1.ConditionTrue:
+		providerCondSet.Manage(ps).MarkTrue(ProviderConditionGatewayDeploymentReady)
+	case available.Status == corev1.ConditionFalse:
+		providerCondSet.Manage(ps).MarkFalse(ProviderConditionGatewayDeploymentReady, available.Reason, available.Message)
+	}
+}
+
+func (ps *ProviderStatus) PropagateGatewayServiceStatus(ss *corev1.ServiceStatus) {
+	// services don't have meaningful status
+	providerCondSet.Manage(ps).MarkTrue(ProviderConditionGatewayServiceReady)
+}
+
+func (ps *ProviderStatus) PropagateProvisionerDeploymentStatus(cds *appsv1.DeploymentStatus) {
+	var available, progressing *appsv1.DeploymentCondition
+	for i := range cds.Conditions {
+		switch cds.Conditions[i].Type {
+		case appsv1.DeploymentAvailable:
+			available = &cds.Conditions[i]
+		case appsv1.DeploymentProgressing:
+			progressing = &cds.Conditions[i]
+		}
+	}
+	if available == nil || progressing == nil {
+		return
+	}
+	if progressing.Status == corev1.ConditionTrue && available.Status == corev1.ConditionFalse {
+		// DeploymentAvailable is False while progressing, avoid reporting ProviderConditionReady as False
+		providerCondSet.Manage(ps).MarkUnknown(ProviderConditionProvisionerDeploymentReady, progressing.Reason, progressing.Message)
+		return
+	}
+	switch {
+	case available.Status == corev1.ConditionUnknown:
+		providerCondSet.Manage(ps).MarkUnknown(ProviderConditionProvisionerDeploymentReady, available.Reason, available.Message)
+	case available.Status == corev1.ConditionTrue:
+		providerCondSet.Manage(ps).MarkTrue(ProviderConditionProvisionerDeploymentReady)
+	case available.Status == corev1.ConditionFalse:
+		providerCondSet.Manage(ps).MarkFalse(ProviderConditionProvisionerDeploymentReady, available.Reason, available.Message)
+	}
+}
+
+func (ps *ProviderStatus) PropagateProvisionerServiceStatus(ss *corev1.ServiceStatus) {
+	// services don't have meaningful status
+	providerCondSet.Manage(ps).MarkTrue(ProviderConditionProvisionerServiceReady)
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/projectriff/system/pkg/apis"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+var (
+	ProviderLabelKey            = GroupVersion.Group + "/provider"             // Identifies all resources originating from a provider
+	ProviderGatewayLabelKey     = GroupVersion.Group + "/provider-gateway"     // Used as a selector
+	ProviderProvisionerLabelKey = GroupVersion.Group + "/provider-provisioner" // Used as a selector
+)
+
+var (
+	_ apis.Resource = (*Provider)(nil)
+)
+
+// ProviderSpec defines the desired state of Provider
+type ProviderSpec struct {
+	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// Class is the name of the ProviderClass describing the gateway and
+	// provisioner to run
+	Class string `json:"class"`
+
+	// Config holds the settings for the provider class, like the address of
+	// the broker. The values are made available to the class's environment
+	// templates.
+	// +optional
+	Config map[string]string `json:"config,omitempty"`
+
+	// PositionStorage configures where the gateway persists consumer
+	// positions. Defaults to Memory.
+	// +optional
+	PositionStorage *PositionStorage `json:"positionStorage,omitempty"`
+
+	// AllowedNamespaces may use the provider for their streams, along with
+	// the provider's own namespace. Defaults to the provider's namespace only.
+	// +optional
+	AllowedNamespaces *AllowedNamespaces `json:"allowedNamespaces,omitempty"`
+}
+
+// ProviderStatus defines the observed state of Provider, it is shared by all
+// provider kinds
+type ProviderStatus struct {
+	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	apis.Status               `json:",inline"`
+	GatewayDeploymentName     string `json:"gatewayDeploymentName,omitempty"`
+	GatewayServiceName        string `json:"gatewayServiceName,omitempty"`
+	ProvisionerDeploymentName string `json:"provisionerDeploymentName,omitempty"`
+	ProvisionerServiceName    string `json:"provisionerServiceName,omitempty"`
+
+	// PositionStorageType is the storage used by the gateway for consumer
+	// positions
+	PositionStorageType PositionStorageType `json:"positionStorageType,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:categories="riff"
+// +kubebuilder:printcolumn:name="Class",type=string,JSONPath=`.spec.class`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+// +genclient
+
+// Provider is the Schema for the providers API
+type Provider struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProviderSpec   `json:"spec,omitempty"`
+	Status ProviderStatus `json:"status,omitempty"`
+}
+
+func (*Provider) GetGroupVersionKind() schema.GroupVersionKind {
+	return SchemeGroupVersion.WithKind("Provider")
+}
+
+func (p *Provider) GetStatus() apis.ResourceStatus {
+	return &p.Status
+}
+
+// +kubebuilder:object:root=true
+
+// ProviderList contains a list of Provider
+type ProviderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Provider `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Provider{}, &ProviderList{})
+}
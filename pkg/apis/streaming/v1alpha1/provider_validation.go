@@ -0,0 +1,81 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/projectriff/system/pkg/validation"
+)
+
+// +kubebuilder:webhook:path=/validate-streaming-projectriff-io-v1alpha1-provider,mutating=false,failurePolicy=fail,groups=streaming.projectriff.io,resources=providers,verbs=create;update,versions=v1alpha1,name=providers.streaming.projectriff.io
+
+var (
+	_ webhook.Validator         = &Provider{}
+	_ validation.FieldValidator = &Provider{}
+)
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Provider) ValidateCreate() error {
+	return r.Validate().ToAggregate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Provider) ValidateUpdate(old runtime.Object) error {
+	// TODO check for immutable fields
+	return r.Validate().ToAggregate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Provider) ValidateDelete() error {
+	return nil
+}
+
+func (r *Provider) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	errs = errs.Also(r.Spec.Validate().ViaField("spec"))
+
+	return errs
+}
+
+func (s *ProviderSpec) Validate() validation.FieldErrors {
+	if equality.Semantic.DeepEqual(s, &ProviderSpec{}) {
+		return validation.ErrMissingField(validation.CurrentField)
+	}
+
+	errs := validation.FieldErrors{}
+
+	if s.Class == "" {
+		errs = errs.Also(validation.ErrMissingField("class"))
+	} else if len(utilvalidation.IsDNS1123Subdomain(s.Class)) != 0 {
+		errs = errs.Also(validation.ErrInvalidValue(s.Class, "class"))
+	}
+
+	if s.PositionStorage != nil {
+		errs = errs.Also(s.PositionStorage.Validate().ViaField("positionStorage"))
+	}
+
+	if s.AllowedNamespaces != nil {
+		errs = errs.Also(s.AllowedNamespaces.Validate().ViaField("allowedNamespaces"))
+	}
+
+	return errs
+}
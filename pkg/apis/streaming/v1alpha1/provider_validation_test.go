@@ -0,0 +1,124 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/projectriff/system/pkg/validation"
+)
+
+func TestValidateProvider(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *Provider
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &Provider{},
+		expected: validation.ErrMissingField("spec"),
+	}, {
+		name: "valid",
+		target: &Provider{
+			Spec: ProviderSpec{
+				Class: "nats",
+			},
+		},
+		expected: validation.FieldErrors{},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateProvider(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
+
+func TestValidateProviderSpec(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *ProviderSpec
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &ProviderSpec{},
+		expected: validation.ErrMissingField(validation.CurrentField),
+	}, {
+		name: "valid",
+		target: &ProviderSpec{
+			Class: "nats",
+			Config: map[string]string{
+				"url": "nats://nats:4222",
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "requires class",
+		target: &ProviderSpec{
+			Config: map[string]string{
+				"url": "nats://nats:4222",
+			},
+		},
+		expected: validation.ErrMissingField("class"),
+	}, {
+		name: "invalid class",
+		target: &ProviderSpec{
+			Class: "NATS",
+		},
+		expected: validation.ErrInvalidValue("NATS", "class"),
+	}, {
+		name: "invalid position storage",
+		target: &ProviderSpec{
+			Class: "nats",
+			PositionStorage: &PositionStorage{
+				Type: PositionStorageTypeRedis,
+			},
+		},
+		expected: validation.ErrMissingField("positionStorage.redis"),
+	}, {
+		name: "allowed namespaces",
+		target: &ProviderSpec{
+			Class: "nats",
+			AllowedNamespaces: &AllowedNamespaces{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"env": "dev"},
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid allowed namespaces",
+		target: &ProviderSpec{
+			Class: "nats",
+			AllowedNamespaces: &AllowedNamespaces{
+				Names: []string{"dev", ""},
+			},
+		},
+		expected: validation.ErrInvalidArrayValue("", "allowedNamespaces.names", 1),
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateProviderSpec(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
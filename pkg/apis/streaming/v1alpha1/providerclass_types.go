@@ -0,0 +1,92 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// ProviderClassSpec defines the gateway and provisioner run for each Provider
+// of the class
+type ProviderClassSpec struct {
+	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// GatewayImage is the liiklus gateway image, built with the records
+	// storage plugin for the class's broker
+	GatewayImage string `json:"gatewayImage"`
+
+	// ProvisionerImage serves the provisioner REST API, creating topics on
+	// the broker for streams
+	ProvisionerImage string `json:"provisionerImage"`
+
+	// RecordsStorageType is the liiklus records storage plugin used by the
+	// gateway, like KAFKA or PULSAR.
+	RecordsStorageType string `json:"recordsStorageType"`
+
+	// GatewayEnv is the environment of the gateway container. Values are
+	// templates, see ProviderClass for the fields available.
+	// +optional
+	GatewayEnv []corev1.EnvVar `json:"gatewayEnv,omitempty"`
+
+	// ProvisionerEnv is the environment of the provisioner container, in
+	// addition to GATEWAY. Values are templates, see ProviderClass for the
+	// fields available.
+	// +optional
+	ProvisionerEnv []corev1.EnvVar `json:"provisionerEnv,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,categories="riff"
+// +kubebuilder:printcolumn:name="Records",type=string,JSONPath=`.spec.recordsStorageType`
+// +genclient
+// +genclient:nonNamespaced
+
+// ProviderClass is the Schema for the providerclasses API. A class describes
+// how to run a stream provider for a kind of broker, each Provider references
+// a class by name.
+//
+// Environment values, and the names of secrets referenced by secretKeyRef, are
+// Go templates rendered for each Provider, with the fields:
+//
+//	.Name       the name of the Provider
+//	.Namespace  the namespace of the Provider
+//	.Config     the Provider's config map, like {{ .Config.serviceURL }}
+//	.Gateway    the address of the gateway service, provisioner only
+type ProviderClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ProviderClassSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ProviderClassList contains a list of ProviderClass
+type ProviderClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProviderClass `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ProviderClass{}, &ProviderClassList{})
+}
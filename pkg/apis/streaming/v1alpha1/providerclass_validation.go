@@ -0,0 +1,112 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/projectriff/system/pkg/validation"
+)
+
+// +kubebuilder:webhook:path=/validate-streaming-projectriff-io-v1alpha1-providerclass,mutating=false,failurePolicy=fail,groups=streaming.projectriff.io,resources=providerclasses,verbs=create;update,versions=v1alpha1,name=providerclasses.streaming.projectriff.io
+
+var (
+	_ webhook.Validator         = &ProviderClass{}
+	_ validation.FieldValidator = &ProviderClass{}
+)
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ProviderClass) ValidateCreate() error {
+	return r.Validate().ToAggregate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ProviderClass) ValidateUpdate(old runtime.Object) error {
+	// TODO check for immutable fields
+	return r.Validate().ToAggregate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ProviderClass) ValidateDelete() error {
+	return nil
+}
+
+func (r *ProviderClass) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	errs = errs.Also(r.Spec.Validate().ViaField("spec"))
+
+	return errs
+}
+
+func (s *ProviderClassSpec) Validate() validation.FieldErrors {
+	if equality.Semantic.DeepEqual(s, &ProviderClassSpec{}) {
+		return validation.ErrMissingField(validation.CurrentField)
+	}
+
+	errs := validation.FieldErrors{}
+
+	if s.GatewayImage == "" {
+		errs = errs.Also(validation.ErrMissingField("gatewayImage"))
+	}
+	if s.ProvisionerImage == "" {
+		errs = errs.Also(validation.ErrMissingField("provisionerImage"))
+	}
+	if s.RecordsStorageType == "" {
+		errs = errs.Also(validation.ErrMissingField("recordsStorageType"))
+	}
+
+	for i, env := range s.GatewayEnv {
+		errs = errs.Also(validateEnvTemplate(env).ViaFieldIndex("gatewayEnv", i))
+	}
+	for i, env := range s.ProvisionerEnv {
+		errs = errs.Also(validateEnvTemplate(env).ViaFieldIndex("provisionerEnv", i))
+	}
+
+	return errs
+}
+
+// validateEnvTemplate checks the env var is named and its value and secret
+// name parse as templates
+func validateEnvTemplate(env corev1.EnvVar) validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if env.Name == "" {
+		errs = errs.Also(validation.ErrMissingField("name"))
+	}
+	if _, err := template.New(env.Name).Parse(env.Value); err != nil {
+		errs = errs.Also(validation.FieldErrors{
+			field.Invalid(field.NewPath("value"), env.Value, err.Error()),
+		})
+	}
+	if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+		name := env.ValueFrom.SecretKeyRef.Name
+		if _, err := template.New(env.Name).Parse(name); err != nil {
+			errs = errs.Also(validation.FieldErrors{
+				field.Invalid(field.NewPath("valueFrom", "secretKeyRef", "name"), name, err.Error()),
+			})
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/projectriff/system/pkg/validation"
+)
+
+func TestValidateProviderClass(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *ProviderClass
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &ProviderClass{},
+		expected: validation.ErrMissingField("spec"),
+	}, {
+		name: "valid",
+		target: &ProviderClass{
+			Spec: ProviderClassSpec{
+				GatewayImage:       "gateway",
+				ProvisionerImage:   "provisioner",
+				RecordsStorageType: "NATS",
+			},
+		},
+		expected: validation.FieldErrors{},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateProviderClass(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
+
+func TestValidateProviderClassSpec(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *ProviderClassSpec
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &ProviderClassSpec{},
+		expected: validation.ErrMissingField(validation.CurrentField),
+	}, {
+		name: "valid",
+		target: &ProviderClassSpec{
+			GatewayImage:       "gateway",
+			ProvisionerImage:   "provisioner",
+			RecordsStorageType: "NATS",
+			GatewayEnv: []corev1.EnvVar{
+				{Name: "nats_url", Value: "{{ .Config.url }}"},
+			},
+			ProvisionerEnv: []corev1.EnvVar{
+				{Name: "BROKER", Value: "{{ .Config.url }}"},
+				{Name: "GATEWAY_ADDRESS", Value: "{{ .Gateway }}"},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid secret name template",
+		target: &ProviderClassSpec{
+			GatewayImage:       "gateway",
+			ProvisionerImage:   "provisioner",
+			RecordsStorageType: "NATS",
+			GatewayEnv: []corev1.EnvVar{
+				{
+					Name: "nats_password",
+					ValueFrom: &corev1.EnvVarSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "{{ .Config.secret"},
+							Key:                  "password",
+						},
+					},
+				},
+			},
+		},
+		expected: validation.FieldErrors{
+			field.Invalid(field.NewPath("gatewayEnv[0].valueFrom.secretKeyRef.name"), "{{ .Config.secret", "template: nats_password:1: unclosed action"),
+		},
+	}, {
+		name: "requires gateway image",
+		target: &ProviderClassSpec{
+			ProvisionerImage:   "provisioner",
+			RecordsStorageType: "NATS",
+		},
+		expected: validation.ErrMissingField("gatewayImage"),
+	}, {
+		name: "requires provisioner image",
+		target: &ProviderClassSpec{
+			GatewayImage:       "gateway",
+			RecordsStorageType: "NATS",
+		},
+		expected: validation.ErrMissingField("provisionerImage"),
+	}, {
+		name: "requires records storage type",
+		target: &ProviderClassSpec{
+			GatewayImage:     "gateway",
+			ProvisionerImage: "provisioner",
+		},
+		expected: validation.ErrMissingField("recordsStorageType"),
+	}, {
+		name: "requires env name",
+		target: &ProviderClassSpec{
+			GatewayImage:       "gateway",
+			ProvisionerImage:   "provisioner",
+			RecordsStorageType: "NATS",
+			GatewayEnv: []corev1.EnvVar{
+				{Name: "nats_url", Value: "{{ .Config.url }}"},
+				{Value: "{{ .Config.url }}"},
+			},
+		},
+		expected: validation.ErrMissingField("gatewayEnv[1].name"),
+	}, {
+		name: "invalid env template",
+		target: &ProviderClassSpec{
+			GatewayImage:       "gateway",
+			ProvisionerImage:   "provisioner",
+			RecordsStorageType: "NATS",
+			ProvisionerEnv: []corev1.EnvVar{
+				{Name: "BROKER", Value: "{{ .Config.url"},
+			},
+		},
+		expected: validation.FieldErrors{
+			field.Invalid(field.NewPath("provisionerEnv[0].value"), "{{ .Config.url", "template: BROKER:1: unclosed action"),
+		},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateProviderClassSpec(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
@@ -15,7 +15,13 @@ limitations under the License.
 */
 package v1alpha1
 
-import "sigs.k8s.io/controller-runtime/pkg/webhook"
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
 
 // +kubebuilder:webhook:path=/mutate-streaming-projectriff-io-v1alpha1-pulsarprovider,mutating=true,failurePolicy=fail,groups=streaming.projectriff.io,resources=pulsarproviders,verbs=create;update,versions=v1alpha1,name=pulsarproviders.streaming.projectriff.io
 
@@ -27,4 +33,34 @@ func (r *PulsarProvider) Default() {
 }
 
 func (s *PulsarProviderSpec) Default() {
+	if s.AdminURL == "" {
+		s.AdminURL = defaultPulsarAdminURL(s.ServiceURL)
+	}
+	if s.PositionStorage == nil {
+		s.PositionStorage = &PositionStorage{}
+	}
+	s.PositionStorage.Default()
+}
+
+// defaultPulsarAdminURL is the web service of the first broker in the service
+// URL, empty when the service URL is not a Pulsar URL
+func defaultPulsarAdminURL(serviceURL string) string {
+	var scheme, port string
+	switch {
+	case strings.HasPrefix(serviceURL, "pulsar://"):
+		scheme, port = "http", "8080"
+	case strings.HasPrefix(serviceURL, "pulsar+ssl://"):
+		scheme, port = "https", "8443"
+	default:
+		return ""
+	}
+	hosts := serviceURL[strings.Index(serviceURL, "://")+3:]
+	host := strings.TrimSuffix(strings.Split(hosts, ",")[0], "/")
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, port))
 }
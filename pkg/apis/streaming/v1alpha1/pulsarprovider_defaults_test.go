@@ -0,0 +1,86 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPulsarProviderSpecDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *PulsarProviderSpec
+		want *PulsarProviderSpec
+	}{{
+		name: "empty",
+		in:   &PulsarProviderSpec{},
+		want: &PulsarProviderSpec{
+			PositionStorage: &PositionStorage{
+				Type: PositionStorageTypeMemory,
+			},
+		},
+	}, {
+		name: "admin url from service url",
+		in: &PulsarProviderSpec{
+			ServiceURL: "pulsar://pulsar-broker.pulsar:6650",
+		},
+		want: &PulsarProviderSpec{
+			ServiceURL: "pulsar://pulsar-broker.pulsar:6650",
+			AdminURL:   "http://pulsar-broker.pulsar:8080",
+			PositionStorage: &PositionStorage{
+				Type: PositionStorageTypeMemory,
+			},
+		},
+	}, {
+		name: "admin url from the first ssl host",
+		in: &PulsarProviderSpec{
+			ServiceURL: "pulsar+ssl://broker-1:6651,broker-2:6651",
+		},
+		want: &PulsarProviderSpec{
+			ServiceURL: "pulsar+ssl://broker-1:6651,broker-2:6651",
+			AdminURL:   "https://broker-1:8443",
+			PositionStorage: &PositionStorage{
+				Type: PositionStorageTypeMemory,
+			},
+		},
+	}, {
+		name: "keep admin url",
+		in: &PulsarProviderSpec{
+			ServiceURL: "pulsar://pulsar-broker:6650",
+			AdminURL:   "http://pulsar-admin:80",
+		},
+		want: &PulsarProviderSpec{
+			ServiceURL: "pulsar://pulsar-broker:6650",
+			AdminURL:   "http://pulsar-admin:80",
+			PositionStorage: &PositionStorage{
+				Type: PositionStorageTypeMemory,
+			},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.in
+			got.Default()
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Default (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
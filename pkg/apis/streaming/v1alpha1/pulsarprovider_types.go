@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
@@ -44,18 +45,39 @@ type PulsarProviderSpec struct {
 
 	// ServiceURL is the Pulsar URL to connect to, in the form pulsar://host:port[,host2:port2].
 	ServiceURL string `json:"serviceURL"`
+
+	// AdminURL is the Pulsar admin REST API the provisioner manages topics
+	// through, in the form http(s)://host:port. Defaults to the first host
+	// of the serviceURL on the default web service port, 8080, or 8443 for
+	// 'pulsar+ssl://'.
+	// +optional
+	AdminURL string `json:"adminURL,omitempty"`
+
+	// TLS secures connections to the brokers, the serviceURL must use the
+	// 'pulsar+ssl://' scheme
+	// +optional
+	TLS *ProviderTLS `json:"tls,omitempty"`
+
+	// TokenSecretRef references a Secret with a token used to authenticate
+	// with the brokers, under the key token. A token may not be combined
+	// with a client certificate.
+	// +optional
+	TokenSecretRef *corev1.LocalObjectReference `json:"tokenSecretRef,omitempty"`
+
+	// PositionStorage configures where the gateway persists consumer
+	// positions. Defaults to Memory.
+	// +optional
+	PositionStorage *PositionStorage `json:"positionStorage,omitempty"`
+
+	// AllowedNamespaces may use the provider for their streams, along with
+	// the provider's own namespace. Defaults to the provider's namespace only.
+	// +optional
+	AllowedNamespaces *AllowedNamespaces `json:"allowedNamespaces,omitempty"`
 }
 
 // PulsarProviderStatus defines the observed state of PulsarProvider
 type PulsarProviderStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
-
-	apis.Status               `json:",inline"`
-	GatewayDeploymentName     string `json:"gatewayDeploymentName,omitempty"`
-	GatewayServiceName        string `json:"gatewayServiceName,omitempty"`
-	ProvisionerDeploymentName string `json:"provisionerDeploymentName,omitempty"`
-	ProvisionerServiceName    string `json:"provisionerServiceName,omitempty"`
+	ProviderStatus `json:",inline"`
 }
 
 // +kubebuilder:object:root=true
@@ -73,5 +73,35 @@ func (s *PulsarProviderSpec) Validate() validation.FieldErrors {
 		})
 	}
 
+	if s.AdminURL != "" && !(strings.HasPrefix(s.AdminURL, "http://") || strings.HasPrefix(s.AdminURL, "https://")) {
+		errs = errs.Also(validation.FieldErrors{
+			field.Invalid(field.NewPath("adminURL"), s.AdminURL, "adminURL must use 'http://' or 'https://' scheme"),
+		})
+	}
+
+	if s.TLS != nil {
+		errs = errs.Also(s.TLS.Validate().ViaField("tls"))
+		if strings.HasPrefix(s.ServiceURL, "pulsar://") {
+			errs = errs.Also(validation.ErrDisallowedFields("tls", "tls requires a 'pulsar+ssl://' serviceURL"))
+		}
+	}
+
+	if s.TokenSecretRef != nil && s.TokenSecretRef.Name == "" {
+		errs = errs.Also(validation.ErrMissingField("tokenSecretRef.name"))
+	}
+
+	// pulsar clients authenticate with a single plugin
+	if s.TokenSecretRef != nil && s.TLS != nil && s.TLS.CertSecretRef != nil {
+		errs = errs.Also(validation.ErrMultipleOneOf("tls.certSecretRef", "tokenSecretRef"))
+	}
+
+	if s.PositionStorage != nil {
+		errs = errs.Also(s.PositionStorage.Validate().ViaField("positionStorage"))
+	}
+
+	if s.AllowedNamespaces != nil {
+		errs = errs.Also(s.AllowedNamespaces.Validate().ViaField("allowedNamespaces"))
+	}
+
 	return errs
 }
@@ -22,6 +22,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/projectriff/system/pkg/validation"
 )
@@ -80,6 +81,65 @@ func TestValidatePulsarProviderSpec(t *testing.T) {
 			ServiceURL: "localhost:6650",
 		},
 		expected: validation.FieldErrors{field.Invalid(field.NewPath("serviceURL"), "localhost:6650", "serviceURL must use 'pulsar://' or 'pulsar+ssl://' scheme")},
+	}, {
+		name: "admin url",
+		target: &PulsarProviderSpec{
+			ServiceURL: "pulsar://localhost:6650",
+			AdminURL:   "http://localhost:8080",
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid admin url",
+		target: &PulsarProviderSpec{
+			ServiceURL: "pulsar://localhost:6650",
+			AdminURL:   "localhost:8080",
+		},
+		expected: validation.FieldErrors{field.Invalid(field.NewPath("adminURL"), "localhost:8080", "adminURL must use 'http://' or 'https://' scheme")},
+	}, {
+		name: "tls and token",
+		target: &PulsarProviderSpec{
+			ServiceURL: "pulsar+ssl://localhost:6651",
+			TLS: &ProviderTLS{
+				CASecretRef: &corev1.LocalObjectReference{Name: "pulsar-ca"},
+			},
+			TokenSecretRef: &corev1.LocalObjectReference{Name: "pulsar-token"},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "client certificate and token",
+		target: &PulsarProviderSpec{
+			ServiceURL: "pulsar+ssl://localhost:6651",
+			TLS: &ProviderTLS{
+				CertSecretRef: &corev1.LocalObjectReference{Name: "pulsar-client"},
+			},
+			TokenSecretRef: &corev1.LocalObjectReference{Name: "pulsar-token"},
+		},
+		expected: validation.ErrMultipleOneOf("tls.certSecretRef", "tokenSecretRef"),
+	}, {
+		name: "tls without ssl scheme",
+		target: &PulsarProviderSpec{
+			ServiceURL: "pulsar://localhost:6650",
+			TLS: &ProviderTLS{
+				CertSecretRef: &corev1.LocalObjectReference{Name: "pulsar-client"},
+			},
+		},
+		expected: validation.ErrDisallowedFields("tls", "tls requires a 'pulsar+ssl://' serviceURL"),
+	}, {
+		name: "token missing name",
+		target: &PulsarProviderSpec{
+			ServiceURL:     "pulsar://localhost:6650",
+			TokenSecretRef: &corev1.LocalObjectReference{},
+		},
+		expected: validation.ErrMissingField("tokenSecretRef.name"),
+	}, {
+		name: "redis position storage missing redis",
+		target: &PulsarProviderSpec{
+			ServiceURL: "pulsar://localhost:6650",
+			PositionStorage: &PositionStorage{
+				Type: PositionStorageTypeRedis,
+			},
+		},
+		expected: validation.ErrMissingField("positionStorage.redis"),
 	}} {
 		t.Run(c.name, func(t *testing.T) {
 			actual := c.target.Validate()
@@ -0,0 +1,84 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PositionStorage configures where a gateway persists the positions of its
+// consumer groups. Positions held in memory are lost when the gateway
+// restarts.
+type PositionStorage struct {
+	// Type of storage used for consumer positions, either Memory or Redis.
+	// Defaults to Memory.
+	// +optional
+	Type PositionStorageType `json:"type,omitempty"`
+
+	// Redis server holding consumer positions, required when the type is
+	// Redis.
+	// +optional
+	Redis *RedisPositionStorage `json:"redis,omitempty"`
+}
+
+// PositionStorageType describes the kind of store used for consumer positions.
+type PositionStorageType string
+
+const (
+	// PositionStorageTypeMemory keeps positions within the gateway process
+	PositionStorageTypeMemory PositionStorageType = "Memory"
+	// PositionStorageTypeRedis persists positions within an external Redis server
+	PositionStorageTypeRedis PositionStorageType = "Redis"
+)
+
+// RedisPositionStorage locates a Redis server
+type RedisPositionStorage struct {
+	// Host name of the Redis server
+	Host string `json:"host"`
+
+	// Port of the Redis server. Defaults to 6379.
+	// +optional
+	Port int32 `json:"port,omitempty"`
+}
+
+// AllowedNamespaces lists the namespaces whose streams may use a provider, in
+// addition to the provider's own namespace. A namespace is allowed when it is
+// named or matches the selector.
+type AllowedNamespaces struct {
+	// Names of namespaces allowed to use the provider
+	// +optional
+	Names []string `json:"names,omitempty"`
+
+	// Selector matching the labels of namespaces allowed to use the provider
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// ProviderTLS secures connections to the brokers. Without a CA the system
+// trust store verifies the brokers.
+type ProviderTLS struct {
+	// CASecretRef references a Secret with the CA bundle trusted to verify
+	// the brokers, under the key ca.crt
+	// +optional
+	CASecretRef *corev1.LocalObjectReference `json:"caSecretRef,omitempty"`
+
+	// CertSecretRef references a kubernetes.io/tls Secret with the client
+	// certificate presented to the brokers, under the keys tls.crt and tls.key
+	// +optional
+	CertSecretRef *corev1.LocalObjectReference `json:"certSecretRef,omitempty"`
+}
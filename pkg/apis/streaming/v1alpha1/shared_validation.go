@@ -0,0 +1,91 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/projectriff/system/pkg/validation"
+)
+
+func (s *PositionStorage) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	switch s.Type {
+	case PositionStorageTypeMemory:
+		if s.Redis != nil {
+			errs = errs.Also(validation.ErrDisallowedFields("redis", "only allowed for Redis storage"))
+		}
+	case PositionStorageTypeRedis:
+		if s.Redis == nil {
+			errs = errs.Also(validation.ErrMissingField("redis"))
+		} else {
+			errs = errs.Also(s.Redis.Validate().ViaField("redis"))
+		}
+	default:
+		errs = errs.Also(validation.ErrInvalidValue(s.Type, "type"))
+	}
+
+	return errs
+}
+
+func (s *RedisPositionStorage) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if s.Host == "" {
+		errs = errs.Also(validation.ErrMissingField("host"))
+	}
+	if s.Port < 1 || s.Port > 65535 {
+		errs = errs.Also(validation.ErrInvalidValue(s.Port, "port"))
+	}
+
+	return errs
+}
+
+func (a *AllowedNamespaces) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	for i, name := range a.Names {
+		if len(utilvalidation.IsDNS1123Label(name)) != 0 {
+			errs = errs.Also(validation.ErrInvalidArrayValue(name, "names", i))
+		}
+	}
+	if a.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(a.Selector); err != nil {
+			errs = errs.Also(validation.FieldErrors{
+				field.Invalid(field.NewPath("selector"), a.Selector, err.Error()),
+			})
+		}
+	}
+
+	return errs
+}
+
+func (t *ProviderTLS) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if t.CASecretRef != nil && t.CASecretRef.Name == "" {
+		errs = errs.Also(validation.ErrMissingField("caSecretRef.name"))
+	}
+	if t.CertSecretRef != nil && t.CertSecretRef.Name == "" {
+		errs = errs.Also(validation.ErrMissingField("certSecretRef.name"))
+	}
+
+	return errs
+}
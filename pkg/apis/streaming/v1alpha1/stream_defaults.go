@@ -27,6 +27,9 @@ var _ webhook.Defaulter = &Stream{}
 // Default implements webhook.Defaulter so a webhook will be registered for the type
 func (r *Stream) Default() {
 	r.Spec.Default()
+	if r.Spec.Provider.Namespace == "" {
+		r.Spec.Provider.Namespace = r.Namespace
+	}
 }
 
 func (s *StreamSpec) Default() {
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestStreamDefault(t *testing.T) {
@@ -35,6 +36,36 @@ func TestStreamDefault(t *testing.T) {
 				ContentType: "application/octet-stream",
 			},
 		},
+	}, {
+		name: "provider namespace",
+		in: &Stream{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"},
+			Spec: StreamSpec{
+				Provider: StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka"},
+			},
+		},
+		want: &Stream{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"},
+			Spec: StreamSpec{
+				Provider:    StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka", Namespace: "team-a"},
+				ContentType: "application/octet-stream",
+			},
+		},
+	}, {
+		name: "shared provider",
+		in: &Stream{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"},
+			Spec: StreamSpec{
+				Provider: StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka", Namespace: "streaming"},
+			},
+		},
+		want: &Stream{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"},
+			Spec: StreamSpec{
+				Provider:    StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka", Namespace: "streaming"},
+				ContentType: "application/octet-stream",
+			},
+		},
 	}}
 
 	for _, test := range tests {
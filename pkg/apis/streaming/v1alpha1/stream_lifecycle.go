@@ -24,9 +24,13 @@ const (
 	StreamConditionReady                                = apis.ConditionReady
 	StreamConditionResourceAvailable apis.ConditionType = "ResourceAvailable"
 	StreamConditionBindingReady      apis.ConditionType = "BindingReady"
+	StreamConditionProviderReady     apis.ConditionType = "ProviderReady"
 )
 
+const streamDeprovisionUnsupportedReason = "DeprovisionUnsupported"
+
 var streamCondSet = apis.NewLivingConditionSet(
+	StreamConditionProviderReady,
 	StreamConditionResourceAvailable,
 	StreamConditionBindingReady,
 )
@@ -51,6 +55,22 @@ func (ss *StreamStatus) InitializeConditions() {
 	streamCondSet.Manage(ss).InitializeConditions()
 }
 
+func (ss *StreamStatus) MarkProviderReady() {
+	streamCondSet.Manage(ss).MarkTrue(StreamConditionProviderReady)
+}
+
+func (ss *StreamStatus) MarkProviderNotFound(kind, name string) {
+	streamCondSet.Manage(ss).MarkFalse(StreamConditionProviderReady, "NotFound", "%s %q not found", kind, name)
+}
+
+func (ss *StreamStatus) MarkProviderNotAllowed(kind, name, namespace string) {
+	streamCondSet.Manage(ss).MarkFalse(StreamConditionProviderReady, "NotAllowed", "%s %q does not allow streams from namespace %q", kind, name, namespace)
+}
+
+func (ss *StreamStatus) MarkProviderNotReady(message string) {
+	streamCondSet.Manage(ss).MarkFalse(StreamConditionProviderReady, "ProviderNotReady", message)
+}
+
 func (ss *StreamStatus) MarkStreamProvisioned() {
 	streamCondSet.Manage(ss).MarkTrue(StreamConditionReady)
 }
@@ -59,6 +79,25 @@ func (ss *StreamStatus) MarkStreamProvisionFailed(message string) {
 	streamCondSet.Manage(ss).MarkFalse(StreamConditionReady, "ProvisionFailed", message)
 }
 
+func (ss *StreamStatus) MarkStreamInUse(message string) {
+	streamCondSet.Manage(ss).MarkFalse(StreamConditionReady, "StreamInUse", message)
+}
+
+func (ss *StreamStatus) MarkStreamDeprovisionFailed(message string) {
+	streamCondSet.Manage(ss).MarkFalse(StreamConditionReady, "DeprovisionFailed", message)
+}
+
+func (ss *StreamStatus) MarkStreamDeprovisionUnsupported(topic string) {
+	streamCondSet.Manage(ss).MarkFalse(StreamConditionReady, streamDeprovisionUnsupportedReason, "the provisioner does not delete topics, topic %q must be deleted manually", topic)
+}
+
+// IsDeprovisionUnsupported checks if the provisioner refused to delete the
+// stream's topic
+func (ss *StreamStatus) IsDeprovisionUnsupported() bool {
+	ready := ss.GetCondition(StreamConditionReady)
+	return ready != nil && ready.Reason == streamDeprovisionUnsupportedReason
+}
+
 func (ss *StreamStatus) MarkBindingReady() {
 	streamCondSet.Manage(ss).MarkTrue(StreamConditionBindingReady)
 }
@@ -33,6 +33,13 @@ var (
 	StreamLabelKey = GroupVersion.Group + "/stream"
 )
 
+const (
+	KafkaProviderKind    = "KafkaProvider"
+	PulsarProviderKind   = "PulsarProvider"
+	InMemoryProviderKind = "InMemoryProvider"
+	ProviderKind         = "Provider"
+)
+
 var (
 	_ apis.Resource = (*Stream)(nil)
 )
@@ -42,8 +49,53 @@ type StreamSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	Provider    string `json:"provider"`
-	ContentType string `json:"contentType"`
+	// Provider responsible for provisioning the stream's topic
+	Provider    StreamProviderReference `json:"provider"`
+	ContentType string                  `json:"contentType"`
+
+	// Partitions for the stream's topic. May be increased, but not
+	// decreased, once provisioned. Defaults to the broker's setting.
+	// +optional
+	Partitions *int32 `json:"partitions,omitempty"`
+
+	// ReplicationFactor for the stream's topic. Cannot be changed once
+	// provisioned. Defaults to the broker's setting.
+	// +optional
+	ReplicationFactor *int32 `json:"replicationFactor,omitempty"`
+
+	// Retention limits how long messages are kept on the stream. Defaults to
+	// the broker's setting.
+	// +optional
+	Retention *StreamRetention `json:"retention,omitempty"`
+
+	// Compaction keeps only the most recent message for each key
+	// +optional
+	Compaction bool `json:"compaction,omitempty"`
+}
+
+// StreamProviderReference locates the provider of a stream
+type StreamProviderReference struct {
+	// Kind of the provider, one of KafkaProvider, PulsarProvider,
+	// InMemoryProvider or Provider
+	Kind string `json:"kind"`
+
+	// Name of the provider
+	Name string `json:"name"`
+
+	// Namespace of the provider. Defaults to the stream's namespace. The
+	// provider must allow streams from the stream's namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type StreamRetention struct {
+	// Time messages are kept before they are discarded
+	// +optional
+	Time *metav1.Duration `json:"time,omitempty"`
+
+	// Bytes kept per partition before the oldest messages are discarded
+	// +optional
+	Bytes *int64 `json:"bytes,omitempty"`
 }
 
 // StreamStatus defines the observed state of Stream
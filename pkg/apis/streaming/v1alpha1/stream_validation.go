@@ -38,8 +38,11 @@ func (r *Stream) ValidateCreate() error {
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (r *Stream) ValidateUpdate(old runtime.Object) error {
-	// TODO check for immutable fields
-	return r.Validate().ToAggregate()
+	errs := r.Validate()
+	if o, ok := old.(*Stream); ok {
+		errs = errs.Also(r.Spec.ValidateUpdate(&o.Spec).ViaField("spec"))
+	}
+	return errs.ToAggregate()
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -62,8 +65,73 @@ func (s *StreamSpec) Validate() validation.FieldErrors {
 
 	errs := validation.FieldErrors{}
 
-	if s.Provider == "" {
+	if s.Provider == (StreamProviderReference{}) {
 		errs = errs.Also(validation.ErrMissingField("provider"))
+	} else {
+		errs = errs.Also(s.Provider.Validate().ViaField("provider"))
+	}
+	if s.Partitions != nil && *s.Partitions < 1 {
+		errs = errs.Also(validation.ErrInvalidValue(*s.Partitions, "partitions"))
+	}
+	if s.ReplicationFactor != nil && *s.ReplicationFactor < 1 {
+		errs = errs.Also(validation.ErrInvalidValue(*s.ReplicationFactor, "replicationFactor"))
+	}
+	if s.Retention != nil {
+		errs = errs.Also(s.Retention.Validate().ViaField("retention"))
+	}
+
+	return errs
+}
+
+// ValidateUpdate rejects changes the provisioner cannot apply to an existing
+// topic
+func (s *StreamSpec) ValidateUpdate(old *StreamSpec) validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if s.Provider.Kind != old.Provider.Kind {
+		errs = errs.Also(validation.ErrDisallowedFields("provider.kind", "provider is immutable"))
+	}
+	if s.Provider.Name != old.Provider.Name {
+		errs = errs.Also(validation.ErrDisallowedFields("provider.name", "provider is immutable"))
+	}
+	if s.Provider.Namespace != old.Provider.Namespace {
+		errs = errs.Also(validation.ErrDisallowedFields("provider.namespace", "provider is immutable"))
+	}
+	if old.Partitions != nil && (s.Partitions == nil || *s.Partitions < *old.Partitions) {
+		errs = errs.Also(validation.ErrDisallowedFields("partitions", "partitions may not be decreased"))
+	}
+	if !equality.Semantic.DeepEqual(s.ReplicationFactor, old.ReplicationFactor) {
+		errs = errs.Also(validation.ErrDisallowedFields("replicationFactor", "replicationFactor is immutable"))
+	}
+
+	return errs
+}
+
+func (r *StreamProviderReference) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	switch r.Kind {
+	case "":
+		errs = errs.Also(validation.ErrMissingField("kind"))
+	case KafkaProviderKind, PulsarProviderKind, InMemoryProviderKind, ProviderKind:
+	default:
+		errs = errs.Also(validation.ErrInvalidValue(r.Kind, "kind"))
+	}
+	if r.Name == "" {
+		errs = errs.Also(validation.ErrMissingField("name"))
+	}
+
+	return errs
+}
+
+func (r *StreamRetention) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if r.Time != nil && r.Time.Duration <= 0 {
+		errs = errs.Also(validation.ErrInvalidValue(r.Time.Duration.String(), "time"))
+	}
+	if r.Bytes != nil && *r.Bytes <= 0 {
+		errs = errs.Also(validation.ErrInvalidValue(*r.Bytes, "bytes"))
 	}
 
 	return errs
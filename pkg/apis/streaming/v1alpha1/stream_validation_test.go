@@ -18,8 +18,10 @@ package v1alpha1
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/projectriff/system/pkg/validation"
 )
@@ -37,7 +39,7 @@ func TestValidateStream(t *testing.T) {
 		name: "valid",
 		target: &Stream{
 			Spec: StreamSpec{
-				Provider:    "kafka",
+				Provider:    StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka"},
 				ContentType: "application/json",
 			},
 		},
@@ -64,23 +66,88 @@ func TestValidateStreamSpec(t *testing.T) {
 	}, {
 		name: "valid",
 		target: &StreamSpec{
-			Provider:    "kafka",
+			Provider:    StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka"},
 			ContentType: "video/mp4",
 		},
 		expected: validation.FieldErrors{},
 	}, {
 		name: "valid without explicit content-type",
 		target: &StreamSpec{
-			Provider: "kafka",
+			Provider: StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka"},
 		},
 		expected: validation.FieldErrors{},
 	}, {
 		name: "requires provider",
 		target: &StreamSpec{
-			Provider:    "",
 			ContentType: "image/*",
 		},
 		expected: validation.ErrMissingField("provider"),
+	}, {
+		name: "pulsar provider",
+		target: &StreamSpec{
+			Provider: StreamProviderReference{Kind: PulsarProviderKind, Name: "pulsar"},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "in memory provider",
+		target: &StreamSpec{
+			Provider: StreamProviderReference{Kind: InMemoryProviderKind, Name: "dev"},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "generic provider",
+		target: &StreamSpec{
+			Provider: StreamProviderReference{Kind: ProviderKind, Name: "nats"},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "requires provider name",
+		target: &StreamSpec{
+			Provider: StreamProviderReference{Kind: KafkaProviderKind},
+		},
+		expected: validation.ErrMissingField("provider.name"),
+	}, {
+		name: "requires provider kind",
+		target: &StreamSpec{
+			Provider: StreamProviderReference{Name: "kafka"},
+		},
+		expected: validation.ErrMissingField("provider.kind"),
+	}, {
+		name: "invalid provider kind",
+		target: &StreamSpec{
+			Provider: StreamProviderReference{Kind: "Deployer", Name: "kafka"},
+		},
+		expected: validation.ErrInvalidValue("Deployer", "provider.kind"),
+	}, {
+		name: "valid topic settings",
+		target: &StreamSpec{
+			Provider:          StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka"},
+			Partitions:        int32Ptr(3),
+			ReplicationFactor: int32Ptr(2),
+			Retention: &StreamRetention{
+				Time:  &metav1.Duration{Duration: 24 * time.Hour},
+				Bytes: int64Ptr(1 << 30),
+			},
+			Compaction: true,
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid topic settings",
+		target: &StreamSpec{
+			Provider:          StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka"},
+			Partitions:        int32Ptr(0),
+			ReplicationFactor: int32Ptr(0),
+			Retention: &StreamRetention{
+				Time:  &metav1.Duration{},
+				Bytes: int64Ptr(-1),
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrInvalidValue(int32(0), "partitions"),
+			validation.ErrInvalidValue(int32(0), "replicationFactor"),
+			validation.ErrInvalidValue("0s", "retention.time"),
+			validation.ErrInvalidValue(int64(-1), "retention.bytes"),
+		),
 	}} {
 		t.Run(c.name, func(t *testing.T) {
 			actual := c.target.Validate()
@@ -90,3 +157,112 @@ func TestValidateStreamSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateStreamSpecUpdate(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *StreamSpec
+		old      *StreamSpec
+		expected validation.FieldErrors
+	}{{
+		name: "unchanged",
+		target: &StreamSpec{
+			Provider:          StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka"},
+			Partitions:        int32Ptr(3),
+			ReplicationFactor: int32Ptr(2),
+		},
+		old: &StreamSpec{
+			Provider:          StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka"},
+			Partitions:        int32Ptr(3),
+			ReplicationFactor: int32Ptr(2),
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "in place changes",
+		target: &StreamSpec{
+			Provider:   StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka"},
+			Partitions: int32Ptr(6),
+			Retention: &StreamRetention{
+				Time: &metav1.Duration{Duration: time.Hour},
+			},
+			Compaction: true,
+		},
+		old: &StreamSpec{
+			Provider:   StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka"},
+			Partitions: int32Ptr(3),
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "decrease partitions",
+		target: &StreamSpec{
+			Provider:   StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka"},
+			Partitions: int32Ptr(1),
+		},
+		old: &StreamSpec{
+			Provider:   StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka"},
+			Partitions: int32Ptr(3),
+		},
+		expected: validation.ErrDisallowedFields("partitions", "partitions may not be decreased"),
+	}, {
+		name: "change replication factor",
+		target: &StreamSpec{
+			Provider:          StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka"},
+			ReplicationFactor: int32Ptr(3),
+		},
+		old: &StreamSpec{
+			Provider: StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka"},
+		},
+		expected: validation.ErrDisallowedFields("replicationFactor", "replicationFactor is immutable"),
+	}, {
+		name: "change provider kind",
+		target: &StreamSpec{
+			Provider: StreamProviderReference{Kind: PulsarProviderKind, Name: "kafka"},
+		},
+		old: &StreamSpec{
+			Provider: StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka"},
+		},
+		expected: validation.ErrDisallowedFields("provider.kind", "provider is immutable"),
+	}, {
+		name: "change provider name",
+		target: &StreamSpec{
+			Provider: StreamProviderReference{Kind: KafkaProviderKind, Name: "other"},
+		},
+		old: &StreamSpec{
+			Provider: StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka"},
+		},
+		expected: validation.ErrDisallowedFields("provider.name", "provider is immutable"),
+	}, {
+		name: "change provider namespace",
+		target: &StreamSpec{
+			Provider: StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka", Namespace: "other"},
+		},
+		old: &StreamSpec{
+			Provider: StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka", Namespace: "default"},
+		},
+		expected: validation.ErrDisallowedFields("provider.namespace", "provider is immutable"),
+	}, {
+		name: "change provider",
+		target: &StreamSpec{
+			Provider: StreamProviderReference{Kind: ProviderKind, Name: "other", Namespace: "other"},
+		},
+		old: &StreamSpec{
+			Provider: StreamProviderReference{Kind: KafkaProviderKind, Name: "kafka", Namespace: "default"},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrDisallowedFields("provider.kind", "provider is immutable"),
+			validation.ErrDisallowedFields("provider.name", "provider is immutable"),
+			validation.ErrDisallowedFields("provider.namespace", "provider is immutable"),
+		),
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.ValidateUpdate(c.old)
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateStreamSpecUpdate(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
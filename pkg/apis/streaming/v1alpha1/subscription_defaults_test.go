@@ -0,0 +1,78 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSubscriptionSpecDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *SubscriptionSpec
+		want *SubscriptionSpec
+	}{{
+		name: "empty",
+		in:   &SubscriptionSpec{},
+		want: &SubscriptionSpec{
+			Delivery: DeliveryGuaranteeAtLeastOnce,
+			Scaling: &Scaling{
+				MinReplicas:     int32Ptr(0),
+				MaxReplicas:     int32Ptr(30),
+				CooldownPeriod:  int32Ptr(30),
+				PollingInterval: int32Ptr(1),
+			},
+		},
+	}, {
+		name: "error policy",
+		in: &SubscriptionSpec{
+			Delivery:    DeliveryGuaranteeAtMostOnce,
+			ErrorPolicy: &ErrorPolicy{},
+		},
+		want: &SubscriptionSpec{
+			Delivery: DeliveryGuaranteeAtMostOnce,
+			Scaling: &Scaling{
+				MinReplicas:     int32Ptr(0),
+				MaxReplicas:     int32Ptr(30),
+				CooldownPeriod:  int32Ptr(30),
+				PollingInterval: int32Ptr(1),
+			},
+			ErrorPolicy: &ErrorPolicy{
+				MaxRetries: int32Ptr(3),
+				Backoff: &Backoff{
+					InitialDelay: &metav1.Duration{Duration: time.Second},
+					MaxDelay:     &metav1.Duration{Duration: time.Minute},
+					Multiplier:   int32Ptr(2),
+				},
+			},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.in
+			got.Default()
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Default (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/projectriff/system/pkg/apis"
+	kedav1alpha1 "github.com/projectriff/system/pkg/apis/thirdparty/keda/v1alpha1"
+)
+
+const (
+	SubscriptionConditionReady                                = apis.ConditionReady
+	SubscriptionConditionStreamsReady      apis.ConditionType = "StreamsReady"
+	SubscriptionConditionSubscriberReady   apis.ConditionType = "SubscriberReady"
+	SubscriptionConditionDeploymentReady   apis.ConditionType = "DeploymentReady"
+	SubscriptionConditionScaledObjectReady apis.ConditionType = "ScaledObjectReady"
+)
+
+var subscriptionCondSet = apis.NewLivingConditionSet(
+	SubscriptionConditionStreamsReady,
+	SubscriptionConditionSubscriberReady,
+	SubscriptionConditionDeploymentReady,
+	SubscriptionConditionScaledObjectReady,
+)
+
+func (ss *SubscriptionStatus) GetObservedGeneration() int64 {
+	return ss.ObservedGeneration
+}
+
+func (ss *SubscriptionStatus) IsReady() bool {
+	return subscriptionCondSet.Manage(ss).IsHappy()
+}
+
+func (*SubscriptionStatus) GetReadyConditionType() apis.ConditionType {
+	return SubscriptionConditionReady
+}
+
+func (ss *SubscriptionStatus) GetCondition(t apis.ConditionType) *apis.Condition {
+	return subscriptionCondSet.Manage(ss).GetCondition(t)
+}
+
+func (ss *SubscriptionStatus) InitializeConditions() {
+	subscriptionCondSet.Manage(ss).InitializeConditions()
+}
+
+func (ss *SubscriptionStatus) MarkStreamsReady() {
+	subscriptionCondSet.Manage(ss).MarkTrue(SubscriptionConditionStreamsReady)
+}
+
+func (ss *SubscriptionStatus) MarkStreamsNotReady(message string) {
+	subscriptionCondSet.Manage(ss).MarkFalse(SubscriptionConditionStreamsReady, "StreamNotReady", message)
+}
+
+func (ss *SubscriptionStatus) MarkSubscriberReady() {
+	subscriptionCondSet.Manage(ss).MarkTrue(SubscriptionConditionSubscriberReady)
+}
+
+func (ss *SubscriptionStatus) MarkSubscriberNotReady(message string) {
+	subscriptionCondSet.Manage(ss).MarkFalse(SubscriptionConditionSubscriberReady, "SubscriberNotReady", message)
+}
+
+func (ss *SubscriptionStatus) PropagateDeploymentStatus(ds *appsv1.DeploymentStatus) {
+	var available, progressing *appsv1.DeploymentCondition
+	for i := range ds.Conditions {
+		switch ds.Conditions[i].Type {
+		case appsv1.DeploymentAvailable:
+			available = &ds.Conditions[i]
+		case appsv1.DeploymentProgressing:
+			progressing = &ds.Conditions[i]
+		}
+	}
+	if available == nil || progressing == nil {
+		return
+	}
+	if progressing.Status == corev1.ConditionTrue && available.Status == corev1.ConditionFalse {
+		// DeploymentAvailable is False while progressing, avoid reporting SubscriptionConditionReady as False
+		subscriptionCondSet.Manage(ss).MarkUnknown(SubscriptionConditionDeploymentReady, progressing.Reason, progressing.Message)
+		return
+	}
+	switch {
+	case available.Status == corev1.ConditionUnknown:
+		subscriptionCondSet.Manage(ss).MarkUnknown(SubscriptionConditionDeploymentReady, available.Reason, available.Message)
+	case available.Status == corev1.ConditionTrue:
+		subscriptionCondSet.Manage(ss).MarkTrue(SubscriptionConditionDeploymentReady)
+	case available.Status == corev1.ConditionFalse:
+		subscriptionCondSet.Manage(ss).MarkFalse(SubscriptionConditionDeploymentReady, available.Reason, available.Message)
+	}
+}
+
+func (ss *SubscriptionStatus) PropagateScaledObjectStatus(sos *kedav1alpha1.ScaledObjectStatus) {
+	// TODO: ScaledObject does not report much atm
+	subscriptionCondSet.Manage(ss).MarkTrue(SubscriptionConditionScaledObjectReady)
+}
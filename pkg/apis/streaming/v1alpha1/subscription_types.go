@@ -0,0 +1,167 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/projectriff/system/pkg/apis"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+var (
+	SubscriptionLabelKey = GroupVersion.Group + "/subscription"
+)
+
+var (
+	_ apis.Resource = (*Subscription)(nil)
+)
+
+// SubscriptionSpec defines the desired state of Subscription
+type SubscriptionSpec struct {
+	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// Stream name, from this namespace, to deliver messages from
+	Stream string `json:"stream"`
+
+	// Subscriber receives each message as the body of an HTTP POST request
+	Subscriber Subscriber `json:"subscriber"`
+
+	// Reply stream name, from this namespace, that receives the body of the
+	// subscriber's responses. When not specified, responses are discarded.
+	// +optional
+	Reply string `json:"reply,omitempty"`
+
+	// Delivery guarantee for messages, either AtLeastOnce or AtMostOnce.
+	// Defaults to AtLeastOnce.
+	// +optional
+	Delivery DeliveryGuarantee `json:"delivery,omitempty"`
+
+	// LagThreshold is the number of pending messages on the stream that
+	// warrants another replica
+	// +optional
+	LagThreshold *int32 `json:"lagThreshold,omitempty"`
+
+	// Scaling bounds and tunes the autoscaling of the subscription
+	// +optional
+	Scaling *Scaling `json:"scaling,omitempty"`
+
+	// ErrorPolicy controls how messages the subscriber fails to accept are
+	// retried and where they end up once retries are exhausted. Only allowed
+	// for AtLeastOnce delivery.
+	// +optional
+	ErrorPolicy *ErrorPolicy `json:"errorPolicy,omitempty"`
+}
+
+// Subscriber is the target of a subscription, either an addressable
+// resource or a URI.
+type Subscriber struct {
+	// Ref to an addressable resource in this namespace. Messages are
+	// delivered to the URL in the resource's status.address, core and
+	// Knative Deployers are addressable.
+	// +optional
+	Ref *SubscriberReference `json:"ref,omitempty"`
+
+	// URI messages are delivered to
+	// +optional
+	URI string `json:"uri,omitempty"`
+}
+
+type SubscriberReference struct {
+	// APIVersion of the referenced resource
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the referenced resource
+	Kind string `json:"kind"`
+
+	// Name of the referenced resource
+	Name string `json:"name"`
+}
+
+// DeliveryGuarantee describes when a message is acknowledged on the stream.
+// AtLeastOnce acknowledges a message once the subscriber accepted it,
+// retrying failures. AtMostOnce acknowledges a message before delivering it,
+// a failed delivery is not retried.
+type DeliveryGuarantee string
+
+const (
+	DeliveryGuaranteeAtLeastOnce DeliveryGuarantee = "AtLeastOnce"
+	DeliveryGuaranteeAtMostOnce  DeliveryGuarantee = "AtMostOnce"
+)
+
+// SubscriptionStatus defines the observed state of Subscription
+type SubscriptionStatus struct {
+	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	apis.Status `json:",inline"`
+
+	InputAddress      string `json:"inputAddress,omitempty"`
+	ReplyAddress      string `json:"replyAddress,omitempty"`
+	DeadLetterAddress string `json:"deadLetterAddress,omitempty"`
+
+	// SubscriberURL is the resolved target of the subscription
+	SubscriberURL string `json:"subscriberURL,omitempty"`
+
+	DeploymentName   string `json:"deploymentName,omitempty"`
+	ScaledObjectName string `json:"scaledObjectName,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:categories="riff"
+// +kubebuilder:printcolumn:name="Stream",type=string,JSONPath=`.spec.stream`
+// +kubebuilder:printcolumn:name="Subscriber",type=string,JSONPath=`.status.subscriberURL`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+// +genclient
+
+// Subscription is the Schema for the subscriptions API. It delivers the
+// messages of a stream to an HTTP subscriber, optionally publishing the
+// subscriber's responses to a reply stream.
+type Subscription struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SubscriptionSpec   `json:"spec,omitempty"`
+	Status SubscriptionStatus `json:"status,omitempty"`
+}
+
+func (*Subscription) GetGroupVersionKind() schema.GroupVersionKind {
+	return SchemeGroupVersion.WithKind("Subscription")
+}
+
+func (s *Subscription) GetStatus() apis.ResourceStatus {
+	return &s.Status
+}
+
+// +kubebuilder:object:root=true
+
+// SubscriptionList contains a list of Subscription
+type SubscriptionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Subscription `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Subscription{}, &SubscriptionList{})
+}
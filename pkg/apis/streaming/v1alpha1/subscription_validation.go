@@ -0,0 +1,135 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"net/url"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/projectriff/system/pkg/validation"
+)
+
+// +kubebuilder:webhook:path=/validate-streaming-projectriff-io-v1alpha1-subscription,mutating=false,failurePolicy=fail,groups=streaming.projectriff.io,resources=subscriptions,verbs=create;update,versions=v1alpha1,name=subscriptions.streaming.projectriff.io
+
+var (
+	_ webhook.Validator         = &Subscription{}
+	_ validation.FieldValidator = &Subscription{}
+)
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Subscription) ValidateCreate() error {
+	return r.Validate().ToAggregate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Subscription) ValidateUpdate(old runtime.Object) error {
+	// TODO check for immutable fields
+	return r.Validate().ToAggregate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Subscription) ValidateDelete() error {
+	return nil
+}
+
+func (r *Subscription) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	errs = errs.Also(r.Spec.Validate().ViaField("spec"))
+
+	return errs
+}
+
+func (s *SubscriptionSpec) Validate() validation.FieldErrors {
+	if equality.Semantic.DeepEqual(s, &SubscriptionSpec{}) {
+		return validation.ErrMissingField(validation.CurrentField)
+	}
+
+	errs := validation.FieldErrors{}
+
+	if s.Stream == "" {
+		errs = errs.Also(validation.ErrMissingField("stream"))
+	}
+	errs = errs.Also(s.Subscriber.Validate().ViaField("subscriber"))
+	if s.Reply != "" && s.Reply == s.Stream {
+		// replies would be delivered to the subscriber again
+		errs = errs.Also(validation.ErrDisallowedFields("reply", "must not be the subscribed stream"))
+	}
+	switch s.Delivery {
+	case DeliveryGuaranteeAtLeastOnce, DeliveryGuaranteeAtMostOnce:
+	default:
+		errs = errs.Also(validation.ErrInvalidValue(s.Delivery, "delivery"))
+	}
+	if s.LagThreshold != nil && *s.LagThreshold < 1 {
+		errs = errs.Also(validation.ErrInvalidValue(*s.LagThreshold, "lagThreshold"))
+	}
+
+	if s.Scaling != nil {
+		errs = errs.Also(s.Scaling.Validate().ViaField("scaling"))
+	}
+
+	if s.ErrorPolicy != nil {
+		if s.Delivery == DeliveryGuaranteeAtMostOnce {
+			errs = errs.Also(validation.ErrDisallowedFields("errorPolicy", "failed deliveries are not retried for AtMostOnce delivery"))
+		}
+		errs = errs.Also(s.ErrorPolicy.Validate().ViaField("errorPolicy"))
+		if s.ErrorPolicy.DeadLetterStream != "" && s.ErrorPolicy.DeadLetterStream == s.Stream {
+			// dead letters would be delivered to the subscriber again
+			errs = errs.Also(validation.ErrDisallowedFields("errorPolicy.deadLetterStream", "must not be the subscribed stream"))
+		}
+	}
+
+	return errs
+}
+
+func (s *Subscriber) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	switch {
+	case s.Ref == nil && s.URI == "":
+		errs = errs.Also(validation.ErrMissingOneOf("ref", "uri"))
+	case s.Ref != nil && s.URI != "":
+		errs = errs.Also(validation.ErrMultipleOneOf("ref", "uri"))
+	case s.Ref != nil:
+		errs = errs.Also(s.Ref.Validate().ViaField("ref"))
+	default:
+		if u, err := url.Parse(s.URI); err != nil || !u.IsAbs() {
+			errs = errs.Also(validation.ErrInvalidValue(s.URI, "uri"))
+		}
+	}
+
+	return errs
+}
+
+func (r *SubscriberReference) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if r.APIVersion == "" {
+		errs = errs.Also(validation.ErrMissingField("apiVersion"))
+	}
+	if r.Kind == "" {
+		errs = errs.Also(validation.ErrMissingField("kind"))
+	}
+	if r.Name == "" {
+		errs = errs.Also(validation.ErrMissingField("name"))
+	}
+
+	return errs
+}
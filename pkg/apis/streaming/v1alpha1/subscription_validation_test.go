@@ -0,0 +1,172 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/projectriff/system/pkg/validation"
+)
+
+func TestValidateSubscription(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *Subscription
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &Subscription{},
+		expected: validation.ErrMissingField("spec"),
+	}, {
+		name: "valid",
+		target: &Subscription{
+			Spec: SubscriptionSpec{
+				Stream:     "my-stream",
+				Subscriber: Subscriber{URI: "http://example.com"},
+				Delivery:   DeliveryGuaranteeAtLeastOnce,
+			},
+		},
+		expected: validation.FieldErrors{},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateSubscription(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
+
+func TestValidateSubscriptionSpec(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *SubscriptionSpec
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &SubscriptionSpec{},
+		expected: validation.ErrMissingField(validation.CurrentField),
+	}, {
+		name: "valid, ref",
+		target: &SubscriptionSpec{
+			Stream: "my-stream",
+			Subscriber: Subscriber{
+				Ref: &SubscriberReference{APIVersion: "core.projectriff.io/v1alpha1", Kind: "Deployer", Name: "my-deployer"},
+			},
+			Reply:        "my-replies",
+			Delivery:     DeliveryGuaranteeAtLeastOnce,
+			LagThreshold: int32Ptr(10),
+			ErrorPolicy: &ErrorPolicy{
+				MaxRetries:       int32Ptr(3),
+				DeadLetterStream: "my-dead-letters",
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "requires stream and subscriber",
+		target: &SubscriptionSpec{
+			Delivery: DeliveryGuaranteeAtLeastOnce,
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrMissingField("stream"),
+			validation.ErrMissingOneOf("ref", "uri").ViaField("subscriber"),
+		),
+	}, {
+		name: "subscriber ref and uri",
+		target: &SubscriptionSpec{
+			Stream: "my-stream",
+			Subscriber: Subscriber{
+				Ref: &SubscriberReference{APIVersion: "core.projectriff.io/v1alpha1", Kind: "Deployer", Name: "my-deployer"},
+				URI: "http://example.com",
+			},
+			Delivery: DeliveryGuaranteeAtLeastOnce,
+		},
+		expected: validation.ErrMultipleOneOf("ref", "uri").ViaField("subscriber"),
+	}, {
+		name: "invalid subscriber",
+		target: &SubscriptionSpec{
+			Stream: "my-stream",
+			Subscriber: Subscriber{
+				Ref: &SubscriberReference{},
+			},
+			Delivery: DeliveryGuaranteeAtLeastOnce,
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrMissingField("subscriber.ref.apiVersion"),
+			validation.ErrMissingField("subscriber.ref.kind"),
+			validation.ErrMissingField("subscriber.ref.name"),
+		),
+	}, {
+		name: "relative uri",
+		target: &SubscriptionSpec{
+			Stream:     "my-stream",
+			Subscriber: Subscriber{URI: "/path"},
+			Delivery:   DeliveryGuaranteeAtLeastOnce,
+		},
+		expected: validation.ErrInvalidValue("/path", "subscriber.uri"),
+	}, {
+		name: "reply to the subscribed stream",
+		target: &SubscriptionSpec{
+			Stream:     "my-stream",
+			Subscriber: Subscriber{URI: "http://example.com"},
+			Reply:      "my-stream",
+			Delivery:   DeliveryGuaranteeAtLeastOnce,
+		},
+		expected: validation.ErrDisallowedFields("reply", "must not be the subscribed stream"),
+	}, {
+		name: "invalid delivery and lag threshold",
+		target: &SubscriptionSpec{
+			Stream:       "my-stream",
+			Subscriber:   Subscriber{URI: "http://example.com"},
+			Delivery:     DeliveryGuarantee("bogus"),
+			LagThreshold: int32Ptr(0),
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrInvalidValue(DeliveryGuarantee("bogus"), "delivery"),
+			validation.ErrInvalidValue(int32(0), "lagThreshold"),
+		),
+	}, {
+		name: "error policy for at most once delivery",
+		target: &SubscriptionSpec{
+			Stream:      "my-stream",
+			Subscriber:  Subscriber{URI: "http://example.com"},
+			Delivery:    DeliveryGuaranteeAtMostOnce,
+			ErrorPolicy: &ErrorPolicy{},
+		},
+		expected: validation.ErrDisallowedFields("errorPolicy", "failed deliveries are not retried for AtMostOnce delivery"),
+	}, {
+		name: "dead letters to the subscribed stream",
+		target: &SubscriptionSpec{
+			Stream:     "my-stream",
+			Subscriber: Subscriber{URI: "http://example.com"},
+			Delivery:   DeliveryGuaranteeAtLeastOnce,
+			ErrorPolicy: &ErrorPolicy{
+				DeadLetterStream: "my-stream",
+			},
+		},
+		expected: validation.ErrDisallowedFields("errorPolicy.deadLetterStream", "must not be the subscribed stream"),
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateSubscriptionSpec(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
@@ -55,6 +55,11 @@ type ScaledObjectSpec struct {
 // ScaledObjectScaleType distinguish between Deployment based and K8s Jobs
 type ScaledObjectScaleType string
 
+const (
+	ScaleTypeDeployment ScaledObjectScaleType = "deployment"
+	ScaleTypeJob        ScaledObjectScaleType = "job"
+)
+
 // ObjectReference holds the a reference to the deployment this
 // ScaledObject applies
 type ObjectReference struct {
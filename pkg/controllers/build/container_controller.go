@@ -18,7 +18,10 @@ package build
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
@@ -33,16 +36,23 @@ import (
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
 )
 
+// kpackDockerAnnotationKey identifies the registry for basic-auth credentials
+const kpackDockerAnnotationKey = "build.pivotal.io/docker"
+
 // ContainerReconciler reconciles a Container object
 type ContainerReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log                 logr.Logger
+	Scheme              *runtime.Scheme
+	ImageDigestResolver ImageDigestResolver
+	// PollInterval is the period between checks of the registry for a new image
+	PollInterval time.Duration
 }
 
 // +kubebuilder:rbac:groups=build.projectriff.io,resources=containers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=build.projectriff.io,resources=containers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts;secrets,verbs=get;list;watch
 
 func (r *ContainerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -95,15 +105,21 @@ func (r *ContainerReconciler) reconcile(ctx context.Context, log logr.Logger, co
 		}
 		return ctrl.Result{}, err
 	}
-	container.Status.MarkImageResolved()
 	container.Status.TargetImage = targetImage
 
-	// TODO resolve to a digest
-	container.Status.LatestImage = container.Status.TargetImage
+	// resolve the current digest for the target image
+	latestImage, err := r.resolveLatestImage(ctx, log, container)
+	if err != nil {
+		container.Status.MarkImageDigestUnresolved(err.Error())
+		return ctrl.Result{}, err
+	}
+	container.Status.MarkImageResolved()
+	container.Status.LatestImage = latestImage
 
 	container.Status.ObservedGeneration = container.Generation
 
-	return ctrl.Result{}, nil
+	// check back later for changes to the tag
+	return ctrl.Result{RequeueAfter: r.PollInterval}, nil
 }
 
 func (r *ContainerReconciler) resolveTargetImage(ctx context.Context, log logr.Logger, container *buildv1alpha1.Container) (string, error) {
@@ -129,6 +145,91 @@ func (r *ContainerReconciler) resolveTargetImage(ctx context.Context, log logr.L
 	return image, nil
 }
 
+func (r *ContainerReconciler) resolveLatestImage(ctx context.Context, log logr.Logger, container *buildv1alpha1.Container) (string, error) {
+	credentials, err := r.registryCredentials(ctx, container.Namespace)
+	if err != nil {
+		return "", err
+	}
+	return r.ImageDigestResolver.ResolveDigest(ctx, container.Status.TargetImage, credentials)
+}
+
+// registryCredentials collects the registry credentials bound to the riff-build
+// service account
+func (r *ContainerReconciler) registryCredentials(ctx context.Context, namespace string) (RegistryCredentials, error) {
+	credentials := RegistryCredentials{}
+
+	var serviceAccount v1.ServiceAccount
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: riffBuildServiceAccount}, &serviceAccount); err != nil {
+		if apierrs.IsNotFound(err) {
+			// public images don't need credentials
+			return credentials, nil
+		}
+		return nil, err
+	}
+
+	secretNames := []string{}
+	for _, secret := range serviceAccount.Secrets {
+		secretNames = append(secretNames, secret.Name)
+	}
+	for _, secret := range serviceAccount.ImagePullSecrets {
+		secretNames = append(secretNames, secret.Name)
+	}
+	for _, secretName := range secretNames {
+		var secret v1.Secret
+		if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: secretName}, &secret); err != nil {
+			if apierrs.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		addRegistryCredentials(credentials, &secret)
+	}
+
+	return credentials, nil
+}
+
+func addRegistryCredentials(credentials RegistryCredentials, secret *v1.Secret) {
+	type dockerConfigEntry struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Auth     string `json:"auth"`
+	}
+	addDockerConfig := func(auths map[string]dockerConfigEntry) {
+		for registry, entry := range auths {
+			if entry.Username == "" && entry.Auth != "" {
+				if decoded, err := base64.StdEncoding.DecodeString(entry.Auth); err == nil {
+					if parts := strings.SplitN(string(decoded), ":", 2); len(parts) == 2 {
+						entry.Username, entry.Password = parts[0], parts[1]
+					}
+				}
+			}
+			credentials[normalizeRegistry(registry)] = RegistryCredential{Username: entry.Username, Password: entry.Password}
+		}
+	}
+
+	switch secret.Type {
+	case v1.SecretTypeDockerConfigJson:
+		config := struct {
+			Auths map[string]dockerConfigEntry `json:"auths"`
+		}{}
+		if err := json.Unmarshal(secret.Data[v1.DockerConfigJsonKey], &config); err == nil {
+			addDockerConfig(config.Auths)
+		}
+	case v1.SecretTypeDockercfg:
+		config := map[string]dockerConfigEntry{}
+		if err := json.Unmarshal(secret.Data[v1.DockerConfigKey], &config); err == nil {
+			addDockerConfig(config)
+		}
+	case v1.SecretTypeBasicAuth:
+		if registry, ok := secret.Annotations[kpackDockerAnnotationKey]; ok {
+			credentials[normalizeRegistry(registry)] = RegistryCredential{
+				Username: string(secret.Data[v1.BasicAuthUsernameKey]),
+				Password: string(secret.Data[v1.BasicAuthPasswordKey]),
+			}
+		}
+	}
+}
+
 func (r *ContainerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&buildv1alpha1.Container{}).
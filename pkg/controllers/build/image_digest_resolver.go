@@ -0,0 +1,302 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	dockerHubRegistry = "index.docker.io"
+	dockerHubAPIHost  = "registry-1.docker.io"
+)
+
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+}
+
+// RegistryCredential authenticates requests to an image registry
+type RegistryCredential struct {
+	Username string
+	Password string
+}
+
+// RegistryCredentials holds credentials keyed by registry host
+type RegistryCredentials map[string]RegistryCredential
+
+// Lookup finds the credential for a registry host, if any
+func (c RegistryCredentials) Lookup(registry string) (RegistryCredential, bool) {
+	cred, ok := c[normalizeRegistry(registry)]
+	return cred, ok
+}
+
+// ImageDigestResolver resolves an image tag to the digest it currently points at
+type ImageDigestResolver interface {
+	ResolveDigest(ctx context.Context, image string, credentials RegistryCredentials) (string, error)
+}
+
+type imageDigestRestResolver struct {
+	httpClient *http.Client
+	logger     logr.Logger
+}
+
+func NewImageDigestResolver(httpClient *http.Client, logger logr.Logger) ImageDigestResolver {
+	return &imageDigestRestResolver{
+		httpClient: httpClient,
+		logger:     logger,
+	}
+}
+
+// ResolveDigest returns the image as `repository@sha256:...`. Images that are
+// already pinned to a digest are returned unchanged.
+func (r *imageDigestRestResolver) ResolveDigest(ctx context.Context, image string, credentials RegistryCredentials) (string, error) {
+	ref, err := parseImageReference(image)
+	if err != nil {
+		return "", err
+	}
+	if ref.digest != "" {
+		return image, nil
+	}
+
+	manifestURL := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", ref.scheme(), ref.apiHost(), ref.path, ref.tag)
+	cred, hasCred := credentials.Lookup(ref.registry)
+
+	res, err := r.fetchManifest(ctx, http.MethodHead, manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode == http.StatusUnauthorized {
+		authorization, err := r.authorize(ctx, res.Header.Get("WWW-Authenticate"), ref, cred, hasCred)
+		r.close(res)
+		if err != nil {
+			return "", err
+		}
+		res, err = r.fetchManifest(ctx, http.MethodHead, manifestURL, authorization)
+		if err != nil {
+			return "", err
+		}
+	}
+	defer r.close(res)
+	if res.StatusCode >= 400 {
+		return "", fmt.Errorf("unable to fetch manifest for %q, status: %d", image, res.StatusCode)
+	}
+
+	digest := res.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		// not all registries report the digest for HEAD requests, compute it from the manifest
+		getRes, err := r.fetchManifest(ctx, http.MethodGet, manifestURL, res.Request.Header.Get("Authorization"))
+		if err != nil {
+			return "", err
+		}
+		defer r.close(getRes)
+		if getRes.StatusCode >= 400 {
+			return "", fmt.Errorf("unable to fetch manifest for %q, status: %d", image, getRes.StatusCode)
+		}
+		manifest, err := ioutil.ReadAll(getRes.Body)
+		if err != nil {
+			return "", err
+		}
+		digest = fmt.Sprintf("sha256:%x", sha256.Sum256(manifest))
+	}
+	if !strings.HasPrefix(digest, "sha256:") {
+		return "", fmt.Errorf("unsupported digest %q for %q", digest, image)
+	}
+
+	return fmt.Sprintf("%s@%s", ref.repository, digest), nil
+}
+
+func (r *imageDigestRestResolver) fetchManifest(ctx context.Context, method, manifestURL, authorization string) (*http.Response, error) {
+	req, err := http.NewRequest(method, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ","))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	return r.httpClient.Do(req)
+}
+
+// authorize computes the Authorization header value for a challenge issued by
+// the registry
+func (r *imageDigestRestResolver) authorize(ctx context.Context, challenge string, ref *imageReference, cred RegistryCredential, hasCred bool) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	switch scheme {
+	case "basic":
+		if !hasCred {
+			return "", fmt.Errorf("registry %q requires credentials", ref.registry)
+		}
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(cred.Username, cred.Password)
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+		tokenURL, err := url.Parse(params["realm"])
+		if err != nil || params["realm"] == "" {
+			return "", fmt.Errorf("invalid bearer realm %q from registry %q", params["realm"], ref.registry)
+		}
+		query := tokenURL.Query()
+		if service, ok := params["service"]; ok {
+			query.Set("service", service)
+		}
+		query.Set("scope", fmt.Sprintf("repository:%s:pull", ref.path))
+		tokenURL.RawQuery = query.Encode()
+
+		req, err := http.NewRequest(http.MethodGet, tokenURL.String(), nil)
+		if err != nil {
+			return "", err
+		}
+		req = req.WithContext(ctx)
+		if hasCred {
+			req.SetBasicAuth(cred.Username, cred.Password)
+		}
+		res, err := r.httpClient.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer r.close(res)
+		if res.StatusCode >= 400 {
+			msg, _ := ioutil.ReadAll(res.Body)
+			return "", fmt.Errorf("unable to obtain registry token, status: %d, body: %q", res.StatusCode, string(msg))
+		}
+		token := struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}{}
+		if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+			return "", err
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		return "Bearer " + token.Token, nil
+	}
+	return "", fmt.Errorf("unsupported authentication challenge %q from registry %q", challenge, ref.registry)
+}
+
+func (r *imageDigestRestResolver) close(res *http.Response) {
+	if err := res.Body.Close(); err != nil {
+		r.logger.Error(err, "Error closing registry response body")
+	}
+}
+
+type imageReference struct {
+	// repository as written in the original image, without the tag or digest
+	repository string
+	registry   string
+	path       string
+	tag        string
+	digest     string
+}
+
+func (r *imageReference) apiHost() string {
+	if r.registry == dockerHubRegistry {
+		return dockerHubAPIHost
+	}
+	return r.registry
+}
+
+// scheme uses plain http for registries on the local machine, similar to the
+// docker daemon's default insecure registries
+func (r *imageReference) scheme() string {
+	host := r.registry
+	if i := strings.LastIndex(host, ":"); i != -1 {
+		host = host[:i]
+	}
+	if host == "localhost" || host == "127.0.0.1" || strings.HasSuffix(host, ".local") {
+		return "http"
+	}
+	return "https"
+}
+
+func parseImageReference(image string) (*imageReference, error) {
+	ref := &imageReference{repository: image}
+	if i := strings.Index(ref.repository, "@"); i != -1 {
+		ref.digest = ref.repository[i+1:]
+		ref.repository = ref.repository[:i]
+	}
+	if i := strings.LastIndex(ref.repository, ":"); i > strings.LastIndex(ref.repository, "/") {
+		ref.tag = ref.repository[i+1:]
+		ref.repository = ref.repository[:i]
+	}
+	if ref.tag == "" {
+		ref.tag = "latest"
+	}
+	if ref.repository == "" || strings.ToLower(ref.repository) != ref.repository {
+		return nil, fmt.Errorf("invalid image %q", image)
+	}
+
+	parts := strings.SplitN(ref.repository, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref.registry = parts[0]
+		ref.path = parts[1]
+	} else {
+		ref.registry = dockerHubRegistry
+		ref.path = ref.repository
+	}
+	ref.registry = normalizeRegistry(ref.registry)
+	if ref.registry == dockerHubRegistry && !strings.Contains(ref.path, "/") {
+		ref.path = "library/" + ref.path
+	}
+
+	return ref, nil
+}
+
+// normalizeRegistry reduces the many ways to write a registry, like
+// `https://index.docker.io/v1/`, to its host
+func normalizeRegistry(registry string) string {
+	registry = strings.TrimPrefix(registry, "https://")
+	registry = strings.TrimPrefix(registry, "http://")
+	if i := strings.Index(registry, "/"); i != -1 {
+		registry = registry[:i]
+	}
+	switch registry {
+	case "docker.io", dockerHubAPIHost:
+		return dockerHubRegistry
+	}
+	return registry
+}
+
+// parseChallenge splits a WWW-Authenticate header into its scheme and params
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	scheme := strings.ToLower(parts[0])
+	if len(parts) == 1 {
+		return scheme, params
+	}
+	for _, param := range strings.Split(parts[1], ",") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		params[strings.ToLower(kv[0])] = strings.Trim(kv[1], `"`)
+	}
+	return scheme, params
+}
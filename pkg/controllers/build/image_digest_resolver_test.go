@@ -0,0 +1,215 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const testDigest = "sha256:0c36e28fd2a6ab66a11e3c79a0eb4bb04f46a66fb66c69c2f8e5f2b5a2b94a6d"
+
+// registryStandIn mimics the parts of the registry API used to resolve digests
+func registryStandIn(t *testing.T, challenge string, username, password string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if u, p, ok := r.BasicAuth(); !ok || u != username || p != password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if scope := r.URL.Query().Get("scope"); scope != "repository:riff/app:pull" {
+				t.Errorf("unexpected token scope %q", scope)
+			}
+			fmt.Fprint(w, `{"token": "secret-token"}`)
+		case r.URL.Path == "/v2/riff/app/manifests/v1":
+			authorized := false
+			switch challenge {
+			case "":
+				authorized = true
+			case "Basic":
+				u, p, ok := r.BasicAuth()
+				authorized = ok && u == username && p == password
+			case "Bearer":
+				authorized = r.Header.Get("Authorization") == "Bearer secret-token"
+			}
+			if !authorized {
+				if challenge == "Bearer" {
+					w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, server.URL))
+				} else {
+					w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+				}
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", testDigest)
+		case r.URL.Path == "/v2/riff/nodigest/manifests/latest":
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, `{"schemaVersion": 2}`)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestResolveDigest(t *testing.T) {
+	for _, c := range []struct {
+		name        string
+		challenge   string
+		image       string
+		credentials RegistryCredentials
+		expected    string
+		expectErr   bool
+	}{{
+		name:     "anonymous",
+		image:    "%s/riff/app:v1",
+		expected: "%s/riff/app@" + testDigest,
+	}, {
+		name:      "basic auth",
+		challenge: "Basic",
+		image:     "%s/riff/app:v1",
+		credentials: RegistryCredentials{
+			"%s": {Username: "user", Password: "pass"},
+		},
+		expected: "%s/riff/app@" + testDigest,
+	}, {
+		name:      "bearer token",
+		challenge: "Bearer",
+		image:     "%s/riff/app:v1",
+		credentials: RegistryCredentials{
+			"%s": {Username: "user", Password: "pass"},
+		},
+		expected: "%s/riff/app@" + testDigest,
+	}, {
+		name:      "bearer token with bad credentials",
+		challenge: "Bearer",
+		image:     "%s/riff/app:v1",
+		credentials: RegistryCredentials{
+			"%s": {Username: "user", Password: "wrong"},
+		},
+		expectErr: true,
+	}, {
+		name:      "missing credentials",
+		challenge: "Basic",
+		image:     "%s/riff/app:v1",
+		expectErr: true,
+	}, {
+		name:     "computed digest",
+		image:    "%s/riff/nodigest",
+		expected: fmt.Sprintf("%%s/riff/nodigest@sha256:%x", sha256.Sum256([]byte(`{"schemaVersion": 2}`))),
+	}, {
+		name:     "already a digest",
+		image:    "%s/riff/app@" + testDigest,
+		expected: "%s/riff/app@" + testDigest,
+	}, {
+		name:      "unknown image",
+		image:     "%s/riff/missing:v1",
+		expectErr: true,
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			server := registryStandIn(t, c.challenge, "user", "pass")
+			defer server.Close()
+			registry := strings.TrimPrefix(server.URL, "http://")
+
+			credentials := RegistryCredentials{}
+			for k, v := range c.credentials {
+				credentials[fmt.Sprintf(k, registry)] = v
+			}
+
+			resolver := NewImageDigestResolver(server.Client(), ctrl.Log)
+			actual, err := resolver.ResolveDigest(context.Background(), fmt.Sprintf(c.image, registry), credentials)
+			if c.expectErr {
+				if err == nil {
+					t.Errorf("ResolveDigest(%s) expected error, got %q", c.name, actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveDigest(%s) unexpected error: %v", c.name, err)
+			}
+			if diff := cmp.Diff(fmt.Sprintf(c.expected, registry), actual); diff != "" {
+				t.Errorf("ResolveDigest(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
+
+func TestParseImageReference(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		image    string
+		expected *imageReference
+	}{{
+		name:  "docker hub library",
+		image: "ubuntu",
+		expected: &imageReference{
+			repository: "ubuntu",
+			registry:   "index.docker.io",
+			path:       "library/ubuntu",
+			tag:        "latest",
+		},
+	}, {
+		name:  "docker hub",
+		image: "projectriff/app:1.0",
+		expected: &imageReference{
+			repository: "projectriff/app",
+			registry:   "index.docker.io",
+			path:       "projectriff/app",
+			tag:        "1.0",
+		},
+	}, {
+		name:  "registry with port",
+		image: "localhost:5000/app",
+		expected: &imageReference{
+			repository: "localhost:5000/app",
+			registry:   "localhost:5000",
+			path:       "app",
+			tag:        "latest",
+		},
+	}, {
+		name:  "digest",
+		image: "gcr.io/riff/app@" + testDigest,
+		expected: &imageReference{
+			repository: "gcr.io/riff/app",
+			registry:   "gcr.io",
+			path:       "riff/app",
+			tag:        "latest",
+			digest:     testDigest,
+		},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := parseImageReference(c.image)
+			if err != nil {
+				t.Fatalf("parseImageReference(%s) unexpected error: %v", c.name, err)
+			}
+			if diff := cmp.Diff(c.expected, actual, cmp.AllowUnexported(imageReference{})); diff != "" {
+				t.Errorf("parseImageReference(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+const (
+	kustomizePrefix = "riff-core" // kustomize adds this prefix to all our resource names
+
+	ingressConfig    = kustomizePrefix + "-ingress" // contains cluster wide ingress settings
+	ingressDomainKey = "domain"
+
+	scheduleConfig          = kustomizePrefix + "-schedule" // contains image names for scheduled invocations
+	scheduleTriggerImageKey = "triggerImage"
+)
@@ -18,17 +18,22 @@ package core
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -40,6 +45,7 @@ import (
 	"github.com/projectriff/system/pkg/apis"
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
 	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
 	"github.com/projectriff/system/pkg/controllers"
 	"github.com/projectriff/system/pkg/tracker"
 )
@@ -48,16 +54,16 @@ const (
 	deploymentIndexField = ".metadata.deploymentController"
 	serviceIndexField    = ".metadata.serviceController"
 	ingressIndexField    = ".metadata.ingressController"
-
-	domain = "example.com"
+	autoscalerIndexField = ".metadata.autoscalerController"
 )
 
 // DeployerReconciler reconciles a Deployer object
 type DeployerReconciler struct {
 	client.Client
-	Log     logr.Logger
-	Scheme  *runtime.Scheme
-	Tracker tracker.Tracker
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Tracker   tracker.Tracker
+	Namespace string
 }
 
 // +kubebuilder:rbac:groups=core.projectriff.io,resources=deployers,verbs=get;list;watch;create;update;patch;delete
@@ -66,6 +72,9 @@ type DeployerReconciler struct {
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=streams,verbs=get;list;watch
 
 func (r *DeployerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -119,8 +128,21 @@ func (r *DeployerReconciler) reconcile(ctx context.Context, log logr.Logger, dep
 		return ctrl.Result{Requeue: true}, err
 	}
 
+	// resolve stream bindings
+	streams, notReady, err := r.resolveStreamBindings(ctx, deployer)
+	if err != nil {
+		log.Error(err, "unable to resolve streams for Deployer", "deployer", deployer)
+		return ctrl.Result{}, err
+	}
+	if notReady != "" {
+		// wait for the streams to be bound before rolling out the workload
+		deployer.Status.MarkStreamsNotReady(notReady)
+		return ctrl.Result{}, nil
+	}
+	deployer.Status.MarkStreamsReady()
+
 	// reconcile deployment
-	childDeployment, err := r.reconcileChildDeployment(ctx, log, deployer)
+	childDeployment, requeueAfter, err := r.reconcileChildDeployment(ctx, log, deployer, streams)
 	if err != nil {
 		log.Error(err, "unable to reconcile child Deployment", "deployer", deployer)
 		return ctrl.Result{}, err
@@ -128,6 +150,20 @@ func (r *DeployerReconciler) reconcile(ctx context.Context, log logr.Logger, dep
 	deployer.Status.DeploymentName = childDeployment.Name
 	deployer.Status.PropagateDeploymentStatus(&childDeployment.Status)
 
+	// reconcile autoscaler
+	childAutoscaler, err := r.reconcileChildAutoscaler(ctx, log, deployer)
+	if err != nil {
+		log.Error(err, "unable to reconcile child HorizontalPodAutoscaler", "deployer", deployer)
+		return ctrl.Result{}, err
+	}
+	if childAutoscaler == nil {
+		deployer.Status.AutoscalerName = ""
+		deployer.Status.MarkAutoscalerNotRequired()
+	} else {
+		deployer.Status.AutoscalerName = childAutoscaler.Name
+		deployer.Status.PropagateAutoscalerStatus(&childAutoscaler.Status)
+	}
+
 	// reconcile service
 	childService, err := r.reconcileChildService(ctx, log, deployer)
 	if err != nil {
@@ -138,8 +174,15 @@ func (r *DeployerReconciler) reconcile(ctx context.Context, log logr.Logger, dep
 	deployer.Status.Address = &apis.Addressable{URL: fmt.Sprintf("http://%s.%s.%s", childService.Name, childService.Namespace, "svc.cluster.local")}
 	deployer.Status.PropagateServiceStatus(&childService.Status)
 
+	// resolve ingress domain
+	domain, err := r.resolveIngressDomain(ctx, deployer)
+	if err != nil {
+		log.Error(err, "unable to resolve ingress domain", "deployer", deployer)
+		return ctrl.Result{}, err
+	}
+
 	// reconcile ingress
-	childIngress, err := r.reconcileIngress(ctx, log, deployer, childService.Name)
+	childIngress, err := r.reconcileIngress(ctx, log, deployer, domain)
 	if err != nil {
 		log.Error(err, "unable to reconcile Ingress", "deployer", deployer)
 		return ctrl.Result{}, err
@@ -147,15 +190,36 @@ func (r *DeployerReconciler) reconcile(ctx context.Context, log logr.Logger, dep
 	if childIngress == nil {
 		deployer.Status.IngressName = ""
 		deployer.Status.URL = ""
-		deployer.Status.MarkIngressNotRequired()
+		if deployer.Spec.IngressPolicy == corev1alpha1.IngressPolicyClusterLocal {
+			deployer.Status.MarkIngressNotRequired()
+		} else {
+			deployer.Status.MarkIngressDomainMissing(fmt.Sprintf("the %q key of ConfigMap %q in namespace %q must define the domain for ingress hosts", ingressDomainKey, ingressConfig, r.Namespace))
+		}
 	} else {
 		deployer.Status.IngressName = childIngress.Name
-		deployer.Status.URL = fmt.Sprintf("http://%s", childIngress.Spec.Rules[0].Host)
+		deployer.Status.URL = controllers.IngressURL(childIngress)
 		deployer.Status.PropagateIngressStatus(&childIngress.Status)
 	}
 
 	deployer.Status.ObservedGeneration = deployer.Generation
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+func (r *DeployerReconciler) resolveIngressDomain(ctx context.Context, deployer *corev1alpha1.Deployer) (string, error) {
+	var config corev1.ConfigMap
+	key := types.NamespacedName{Namespace: r.Namespace, Name: ingressConfig}
+	// track config map for domain changes
+	r.Tracker.Track(
+		tracker.NewKey(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, key),
+		types.NamespacedName{Namespace: deployer.Namespace, Name: deployer.Name},
+	)
+	if err := r.Get(ctx, key, &config); err != nil {
+		if apierrs.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return config.Data[ingressDomainKey], nil
 }
 
 func (r *DeployerReconciler) reconcileBuildImage(ctx context.Context, log logr.Logger, deployer *corev1alpha1.Deployer) error {
@@ -222,28 +286,45 @@ func (r *DeployerReconciler) reconcileBuildImage(ctx context.Context, log logr.L
 	return fmt.Errorf("invalid deployer build")
 }
 
-func (r *DeployerReconciler) reconcileChildDeployment(ctx context.Context, log logr.Logger, deployer *corev1alpha1.Deployer) (*appsv1.Deployment, error) {
-	var actualDeployment appsv1.Deployment
+func (r *DeployerReconciler) resolveStreamBindings(ctx context.Context, deployer *corev1alpha1.Deployer) ([]controllers.StreamBinding, string, error) {
+	bindings := make([]controllers.StreamBinding, len(deployer.Spec.Streams))
+	for i, stream := range deployer.Spec.Streams {
+		bindings[i] = controllers.StreamBinding{Stream: stream.Stream, Alias: stream.Alias}
+		if stream.Mode == corev1alpha1.StreamBindingModeSubscribe {
+			bindings[i].Group = controllers.ConsumerGroup("core-deployer", deployer.Name)
+		}
+	}
+	owner := types.NamespacedName{Namespace: deployer.Namespace, Name: deployer.Name}
+	notReady, err := controllers.ResolveStreamBindings(ctx, r.Client, r.Tracker, owner, bindings)
+	return bindings, notReady, err
+}
+
+func (r *DeployerReconciler) reconcileChildDeployment(ctx context.Context, log logr.Logger, deployer *corev1alpha1.Deployer, streams []controllers.StreamBinding) (*appsv1.Deployment, time.Duration, error) {
 	var childDeployments appsv1.DeploymentList
 	if err := r.List(ctx, &childDeployments, client.InNamespace(deployer.Namespace), client.MatchingField(deploymentIndexField, deployer.Name)); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	// TODO do we need to remove resources pending deletion?
-	if len(childDeployments.Items) == 1 {
-		actualDeployment = childDeployments.Items[0]
-	} else if len(childDeployments.Items) > 1 {
-		// this shouldn't happen, delete everything to a clean slate
-		for _, extraDeployment := range childDeployments.Items {
-			log.Info("deleting extra deployment", "deployment", extraDeployment)
-			if err := r.Delete(ctx, &extraDeployment); err != nil {
-				return nil, err
-			}
+	var stableDeployments, canaryDeployments []appsv1.Deployment
+	for _, deployment := range childDeployments.Items {
+		if _, ok := deployment.Labels[corev1alpha1.DeployerCanaryLabelKey]; ok {
+			canaryDeployments = append(canaryDeployments, deployment)
+		} else {
+			stableDeployments = append(stableDeployments, deployment)
 		}
 	}
+	// TODO do we need to remove resources pending deletion?
+	actualDeployment, err := r.singleDeployment(ctx, log, stableDeployments)
+	if err != nil {
+		return nil, 0, err
+	}
+	actualCanary, err := r.singleDeployment(ctx, log, canaryDeployments)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	desiredDeployment, err := r.constructDeploymentForDeployer(deployer)
+	desiredDeployment, err := r.constructDeploymentForDeployer(deployer, streams)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	// delete deployment if no longer needed
@@ -251,27 +332,61 @@ func (r *DeployerReconciler) reconcileChildDeployment(ctx context.Context, log l
 		log.Info("deleting deployment", "deployment", actualDeployment)
 		if err := r.Delete(ctx, &actualDeployment); err != nil {
 			log.Error(err, "unable to delete Deployment for Deployer", "deployment", actualDeployment)
-			return nil, err
+			return nil, 0, err
+		}
+		return nil, 0, nil
+	}
+
+	// reconcile rollout
+	var heldReplicas int32
+	if rs := deployer.Status.Rollout; rs != nil {
+		heldReplicas = rs.Replicas
+	}
+	revision := desiredDeployment.Labels[corev1alpha1.DeployerRevisionLabelKey]
+	requeueAfter := r.reconcileRollout(log, deployer, revision, &actualDeployment, &actualCanary)
+	canary, err := r.reconcileCanaryDeployment(ctx, log, deployer, desiredDeployment, &actualDeployment, &actualCanary)
+	if err != nil {
+		return nil, 0, err
+	}
+	stableReplicas := r.stableReplicas(deployer, heldReplicas)
+	if rs := deployer.Status.Rollout; rs != nil {
+		rs.CanaryDeploymentName = ""
+		if canary != nil {
+			rs.CanaryDeploymentName = canary.Name
+		}
+		if actualDeployment.Name != "" && rs.StableRevisionName != revision {
+			// hold the stable revision while the candidate rolls out
+			deployment, err := r.scaleDeployment(ctx, log, &actualDeployment, stableReplicas)
+			if err != nil {
+				return nil, 0, err
+			}
+			return deployment, requeueAfter, nil
 		}
-		return nil, nil
 	}
 
 	// create deployment if it doesn't exist
 	if actualDeployment.Name == "" {
+		if scale := deployer.Spec.Scale; scale != nil {
+			// start at the minimum, the autoscaler manages replicas from here
+			desiredDeployment.Spec.Replicas = scale.Min
+		}
 		log.Info("creating deployment", "spec", desiredDeployment.Spec)
 		if err := r.Create(ctx, desiredDeployment); err != nil {
 			log.Error(err, "unable to create Deployment for Deployer", "deployment", desiredDeployment)
-			return nil, err
+			return nil, 0, err
 		}
-		return desiredDeployment, nil
+		return desiredDeployment, requeueAfter, nil
 	}
 
 	// overwrite fields that should not be mutated
 	desiredDeployment.Spec.Replicas = actualDeployment.Spec.Replicas
+	if stableReplicas != nil {
+		desiredDeployment.Spec.Replicas = stableReplicas
+	}
 
 	if r.deploymentSemanticEquals(desiredDeployment, &actualDeployment) {
 		// deployment is unchanged
-		return &actualDeployment, nil
+		return &actualDeployment, requeueAfter, nil
 	}
 
 	// update deployment with desired changes
@@ -281,20 +396,235 @@ func (r *DeployerReconciler) reconcileChildDeployment(ctx context.Context, log l
 	log.Info("reconciling deployment", "diff", cmp.Diff(actualDeployment.Spec, deployment.Spec))
 	if err := r.Update(ctx, deployment); err != nil {
 		log.Error(err, "unable to update Deployment for Deployer", "deployment", deployment)
+		return nil, 0, err
+	}
+
+	return deployment, requeueAfter, nil
+}
+
+// stableReplicas is the share of the replicas held by a rollout left to the
+// stable Deployment, or nil when the replicas are not managed. The replicas
+// are given back once the rollout ends.
+func (r *DeployerReconciler) stableReplicas(deployer *corev1alpha1.Deployer, heldReplicas int32) *int32 {
+	if rs := deployer.Status.Rollout; rs != nil && rs.CandidateRevisionName != "" {
+		_, stable := splitReplicas(rs.Replicas, rs.CandidatePercent(deployer.Spec.Rollout))
+		return &stable
+	}
+	if heldReplicas != 0 {
+		return &heldReplicas
+	}
+	return nil
+}
+
+func (r *DeployerReconciler) scaleDeployment(ctx context.Context, log logr.Logger, actualDeployment *appsv1.Deployment, replicas *int32) (*appsv1.Deployment, error) {
+	if replicas == nil || (actualDeployment.Spec.Replicas != nil && *actualDeployment.Spec.Replicas == *replicas) {
+		// deployment is unchanged
+		return actualDeployment, nil
+	}
+
+	deployment := actualDeployment.DeepCopy()
+	deployment.Spec.Replicas = replicas
+	log.Info("scaling deployment", "deployment", deployment.Name, "replicas", *replicas)
+	if err := r.Update(ctx, deployment); err != nil {
+		log.Error(err, "unable to scale Deployment for Deployer", "deployment", deployment)
 		return nil, err
 	}
 
 	return deployment, nil
 }
 
+func (r *DeployerReconciler) singleDeployment(ctx context.Context, log logr.Logger, deployments []appsv1.Deployment) (appsv1.Deployment, error) {
+	if len(deployments) == 1 {
+		return deployments[0], nil
+	}
+	// this shouldn't happen, delete everything to a clean slate
+	for _, extraDeployment := range deployments {
+		log.Info("deleting extra deployment", "deployment", extraDeployment)
+		if err := r.Delete(ctx, &extraDeployment); err != nil {
+			return appsv1.Deployment{}, err
+		}
+	}
+	return appsv1.Deployment{}, nil
+}
+
+// reconcileRollout advances the rollout of the revision while its canary
+// Deployment is available. The stable revision is updated in place when the
+// deployer has no rollout. The returned duration is the time until the
+// current step completes.
+func (r *DeployerReconciler) reconcileRollout(log logr.Logger, deployer *corev1alpha1.Deployer, revision string, stable, canary *appsv1.Deployment) time.Duration {
+	rollout := deployer.Spec.Rollout
+	if rollout == nil {
+		deployer.Status.Rollout = nil
+		deployer.Status.MarkRolloutNotRequired()
+		return 0
+	}
+
+	status := deployer.Status.Rollout
+	if status == nil {
+		status = &corev1alpha1.RolloutStatus{}
+		deployer.Status.Rollout = status
+	}
+	if status.StableRevisionName == "" {
+		// adopt the running revision, there is nothing to roll out from
+		status.StableRevisionName = stable.Labels[corev1alpha1.DeployerRevisionLabelKey]
+		if status.StableRevisionName == "" {
+			status.StableRevisionName = revision
+		}
+	}
+
+	available, message := r.canaryAvailability(canary, revision)
+	previous := status.DeepCopy()
+	requeueAfter := deployer.Status.ProgressRollout(rollout, revision, available, message, time.Now())
+	if status.CandidateRevisionName != "" && status.Replicas == 0 {
+		// hold the stable replicas, they are split with the canary
+		status.Replicas = 1
+		if stable.Spec.Replicas != nil && *stable.Spec.Replicas > 0 {
+			status.Replicas = *stable.Spec.Replicas
+		}
+	} else if status.CandidateRevisionName == "" && stable.Spec.Replicas != nil && *stable.Spec.Replicas == status.Replicas {
+		// the stable Deployment got its replicas back
+		status.Replicas = 0
+	}
+	switch {
+	case status.CandidateRevisionName != "" && status.CandidateRevisionName != previous.CandidateRevisionName:
+		log.Info("starting rollout", "revision", revision)
+	case status.FailedRevisionName != previous.FailedRevisionName:
+		log.Info("rolling back", "revision", revision)
+	case status.StableRevisionName != previous.StableRevisionName:
+		log.Info("completing rollout", "revision", revision)
+	}
+	return requeueAfter
+}
+
+// canaryAvailability is True once every replica of the canary runs the
+// revision and is available, and False when the canary exceeds its progress
+// deadline.
+func (r *DeployerReconciler) canaryAvailability(canary *appsv1.Deployment, revision string) (corev1.ConditionStatus, string) {
+	if canary.Name == "" || canary.Labels[corev1alpha1.DeployerRevisionLabelKey] != revision || canary.Status.ObservedGeneration < canary.Generation {
+		return corev1.ConditionUnknown, ""
+	}
+	available := corev1.ConditionUnknown
+	for _, cond := range canary.Status.Conditions {
+		switch cond.Type {
+		case appsv1.DeploymentProgressing:
+			if cond.Status == corev1.ConditionFalse {
+				return corev1.ConditionFalse, cond.Message
+			}
+		case appsv1.DeploymentAvailable:
+			available = cond.Status
+		}
+	}
+	replicas := int32(1)
+	if canary.Spec.Replicas != nil {
+		replicas = *canary.Spec.Replicas
+	}
+	if available != corev1.ConditionTrue || canary.Status.UpdatedReplicas < replicas || canary.Status.AvailableReplicas < replicas {
+		// a canary that is not available yet may still progress
+		return corev1.ConditionUnknown, ""
+	}
+	return corev1.ConditionTrue, ""
+}
+
+func (r *DeployerReconciler) reconcileCanaryDeployment(ctx context.Context, log logr.Logger, deployer *corev1alpha1.Deployer, desiredDeployment, actualDeployment, actualCanary *appsv1.Deployment) (*appsv1.Deployment, error) {
+	desiredCanary := r.constructCanaryForDeployer(deployer, desiredDeployment, actualDeployment)
+
+	// delete canary if no longer needed
+	if desiredCanary == nil {
+		if actualCanary.Name == "" {
+			return nil, nil
+		}
+		log.Info("deleting canary deployment", "deployment", actualCanary)
+		if err := r.Delete(ctx, actualCanary); err != nil {
+			log.Error(err, "unable to delete canary Deployment for Deployer", "deployment", actualCanary)
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// create canary if it doesn't exist
+	if actualCanary.Name == "" {
+		log.Info("creating canary deployment", "spec", desiredCanary.Spec)
+		if err := r.Create(ctx, desiredCanary); err != nil {
+			log.Error(err, "unable to create canary Deployment for Deployer", "deployment", desiredCanary)
+			return nil, err
+		}
+		return desiredCanary, nil
+	}
+
+	if r.deploymentSemanticEquals(desiredCanary, actualCanary) {
+		// canary is unchanged
+		return actualCanary, nil
+	}
+
+	// update canary with desired changes
+	canary := actualCanary.DeepCopy()
+	canary.ObjectMeta.Labels = desiredCanary.ObjectMeta.Labels
+	canary.Spec = desiredCanary.Spec
+	log.Info("reconciling canary deployment", "diff", cmp.Diff(actualCanary.Spec, canary.Spec))
+	if err := r.Update(ctx, canary); err != nil {
+		log.Error(err, "unable to update canary Deployment for Deployer", "deployment", canary)
+		return nil, err
+	}
+
+	return canary, nil
+}
+
+// constructCanaryForDeployer runs the candidate revision alongside the stable
+// Deployment. Both are selected by the same Service, so requests split by the
+// ratio of their replicas. The replicas held by the rollout are divided
+// between them.
+func (r *DeployerReconciler) constructCanaryForDeployer(deployer *corev1alpha1.Deployer, desiredDeployment, stable *appsv1.Deployment) *appsv1.Deployment {
+	rollout := deployer.Status.Rollout
+	if rollout == nil || rollout.CandidateRevisionName == "" || stable.Name == "" {
+		// skip canary
+		return nil
+	}
+
+	canary := desiredDeployment.DeepCopy()
+	canary.GenerateName = fmt.Sprintf("%s-deployer-canary-", deployer.Name)
+	canary.Labels[corev1alpha1.DeployerCanaryLabelKey] = "true"
+	canary.Spec.Selector.MatchLabels[corev1alpha1.DeployerCanaryLabelKey] = "true"
+	canary.Spec.Template.Labels[corev1alpha1.DeployerCanaryLabelKey] = "true"
+
+	replicas, _ := splitReplicas(rollout.Replicas, rollout.CandidatePercent(deployer.Spec.Rollout))
+	canary.Spec.Replicas = &replicas
+
+	return canary
+}
+
+// splitReplicas divides the replicas between the canary and stable
+// Deployments so the canary receives about percent of requests. Both keep a
+// replica until the canary takes every request, a single replica is doubled
+// for the partial steps.
+func splitReplicas(replicas int32, percent int64) (canary, stable int32) {
+	if replicas < 1 {
+		replicas = 1
+	}
+	if percent >= 100 {
+		return replicas, 0
+	}
+	if replicas == 1 {
+		return 1, 1
+	}
+	canary = int32((int64(replicas)*percent + 50) / 100)
+	if canary < 1 {
+		canary = 1
+	}
+	if canary > replicas-1 {
+		canary = replicas - 1
+	}
+	return canary, replicas - canary
+}
+
 func (r *DeployerReconciler) deploymentSemanticEquals(desiredDeployment, deployment *appsv1.Deployment) bool {
 	return equality.Semantic.DeepEqual(desiredDeployment.Spec, deployment.Spec) &&
 		equality.Semantic.DeepEqual(desiredDeployment.ObjectMeta.Labels, deployment.ObjectMeta.Labels)
 }
 
-func (r *DeployerReconciler) constructDeploymentForDeployer(deployer *corev1alpha1.Deployer) (*appsv1.Deployment, error) {
+func (r *DeployerReconciler) constructDeploymentForDeployer(deployer *corev1alpha1.Deployer, streams []controllers.StreamBinding) (*appsv1.Deployment, error) {
 	labels := r.constructLabelsForDeployer(deployer)
 	podSpec := r.constructPodSpecForDeployer(deployer)
+	controllers.ProjectStreamBindings(&podSpec, streams)
 
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -320,6 +650,13 @@ func (r *DeployerReconciler) constructDeploymentForDeployer(deployer *corev1alph
 	if deployment.Spec.Template.Spec.Containers[0].Image == "" {
 		deployment.Spec.Template.Spec.Containers[0].Image = deployer.Status.LatestImage
 	}
+	revision, err := podTemplateRevision(&deployment.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+	// the revision labels the deployment, not its pods
+	deployment.Labels = r.constructLabelsForDeployer(deployer)
+	deployment.Labels[corev1alpha1.DeployerRevisionLabelKey] = revision
 	if err := ctrl.SetControllerReference(deployer, deployment, r.Scheme); err != nil {
 		return nil, err
 	}
@@ -327,6 +664,16 @@ func (r *DeployerReconciler) constructDeploymentForDeployer(deployer *corev1alph
 	return deployment, nil
 }
 
+// podTemplateRevision names a revision of the workload by a hash of its pod
+// template.
+func podTemplateRevision(template *corev1.PodTemplateSpec) (string, error) {
+	b, err := json.Marshal(template)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b))[:10], nil
+}
+
 func (r *DeployerReconciler) constructPodSpecForDeployer(deployer *corev1alpha1.Deployer) corev1.PodSpec {
 	podSpec := *deployer.Spec.Template.DeepCopy()
 	targetPort := podSpec.Containers[0].Ports[0]
@@ -357,7 +704,127 @@ func (r *DeployerReconciler) constructPodSpecForDeployer(deployer *corev1alpha1.
 	return podSpec
 }
 
-func (r *DeployerReconciler) reconcileIngress(ctx context.Context, log logr.Logger, deployer *corev1alpha1.Deployer, serviceName string) (*networkingv1beta1.Ingress, error) {
+func (r *DeployerReconciler) reconcileChildAutoscaler(ctx context.Context, log logr.Logger, deployer *corev1alpha1.Deployer) (*autoscalingv2beta2.HorizontalPodAutoscaler, error) {
+	var actualAutoscaler autoscalingv2beta2.HorizontalPodAutoscaler
+	var childAutoscalers autoscalingv2beta2.HorizontalPodAutoscalerList
+	if err := r.List(ctx, &childAutoscalers, client.InNamespace(deployer.Namespace), client.MatchingField(autoscalerIndexField, deployer.Name)); err != nil {
+		return nil, err
+	}
+	if len(childAutoscalers.Items) == 1 {
+		actualAutoscaler = childAutoscalers.Items[0]
+	} else if len(childAutoscalers.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraAutoscaler := range childAutoscalers.Items {
+			log.Info("deleting extra autoscaler", "autoscaler", extraAutoscaler)
+			if err := r.Delete(ctx, &extraAutoscaler); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	desiredAutoscaler, err := r.constructAutoscalerForDeployer(deployer)
+	if err != nil {
+		return nil, err
+	}
+
+	// delete autoscaler if no longer needed
+	if desiredAutoscaler == nil {
+		if actualAutoscaler.Name == "" {
+			return nil, nil
+		}
+		log.Info("deleting autoscaler", "autoscaler", actualAutoscaler)
+		if err := r.Delete(ctx, &actualAutoscaler); err != nil {
+			log.Error(err, "unable to delete HorizontalPodAutoscaler for Deployer", "autoscaler", actualAutoscaler)
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// create autoscaler if it doesn't exist
+	if actualAutoscaler.Name == "" {
+		log.Info("creating autoscaler", "spec", desiredAutoscaler.Spec)
+		if err := r.Create(ctx, desiredAutoscaler); err != nil {
+			log.Error(err, "unable to create HorizontalPodAutoscaler for Deployer", "autoscaler", desiredAutoscaler)
+			return nil, err
+		}
+		return desiredAutoscaler, nil
+	}
+
+	if r.autoscalerSemanticEquals(desiredAutoscaler, &actualAutoscaler) {
+		// autoscaler is unchanged
+		return &actualAutoscaler, nil
+	}
+
+	// update autoscaler with desired changes
+	autoscaler := actualAutoscaler.DeepCopy()
+	autoscaler.ObjectMeta.Labels = desiredAutoscaler.ObjectMeta.Labels
+	autoscaler.Spec = desiredAutoscaler.Spec
+	log.Info("reconciling autoscaler", "diff", cmp.Diff(actualAutoscaler.Spec, autoscaler.Spec))
+	if err := r.Update(ctx, autoscaler); err != nil {
+		log.Error(err, "unable to update HorizontalPodAutoscaler for Deployer", "autoscaler", autoscaler)
+		return nil, err
+	}
+
+	return autoscaler, nil
+}
+
+func (r *DeployerReconciler) autoscalerSemanticEquals(desiredAutoscaler, autoscaler *autoscalingv2beta2.HorizontalPodAutoscaler) bool {
+	return equality.Semantic.DeepEqual(desiredAutoscaler.Spec, autoscaler.Spec) &&
+		equality.Semantic.DeepEqual(desiredAutoscaler.ObjectMeta.Labels, autoscaler.ObjectMeta.Labels)
+}
+
+func (r *DeployerReconciler) constructAutoscalerForDeployer(deployer *corev1alpha1.Deployer) (*autoscalingv2beta2.HorizontalPodAutoscaler, error) {
+	scale := deployer.Spec.Scale
+	if scale == nil || deployer.Status.DeploymentName == "" {
+		// skip autoscaler
+		return nil, nil
+	}
+	if rs := deployer.Status.Rollout; rs != nil && rs.CandidateRevisionName != "" {
+		// the rollout holds the replicas until it ends
+		return nil, nil
+	}
+	labels := r.constructLabelsForDeployer(deployer)
+
+	metrics := []autoscalingv2beta2.MetricSpec{}
+	if scale.TargetCPUUtilization != nil {
+		metrics = append(metrics, autoscalingv2beta2.MetricSpec{
+			Type: autoscalingv2beta2.ResourceMetricSourceType,
+			Resource: &autoscalingv2beta2.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+				Target: autoscalingv2beta2.MetricTarget{
+					Type:               autoscalingv2beta2.UtilizationMetricType,
+					AverageUtilization: scale.TargetCPUUtilization,
+				},
+			},
+		})
+	}
+
+	autoscaler := &autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       labels,
+			Annotations:  make(map[string]string),
+			GenerateName: fmt.Sprintf("%s-deployer-", deployer.Name),
+			Namespace:    deployer.Namespace,
+		},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       deployer.Status.DeploymentName,
+			},
+			MinReplicas: scale.Min,
+			MaxReplicas: *scale.Max,
+			Metrics:     metrics,
+		},
+	}
+	if err := ctrl.SetControllerReference(deployer, autoscaler, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return autoscaler, nil
+}
+
+func (r *DeployerReconciler) reconcileIngress(ctx context.Context, log logr.Logger, deployer *corev1alpha1.Deployer, domain string) (*networkingv1beta1.Ingress, error) {
 	var actualIngress networkingv1beta1.Ingress
 	var childIngresses networkingv1beta1.IngressList
 
@@ -377,13 +844,16 @@ func (r *DeployerReconciler) reconcileIngress(ctx context.Context, log logr.Logg
 		}
 	}
 
-	desiredIngress, err := r.constructIngressForDeployer(deployer)
+	desiredIngress, err := r.constructIngressForDeployer(deployer, domain)
 	if err != nil {
 		return nil, err
 	}
 
 	// delete ingress if no longer needed
 	if desiredIngress == nil {
+		if actualIngress.Name == "" {
+			return nil, nil
+		}
 		log.Info("deleting ingress", "ingress", actualIngress)
 		if err := r.Delete(ctx, &actualIngress); err != nil {
 			log.Error(err, "unable to delete ingress for Deployer", "ingress", actualIngress)
@@ -410,6 +880,7 @@ func (r *DeployerReconciler) reconcileIngress(ctx context.Context, log logr.Logg
 	// update ingress with desired changes
 	ingress := actualIngress.DeepCopy()
 	ingress.ObjectMeta.Labels = desiredIngress.ObjectMeta.Labels
+	ingress.ObjectMeta.Annotations = desiredIngress.ObjectMeta.Annotations
 	ingress.Spec = desiredIngress.Spec
 	log.Info("reconciling ingress", "diff", cmp.Diff(actualIngress.Spec, ingress.Spec))
 	if err := r.Update(ctx, ingress); err != nil {
@@ -422,39 +893,29 @@ func (r *DeployerReconciler) reconcileIngress(ctx context.Context, log logr.Logg
 
 func (r *DeployerReconciler) ingressSemanticEquals(desiredIngress, ingress *networkingv1beta1.Ingress) bool {
 	return equality.Semantic.DeepEqual(desiredIngress.Spec, ingress.Spec) &&
-		equality.Semantic.DeepEqual(desiredIngress.ObjectMeta.Labels, ingress.ObjectMeta.Labels)
+		equality.Semantic.DeepEqual(desiredIngress.ObjectMeta.Labels, ingress.ObjectMeta.Labels) &&
+		equality.Semantic.DeepEqual(desiredIngress.ObjectMeta.Annotations, ingress.ObjectMeta.Annotations)
 }
 
-func (r *DeployerReconciler) constructIngressForDeployer(deployer *corev1alpha1.Deployer) (*networkingv1beta1.Ingress, error) {
+func (r *DeployerReconciler) constructIngressForDeployer(deployer *corev1alpha1.Deployer, domain string) (*networkingv1beta1.Ingress, error) {
 	if deployer.Status.ServiceName == "" || deployer.Spec.IngressPolicy == corev1alpha1.IngressPolicyClusterLocal {
 		// skip ingress
 		return nil, nil
 	}
-	labels := r.constructLabelsForDeployer(deployer)
+	spec, annotations := controllers.ConstructIngressSpec(deployer.Spec.Ingress, deployer.Status.ServiceName, deployer.Namespace, domain, fmt.Sprintf("%s-deployer-tls", deployer.Name))
+	if spec == nil {
+		// skip ingress until a domain is configured
+		return nil, nil
+	}
 
 	ingress := &networkingv1beta1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
-			Labels:       labels,
-			Annotations:  make(map[string]string),
+			Labels:       r.constructLabelsForDeployer(deployer),
+			Annotations:  annotations,
 			GenerateName: fmt.Sprintf("%s-deployer-", deployer.Name),
 			Namespace:    deployer.Namespace,
 		},
-		Spec: networkingv1beta1.IngressSpec{
-			Rules: []networkingv1beta1.IngressRule{{
-				Host: fmt.Sprintf("%s.%s.%s", deployer.Status.ServiceName, deployer.Namespace, domain),
-				IngressRuleValue: networkingv1beta1.IngressRuleValue{
-					HTTP: &networkingv1beta1.HTTPIngressRuleValue{
-						Paths: []networkingv1beta1.HTTPIngressPath{{
-							Path: "/",
-							Backend: networkingv1beta1.IngressBackend{
-								ServiceName: deployer.Status.ServiceName,
-								ServicePort: intstr.FromInt(80),
-							},
-						}},
-					},
-				},
-			}},
-		},
+		Spec: *spec,
 	}
 
 	if err := ctrl.SetControllerReference(deployer, ingress, r.Scheme); err != nil {
@@ -590,6 +1051,22 @@ func (r *DeployerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		}
 	}
 
+	enqueueTrackedConfig := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			requests := []reconcile.Request{}
+			if a.Meta.GetNamespace() == r.Namespace && a.Meta.GetName() == ingressConfig {
+				key := tracker.NewKey(
+					schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+					types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: a.Meta.GetName()},
+				)
+				for _, item := range r.Tracker.Lookup(key) {
+					requests = append(requests, reconcile.Request{NamespacedName: item})
+				}
+			}
+			return requests
+		}),
+	}
+
 	if err := controllers.IndexControllersOfType(mgr, deploymentIndexField, &corev1alpha1.Deployer{}, &appsv1.Deployment{}); err != nil {
 		return err
 	}
@@ -599,15 +1076,25 @@ func (r *DeployerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err := controllers.IndexControllersOfType(mgr, ingressIndexField, &corev1alpha1.Deployer{}, &networkingv1beta1.Ingress{}); err != nil {
 		return err
 	}
+	if err := controllers.IndexControllersOfType(mgr, autoscalerIndexField, &corev1alpha1.Deployer{}, &autoscalingv2beta2.HorizontalPodAutoscaler{}); err != nil {
+		return err
+	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&corev1alpha1.Deployer{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
 		Owns(&networkingv1beta1.Ingress{}).
+		Owns(&autoscalingv2beta2.HorizontalPodAutoscaler{}).
 		// watch for build mutations to update dependent deployers
 		Watches(&source.Kind{Type: &buildv1alpha1.Application{}}, enqueueTrackedResources(&buildv1alpha1.Application{})).
 		Watches(&source.Kind{Type: &buildv1alpha1.Container{}}, enqueueTrackedResources(&buildv1alpha1.Container{})).
 		Watches(&source.Kind{Type: &buildv1alpha1.Function{}}, enqueueTrackedResources(&buildv1alpha1.Function{})).
-		Complete(r)
+		// watch for cluster config mutations to update all deployers
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueTrackedConfig)
+	if controllers.IsKindInstalled(mgr, (&streamingv1alpha1.Stream{}).GetGroupVersionKind()) {
+		// watch for stream mutations to update bound deployers
+		builder = builder.Watches(&source.Kind{Type: &streamingv1alpha1.Stream{}}, enqueueTrackedResources(&streamingv1alpha1.Stream{}))
+	}
+	return builder.Complete(r)
 }
@@ -0,0 +1,698 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+)
+
+// newTestDeployer runs the image, rolling out new revisions to a quarter of
+// requests and then to all of them, with a minute of pause for each step
+func newTestDeployer(image string) *corev1alpha1.Deployer {
+	deployer := &corev1alpha1.Deployer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "my-deployer"},
+		Spec: corev1alpha1.DeployerSpec{
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{{Image: image}},
+			},
+			IngressPolicy: corev1alpha1.IngressPolicyClusterLocal,
+			Rollout: &corev1alpha1.Rollout{
+				Steps: []corev1alpha1.RolloutStep{
+					{Percent: 25, Pause: metav1.Duration{Duration: time.Minute}},
+					{Percent: 100, Pause: metav1.Duration{Duration: time.Minute}},
+				},
+			},
+		},
+	}
+	deployer.Default()
+	return deployer
+}
+
+// testDeployment is the stable Deployment of the deployer running the image
+func testDeployment(t *testing.T, image string, replicas int32) *appsv1.Deployment {
+	r := &DeployerReconciler{Scheme: scheme.Scheme}
+	deployment, err := r.constructDeploymentForDeployer(newTestDeployer(image), nil)
+	if err != nil {
+		t.Fatalf("unable to construct deployment: %v", err)
+	}
+	deployment.Name = "my-deployer-deployer-stable"
+	deployment.Spec.Replicas = &replicas
+	return deployment
+}
+
+// testCanary is the canary Deployment running the image, available when the
+// conditions say so
+func testCanary(t *testing.T, image string, replicas int32, conditions ...appsv1.DeploymentCondition) *appsv1.Deployment {
+	canary := testDeployment(t, image, replicas)
+	canary.Name = "my-deployer-deployer-canary"
+	canary.Labels[corev1alpha1.DeployerCanaryLabelKey] = "true"
+	canary.Spec.Selector.MatchLabels[corev1alpha1.DeployerCanaryLabelKey] = "true"
+	canary.Spec.Template.Labels[corev1alpha1.DeployerCanaryLabelKey] = "true"
+	canary.Status.Conditions = conditions
+	for _, cond := range conditions {
+		if cond.Type == appsv1.DeploymentAvailable && cond.Status == corev1.ConditionTrue {
+			canary.Status.UpdatedReplicas = replicas
+			canary.Status.AvailableReplicas = replicas
+		}
+	}
+	return canary
+}
+
+func revisionOf(deployment *appsv1.Deployment) string {
+	return deployment.Labels[corev1alpha1.DeployerRevisionLabelKey]
+}
+
+func TestDeployerReconcileRollout(t *testing.T) {
+	// times are stored with a precision of seconds
+	now := time.Now().Truncate(time.Second)
+	ago := func(d time.Duration) *metav1.Time {
+		return &metav1.Time{Time: now.Add(-d)}
+	}
+	oldRevision := revisionOf(testDeployment(t, "old-image", 1))
+	newRevision := revisionOf(testDeployment(t, "new-image", 1))
+	available := []appsv1.DeploymentCondition{
+		{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+		{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+	}
+	unavailable := []appsv1.DeploymentCondition{
+		{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse},
+		{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+	}
+	failed := []appsv1.DeploymentCondition{
+		{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse},
+		{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded", Message: "timed out"},
+	}
+
+	tests := []struct {
+		name    string
+		rollout *corev1alpha1.RolloutStatus
+		scale   *corev1alpha1.Scale
+		objects []runtime.Object
+		// wantRollout is compared ignoring the step times, which are
+		// checked by wantStepStarted and wantStepElapsed
+		wantRollout      corev1alpha1.RolloutStatus
+		wantStepStarted  bool
+		wantStepElapsed  time.Duration
+		wantReason       string
+		wantStable       string
+		wantStableScale  int32
+		wantCanaryScale  int32
+		wantRequeueAfter time.Duration
+		wantAutoscaler   bool
+	}{{
+		name:    "starts with a share of the stable replicas",
+		rollout: &corev1alpha1.RolloutStatus{StableRevisionName: oldRevision},
+		objects: []runtime.Object{testDeployment(t, "old-image", 4)},
+		wantRollout: corev1alpha1.RolloutStatus{
+			StableRevisionName:    oldRevision,
+			CandidateRevisionName: newRevision,
+			Replicas:              4,
+		},
+		wantReason:      "RolloutWaiting",
+		wantStable:      oldRevision,
+		wantStableScale: 3,
+		wantCanaryScale: 1,
+	}, {
+		name: "waits out the pause of a step",
+		rollout: &corev1alpha1.RolloutStatus{
+			StableRevisionName:    oldRevision,
+			CandidateRevisionName: newRevision,
+			StepStartTime:         ago(20 * time.Second),
+			Replicas:              4,
+		},
+		objects: []runtime.Object{testDeployment(t, "old-image", 3), testCanary(t, "new-image", 1, available...)},
+		wantRollout: corev1alpha1.RolloutStatus{
+			StableRevisionName:    oldRevision,
+			CandidateRevisionName: newRevision,
+			Replicas:              4,
+		},
+		wantStepStarted:  true,
+		wantReason:       "RolloutProgressing",
+		wantStable:       oldRevision,
+		wantStableScale:  3,
+		wantCanaryScale:  1,
+		wantRequeueAfter: 40 * time.Second,
+	}, {
+		name: "advances once the pause elapsed",
+		rollout: &corev1alpha1.RolloutStatus{
+			StableRevisionName:    oldRevision,
+			CandidateRevisionName: newRevision,
+			StepStartTime:         ago(90 * time.Second),
+			Replicas:              4,
+		},
+		objects: []runtime.Object{testDeployment(t, "old-image", 3), testCanary(t, "new-image", 1, available...)},
+		wantRollout: corev1alpha1.RolloutStatus{
+			StableRevisionName:    oldRevision,
+			CandidateRevisionName: newRevision,
+			Step:                  1,
+			Replicas:              4,
+		},
+		wantStepStarted:  true,
+		wantReason:       "RolloutProgressing",
+		wantStable:       oldRevision,
+		wantStableScale:  0,
+		wantCanaryScale:  4,
+		wantRequeueAfter: time.Minute,
+	}, {
+		name: "stops the pause while the canary is unavailable",
+		rollout: &corev1alpha1.RolloutStatus{
+			StableRevisionName:    oldRevision,
+			CandidateRevisionName: newRevision,
+			StepStartTime:         ago(20 * time.Second),
+			Replicas:              4,
+		},
+		objects: []runtime.Object{testDeployment(t, "old-image", 3), testCanary(t, "new-image", 1, unavailable...)},
+		wantRollout: corev1alpha1.RolloutStatus{
+			StableRevisionName:    oldRevision,
+			CandidateRevisionName: newRevision,
+			Replicas:              4,
+		},
+		wantStepElapsed: 20 * time.Second,
+		wantReason:      "RolloutWaiting",
+		wantStable:      oldRevision,
+		wantStableScale: 3,
+		wantCanaryScale: 1,
+	}, {
+		name: "resumes the pause once the canary is available again",
+		rollout: &corev1alpha1.RolloutStatus{
+			StableRevisionName:    oldRevision,
+			CandidateRevisionName: newRevision,
+			StepElapsed:           &metav1.Duration{Duration: 20 * time.Second},
+			Replicas:              4,
+		},
+		objects: []runtime.Object{testDeployment(t, "old-image", 3), testCanary(t, "new-image", 1, available...)},
+		wantRollout: corev1alpha1.RolloutStatus{
+			StableRevisionName:    oldRevision,
+			CandidateRevisionName: newRevision,
+			Replicas:              4,
+		},
+		wantStepStarted:  true,
+		wantStepElapsed:  20 * time.Second,
+		wantReason:       "RolloutProgressing",
+		wantStable:       oldRevision,
+		wantStableScale:  3,
+		wantCanaryScale:  1,
+		wantRequeueAfter: 40 * time.Second,
+	}, {
+		name: "completes the rollout",
+		rollout: &corev1alpha1.RolloutStatus{
+			StableRevisionName:    oldRevision,
+			CandidateRevisionName: newRevision,
+			Step:                  1,
+			StepStartTime:         ago(90 * time.Second),
+			Replicas:              4,
+		},
+		objects: []runtime.Object{testDeployment(t, "old-image", 0), testCanary(t, "new-image", 4, available...)},
+		wantRollout: corev1alpha1.RolloutStatus{
+			StableRevisionName: newRevision,
+			Replicas:           4,
+		},
+		wantStable:      newRevision,
+		wantStableScale: 4,
+	}, {
+		name: "rolls back a failed canary",
+		rollout: &corev1alpha1.RolloutStatus{
+			StableRevisionName:    oldRevision,
+			CandidateRevisionName: newRevision,
+			StepStartTime:         ago(20 * time.Second),
+			Replicas:              4,
+		},
+		objects: []runtime.Object{testDeployment(t, "old-image", 3), testCanary(t, "new-image", 1, failed...)},
+		wantRollout: corev1alpha1.RolloutStatus{
+			StableRevisionName: oldRevision,
+			FailedRevisionName: newRevision,
+			Replicas:           4,
+		},
+		wantReason:      "RolledBack",
+		wantStable:      oldRevision,
+		wantStableScale: 4,
+	}, {
+		name: "releases the replicas once given back",
+		rollout: &corev1alpha1.RolloutStatus{
+			StableRevisionName: newRevision,
+			Replicas:           4,
+		},
+		objects:         []runtime.Object{testDeployment(t, "new-image", 4)},
+		wantRollout:     corev1alpha1.RolloutStatus{StableRevisionName: newRevision},
+		wantStable:      newRevision,
+		wantStableScale: 4,
+	}, {
+		name: "suspends the autoscaler during a rollout",
+		rollout: &corev1alpha1.RolloutStatus{
+			StableRevisionName:    oldRevision,
+			CandidateRevisionName: newRevision,
+			Replicas:              4,
+		},
+		scale: &corev1alpha1.Scale{Max: int32Ptr(8)},
+		objects: []runtime.Object{
+			testDeployment(t, "old-image", 3),
+			testCanary(t, "new-image", 1, unavailable...),
+			&autoscalingv2beta2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "my-deployer-deployer-autoscaler"},
+			},
+		},
+		wantRollout: corev1alpha1.RolloutStatus{
+			StableRevisionName:    oldRevision,
+			CandidateRevisionName: newRevision,
+			Replicas:              4,
+		},
+		wantReason:      "RolloutWaiting",
+		wantStable:      oldRevision,
+		wantStableScale: 3,
+		wantCanaryScale: 1,
+	}, {
+		name:    "autoscales the stable revision",
+		rollout: &corev1alpha1.RolloutStatus{StableRevisionName: newRevision},
+		scale:   &corev1alpha1.Scale{Max: int32Ptr(8)},
+		objects: []runtime.Object{testDeployment(t, "new-image", 4)},
+		wantRollout: corev1alpha1.RolloutStatus{
+			StableRevisionName: newRevision,
+		},
+		wantStable:      newRevision,
+		wantStableScale: 4,
+		wantAutoscaler:  true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deployer := newTestDeployer("new-image")
+			deployer.Spec.Scale = test.scale
+			deployer.Default()
+			deployer.Status.Rollout = test.rollout
+			c := newFakeClient(append(test.objects, deployer)...)
+			r := &DeployerReconciler{
+				Client:    c,
+				Log:       zap.Logger(true),
+				Scheme:    scheme.Scheme,
+				Tracker:   newTestTracker(),
+				Namespace: testSystemNamespace,
+			}
+			key := types.NamespacedName{Namespace: testNamespace, Name: "my-deployer"}
+			result, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+			if err != nil {
+				t.Fatalf("Reconcile() unexpected error: %v", err)
+			}
+			// the step started a moment before the reconcile
+			if result.RequeueAfter > test.wantRequeueAfter || result.RequeueAfter < test.wantRequeueAfter-2*time.Second {
+				t.Errorf("Reconcile() RequeueAfter = %v, want about %v", result.RequeueAfter, test.wantRequeueAfter)
+			}
+
+			var actual corev1alpha1.Deployer
+			if err := c.Get(context.Background(), key, &actual); err != nil {
+				t.Fatalf("unable to get deployer: %v", err)
+			}
+			rs := actual.Status.Rollout
+			if rs == nil {
+				t.Fatalf("Status.Rollout = nil")
+			}
+			if started := rs.StepStartTime != nil; started != test.wantStepStarted {
+				t.Errorf("Status.Rollout.StepStartTime = %v, want set %v", rs.StepStartTime, test.wantStepStarted)
+			}
+			var elapsed time.Duration
+			if rs.StepElapsed != nil {
+				elapsed = rs.StepElapsed.Duration
+			}
+			if elapsed < test.wantStepElapsed || elapsed > test.wantStepElapsed+2*time.Second {
+				t.Errorf("Status.Rollout.StepElapsed = %v, want about %v", elapsed, test.wantStepElapsed)
+			}
+			got := *rs
+			got.StepStartTime, got.StepElapsed, got.CanaryDeploymentName = nil, nil, ""
+			if got != test.wantRollout {
+				t.Errorf("Status.Rollout = %+v, want %+v", got, test.wantRollout)
+			}
+			cond := actual.Status.GetCondition(corev1alpha1.DeployerConditionRolloutReady)
+			if cond == nil || cond.Reason != test.wantReason {
+				t.Errorf("RolloutReady condition = %+v, want reason %q", cond, test.wantReason)
+			}
+
+			var deployments appsv1.DeploymentList
+			if err := c.List(context.Background(), &deployments, client.InNamespace(testNamespace)); err != nil {
+				t.Fatalf("unable to list deployments: %v", err)
+			}
+			var stable, canary *appsv1.Deployment
+			for i := range deployments.Items {
+				if _, ok := deployments.Items[i].Labels[corev1alpha1.DeployerCanaryLabelKey]; ok {
+					canary = &deployments.Items[i]
+				} else {
+					stable = &deployments.Items[i]
+				}
+			}
+			if stable == nil {
+				t.Fatalf("missing stable deployment")
+			}
+			if revisionOf(stable) != test.wantStable || *stable.Spec.Replicas != test.wantStableScale {
+				t.Errorf("stable deployment runs %d replicas of %q, want %d of %q", *stable.Spec.Replicas, revisionOf(stable), test.wantStableScale, test.wantStable)
+			}
+			switch {
+			case canary == nil && test.wantCanaryScale != 0:
+				t.Errorf("missing canary deployment, want %d replicas", test.wantCanaryScale)
+			case canary != nil && test.wantCanaryScale == 0:
+				t.Errorf("unexpected canary deployment with %d replicas", *canary.Spec.Replicas)
+			case canary != nil && (revisionOf(canary) != newRevision || *canary.Spec.Replicas != test.wantCanaryScale):
+				t.Errorf("canary deployment runs %d replicas of %q, want %d of %q", *canary.Spec.Replicas, revisionOf(canary), test.wantCanaryScale, newRevision)
+			}
+
+			var autoscalers autoscalingv2beta2.HorizontalPodAutoscalerList
+			if err := c.List(context.Background(), &autoscalers, client.InNamespace(testNamespace)); err != nil {
+				t.Fatalf("unable to list autoscalers: %v", err)
+			}
+			if got := len(autoscalers.Items) == 1; got != test.wantAutoscaler {
+				t.Errorf("got %d autoscalers, want autoscaler %v", len(autoscalers.Items), test.wantAutoscaler)
+			}
+		})
+	}
+}
+
+func TestSplitReplicas(t *testing.T) {
+	tests := []struct {
+		replicas   int32
+		percent    int64
+		wantCanary int32
+		wantStable int32
+	}{
+		{replicas: 4, percent: 25, wantCanary: 1, wantStable: 3},
+		{replicas: 10, percent: 30, wantCanary: 3, wantStable: 7},
+		{replicas: 4, percent: 100, wantCanary: 4, wantStable: 0},
+		{replicas: 1, percent: 100, wantCanary: 1, wantStable: 0},
+		// each revision keeps a replica for partial steps
+		{replicas: 4, percent: 5, wantCanary: 1, wantStable: 3},
+		{replicas: 4, percent: 95, wantCanary: 3, wantStable: 1},
+		{replicas: 1, percent: 50, wantCanary: 1, wantStable: 1},
+	}
+	for _, test := range tests {
+		canary, stable := splitReplicas(test.replicas, test.percent)
+		if canary != test.wantCanary || stable != test.wantStable {
+			t.Errorf("splitReplicas(%d, %d) = %d, %d, want %d, %d", test.replicas, test.percent, canary, stable, test.wantCanary, test.wantStable)
+		}
+	}
+}
+
+// boundStream is a stream of the in-memory provider with its binding secret
+func boundStream(name string) *streamingv1alpha1.Stream {
+	stream := &streamingv1alpha1.Stream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: name},
+	}
+	stream.Status.Address = streamingv1alpha1.StreamAddress{Gateway: "memory-gateway:6565", Topic: testNamespace + "_" + name}
+	stream.Status.Binding.SecretRef.Name = name + "-stream-binding"
+	return stream
+}
+
+func TestDeployerReconcileStreams(t *testing.T) {
+	unboundStream := boundStream("numbers")
+	unboundStream.Status.Binding.SecretRef.Name = ""
+
+	tests := []struct {
+		name               string
+		streams            []corev1alpha1.StreamBinding
+		objects            []runtime.Object
+		wantStreamsMessage string
+		wantEnv            []corev1.EnvVar
+		wantVolumes        []corev1.Volume
+		wantMountPaths     []string
+		wantNoDeployment   bool
+	}{{
+		name:               "waits for a missing stream",
+		streams:            []corev1alpha1.StreamBinding{{Stream: "numbers"}},
+		wantStreamsMessage: `stream "numbers" not found`,
+		wantNoDeployment:   true,
+	}, {
+		name:               "waits for the stream binding",
+		streams:            []corev1alpha1.StreamBinding{{Stream: "numbers"}},
+		objects:            []runtime.Object{unboundStream},
+		wantStreamsMessage: `stream "numbers" does not have a binding`,
+		wantNoDeployment:   true,
+	}, {
+		name:    "projects streams to publish to",
+		streams: []corev1alpha1.StreamBinding{{Stream: "numbers"}},
+		objects: []runtime.Object{boundStream("numbers")},
+		wantEnv: []corev1.EnvVar{
+			{Name: "STREAM_NUMBERS_GATEWAY", Value: "memory-gateway:6565"},
+			{Name: "STREAM_NUMBERS_TOPIC", Value: "test-namespace_numbers"},
+		},
+		wantVolumes: []corev1.Volume{
+			{Name: "stream-numbers", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "numbers-stream-binding"}}},
+		},
+		wantMountPaths: []string{"/var/riff/streams/numbers"},
+	}, {
+		name: "projects subscriptions under their alias",
+		streams: []corev1alpha1.StreamBinding{
+			{Stream: "numbers", Alias: "in-numbers", Mode: corev1alpha1.StreamBindingModeSubscribe},
+			{Stream: "squares"},
+		},
+		objects: []runtime.Object{boundStream("numbers"), boundStream("squares")},
+		wantEnv: []corev1.EnvVar{
+			{Name: "STREAM_IN_NUMBERS_GATEWAY", Value: "memory-gateway:6565"},
+			{Name: "STREAM_IN_NUMBERS_TOPIC", Value: "test-namespace_numbers"},
+			{Name: "STREAM_IN_NUMBERS_GROUP", Value: "core-deployer-my-deployer"},
+			{Name: "STREAM_SQUARES_GATEWAY", Value: "memory-gateway:6565"},
+			{Name: "STREAM_SQUARES_TOPIC", Value: "test-namespace_squares"},
+		},
+		wantVolumes: []corev1.Volume{
+			{Name: "stream-in-numbers", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "numbers-stream-binding"}}},
+			{Name: "stream-squares", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "squares-stream-binding"}}},
+		},
+		wantMountPaths: []string{"/var/riff/streams/in-numbers", "/var/riff/streams/squares"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			deployer := newTestDeployer("my-image")
+			deployer.Spec.Rollout = nil
+			deployer.Spec.Streams = test.streams
+			deployer.Default()
+			c := newFakeClient(append(test.objects, deployer)...)
+			r := &DeployerReconciler{
+				Client:    c,
+				Log:       zap.Logger(true),
+				Scheme:    scheme.Scheme,
+				Tracker:   newTestTracker(),
+				Namespace: testSystemNamespace,
+			}
+			key := types.NamespacedName{Namespace: testNamespace, Name: "my-deployer"}
+			if _, err := r.Reconcile(ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() unexpected error: %v", err)
+			}
+
+			if err := c.Get(ctx, key, deployer); err != nil {
+				t.Fatalf("unable to get deployer: %v", err)
+			}
+			streamsReady := deployer.Status.GetCondition(corev1alpha1.DeployerConditionStreamsReady)
+			if test.wantStreamsMessage != "" {
+				if streamsReady.IsTrue() || streamsReady.Message != test.wantStreamsMessage {
+					t.Errorf("StreamsReady = %s %q, want False %q", streamsReady.Status, streamsReady.Message, test.wantStreamsMessage)
+				}
+			} else if !streamsReady.IsTrue() {
+				t.Errorf("StreamsReady = %s %q, want True", streamsReady.Status, streamsReady.Message)
+			}
+
+			var deployments appsv1.DeploymentList
+			if err := c.List(ctx, &deployments, client.InNamespace(testNamespace)); err != nil {
+				t.Fatalf("unable to list deployments: %v", err)
+			}
+			if test.wantNoDeployment {
+				if len(deployments.Items) != 0 {
+					t.Errorf("found %d deployments, want none", len(deployments.Items))
+				}
+				return
+			}
+			if len(deployments.Items) != 1 {
+				t.Fatalf("found %d deployments, want 1", len(deployments.Items))
+			}
+			podSpec := deployments.Items[0].Spec.Template.Spec
+			env := []corev1.EnvVar{}
+			for _, e := range podSpec.Containers[0].Env {
+				if strings.HasPrefix(e.Name, "STREAM_") {
+					env = append(env, e)
+				}
+			}
+			if diff := cmp.Diff(test.wantEnv, env); diff != "" {
+				t.Errorf("stream env (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.wantVolumes, podSpec.Volumes); diff != "" {
+				t.Errorf("volumes (-want, +got) = %v", diff)
+			}
+			mountPaths := []string{}
+			for _, mount := range podSpec.Containers[0].VolumeMounts {
+				if !mount.ReadOnly {
+					t.Errorf("volume mount %q is writable", mount.Name)
+				}
+				mountPaths = append(mountPaths, mount.MountPath)
+			}
+			if diff := cmp.Diff(test.wantMountPaths, mountPaths); diff != "" {
+				t.Errorf("mount paths (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestDeployerReconcileAutoscaler(t *testing.T) {
+	autoscaler := func(conditions ...autoscalingv2beta2.HorizontalPodAutoscalerCondition) *autoscalingv2beta2.HorizontalPodAutoscaler {
+		return &autoscalingv2beta2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "my-deployer-deployer-autoscaler"},
+			Status:     autoscalingv2beta2.HorizontalPodAutoscalerStatus{Conditions: conditions},
+		}
+	}
+	cpuMetric := func(utilization int32) []autoscalingv2beta2.MetricSpec {
+		return []autoscalingv2beta2.MetricSpec{{
+			Type: autoscalingv2beta2.ResourceMetricSourceType,
+			Resource: &autoscalingv2beta2.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+				Target: autoscalingv2beta2.MetricTarget{
+					Type:               autoscalingv2beta2.UtilizationMetricType,
+					AverageUtilization: &utilization,
+				},
+			},
+		}}
+	}
+
+	tests := []struct {
+		name        string
+		scale       *corev1alpha1.Scale
+		objects     []runtime.Object
+		wantMin     int32
+		wantMax     int32
+		wantMetrics []autoscalingv2beta2.MetricSpec
+		wantReady   corev1.ConditionStatus
+		wantReason  string
+	}{{
+		name:      "runs a fixed number of replicas without a scale",
+		wantReady: corev1.ConditionTrue,
+	}, {
+		name:        "scales on the default cpu utilization",
+		scale:       &corev1alpha1.Scale{Max: int32Ptr(5)},
+		wantMin:     1,
+		wantMax:     5,
+		wantMetrics: cpuMetric(80),
+		wantReady:   corev1.ConditionUnknown,
+		wantReason:  "AutoscalerNotConfigured",
+	}, {
+		name:        "scales between the bounds on the cpu utilization",
+		scale:       &corev1alpha1.Scale{Min: int32Ptr(2), Max: int32Ptr(10), TargetCPUUtilization: int32Ptr(50)},
+		wantMin:     2,
+		wantMax:     10,
+		wantMetrics: cpuMetric(50),
+		wantReady:   corev1.ConditionUnknown,
+		wantReason:  "AutoscalerNotConfigured",
+	}, {
+		name:      "deletes the autoscaler once the scale is removed",
+		objects:   []runtime.Object{autoscaler()},
+		wantReady: corev1.ConditionTrue,
+	}, {
+		name:  "reports an autoscaler unable to scale",
+		scale: &corev1alpha1.Scale{Max: int32Ptr(5)},
+		objects: []runtime.Object{autoscaler(
+			autoscalingv2beta2.HorizontalPodAutoscalerCondition{Type: autoscalingv2beta2.AbleToScale, Status: corev1.ConditionFalse, Reason: "FailedGetScale"},
+			autoscalingv2beta2.HorizontalPodAutoscalerCondition{Type: autoscalingv2beta2.ScalingActive, Status: corev1.ConditionTrue},
+		)},
+		wantMin:     1,
+		wantMax:     5,
+		wantMetrics: cpuMetric(80),
+		wantReady:   corev1.ConditionFalse,
+		wantReason:  "FailedGetScale",
+	}, {
+		name:  "is ready once the autoscaler is active",
+		scale: &corev1alpha1.Scale{Max: int32Ptr(5)},
+		objects: []runtime.Object{autoscaler(
+			autoscalingv2beta2.HorizontalPodAutoscalerCondition{Type: autoscalingv2beta2.AbleToScale, Status: corev1.ConditionTrue},
+			autoscalingv2beta2.HorizontalPodAutoscalerCondition{Type: autoscalingv2beta2.ScalingActive, Status: corev1.ConditionTrue},
+		)},
+		wantMin:     1,
+		wantMax:     5,
+		wantMetrics: cpuMetric(80),
+		wantReady:   corev1.ConditionTrue,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			deployer := newTestDeployer("my-image")
+			deployer.Spec.Rollout = nil
+			deployer.Spec.Scale = test.scale
+			deployer.Default()
+			c := newFakeClient(append(test.objects, deployer)...)
+			r := &DeployerReconciler{
+				Client:    c,
+				Log:       zap.Logger(true),
+				Scheme:    scheme.Scheme,
+				Tracker:   newTestTracker(),
+				Namespace: testSystemNamespace,
+			}
+			key := types.NamespacedName{Namespace: testNamespace, Name: "my-deployer"}
+			if _, err := r.Reconcile(ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() unexpected error: %v", err)
+			}
+
+			if err := c.Get(ctx, key, deployer); err != nil {
+				t.Fatalf("unable to get deployer: %v", err)
+			}
+			ready := deployer.Status.GetCondition(corev1alpha1.DeployerConditionAutoscalerReady)
+			if ready.Status != test.wantReady || ready.Reason != test.wantReason {
+				t.Errorf("AutoscalerReady = %s %s, want %s %s", ready.Status, ready.Reason, test.wantReady, test.wantReason)
+			}
+
+			var autoscalers autoscalingv2beta2.HorizontalPodAutoscalerList
+			if err := c.List(ctx, &autoscalers, client.InNamespace(testNamespace)); err != nil {
+				t.Fatalf("unable to list autoscalers: %v", err)
+			}
+			if test.scale == nil {
+				if len(autoscalers.Items) != 0 || deployer.Status.AutoscalerName != "" {
+					t.Errorf("found %d autoscalers and name %q, want none", len(autoscalers.Items), deployer.Status.AutoscalerName)
+				}
+				return
+			}
+			if len(autoscalers.Items) != 1 {
+				t.Fatalf("found %d autoscalers, want 1", len(autoscalers.Items))
+			}
+			actual := autoscalers.Items[0]
+			if deployer.Status.AutoscalerName != actual.Name {
+				t.Errorf("autoscaler name = %q, want %q", deployer.Status.AutoscalerName, actual.Name)
+			}
+			if target := actual.Spec.ScaleTargetRef; target.Kind != "Deployment" || target.Name == "" || target.Name != deployer.Status.DeploymentName {
+				t.Errorf("autoscaler target = %s %q, want Deployment %q", target.Kind, target.Name, deployer.Status.DeploymentName)
+			}
+			if actual.Spec.MinReplicas == nil || *actual.Spec.MinReplicas != test.wantMin || actual.Spec.MaxReplicas != test.wantMax {
+				t.Errorf("autoscaler replicas = %v to %d, want %d to %d", actual.Spec.MinReplicas, actual.Spec.MaxReplicas, test.wantMin, test.wantMax)
+			}
+			if diff := cmp.Diff(test.wantMetrics, actual.Spec.Metrics); diff != "" {
+				t.Errorf("autoscaler metrics (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
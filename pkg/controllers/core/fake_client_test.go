@@ -0,0 +1,77 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/tracker"
+)
+
+func init() {
+	// the fake client decodes objects through the client-go scheme
+	utilruntime.Must(corev1alpha1.AddToScheme(scheme.Scheme))
+	utilruntime.Must(buildv1alpha1.AddToScheme(scheme.Scheme))
+	utilruntime.Must(streamingv1alpha1.AddToScheme(scheme.Scheme))
+}
+
+const (
+	testNamespace       = "test-namespace"
+	testSystemNamespace = "riff-system"
+)
+
+// newFakeClient returns a client holding the objects, as reconcile tests run
+// without an API server
+func newFakeClient(objs ...runtime.Object) client.Client {
+	return &generateNameClient{Client: fake.NewFakeClientWithScheme(scheme.Scheme, objs...)}
+}
+
+// generateNameClient names created objects from their generateName like the
+// API server, the fake client would store them all under an empty name
+type generateNameClient struct {
+	client.Client
+	generated int
+}
+
+func (c *generateNameClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	if accessor.GetName() == "" && accessor.GetGenerateName() != "" {
+		c.generated++
+		accessor.SetName(fmt.Sprintf("%s%05d", accessor.GetGenerateName(), c.generated))
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func newTestTracker() tracker.Tracker {
+	return tracker.New(time.Hour, zap.Logger(true))
+}
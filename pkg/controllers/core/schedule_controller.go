@@ -0,0 +1,511 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/projectriff/system/pkg/apis"
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/controllers"
+	"github.com/projectriff/system/pkg/tracker"
+)
+
+const (
+	cronJobIndexField = ".metadata.cronJobController"
+
+	// scheduleOutputAlias is the alias the output stream is bound to within
+	// the trigger container
+	scheduleOutputAlias = "output"
+)
+
+// ScheduleReconciler reconciles a Schedule object
+type ScheduleReconciler struct {
+	client.Client
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Tracker   tracker.Tracker
+	Namespace string
+}
+
+// +kubebuilder:rbac:groups=core.projectriff.io,resources=schedules,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core.projectriff.io,resources=schedules/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=build.projectriff.io,resources=applications;containers;functions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=streams,verbs=get;list;watch
+
+func (r *ScheduleReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("schedule", req.NamespacedName)
+
+	var originalSchedule corev1alpha1.Schedule
+	if err := r.Get(ctx, req.NamespacedName, &originalSchedule); err != nil {
+		if apierrs.IsNotFound(err) {
+			// we'll ignore not-found errors, since they can't be fixed by an immediate
+			// requeue (we'll need to wait for a new notification), and we can get them
+			// on deleted requests.
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "unable to fetch Schedule")
+		return ctrl.Result{}, err
+	}
+	schedule := *(originalSchedule.DeepCopy())
+
+	schedule.Default()
+	schedule.Status.InitializeConditions()
+
+	result, err := r.reconcile(ctx, log, &schedule)
+
+	// check if status has changed before updating, unless requeued
+	if !result.Requeue && !equality.Semantic.DeepEqual(schedule.Status, originalSchedule.Status) {
+		// update status
+		log.Info("updating schedule status", "diff", cmp.Diff(originalSchedule.Status, schedule.Status))
+		if updateErr := r.Status().Update(ctx, &schedule); updateErr != nil {
+			log.Error(updateErr, "unable to update Schedule status", "schedule", schedule)
+			return ctrl.Result{Requeue: true}, updateErr
+		}
+	}
+
+	// return original reconcile result
+	return result, err
+}
+
+func (r *ScheduleReconciler) reconcile(ctx context.Context, log logr.Logger, schedule *corev1alpha1.Schedule) (ctrl.Result, error) {
+	if schedule.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	// resolve build image
+	if err := r.reconcileBuildImage(ctx, log, schedule); err != nil {
+		if apierrs.IsNotFound(err) {
+			// we'll ignore not-found errors, since the reference build resource
+			// may not exist yet.
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "unable to resolve image for Schedule", "schedule", schedule)
+		return ctrl.Result{Requeue: true}, err
+	}
+
+	// resolve trigger image
+	triggerImage, err := r.resolveTriggerImage(ctx, schedule)
+	if err != nil {
+		log.Error(err, "unable to resolve trigger image for Schedule", "schedule", schedule)
+		return ctrl.Result{}, err
+	}
+
+	// resolve output stream
+	streams, notReady, err := r.resolveStreamBindings(ctx, schedule)
+	if err != nil {
+		log.Error(err, "unable to resolve streams for Schedule", "schedule", schedule)
+		return ctrl.Result{}, err
+	}
+	if notReady != "" {
+		// wait for the output to be bound before invoking the workload
+		schedule.Status.MarkStreamsNotReady(notReady)
+		return ctrl.Result{}, nil
+	}
+	schedule.Status.MarkStreamsReady()
+
+	// reconcile cron job
+	childCronJob, err := r.reconcileChildCronJob(ctx, log, schedule, triggerImage, streams)
+	if err != nil {
+		log.Error(err, "unable to reconcile child CronJob", "schedule", schedule)
+		return ctrl.Result{}, err
+	}
+	schedule.Status.CronJobName = childCronJob.Name
+	schedule.Status.PropagateCronJobStatus(&childCronJob.Status)
+
+	// reflect the outcome of past invocations
+	if err := r.reconcileLastRun(ctx, schedule); err != nil {
+		log.Error(err, "unable to resolve Jobs for Schedule", "schedule", schedule)
+		return ctrl.Result{}, err
+	}
+
+	schedule.Status.ObservedGeneration = schedule.Generation
+	return ctrl.Result{}, nil
+}
+
+func (r *ScheduleReconciler) reconcileBuildImage(ctx context.Context, log logr.Logger, schedule *corev1alpha1.Schedule) error {
+	build := schedule.Spec.Build
+	if build == nil {
+		schedule.Status.LatestImage = schedule.Spec.Template.Containers[0].Image
+		return nil
+	}
+
+	switch {
+	case build.ApplicationRef != "":
+		var application buildv1alpha1.Application
+		key := types.NamespacedName{Namespace: schedule.Namespace, Name: build.ApplicationRef}
+		// track application for new images
+		r.Tracker.Track(
+			tracker.NewKey(application.GetGroupVersionKind(), key),
+			types.NamespacedName{Namespace: schedule.Namespace, Name: schedule.Name},
+		)
+		if err := r.Get(ctx, key, &application); err != nil {
+			return err
+		}
+		if application.Status.LatestImage == "" {
+			return fmt.Errorf("application %q does not have a ready image", build.ApplicationRef)
+		}
+		schedule.Status.LatestImage = application.Status.LatestImage
+		return nil
+
+	case build.ContainerRef != "":
+		var container buildv1alpha1.Container
+		key := types.NamespacedName{Namespace: schedule.Namespace, Name: build.ContainerRef}
+		// track container for new images
+		r.Tracker.Track(
+			tracker.NewKey(container.GetGroupVersionKind(), key),
+			types.NamespacedName{Namespace: schedule.Namespace, Name: schedule.Name},
+		)
+		if err := r.Get(ctx, key, &container); err != nil {
+			return err
+		}
+		if container.Status.LatestImage == "" {
+			return fmt.Errorf("container %q does not have a ready image", build.ContainerRef)
+		}
+		schedule.Status.LatestImage = container.Status.LatestImage
+		return nil
+
+	case build.FunctionRef != "":
+		var function buildv1alpha1.Function
+		key := types.NamespacedName{Namespace: schedule.Namespace, Name: build.FunctionRef}
+		// track function for new images
+		r.Tracker.Track(
+			tracker.NewKey(function.GetGroupVersionKind(), key),
+			types.NamespacedName{Namespace: schedule.Namespace, Name: schedule.Name},
+		)
+		if err := r.Get(ctx, key, &function); err != nil {
+			return err
+		}
+		if function.Status.LatestImage == "" {
+			return fmt.Errorf("function %q does not have a ready image", build.FunctionRef)
+		}
+		schedule.Status.LatestImage = function.Status.LatestImage
+		return nil
+
+	}
+
+	return fmt.Errorf("invalid schedule build")
+}
+
+func (r *ScheduleReconciler) resolveTriggerImage(ctx context.Context, schedule *corev1alpha1.Schedule) (string, error) {
+	var config corev1.ConfigMap
+	key := types.NamespacedName{Namespace: r.Namespace, Name: scheduleConfig}
+	// track config map for new images
+	r.Tracker.Track(
+		tracker.NewKey(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, key),
+		types.NamespacedName{Namespace: schedule.Namespace, Name: schedule.Name},
+	)
+	if err := r.Get(ctx, key, &config); err != nil {
+		return "", err
+	}
+	image := config.Data[scheduleTriggerImageKey]
+	if image == "" {
+		return "", fmt.Errorf("missing trigger image configuration")
+	}
+	return image, nil
+}
+
+func (r *ScheduleReconciler) resolveStreamBindings(ctx context.Context, schedule *corev1alpha1.Schedule) ([]controllers.StreamBinding, string, error) {
+	if schedule.Spec.Output == "" {
+		return nil, "", nil
+	}
+	bindings := []controllers.StreamBinding{
+		{Stream: schedule.Spec.Output, Alias: scheduleOutputAlias},
+	}
+	owner := types.NamespacedName{Namespace: schedule.Namespace, Name: schedule.Name}
+	notReady, err := controllers.ResolveStreamBindings(ctx, r.Client, r.Tracker, owner, bindings)
+	return bindings, notReady, err
+}
+
+func (r *ScheduleReconciler) reconcileChildCronJob(ctx context.Context, log logr.Logger, schedule *corev1alpha1.Schedule, triggerImage string, streams []controllers.StreamBinding) (*batchv1beta1.CronJob, error) {
+	var actualCronJob batchv1beta1.CronJob
+	var childCronJobs batchv1beta1.CronJobList
+	if err := r.List(ctx, &childCronJobs, client.InNamespace(schedule.Namespace), client.MatchingField(cronJobIndexField, schedule.Name)); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childCronJobs.Items) == 1 {
+		actualCronJob = childCronJobs.Items[0]
+	} else if len(childCronJobs.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraCronJob := range childCronJobs.Items {
+			log.Info("deleting extra cron job", "cronJob", extraCronJob)
+			if err := r.Delete(ctx, &extraCronJob); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	desiredCronJob, err := r.constructCronJobForSchedule(schedule, triggerImage, streams)
+	if err != nil {
+		return nil, err
+	}
+
+	// create cron job if it doesn't exist
+	if actualCronJob.Name == "" {
+		log.Info("creating cron job", "spec", desiredCronJob.Spec)
+		if err := r.Create(ctx, desiredCronJob); err != nil {
+			log.Error(err, "unable to create CronJob for Schedule", "cronJob", desiredCronJob)
+			return nil, err
+		}
+		return desiredCronJob, nil
+	}
+
+	// overwrite fields that should not be mutated
+	desiredCronJob.Spec.SuccessfulJobsHistoryLimit = actualCronJob.Spec.SuccessfulJobsHistoryLimit
+	desiredCronJob.Spec.FailedJobsHistoryLimit = actualCronJob.Spec.FailedJobsHistoryLimit
+	desiredCronJob.Spec.Suspend = actualCronJob.Spec.Suspend
+
+	if r.cronJobSemanticEquals(desiredCronJob, &actualCronJob) {
+		// cron job is unchanged
+		return &actualCronJob, nil
+	}
+
+	// update cron job with desired changes
+	cronJob := actualCronJob.DeepCopy()
+	cronJob.ObjectMeta.Labels = desiredCronJob.ObjectMeta.Labels
+	cronJob.Spec = desiredCronJob.Spec
+	log.Info("reconciling cron job", "diff", cmp.Diff(actualCronJob.Spec, cronJob.Spec))
+	if err := r.Update(ctx, cronJob); err != nil {
+		log.Error(err, "unable to update CronJob for Schedule", "cronJob", cronJob)
+		return nil, err
+	}
+
+	return cronJob, nil
+}
+
+func (r *ScheduleReconciler) cronJobSemanticEquals(desiredCronJob, cronJob *batchv1beta1.CronJob) bool {
+	return equality.Semantic.DeepEqual(desiredCronJob.Spec, cronJob.Spec) &&
+		equality.Semantic.DeepEqual(desiredCronJob.ObjectMeta.Labels, cronJob.ObjectMeta.Labels)
+}
+
+func (r *ScheduleReconciler) constructCronJobForSchedule(schedule *corev1alpha1.Schedule, triggerImage string, streams []controllers.StreamBinding) (*batchv1beta1.CronJob, error) {
+	labels := r.constructLabelsForSchedule(schedule)
+
+	cronJob := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       labels,
+			GenerateName: fmt.Sprintf("%s-schedule-", schedule.Name),
+			Namespace:    schedule.Namespace,
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:          schedule.Spec.Schedule,
+			ConcurrencyPolicy: schedule.Spec.ConcurrencyPolicy,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					// jobs are labeled so their outcome is reflected on the schedule
+					Labels: labels,
+				},
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: labels,
+						},
+						Spec: r.constructPodSpecForSchedule(schedule, triggerImage, streams),
+					},
+				},
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(schedule, cronJob, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return cronJob, nil
+}
+
+// constructPodSpecForSchedule runs the workload next to a trigger container.
+// The trigger invokes the workload once, publishes the result to the output
+// stream when bound, and then stops the workload so the job completes.
+func (r *ScheduleReconciler) constructPodSpecForSchedule(schedule *corev1alpha1.Schedule, triggerImage string, streams []controllers.StreamBinding) corev1.PodSpec {
+	podSpec := *schedule.Spec.Template.DeepCopy()
+	targetPort := podSpec.Containers[0].Ports[0]
+
+	if podSpec.Containers[0].Image == "" {
+		podSpec.Containers[0].Image = schedule.Status.LatestImage
+	}
+	podSpec.Containers[0].Env = append(podSpec.Containers[0].Env, corev1.EnvVar{
+		Name:  "PORT",
+		Value: fmt.Sprintf("%d", targetPort.ContainerPort),
+	})
+
+	trigger := corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:            "trigger",
+				Image:           triggerImage,
+				ImagePullPolicy: corev1.PullAlways,
+				Env: []corev1.EnvVar{
+					{Name: "TARGET", Value: fmt.Sprintf("http://localhost:%d", targetPort.ContainerPort)},
+					{Name: "PAYLOAD", Value: schedule.Spec.Payload},
+					{Name: "CONTENT_TYPE", Value: schedule.Spec.ContentType},
+				},
+			},
+		},
+	}
+	controllers.ProjectStreamBindings(&trigger, streams)
+	podSpec.Containers = append(podSpec.Containers, trigger.Containers...)
+	podSpec.Volumes = append(podSpec.Volumes, trigger.Volumes...)
+
+	// allow the trigger to stop the workload once invoked
+	shareProcessNamespace := true
+	podSpec.ShareProcessNamespace = &shareProcessNamespace
+	podSpec.RestartPolicy = corev1.RestartPolicyNever
+
+	return podSpec
+}
+
+func (r *ScheduleReconciler) reconcileLastRun(ctx context.Context, schedule *corev1alpha1.Schedule) error {
+	var jobs batchv1.JobList
+	if err := r.List(ctx, &jobs, client.InNamespace(schedule.Namespace), client.MatchingLabels{corev1alpha1.ScheduleLabelKey: schedule.Name}); err != nil {
+		return err
+	}
+
+	var lastJob *batchv1.Job
+	var lastFinished *metav1.Time
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		finished := controllers.JobFinishedTime(job)
+		if finished == nil {
+			continue
+		}
+		if controllers.IsJobFailed(job) {
+			if schedule.Status.LastFailureTime == nil || schedule.Status.LastFailureTime.Before(finished) {
+				schedule.Status.LastFailureTime = finished
+			}
+		} else {
+			if schedule.Status.LastSuccessfulTime == nil || schedule.Status.LastSuccessfulTime.Before(finished) {
+				schedule.Status.LastSuccessfulTime = finished
+			}
+		}
+		if lastFinished == nil || lastFinished.Before(finished) {
+			lastJob = job
+			lastFinished = finished
+		}
+	}
+
+	if lastJob == nil {
+		if schedule.Status.LastSuccessfulTime == nil && schedule.Status.LastFailureTime == nil {
+			schedule.Status.MarkNoRuns()
+		}
+		return nil
+	}
+	schedule.Status.PropagateLastJobStatus(&lastJob.Status)
+	return nil
+}
+
+func (r *ScheduleReconciler) constructLabelsForSchedule(schedule *corev1alpha1.Schedule) map[string]string {
+	labels := make(map[string]string, len(schedule.ObjectMeta.Labels)+1)
+	// pass through existing labels
+	for k, v := range schedule.ObjectMeta.Labels {
+		labels[k] = v
+	}
+
+	labels[corev1alpha1.ScheduleLabelKey] = schedule.Name
+	return labels
+}
+
+func (r *ScheduleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	enqueueTrackedResources := func(t apis.Resource) handler.EventHandler {
+		return &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+				requests := []reconcile.Request{}
+				key := tracker.NewKey(
+					t.GetGroupVersionKind(),
+					types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: a.Meta.GetName()},
+				)
+				for _, item := range r.Tracker.Lookup(key) {
+					requests = append(requests, reconcile.Request{NamespacedName: item})
+				}
+				return requests
+			}),
+		}
+	}
+
+	enqueueTrackedConfig := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			requests := []reconcile.Request{}
+			if a.Meta.GetNamespace() == r.Namespace && a.Meta.GetName() == scheduleConfig {
+				key := tracker.NewKey(
+					schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+					types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: a.Meta.GetName()},
+				)
+				for _, item := range r.Tracker.Lookup(key) {
+					requests = append(requests, reconcile.Request{NamespacedName: item})
+				}
+			}
+			return requests
+		}),
+	}
+
+	enqueueScheduleForJob := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			name, ok := a.Meta.GetLabels()[corev1alpha1.ScheduleLabelKey]
+			if !ok {
+				return nil
+			}
+			return []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: name}},
+			}
+		}),
+	}
+
+	if err := controllers.IndexControllersOfType(mgr, cronJobIndexField, &corev1alpha1.Schedule{}, &batchv1beta1.CronJob{}); err != nil {
+		return err
+	}
+
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&corev1alpha1.Schedule{}).
+		Owns(&batchv1beta1.CronJob{}).
+		// watch for job completions to report the outcome of invocations
+		Watches(&source.Kind{Type: &batchv1.Job{}}, enqueueScheduleForJob).
+		// watch for build mutations to update dependent schedules
+		Watches(&source.Kind{Type: &buildv1alpha1.Application{}}, enqueueTrackedResources(&buildv1alpha1.Application{})).
+		Watches(&source.Kind{Type: &buildv1alpha1.Container{}}, enqueueTrackedResources(&buildv1alpha1.Container{})).
+		Watches(&source.Kind{Type: &buildv1alpha1.Function{}}, enqueueTrackedResources(&buildv1alpha1.Function{})).
+		// watch for cluster config mutations to update all schedules
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueTrackedConfig)
+	if controllers.IsKindInstalled(mgr, (&streamingv1alpha1.Stream{}).GetGroupVersionKind()) {
+		// watch for stream mutations to update bound schedules
+		builder = builder.Watches(&source.Kind{Type: &streamingv1alpha1.Stream{}}, enqueueTrackedResources(&streamingv1alpha1.Stream{}))
+	}
+	return builder.Complete(r)
+}
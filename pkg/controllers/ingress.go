@@ -0,0 +1,108 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
+)
+
+const (
+	ingressClassAnnotationKey             = "kubernetes.io/ingress.class"
+	certManagerIssuerAnnotationKey        = "cert-manager.io/issuer"
+	certManagerClusterIssuerAnnotationKey = "cert-manager.io/cluster-issuer"
+)
+
+// ConstructIngressSpec routes the host and path of the ingress settings to
+// port 80 of the service. The host defaults to
+// `<service>.<namespace>.<domain>`, nil is returned when neither a host nor a
+// domain is available. The ingress class and cert-manager issuer are returned
+// as annotations. The TLS secret defaults to tlsSecretName.
+func ConstructIngressSpec(settings *corev1alpha1.Ingress, serviceName, namespace, domain, tlsSecretName string) (*networkingv1beta1.IngressSpec, map[string]string) {
+	if settings == nil {
+		settings = &corev1alpha1.Ingress{}
+		settings.Default()
+	}
+	annotations := map[string]string{}
+
+	host := settings.Host
+	if host == "" {
+		if domain == "" {
+			return nil, nil
+		}
+		host = fmt.Sprintf("%s.%s.%s", serviceName, namespace, domain)
+	}
+	if settings.Class != "" {
+		annotations[ingressClassAnnotationKey] = settings.Class
+	}
+
+	spec := &networkingv1beta1.IngressSpec{
+		Rules: []networkingv1beta1.IngressRule{{
+			Host: host,
+			IngressRuleValue: networkingv1beta1.IngressRuleValue{
+				HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+					Paths: []networkingv1beta1.HTTPIngressPath{{
+						Path: settings.Path,
+						Backend: networkingv1beta1.IngressBackend{
+							ServiceName: serviceName,
+							ServicePort: intstr.FromInt(80),
+						},
+					}},
+				},
+			},
+		}},
+	}
+
+	if tls := settings.TLS; tls != nil {
+		secretName := tls.SecretName
+		if secretName == "" {
+			secretName = tlsSecretName
+		}
+		spec.TLS = []networkingv1beta1.IngressTLS{{
+			Hosts:      []string{host},
+			SecretName: secretName,
+		}}
+		if tls.IssuerRef != nil {
+			if tls.IssuerRef.Kind == corev1alpha1.IssuerKind {
+				annotations[certManagerIssuerAnnotationKey] = tls.IssuerRef.Name
+			} else {
+				annotations[certManagerClusterIssuerAnnotationKey] = tls.IssuerRef.Name
+			}
+		}
+	}
+
+	return spec, annotations
+}
+
+// IngressURL is the url of the first rule of the ingress, https when the
+// ingress terminates TLS.
+func IngressURL(ingress *networkingv1beta1.Ingress) string {
+	scheme := "http"
+	if len(ingress.Spec.TLS) != 0 {
+		scheme = "https"
+	}
+	rule := ingress.Spec.Rules[0]
+	path := rule.HTTP.Paths[0].Path
+	if path == "/" {
+		path = ""
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, rule.Host, path)
+}
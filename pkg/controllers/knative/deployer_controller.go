@@ -19,6 +19,7 @@ package knative
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
@@ -37,6 +38,7 @@ import (
 	"github.com/projectriff/system/pkg/apis"
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
 	knativev1alpha1 "github.com/projectriff/system/pkg/apis/knative/v1alpha1"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
 	servingv1 "github.com/projectriff/system/pkg/apis/thirdparty/knative/serving/v1"
 	"github.com/projectriff/system/pkg/controllers"
 	"github.com/projectriff/system/pkg/tracker"
@@ -45,6 +47,9 @@ import (
 const (
 	configurationIndexField = ".metadata.configurationController"
 	routeIndexField         = ".metadata.routeController"
+
+	stableTrafficTag    = "stable"
+	candidateTrafficTag = "candidate"
 )
 
 // DeployerReconciler reconciles a Deployer object
@@ -59,6 +64,8 @@ type DeployerReconciler struct {
 // +kubebuilder:rbac:groups=knative.projectriff.io,resources=deployers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=build.projectriff.io,resources=applications;containers;functions,verbs=get;list;watch
 // +kubebuilder:rbac:groups=serving.knative.dev,resources=configurations;routes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=serving.knative.dev,resources=revisions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=streams,verbs=get;list;watch
 
 func (r *DeployerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -112,8 +119,21 @@ func (r *DeployerReconciler) reconcile(ctx context.Context, log logr.Logger, dep
 		return ctrl.Result{Requeue: true}, err
 	}
 
+	// resolve stream bindings
+	streams, notReady, err := r.resolveStreamBindings(ctx, deployer)
+	if err != nil {
+		log.Error(err, "unable to resolve streams for Deployer", "deployer", deployer)
+		return ctrl.Result{}, err
+	}
+	if notReady != "" {
+		// wait for the streams to be bound before rolling out the workload
+		deployer.Status.MarkStreamsNotReady(notReady)
+		return ctrl.Result{}, nil
+	}
+	deployer.Status.MarkStreamsReady()
+
 	// reconcile configuration
-	childConfiguration, err := r.reconcileChildConfiguration(ctx, log, deployer)
+	childConfiguration, err := r.reconcileChildConfiguration(ctx, log, deployer, streams)
 	if err != nil {
 		log.Error(err, "unable to reconcile child Configuration", "deployer", deployer)
 		return ctrl.Result{}, err
@@ -121,8 +141,15 @@ func (r *DeployerReconciler) reconcile(ctx context.Context, log logr.Logger, dep
 	deployer.Status.ConfigurationName = childConfiguration.Name
 	deployer.Status.PropagateConfigurationStatus(&childConfiguration.Status)
 
+	// reconcile rollout
+	traffic, requeueAfter, err := r.reconcileRollout(ctx, log, deployer, childConfiguration)
+	if err != nil {
+		log.Error(err, "unable to reconcile rollout", "deployer", deployer)
+		return ctrl.Result{}, err
+	}
+
 	// reconcile route
-	childRoute, err := r.reconcileChildRoute(ctx, log, deployer)
+	childRoute, err := r.reconcileChildRoute(ctx, log, deployer, traffic)
 	if err != nil {
 		log.Error(err, "unable to reconcile child Route", "deployer", deployer)
 		return ctrl.Result{}, err
@@ -132,7 +159,78 @@ func (r *DeployerReconciler) reconcile(ctx context.Context, log logr.Logger, dep
 
 	deployer.Status.ObservedGeneration = deployer.Generation
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// reconcileRollout resolves the traffic targets for the route, advancing the
+// rollout of a new revision one step at a time while the revision is ready.
+// The returned duration is the time until the current step completes.
+func (r *DeployerReconciler) reconcileRollout(ctx context.Context, log logr.Logger, deployer *knativev1alpha1.Deployer, configuration *servingv1.Configuration) ([]knativev1alpha1.TrafficTarget, time.Duration, error) {
+	rollout := deployer.Spec.Rollout
+	if rollout == nil {
+		deployer.Status.Rollout = nil
+		deployer.Status.MarkRolloutNotRequired()
+		return deployer.Spec.Traffic, 0, nil
+	}
+
+	status := deployer.Status.Rollout
+	if status == nil {
+		status = &knativev1alpha1.RolloutStatus{}
+		deployer.Status.Rollout = status
+	}
+	if status.StableRevisionName == "" {
+		// adopt the latest ready revision, there is nothing to roll out from
+		status.StableRevisionName = configuration.Status.LatestReadyRevisionName
+	}
+	latest := configuration.Status.LatestCreatedRevisionName
+	if status.StableRevisionName == "" {
+		// route to the first revision once it is ready
+		if latest != "" {
+			deployer.Status.MarkRolloutWaiting(latest)
+		}
+		return nil, 0, nil
+	}
+	ready, message := corev1.ConditionUnknown, ""
+	if latest != "" && latest != status.StableRevisionName && latest != status.FailedRevisionName {
+		var revision servingv1.Revision
+		key := types.NamespacedName{Namespace: deployer.Namespace, Name: latest}
+		// track revision for readiness changes
+		r.Tracker.Track(
+			tracker.NewKey(revision.GetGroupVersionKind(), key),
+			types.NamespacedName{Namespace: deployer.Namespace, Name: deployer.Name},
+		)
+		if err := r.Get(ctx, key, &revision); err != nil {
+			if !apierrs.IsNotFound(err) {
+				return nil, 0, err
+			}
+		}
+		if cond := revision.Status.GetCondition(servingv1.RevisionConditionReady); cond != nil {
+			ready, message = cond.Status, cond.Message
+		}
+	}
+
+	previous := status.DeepCopy()
+	requeueAfter := deployer.Status.ProgressRollout(rollout, latest, ready, message, time.Now())
+	switch {
+	case status.CandidateRevisionName != "" && status.CandidateRevisionName != previous.CandidateRevisionName:
+		log.Info("starting rollout", "revision", latest)
+	case status.FailedRevisionName != previous.FailedRevisionName:
+		log.Info("rolling back", "revision", latest)
+	case status.StableRevisionName != previous.StableRevisionName:
+		log.Info("completing rollout", "revision", latest)
+	}
+
+	stableTraffic := []knativev1alpha1.TrafficTarget{
+		{RevisionName: status.StableRevisionName, Percent: int64Ptr(100), Tag: stableTrafficTag},
+	}
+	percent := status.CandidatePercent(rollout)
+	if percent == 0 {
+		return stableTraffic, requeueAfter, nil
+	}
+	return []knativev1alpha1.TrafficTarget{
+		{RevisionName: status.StableRevisionName, Percent: int64Ptr(100 - percent), Tag: stableTrafficTag},
+		{RevisionName: status.CandidateRevisionName, Percent: int64Ptr(percent), Tag: candidateTrafficTag},
+	}, requeueAfter, nil
 }
 
 func (r *DeployerReconciler) reconcileBuildImage(ctx context.Context, log logr.Logger, deployer *knativev1alpha1.Deployer) error {
@@ -199,7 +297,20 @@ func (r *DeployerReconciler) reconcileBuildImage(ctx context.Context, log logr.L
 	return fmt.Errorf("invalid deployer build")
 }
 
-func (r *DeployerReconciler) reconcileChildConfiguration(ctx context.Context, log logr.Logger, deployer *knativev1alpha1.Deployer) (*servingv1.Configuration, error) {
+func (r *DeployerReconciler) resolveStreamBindings(ctx context.Context, deployer *knativev1alpha1.Deployer) ([]controllers.StreamBinding, string, error) {
+	bindings := make([]controllers.StreamBinding, len(deployer.Spec.Streams))
+	for i, stream := range deployer.Spec.Streams {
+		bindings[i] = controllers.StreamBinding{Stream: stream.Stream, Alias: stream.Alias}
+		if stream.Mode == knativev1alpha1.StreamBindingModeSubscribe {
+			bindings[i].Group = controllers.ConsumerGroup("knative-deployer", deployer.Name)
+		}
+	}
+	owner := types.NamespacedName{Namespace: deployer.Namespace, Name: deployer.Name}
+	notReady, err := controllers.ResolveStreamBindings(ctx, r.Client, r.Tracker, owner, bindings)
+	return bindings, notReady, err
+}
+
+func (r *DeployerReconciler) reconcileChildConfiguration(ctx context.Context, log logr.Logger, deployer *knativev1alpha1.Deployer, streams []controllers.StreamBinding) (*servingv1.Configuration, error) {
 	var actualConfiguration servingv1.Configuration
 	var childConfigurations servingv1.ConfigurationList
 	if err := r.List(ctx, &childConfigurations, client.InNamespace(deployer.Namespace), client.MatchingField(configurationIndexField, deployer.Name)); err != nil {
@@ -218,7 +329,7 @@ func (r *DeployerReconciler) reconcileChildConfiguration(ctx context.Context, lo
 		}
 	}
 
-	desiredConfiguration, err := r.constructConfigurationForDeployer(deployer)
+	desiredConfiguration, err := r.constructConfigurationForDeployer(deployer, streams)
 	if err != nil {
 		return nil, err
 	}
@@ -266,8 +377,10 @@ func (r *DeployerReconciler) configurationSemanticEquals(desiredConfiguration, c
 		equality.Semantic.DeepEqual(desiredConfiguration.ObjectMeta.Labels, configuration.ObjectMeta.Labels)
 }
 
-func (r *DeployerReconciler) constructConfigurationForDeployer(deployer *knativev1alpha1.Deployer) (*servingv1.Configuration, error) {
+func (r *DeployerReconciler) constructConfigurationForDeployer(deployer *knativev1alpha1.Deployer, streams []controllers.StreamBinding) (*servingv1.Configuration, error) {
 	labels := r.constructLabelsForDeployer(deployer)
+	podSpec := deployer.Spec.Template.DeepCopy()
+	controllers.ProjectStreamBindings(podSpec, streams)
 
 	configuration := &servingv1.Configuration{
 		ObjectMeta: metav1.ObjectMeta{
@@ -282,9 +395,9 @@ func (r *DeployerReconciler) constructConfigurationForDeployer(deployer *knative
 				},
 				Spec: servingv1.RevisionSpec{
 					PodSpec: corev1.PodSpec{
-						ServiceAccountName: deployer.Spec.Template.ServiceAccountName,
-						Containers:         deployer.Spec.Template.Containers,
-						Volumes:            deployer.Spec.Template.Volumes,
+						ServiceAccountName: podSpec.ServiceAccountName,
+						Containers:         podSpec.Containers,
+						Volumes:            podSpec.Volumes,
 					},
 				},
 			},
@@ -300,7 +413,7 @@ func (r *DeployerReconciler) constructConfigurationForDeployer(deployer *knative
 	return configuration, nil
 }
 
-func (r *DeployerReconciler) reconcileChildRoute(ctx context.Context, log logr.Logger, deployer *knativev1alpha1.Deployer) (*servingv1.Route, error) {
+func (r *DeployerReconciler) reconcileChildRoute(ctx context.Context, log logr.Logger, deployer *knativev1alpha1.Deployer, traffic []knativev1alpha1.TrafficTarget) (*servingv1.Route, error) {
 	var actualRoute servingv1.Route
 	var childRoutes servingv1.RouteList
 	if err := r.List(ctx, &childRoutes, client.InNamespace(deployer.Namespace), client.MatchingField(routeIndexField, deployer.Name)); err != nil {
@@ -319,7 +432,7 @@ func (r *DeployerReconciler) reconcileChildRoute(ctx context.Context, log logr.L
 		}
 	}
 
-	desiredRoute, err := r.constructRouteForDeployer(deployer)
+	desiredRoute, err := r.constructRouteForDeployer(deployer, traffic)
 	if err != nil {
 		return nil, err
 	}
@@ -367,13 +480,33 @@ func (r *DeployerReconciler) routeSemanticEquals(desiredRoute, route *servingv1.
 		equality.Semantic.DeepEqual(desiredRoute.ObjectMeta.Labels, route.ObjectMeta.Labels)
 }
 
-func (r *DeployerReconciler) constructRouteForDeployer(deployer *knativev1alpha1.Deployer) (*servingv1.Route, error) {
+func (r *DeployerReconciler) constructRouteForDeployer(deployer *knativev1alpha1.Deployer, targets []knativev1alpha1.TrafficTarget) (*servingv1.Route, error) {
 	if deployer.Status.ConfigurationName == "" {
 		return nil, fmt.Errorf("unable to create Route, waiting for Configuration")
 	}
 
 	labels := r.constructLabelsForDeployer(deployer)
 	var allTraffic int64 = 100
+	traffic := []servingv1.TrafficTarget{
+		{
+			Percent:           &allTraffic,
+			ConfigurationName: deployer.Status.ConfigurationName,
+		},
+	}
+	if len(targets) != 0 {
+		traffic = make([]servingv1.TrafficTarget, len(targets))
+		for i, t := range targets {
+			traffic[i] = servingv1.TrafficTarget{
+				Tag:     t.Tag,
+				Percent: t.Percent,
+			}
+			if t.RevisionName == knativev1alpha1.LatestRevisionName {
+				traffic[i].ConfigurationName = deployer.Status.ConfigurationName
+			} else {
+				traffic[i].RevisionName = t.RevisionName
+			}
+		}
+	}
 
 	route := &servingv1.Route{
 		ObjectMeta: metav1.ObjectMeta{
@@ -383,12 +516,7 @@ func (r *DeployerReconciler) constructRouteForDeployer(deployer *knativev1alpha1
 			Namespace:    deployer.Namespace,
 		},
 		Spec: servingv1.RouteSpec{
-			Traffic: []servingv1.TrafficTarget{
-				{
-					Percent:           &allTraffic,
-					ConfigurationName: deployer.Status.ConfigurationName,
-				},
-			},
+			Traffic: traffic,
 		},
 	}
 	if err := ctrl.SetControllerReference(deployer, route, r.Scheme); err != nil {
@@ -438,7 +566,7 @@ func (r *DeployerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&knativev1alpha1.Deployer{}).
 		Owns(&servingv1.Configuration{}).
 		Owns(&servingv1.Route{}).
@@ -446,5 +574,15 @@ func (r *DeployerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(&source.Kind{Type: &buildv1alpha1.Application{}}, enqueueTrackedResources(&buildv1alpha1.Application{})).
 		Watches(&source.Kind{Type: &buildv1alpha1.Container{}}, enqueueTrackedResources(&buildv1alpha1.Container{})).
 		Watches(&source.Kind{Type: &buildv1alpha1.Function{}}, enqueueTrackedResources(&buildv1alpha1.Function{})).
-		Complete(r)
+		// watch for revision readiness to advance rollouts
+		Watches(&source.Kind{Type: &servingv1.Revision{}}, enqueueTrackedResources(&servingv1.Revision{}))
+	if controllers.IsKindInstalled(mgr, (&streamingv1alpha1.Stream{}).GetGroupVersionKind()) {
+		// watch for stream mutations to update bound deployers
+		builder = builder.Watches(&source.Kind{Type: &streamingv1alpha1.Stream{}}, enqueueTrackedResources(&streamingv1alpha1.Stream{}))
+	}
+	return builder.Complete(r)
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
 }
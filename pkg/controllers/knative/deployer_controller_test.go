@@ -0,0 +1,175 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package knative
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	knativev1alpha1 "github.com/projectriff/system/pkg/apis/knative/v1alpha1"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	servingv1 "github.com/projectriff/system/pkg/apis/thirdparty/knative/serving/v1"
+)
+
+// boundStream is a stream of the in-memory provider with its binding secret
+func boundStream(name string) *streamingv1alpha1.Stream {
+	stream := &streamingv1alpha1.Stream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: name},
+	}
+	stream.Status.Address = streamingv1alpha1.StreamAddress{Gateway: "memory-gateway:6565", Topic: testNamespace + "_" + name}
+	stream.Status.Binding.SecretRef.Name = name + "-stream-binding"
+	return stream
+}
+
+func TestDeployerReconcileStreams(t *testing.T) {
+	unboundStream := boundStream("numbers")
+	unboundStream.Status.Address.Gateway = ""
+
+	tests := []struct {
+		name               string
+		streams            []knativev1alpha1.StreamBinding
+		objects            []runtime.Object
+		wantStreamsMessage string
+		wantEnv            []corev1.EnvVar
+		wantVolumes        []corev1.Volume
+		wantMountPaths     []string
+		wantNoConfig       bool
+	}{{
+		name:               "waits for a missing stream",
+		streams:            []knativev1alpha1.StreamBinding{{Stream: "numbers"}},
+		wantStreamsMessage: `stream "numbers" not found`,
+		wantNoConfig:       true,
+	}, {
+		name:               "waits for the stream to be provisioned",
+		streams:            []knativev1alpha1.StreamBinding{{Stream: "numbers"}},
+		objects:            []runtime.Object{unboundStream},
+		wantStreamsMessage: `stream "numbers" does not have a binding`,
+		wantNoConfig:       true,
+	}, {
+		name:    "projects streams to publish to",
+		streams: []knativev1alpha1.StreamBinding{{Stream: "numbers"}},
+		objects: []runtime.Object{boundStream("numbers")},
+		wantEnv: []corev1.EnvVar{
+			{Name: "STREAM_NUMBERS_GATEWAY", Value: "memory-gateway:6565"},
+			{Name: "STREAM_NUMBERS_TOPIC", Value: "test-namespace_numbers"},
+		},
+		wantVolumes: []corev1.Volume{
+			{Name: "stream-numbers", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "numbers-stream-binding"}}},
+		},
+		wantMountPaths: []string{"/var/riff/streams/numbers"},
+	}, {
+		name:    "projects subscriptions under their alias",
+		streams: []knativev1alpha1.StreamBinding{{Stream: "numbers", Alias: "in-numbers", Mode: knativev1alpha1.StreamBindingModeSubscribe}},
+		objects: []runtime.Object{boundStream("numbers")},
+		wantEnv: []corev1.EnvVar{
+			{Name: "STREAM_IN_NUMBERS_GATEWAY", Value: "memory-gateway:6565"},
+			{Name: "STREAM_IN_NUMBERS_TOPIC", Value: "test-namespace_numbers"},
+			{Name: "STREAM_IN_NUMBERS_GROUP", Value: "knative-deployer-my-deployer"},
+		},
+		wantVolumes: []corev1.Volume{
+			{Name: "stream-in-numbers", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "numbers-stream-binding"}}},
+		},
+		wantMountPaths: []string{"/var/riff/streams/in-numbers"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			deployer := &knativev1alpha1.Deployer{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "my-deployer"},
+				Spec: knativev1alpha1.DeployerSpec{
+					Template: &corev1.PodSpec{
+						Containers: []corev1.Container{{Image: "my-image"}},
+					},
+					Streams: test.streams,
+				},
+			}
+			deployer.Default()
+			c := newFakeClient(append(test.objects, deployer)...)
+			r := &DeployerReconciler{
+				Client:  c,
+				Log:     zap.Logger(true),
+				Scheme:  scheme.Scheme,
+				Tracker: newTestTracker(),
+			}
+			key := types.NamespacedName{Namespace: testNamespace, Name: "my-deployer"}
+			if _, err := r.Reconcile(ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() unexpected error: %v", err)
+			}
+
+			if err := c.Get(ctx, key, deployer); err != nil {
+				t.Fatalf("unable to get deployer: %v", err)
+			}
+			streamsReady := deployer.Status.GetCondition(knativev1alpha1.DeployerConditionStreamsReady)
+			if test.wantStreamsMessage != "" {
+				if streamsReady.IsTrue() || streamsReady.Message != test.wantStreamsMessage {
+					t.Errorf("StreamsReady = %s %q, want False %q", streamsReady.Status, streamsReady.Message, test.wantStreamsMessage)
+				}
+			} else if !streamsReady.IsTrue() {
+				t.Errorf("StreamsReady = %s %q, want True", streamsReady.Status, streamsReady.Message)
+			}
+
+			var configurations servingv1.ConfigurationList
+			if err := c.List(ctx, &configurations, client.InNamespace(testNamespace)); err != nil {
+				t.Fatalf("unable to list configurations: %v", err)
+			}
+			if test.wantNoConfig {
+				if len(configurations.Items) != 0 {
+					t.Errorf("found %d configurations, want none", len(configurations.Items))
+				}
+				return
+			}
+			if len(configurations.Items) != 1 {
+				t.Fatalf("found %d configurations, want 1", len(configurations.Items))
+			}
+			podSpec := configurations.Items[0].Spec.Template.Spec.PodSpec
+			env := []corev1.EnvVar{}
+			for _, e := range podSpec.Containers[0].Env {
+				if strings.HasPrefix(e.Name, "STREAM_") {
+					env = append(env, e)
+				}
+			}
+			if diff := cmp.Diff(test.wantEnv, env); diff != "" {
+				t.Errorf("stream env (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.wantVolumes, podSpec.Volumes); diff != "" {
+				t.Errorf("volumes (-want, +got) = %v", diff)
+			}
+			mountPaths := []string{}
+			for _, mount := range podSpec.Containers[0].VolumeMounts {
+				if !mount.ReadOnly {
+					t.Errorf("volume mount %q is writable", mount.Name)
+				}
+				mountPaths = append(mountPaths, mount.MountPath)
+			}
+			if diff := cmp.Diff(test.wantMountPaths, mountPaths); diff != "" {
+				t.Errorf("mount paths (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
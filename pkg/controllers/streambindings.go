@@ -0,0 +1,117 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/tracker"
+)
+
+const streamBindingsMountPath = "/var/riff/streams"
+
+// StreamBinding is a stream bound to a workload under an alias. Gateway,
+// Topic and SecretName are populated by ResolveStreamBindings.
+type StreamBinding struct {
+	Stream string
+	Alias  string
+	// Group is the consumer group of a subscriber, empty for publishers
+	Group string
+
+	Gateway    string
+	Topic      string
+	SecretName string
+}
+
+// ConsumerGroup is the default consumer group of a workload, qualified by the
+// workload's kind so that workloads of different kinds sharing a name do not
+// join one group and split the messages of a stream between them. Processors
+// predate the qualification and keep their name as their group.
+func ConsumerGroup(kind, name string) string {
+	return fmt.Sprintf("%s-%s", kind, name)
+}
+
+// ResolveStreamBindings looks up the stream for each binding, tracking it on
+// behalf of the owner. A non-empty message is returned when a stream is
+// missing or is not yet able to be bound.
+func ResolveStreamBindings(ctx context.Context, c client.Client, t tracker.Tracker, owner types.NamespacedName, bindings []StreamBinding) (string, error) {
+	for i := range bindings {
+		binding := &bindings[i]
+		var stream streamingv1alpha1.Stream
+		key := types.NamespacedName{Namespace: owner.Namespace, Name: binding.Stream}
+		// track stream for new coordinates
+		t.Track(tracker.NewKey(stream.GetGroupVersionKind(), key), owner)
+		if err := c.Get(ctx, key, &stream); err != nil {
+			if apierrs.IsNotFound(err) {
+				return fmt.Sprintf("stream %q not found", binding.Stream), nil
+			}
+			return "", err
+		}
+		if stream.Status.Address.Gateway == "" || stream.Status.Address.Topic == "" || stream.Status.Binding.SecretRef.Name == "" {
+			return fmt.Sprintf("stream %q does not have a binding", binding.Stream), nil
+		}
+		binding.Gateway = stream.Status.Address.Gateway
+		binding.Topic = stream.Status.Address.Topic
+		binding.SecretName = stream.Status.Binding.SecretRef.Name
+	}
+	return "", nil
+}
+
+// ProjectStreamBindings exposes resolved stream bindings to the first
+// container of the pod as environment variables and as files mounted from
+// each stream's binding secret. The coordinates are set literally so that a
+// change to a stream rolls the pods.
+func ProjectStreamBindings(podSpec *corev1.PodSpec, bindings []StreamBinding) {
+	if len(bindings) == 0 {
+		return
+	}
+	container := &podSpec.Containers[0]
+	for _, binding := range bindings {
+		prefix := fmt.Sprintf("STREAM_%s_", strings.ToUpper(strings.ReplaceAll(binding.Alias, "-", "_")))
+		container.Env = append(container.Env,
+			corev1.EnvVar{Name: prefix + "GATEWAY", Value: binding.Gateway},
+			corev1.EnvVar{Name: prefix + "TOPIC", Value: binding.Topic},
+		)
+		if binding.Group != "" {
+			container.Env = append(container.Env, corev1.EnvVar{Name: prefix + "GROUP", Value: binding.Group})
+		}
+
+		volumeName := fmt.Sprintf("stream-%s", binding.Alias)
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: binding.SecretName,
+				},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      volumeName,
+			MountPath: path.Join(streamBindingsMountPath, binding.Alias),
+			ReadOnly:  true,
+		})
+	}
+}
@@ -19,11 +19,21 @@ package streaming
 const (
 	kustomizePrefix = "riff-streaming" // kustomize adds this prefix to all our resource names
 
-	kafkaProviderImages  = kustomizePrefix + "-kafka-provider"  // contains image names for the kafka provider
-	pulsarProviderImages = kustomizePrefix + "-pulsar-provider" // contains image names for the pulsar provider
-	gatewayImageKey      = "gatewayImage"
-	provisionerImageKey  = "provisionerImage"
+	kafkaProviderImages    = kustomizePrefix + "-kafka-provider"    // contains image names for the kafka provider
+	pulsarProviderImages   = kustomizePrefix + "-pulsar-provider"   // contains image names for the pulsar provider
+	inMemoryProviderImages = kustomizePrefix + "-inmemory-provider" // contains image names for the in-memory provider
+	gatewayImageKey        = "gatewayImage"
+	provisionerImageKey    = "provisionerImage"
 
 	processorImages   = kustomizePrefix + "-processor" // contains image names for the streaming processor
 	processorImageKey = "processorImage"
+
+	httpSourceImages   = kustomizePrefix + "-http-source" // contains image names for the http source
+	httpSourceImageKey = "httpSourceImage"
+
+	subscriptionImages = kustomizePrefix + "-subscription" // contains image names for the subscription dispatcher
+	dispatcherImageKey = "dispatcherImage"
+
+	coreIngressConfig = "riff-core-ingress" // contains cluster wide ingress settings, shared with the core runtime
+	ingressDomainKey  = "domain"
 )
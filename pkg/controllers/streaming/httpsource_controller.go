@@ -0,0 +1,589 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/projectriff/system/pkg/apis"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/controllers"
+	"github.com/projectriff/system/pkg/tracker"
+)
+
+const (
+	httpSourceDeploymentIndexField = ".metadata.httpSourceDeploymentController"
+	httpSourceServiceIndexField    = ".metadata.httpSourceServiceController"
+	httpSourceIngressIndexField    = ".metadata.httpSourceIngressController"
+
+	httpSourcePort = 8080
+)
+
+// HTTPSourceReconciler reconciles a HTTPSource object
+type HTTPSourceReconciler struct {
+	client.Client
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Tracker   tracker.Tracker
+	Namespace string
+}
+
+// For
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=httpsources,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=httpsources/status,verbs=get;update;patch
+// Owns
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// Watches
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=streams,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+
+func (r *HTTPSourceReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("httpsource", req.NamespacedName)
+
+	var original streamingv1alpha1.HTTPSource
+	if err := r.Client.Get(ctx, req.NamespacedName, &original); err != nil {
+		return ctrl.Result{}, ignoreNotFound(err)
+	}
+
+	// Don't modify the informers copy
+	httpSource := original.DeepCopy()
+
+	// Reconcile this copy of the source and then write back any status
+	// updates regardless of whether the reconciliation errored out.
+	result, err := r.reconcile(ctx, log, httpSource)
+
+	// check if status has changed before updating, unless requeued
+	if !result.Requeue && !equality.Semantic.DeepEqual(httpSource.Status, original.Status) {
+		// update status
+		log.Info("updating http httpSource status", "diff", cmp.Diff(original.Status, httpSource.Status))
+		if updateErr := r.Status().Update(ctx, httpSource); updateErr != nil {
+			log.Error(updateErr, "unable to update HTTPSource status", "httpsource", httpSource)
+			return ctrl.Result{Requeue: true}, updateErr
+		}
+	}
+	return result, err
+}
+
+func (r *HTTPSourceReconciler) reconcile(ctx context.Context, log logr.Logger, httpSource *streamingv1alpha1.HTTPSource) (ctrl.Result, error) {
+	if httpSource.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	// We may be reading a version of the object that was stored at an older version
+	// and may not have had all of the assumed defaults specified.  This won't result
+	// in this getting written back to the API Server, but lets downstream logic make
+	// assumptions about defaulting.
+	httpSource.Default()
+
+	httpSource.Status.InitializeConditions()
+
+	// resolve the image for the http endpoint
+	image, err := r.resolveImage(ctx, httpSource)
+	if err != nil {
+		log.Error(err, "unable to resolve image for HTTPSource", "httpsource", httpSource)
+		return ctrl.Result{}, err
+	}
+
+	// resolve the stream to publish to
+	stream, err := r.resolveStream(ctx, httpSource)
+	if err != nil {
+		log.Error(err, "unable to resolve stream for HTTPSource", "httpsource", httpSource)
+		return ctrl.Result{}, err
+	}
+	if stream == nil {
+		return ctrl.Result{}, nil
+	}
+
+	// reconcile deployment
+	childDeployment, err := r.reconcileChildDeployment(ctx, log, httpSource, stream, image)
+	if err != nil {
+		log.Error(err, "unable to reconcile child Deployment", "httpsource", httpSource)
+		return ctrl.Result{}, err
+	}
+	httpSource.Status.DeploymentName = childDeployment.Name
+	httpSource.Status.PropagateDeploymentStatus(&childDeployment.Status)
+
+	// reconcile service
+	childService, err := r.reconcileChildService(ctx, log, httpSource)
+	if err != nil {
+		log.Error(err, "unable to reconcile child Service", "httpsource", httpSource)
+		return ctrl.Result{}, err
+	}
+	httpSource.Status.ServiceName = childService.Name
+	httpSource.Status.Address = &apis.Addressable{URL: fmt.Sprintf("http://%s.%s.%s", childService.Name, childService.Namespace, "svc.cluster.local")}
+	httpSource.Status.PropagateServiceStatus(&childService.Status)
+
+	// resolve ingress domain
+	domain, err := r.resolveIngressDomain(ctx, httpSource)
+	if err != nil {
+		log.Error(err, "unable to resolve ingress domain", "httpsource", httpSource)
+		return ctrl.Result{}, err
+	}
+
+	// reconcile ingress
+	childIngress, err := r.reconcileChildIngress(ctx, log, httpSource, domain)
+	if err != nil {
+		log.Error(err, "unable to reconcile child Ingress", "httpsource", httpSource)
+		return ctrl.Result{}, err
+	}
+	if childIngress == nil {
+		httpSource.Status.IngressName = ""
+		httpSource.Status.URL = ""
+		if httpSource.Spec.IngressPolicy == streamingv1alpha1.IngressPolicyClusterLocal {
+			httpSource.Status.MarkIngressNotRequired()
+		} else {
+			httpSource.Status.MarkIngressDomainMissing(fmt.Sprintf("the %q key of ConfigMap %q in namespace %q must define the domain for ingress hosts", ingressDomainKey, coreIngressConfig, r.Namespace))
+		}
+	} else {
+		httpSource.Status.IngressName = childIngress.Name
+		httpSource.Status.URL = controllers.IngressURL(childIngress)
+		httpSource.Status.PropagateIngressStatus(&childIngress.Status)
+	}
+
+	httpSource.Status.ObservedGeneration = httpSource.Generation
+	return ctrl.Result{}, nil
+}
+
+func (r *HTTPSourceReconciler) resolveImage(ctx context.Context, httpSource *streamingv1alpha1.HTTPSource) (string, error) {
+	var config corev1.ConfigMap
+	key := types.NamespacedName{Namespace: r.Namespace, Name: httpSourceImages}
+	// track config map for new images
+	r.Tracker.Track(
+		tracker.NewKey(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, key),
+		namespacedNamedFor(httpSource),
+	)
+	if err := r.Get(ctx, key, &config); err != nil {
+		return "", err
+	}
+	image := config.Data[httpSourceImageKey]
+	if image == "" {
+		return "", fmt.Errorf("missing http source image configuration")
+	}
+	return image, nil
+}
+
+// resolveStream returns the stream to publish to, or nil when the stream is
+// not yet able to accept messages.
+func (r *HTTPSourceReconciler) resolveStream(ctx context.Context, httpSource *streamingv1alpha1.HTTPSource) (*streamingv1alpha1.Stream, error) {
+	var stream streamingv1alpha1.Stream
+	key := types.NamespacedName{Namespace: httpSource.Namespace, Name: httpSource.Spec.Stream}
+	// track stream for new coordinates
+	r.Tracker.Track(
+		tracker.NewKey(stream.GetGroupVersionKind(), key),
+		namespacedNamedFor(httpSource),
+	)
+	if err := r.Get(ctx, key, &stream); err != nil {
+		if apierrs.IsNotFound(err) {
+			httpSource.Status.MarkStreamNotReady(fmt.Sprintf("stream %q not found", httpSource.Spec.Stream))
+			return nil, nil
+		}
+		return nil, err
+	}
+	ready := stream.Status.GetCondition(stream.Status.GetReadyConditionType())
+	if ready == nil {
+		ready = &apis.Condition{Message: "stream has no ready condition"}
+	}
+	if !ready.IsTrue() || stream.Status.Address.Gateway == "" {
+		httpSource.Status.MarkStreamNotReady(fmt.Sprintf("stream %q is not ready: %s", stream.Name, ready.Message))
+		return nil, nil
+	}
+	httpSource.Status.MarkStreamReady()
+	return &stream, nil
+}
+
+func (r *HTTPSourceReconciler) resolveIngressDomain(ctx context.Context, httpSource *streamingv1alpha1.HTTPSource) (string, error) {
+	var config corev1.ConfigMap
+	key := types.NamespacedName{Namespace: r.Namespace, Name: coreIngressConfig}
+	// track config map for domain changes
+	r.Tracker.Track(
+		tracker.NewKey(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, key),
+		namespacedNamedFor(httpSource),
+	)
+	if err := r.Get(ctx, key, &config); err != nil {
+		if apierrs.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return config.Data[ingressDomainKey], nil
+}
+
+func (r *HTTPSourceReconciler) reconcileChildDeployment(ctx context.Context, log logr.Logger, httpSource *streamingv1alpha1.HTTPSource, stream *streamingv1alpha1.Stream, image string) (*appsv1.Deployment, error) {
+	var actualDeployment appsv1.Deployment
+	var childDeployments appsv1.DeploymentList
+	if err := r.List(ctx, &childDeployments, client.InNamespace(httpSource.Namespace), client.MatchingField(httpSourceDeploymentIndexField, httpSource.Name)); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childDeployments.Items) == 1 {
+		actualDeployment = childDeployments.Items[0]
+	} else if len(childDeployments.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraDeployment := range childDeployments.Items {
+			log.Info("deleting extra deployment", "deployment", extraDeployment)
+			if err := r.Delete(ctx, &extraDeployment); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	desiredDeployment, err := r.constructDeploymentForHTTPSource(httpSource, stream, image)
+	if err != nil {
+		return nil, err
+	}
+
+	// create deployment if it doesn't exist
+	if actualDeployment.Name == "" {
+		log.Info("creating deployment", "spec", desiredDeployment.Spec)
+		if err := r.Create(ctx, desiredDeployment); err != nil {
+			log.Error(err, "unable to create Deployment for HTTPSource", "deployment", desiredDeployment)
+			return nil, err
+		}
+		return desiredDeployment, nil
+	}
+
+	// overwrite fields that should not be mutated
+	desiredDeployment.Spec.Replicas = actualDeployment.Spec.Replicas
+
+	if r.deploymentSemanticEquals(desiredDeployment, &actualDeployment) {
+		// deployment is unchanged
+		return &actualDeployment, nil
+	}
+
+	// update deployment with desired changes
+	deployment := actualDeployment.DeepCopy()
+	deployment.ObjectMeta.Labels = desiredDeployment.ObjectMeta.Labels
+	deployment.Spec = desiredDeployment.Spec
+	log.Info("reconciling deployment", "diff", cmp.Diff(actualDeployment.Spec, deployment.Spec))
+	if err := r.Update(ctx, deployment); err != nil {
+		log.Error(err, "unable to update Deployment for HTTPSource", "deployment", deployment)
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+func (r *HTTPSourceReconciler) deploymentSemanticEquals(desiredDeployment, deployment *appsv1.Deployment) bool {
+	return equality.Semantic.DeepEqual(desiredDeployment.Spec, deployment.Spec) &&
+		equality.Semantic.DeepEqual(desiredDeployment.ObjectMeta.Labels, deployment.ObjectMeta.Labels)
+}
+
+func (r *HTTPSourceReconciler) constructDeploymentForHTTPSource(httpSource *streamingv1alpha1.HTTPSource, stream *streamingv1alpha1.Stream, image string) (*appsv1.Deployment, error) {
+	labels := r.constructLabelsForHTTPSource(httpSource)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       labels,
+			GenerateName: fmt.Sprintf("%s-http-source-", httpSource.Name),
+			Namespace:    httpSource.Namespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					streamingv1alpha1.HTTPSourceLabelKey: httpSource.Name,
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "http-source",
+							Image: image,
+							Ports: []corev1.ContainerPort{
+								{Name: "http", ContainerPort: httpSourcePort, Protocol: corev1.ProtocolTCP},
+							},
+							Env: []corev1.EnvVar{
+								{Name: "PORT", Value: fmt.Sprintf("%d", httpSourcePort)},
+								{Name: "OUTPUT", Value: stream.Status.Address.String()},
+								{Name: "OUTPUT_CONTENT_TYPE", Value: stream.Spec.ContentType},
+							},
+							ReadinessProbe: &corev1.Probe{
+								Handler: corev1.Handler{
+									TCPSocket: &corev1.TCPSocketAction{
+										Port: intstr.FromInt(httpSourcePort),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(httpSource, deployment, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+func (r *HTTPSourceReconciler) reconcileChildService(ctx context.Context, log logr.Logger, httpSource *streamingv1alpha1.HTTPSource) (*corev1.Service, error) {
+	var actualService corev1.Service
+	var childServices corev1.ServiceList
+	if err := r.List(ctx, &childServices, client.InNamespace(httpSource.Namespace), client.MatchingField(httpSourceServiceIndexField, httpSource.Name)); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childServices.Items) == 1 {
+		actualService = childServices.Items[0]
+	} else if len(childServices.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraService := range childServices.Items {
+			log.Info("deleting extra service", "service", extraService)
+			if err := r.Delete(ctx, &extraService); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	desiredService, err := r.constructServiceForHTTPSource(httpSource)
+	if err != nil {
+		return nil, err
+	}
+
+	// create service if it doesn't exist
+	if actualService.Name == "" {
+		log.Info("creating service", "spec", desiredService.Spec)
+		if err := r.Create(ctx, desiredService); err != nil {
+			log.Error(err, "unable to create Service for HTTPSource", "service", desiredService)
+			return nil, err
+		}
+		return desiredService, nil
+	}
+
+	// overwrite fields that should not be mutated
+	desiredService.Spec.ClusterIP = actualService.Spec.ClusterIP
+
+	if r.serviceSemanticEquals(desiredService, &actualService) {
+		// service is unchanged
+		return &actualService, nil
+	}
+
+	// update service with desired changes
+	service := actualService.DeepCopy()
+	service.ObjectMeta.Labels = desiredService.ObjectMeta.Labels
+	service.Spec = desiredService.Spec
+	log.Info("reconciling service", "diff", cmp.Diff(actualService.Spec, service.Spec))
+	if err := r.Update(ctx, service); err != nil {
+		log.Error(err, "unable to update Service for HTTPSource", "service", service)
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func (r *HTTPSourceReconciler) serviceSemanticEquals(desiredService, service *corev1.Service) bool {
+	return equality.Semantic.DeepEqual(desiredService.Spec, service.Spec) &&
+		equality.Semantic.DeepEqual(desiredService.ObjectMeta.Labels, service.ObjectMeta.Labels)
+}
+
+func (r *HTTPSourceReconciler) constructServiceForHTTPSource(httpSource *streamingv1alpha1.HTTPSource) (*corev1.Service, error) {
+	labels := r.constructLabelsForHTTPSource(httpSource)
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       labels,
+			GenerateName: fmt.Sprintf("%s-http-source-", httpSource.Name),
+			Namespace:    httpSource.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromInt(httpSourcePort)},
+			},
+			Selector: map[string]string{
+				streamingv1alpha1.HTTPSourceLabelKey: httpSource.Name,
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(httpSource, service, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func (r *HTTPSourceReconciler) reconcileChildIngress(ctx context.Context, log logr.Logger, httpSource *streamingv1alpha1.HTTPSource, domain string) (*networkingv1beta1.Ingress, error) {
+	var actualIngress networkingv1beta1.Ingress
+	var childIngresses networkingv1beta1.IngressList
+	if err := r.List(ctx, &childIngresses, client.InNamespace(httpSource.Namespace), client.MatchingField(httpSourceIngressIndexField, httpSource.Name)); err != nil {
+		return nil, err
+	}
+	if len(childIngresses.Items) == 1 {
+		actualIngress = childIngresses.Items[0]
+	} else if len(childIngresses.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraIngress := range childIngresses.Items {
+			log.Info("deleting extra ingress", "ingress", extraIngress)
+			if err := r.Delete(ctx, &extraIngress); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	desiredIngress, err := r.constructIngressForHTTPSource(httpSource, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	// delete ingress if no longer needed
+	if desiredIngress == nil {
+		if actualIngress.Name == "" {
+			return nil, nil
+		}
+		log.Info("deleting ingress", "ingress", actualIngress)
+		if err := r.Delete(ctx, &actualIngress); err != nil {
+			log.Error(err, "unable to delete Ingress for HTTPSource", "ingress", actualIngress)
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// create ingress if it doesn't exist
+	if actualIngress.Name == "" {
+		log.Info("creating ingress", "spec", desiredIngress.Spec)
+		if err := r.Create(ctx, desiredIngress); err != nil {
+			log.Error(err, "unable to create Ingress for HTTPSource", "ingress", desiredIngress)
+			return nil, err
+		}
+		return desiredIngress, nil
+	}
+
+	if r.ingressSemanticEquals(desiredIngress, &actualIngress) {
+		// ingress is unchanged
+		return &actualIngress, nil
+	}
+
+	// update ingress with desired changes
+	ingress := actualIngress.DeepCopy()
+	ingress.ObjectMeta.Labels = desiredIngress.ObjectMeta.Labels
+	ingress.ObjectMeta.Annotations = desiredIngress.ObjectMeta.Annotations
+	ingress.Spec = desiredIngress.Spec
+	log.Info("reconciling ingress", "diff", cmp.Diff(actualIngress.Spec, ingress.Spec))
+	if err := r.Update(ctx, ingress); err != nil {
+		log.Error(err, "unable to update Ingress for HTTPSource", "ingress", ingress)
+		return nil, err
+	}
+
+	return ingress, nil
+}
+
+func (r *HTTPSourceReconciler) ingressSemanticEquals(desiredIngress, ingress *networkingv1beta1.Ingress) bool {
+	return equality.Semantic.DeepEqual(desiredIngress.Spec, ingress.Spec) &&
+		equality.Semantic.DeepEqual(desiredIngress.ObjectMeta.Labels, ingress.ObjectMeta.Labels) &&
+		equality.Semantic.DeepEqual(desiredIngress.ObjectMeta.Annotations, ingress.ObjectMeta.Annotations)
+}
+
+func (r *HTTPSourceReconciler) constructIngressForHTTPSource(httpSource *streamingv1alpha1.HTTPSource, domain string) (*networkingv1beta1.Ingress, error) {
+	if httpSource.Status.ServiceName == "" || httpSource.Spec.IngressPolicy == streamingv1alpha1.IngressPolicyClusterLocal {
+		// skip ingress
+		return nil, nil
+	}
+	spec, annotations := controllers.ConstructIngressSpec(httpSource.Spec.Ingress, httpSource.Status.ServiceName, httpSource.Namespace, domain, fmt.Sprintf("%s-http-source-tls", httpSource.Name))
+	if spec == nil {
+		// skip ingress until a domain is configured
+		return nil, nil
+	}
+
+	ingress := &networkingv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       r.constructLabelsForHTTPSource(httpSource),
+			Annotations:  annotations,
+			GenerateName: fmt.Sprintf("%s-http-source-", httpSource.Name),
+			Namespace:    httpSource.Namespace,
+		},
+		Spec: *spec,
+	}
+	if err := ctrl.SetControllerReference(httpSource, ingress, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return ingress, nil
+}
+
+func (r *HTTPSourceReconciler) constructLabelsForHTTPSource(httpSource *streamingv1alpha1.HTTPSource) map[string]string {
+	labels := make(map[string]string, len(httpSource.ObjectMeta.Labels)+1)
+	// pass through existing labels
+	for k, v := range httpSource.ObjectMeta.Labels {
+		labels[k] = v
+	}
+
+	labels[streamingv1alpha1.HTTPSourceLabelKey] = httpSource.Name
+	return labels
+}
+
+func (r *HTTPSourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	enqueueTrackedResources := func(t apis.Resource) handler.EventHandler {
+		return &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+				requests := []reconcile.Request{}
+				key := tracker.NewKey(
+					t.GetGroupVersionKind(),
+					types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: a.Meta.GetName()},
+				)
+				for _, item := range r.Tracker.Lookup(key) {
+					requests = append(requests, reconcile.Request{NamespacedName: item})
+				}
+				return requests
+			}),
+		}
+	}
+
+	if err := controllers.IndexControllersOfType(mgr, httpSourceDeploymentIndexField, &streamingv1alpha1.HTTPSource{}, &appsv1.Deployment{}); err != nil {
+		return err
+	}
+	if err := controllers.IndexControllersOfType(mgr, httpSourceServiceIndexField, &streamingv1alpha1.HTTPSource{}, &corev1.Service{}); err != nil {
+		return err
+	}
+	if err := controllers.IndexControllersOfType(mgr, httpSourceIngressIndexField, &streamingv1alpha1.HTTPSource{}, &networkingv1beta1.Ingress{}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&streamingv1alpha1.HTTPSource{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&corev1.Service{}).
+		Owns(&networkingv1beta1.Ingress{}).
+		Watches(&source.Kind{Type: &streamingv1alpha1.Stream{}}, enqueueTrackedResources(&streamingv1alpha1.Stream{})).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueTrackedConfigMap(r.Tracker, r.Namespace, httpSourceImages)).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueTrackedConfigMap(r.Tracker, r.Namespace, coreIngressConfig)).
+		Complete(r)
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+)
+
+func TestHTTPSourceReconcile(t *testing.T) {
+	images := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testSystemNamespace, Name: httpSourceImages},
+		Data:       map[string]string{httpSourceImageKey: "http-source-image"},
+	}
+	ingressConfig := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testSystemNamespace, Name: coreIngressConfig},
+		Data:       map[string]string{ingressDomainKey: "example.com"},
+	}
+	newSource := func(policy streamingv1alpha1.IngressPolicy, ingress *corev1alpha1.Ingress) *streamingv1alpha1.HTTPSource {
+		return &streamingv1alpha1.HTTPSource{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "my-source"},
+			Spec: streamingv1alpha1.HTTPSourceSpec{
+				Stream:        "numbers",
+				IngressPolicy: policy,
+				Ingress:       ingress,
+			},
+		}
+	}
+	unreadyStream := readyStream("numbers")
+	unreadyStream.Status.MarkStreamProvisionFailed("topic quota exceeded")
+	staleIngress := &networkingv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "my-source-http-source-stale"},
+	}
+
+	tests := []struct {
+		name              string
+		source            *streamingv1alpha1.HTTPSource
+		objects           []runtime.Object
+		wantStreamReady   corev1.ConditionStatus
+		wantStreamMessage string
+		wantDeployment    bool
+		wantIngressHost   string
+		wantIngressReady  corev1.ConditionStatus
+		wantIngressReason string
+	}{{
+		name:              "waits for a missing stream",
+		source:            newSource("", nil),
+		objects:           []runtime.Object{images},
+		wantStreamReady:   corev1.ConditionFalse,
+		wantStreamMessage: `stream "numbers" not found`,
+		wantIngressReady:  corev1.ConditionUnknown,
+	}, {
+		name:              "waits for the stream to be ready",
+		source:            newSource("", nil),
+		objects:           []runtime.Object{images, unreadyStream},
+		wantStreamReady:   corev1.ConditionFalse,
+		wantStreamMessage: `stream "numbers" is not ready: topic quota exceeded`,
+		wantIngressReady:  corev1.ConditionUnknown,
+	}, {
+		name:              "publishes to the stream from within the cluster",
+		source:            newSource("", nil),
+		objects:           []runtime.Object{images, readyStream("numbers"), ingressConfig},
+		wantStreamReady:   corev1.ConditionTrue,
+		wantDeployment:    true,
+		wantIngressReady:  corev1.ConditionFalse,
+		wantIngressReason: "IngressNotRequired",
+	}, {
+		name:              "exposes external sources under the ingress domain",
+		source:            newSource(streamingv1alpha1.IngressPolicyExternal, nil),
+		objects:           []runtime.Object{images, readyStream("numbers"), ingressConfig},
+		wantStreamReady:   corev1.ConditionTrue,
+		wantDeployment:    true,
+		wantIngressHost:   "my-source-http-source-00002.test-namespace.example.com",
+		wantIngressReady:  corev1.ConditionUnknown,
+		wantIngressReason: "IngressNotConfigured",
+	}, {
+		name:              "exposes external sources on a custom host",
+		source:            newSource(streamingv1alpha1.IngressPolicyExternal, &corev1alpha1.Ingress{Host: "numbers.example.org"}),
+		objects:           []runtime.Object{images, readyStream("numbers")},
+		wantStreamReady:   corev1.ConditionTrue,
+		wantDeployment:    true,
+		wantIngressHost:   "numbers.example.org",
+		wantIngressReady:  corev1.ConditionUnknown,
+		wantIngressReason: "IngressNotConfigured",
+	}, {
+		name:              "reports a missing ingress domain",
+		source:            newSource(streamingv1alpha1.IngressPolicyExternal, nil),
+		objects:           []runtime.Object{images, readyStream("numbers")},
+		wantStreamReady:   corev1.ConditionTrue,
+		wantDeployment:    true,
+		wantIngressReady:  corev1.ConditionFalse,
+		wantIngressReason: "DomainMissing",
+	}, {
+		name:              "deletes the ingress of sources no longer external",
+		source:            newSource(streamingv1alpha1.IngressPolicyClusterLocal, nil),
+		objects:           []runtime.Object{images, readyStream("numbers"), ingressConfig, staleIngress},
+		wantStreamReady:   corev1.ConditionTrue,
+		wantDeployment:    true,
+		wantIngressReady:  corev1.ConditionFalse,
+		wantIngressReason: "IngressNotRequired",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := newFakeClient(append(test.objects, test.source)...)
+			r := &HTTPSourceReconciler{
+				Client:    c,
+				Log:       zap.Logger(true),
+				Scheme:    scheme.Scheme,
+				Tracker:   newTestTracker(),
+				Namespace: testSystemNamespace,
+			}
+			key := types.NamespacedName{Namespace: testNamespace, Name: "my-source"}
+			if _, err := r.Reconcile(ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() unexpected error: %v", err)
+			}
+
+			var source streamingv1alpha1.HTTPSource
+			if err := c.Get(ctx, key, &source); err != nil {
+				t.Fatalf("unable to get source: %v", err)
+			}
+			streamReady := source.Status.GetCondition(streamingv1alpha1.HTTPSourceConditionStreamReady)
+			if streamReady.Status != test.wantStreamReady || streamReady.Message != test.wantStreamMessage {
+				t.Errorf("StreamReady = %s %q, want %s %q", streamReady.Status, streamReady.Message, test.wantStreamReady, test.wantStreamMessage)
+			}
+
+			var deployments appsv1.DeploymentList
+			if err := c.List(ctx, &deployments, client.InNamespace(testNamespace)); err != nil {
+				t.Fatalf("unable to list deployments: %v", err)
+			}
+			if !test.wantDeployment {
+				if len(deployments.Items) != 0 {
+					t.Errorf("found %d deployments, want none", len(deployments.Items))
+				}
+				return
+			}
+			if len(deployments.Items) != 1 {
+				t.Fatalf("found %d deployments, want 1", len(deployments.Items))
+			}
+			container := deployments.Items[0].Spec.Template.Spec.Containers[0]
+			if container.Image != "http-source-image" {
+				t.Errorf("image = %q, want %q", container.Image, "http-source-image")
+			}
+			if output := findEnv(container.Env, "OUTPUT"); output == nil || output.Value != "memory-gateway:6565/test-namespace_numbers" {
+				t.Errorf("OUTPUT = %v, want %q", output, "memory-gateway:6565/test-namespace_numbers")
+			}
+			if contentType := findEnv(container.Env, "OUTPUT_CONTENT_TYPE"); contentType == nil || contentType.Value != "text/plain" {
+				t.Errorf("OUTPUT_CONTENT_TYPE = %v, want %q", contentType, "text/plain")
+			}
+			if source.Status.Address == nil || source.Status.Address.URL != "http://"+source.Status.ServiceName+".test-namespace.svc.cluster.local" {
+				t.Errorf("address = %v, want the url of service %q", source.Status.Address, source.Status.ServiceName)
+			}
+
+			var ingresses networkingv1beta1.IngressList
+			if err := c.List(ctx, &ingresses, client.InNamespace(testNamespace)); err != nil {
+				t.Fatalf("unable to list ingresses: %v", err)
+			}
+			if test.wantIngressHost == "" {
+				if len(ingresses.Items) != 0 || source.Status.URL != "" {
+					t.Errorf("found %d ingresses and url %q, want none", len(ingresses.Items), source.Status.URL)
+				}
+			} else {
+				if len(ingresses.Items) != 1 {
+					t.Fatalf("found %d ingresses, want 1", len(ingresses.Items))
+				}
+				if host := ingresses.Items[0].Spec.Rules[0].Host; host != test.wantIngressHost {
+					t.Errorf("ingress host = %q, want %q", host, test.wantIngressHost)
+				}
+				if source.Status.URL != "http://"+test.wantIngressHost {
+					t.Errorf("url = %q, want %q", source.Status.URL, "http://"+test.wantIngressHost)
+				}
+			}
+			ingressReady := source.Status.GetCondition(streamingv1alpha1.HTTPSourceConditionIngressReady)
+			if ingressReady.Status != test.wantIngressReady || ingressReady.Reason != test.wantIngressReason {
+				t.Errorf("IngressReady = %s %s, want %s %s", ingressReady.Status, ingressReady.Reason, test.wantIngressReady, test.wantIngressReason)
+			}
+		})
+	}
+}
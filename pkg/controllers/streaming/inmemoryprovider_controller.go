@@ -0,0 +1,130 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/tracker"
+)
+
+const (
+	inMemoryProviderDeploymentIndexField = ".metadata.inMemoryProviderDeploymentController"
+	inMemoryProviderServiceIndexField    = ".metadata.inMemoryProviderServiceController"
+	// inMemoryStoragePath is where the gateway mounts its disk
+	inMemoryStoragePath = "/var/riff/gateway"
+)
+
+// InMemoryProviderReconciler reconciles a InMemoryProvider object
+type InMemoryProviderReconciler struct {
+	client.Client
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Tracker   tracker.Tracker
+	Namespace string
+}
+
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=inmemoryproviders,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=inmemoryproviders/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+
+func (r *InMemoryProviderReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	var inMemoryProvider streamingv1alpha1.InMemoryProvider
+	log := r.Log.WithValues("inmemoryprovider", req.NamespacedName)
+	return reconcileProvider(r.Client, log, req, &inMemoryProvider, &inMemoryProvider.Status.ProviderStatus, func(ctx context.Context, log logr.Logger) (ctrl.Result, error) {
+		return r.reconcile(ctx, log, &inMemoryProvider)
+	})
+}
+
+func (r *InMemoryProviderReconciler) reconcile(ctx context.Context, log logr.Logger, inMemoryProvider *streamingv1alpha1.InMemoryProvider) (ctrl.Result, error) {
+
+	// Lookup and track configMap to know which images to use
+	gatewayImg, provisionerImg, err := providerImages(ctx, r.Client, r.Tracker, r.Namespace, inMemoryProviderImages, namespacedNamedFor(inMemoryProvider))
+	if err != nil {
+		log.Error(err, "unable to lookup images configMap")
+		return ctrl.Result{}, err
+	}
+
+	template := r.providerTemplateForInMemoryProvider(inMemoryProvider, gatewayImg, provisionerImg)
+	if err := reconcileProviderChildren(ctx, log, r.Client, r.Scheme, inMemoryProvider, &inMemoryProvider.Status.ProviderStatus, template); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *InMemoryProviderReconciler) providerTemplateForInMemoryProvider(inMemoryProvider *streamingv1alpha1.InMemoryProvider, gatewayImg, provisionerImg string) providerTemplate {
+	template := providerTemplate{
+		kind:                 "inmemory",
+		labelKey:             streamingv1alpha1.InMemoryProviderLabelKey,
+		gatewayLabelKey:      streamingv1alpha1.InMemoryProviderGatewayLabelKey,
+		provisionerLabelKey:  streamingv1alpha1.InMemoryProviderProvisionerLabelKey,
+		provisioner:          streamingv1alpha1.InMemoryProvisioner,
+		deploymentIndexField: inMemoryProviderDeploymentIndexField,
+		serviceIndexField:    inMemoryProviderServiceIndexField,
+
+		// the gateway keeps consumer positions along with the records
+		gatewayImage: gatewayImg,
+
+		// the gateway creates topics on first use, the provisioner only needs
+		// to know where the gateway is
+		provisionerImage: provisionerImg,
+	}
+	if storage := inMemoryProvider.Spec.Storage; storage != nil && storage.Type == streamingv1alpha1.InMemoryStorageTypeDisk && storage.Disk != nil {
+		template.gatewayEnv = []corev1.EnvVar{
+			{Name: "STORAGE_PATH", Value: inMemoryStoragePath},
+		}
+		template.gatewayVolumes = []corev1.Volume{{
+			Name: "storage",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: storage.Disk.ClaimName,
+				},
+			},
+		}}
+		template.gatewayVolumeMounts = []corev1.VolumeMount{
+			{Name: "storage", MountPath: inMemoryStoragePath},
+		}
+		// the old gateway releases the volume before the new one starts
+		template.gatewayStrategy = appsv1.DeploymentStrategy{
+			Type: appsv1.RecreateDeploymentStrategyType,
+		}
+	}
+	return template
+}
+
+func (r *InMemoryProviderReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	builder, err := newProviderControllerManagedBy(mgr, &streamingv1alpha1.InMemoryProvider{}, inMemoryProviderDeploymentIndexField, inMemoryProviderServiceIndexField)
+	if err != nil {
+		return err
+	}
+
+	return builder.
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueTrackedConfigMap(r.Tracker, r.Namespace, inMemoryProviderImages)).
+		Complete(r)
+}
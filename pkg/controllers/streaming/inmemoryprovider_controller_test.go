@@ -0,0 +1,129 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+)
+
+func TestInMemoryProviderReconcile(t *testing.T) {
+	images := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testSystemNamespace, Name: inMemoryProviderImages},
+		Data: map[string]string{
+			gatewayImageKey:     "gateway-image",
+			provisionerImageKey: "provisioner-image",
+		},
+	}
+
+	tests := []struct {
+		name             string
+		spec             streamingv1alpha1.InMemoryProviderSpec
+		wantEnv          []corev1.EnvVar
+		wantVolumes      []corev1.Volume
+		wantVolumeMounts []corev1.VolumeMount
+		wantStrategy     appsv1.DeploymentStrategy
+	}{{
+		name: "memory",
+		spec: streamingv1alpha1.InMemoryProviderSpec{
+			Storage: &streamingv1alpha1.InMemoryStorage{Type: streamingv1alpha1.InMemoryStorageTypeMemory},
+		},
+	}, {
+		name: "disk",
+		spec: streamingv1alpha1.InMemoryProviderSpec{
+			Storage: &streamingv1alpha1.InMemoryStorage{
+				Type: streamingv1alpha1.InMemoryStorageTypeDisk,
+				Disk: &streamingv1alpha1.InMemoryDiskStorage{ClaimName: "streams"},
+			},
+		},
+		wantEnv: []corev1.EnvVar{
+			{Name: "STORAGE_PATH", Value: "/var/riff/gateway"},
+		},
+		wantVolumes: []corev1.Volume{{
+			Name: "storage",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "streams"},
+			},
+		}},
+		wantVolumeMounts: []corev1.VolumeMount{
+			{Name: "storage", MountPath: "/var/riff/gateway"},
+		},
+		wantStrategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider := &streamingv1alpha1.InMemoryProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "my-provider"},
+				Spec:       test.spec,
+			}
+			c := newFakeClient(images, provider)
+			r := &InMemoryProviderReconciler{
+				Client:    c,
+				Log:       zap.Logger(true),
+				Scheme:    scheme.Scheme,
+				Tracker:   newTestTracker(),
+				Namespace: testSystemNamespace,
+			}
+			key := types.NamespacedName{Namespace: testNamespace, Name: "my-provider"}
+			if _, err := r.Reconcile(ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() unexpected error: %v", err)
+			}
+
+			var deployments appsv1.DeploymentList
+			if err := c.List(context.Background(), &deployments, client.InNamespace(testNamespace),
+				client.MatchingLabels{streamingv1alpha1.InMemoryProviderGatewayLabelKey: "my-provider"}); err != nil {
+				t.Fatalf("List() unexpected error: %v", err)
+			}
+			if len(deployments.Items) != 1 {
+				t.Fatalf("got %d gateway deployments, want 1", len(deployments.Items))
+			}
+			gateway := deployments.Items[0].Spec
+			if diff := cmp.Diff(test.wantEnv, gateway.Template.Spec.Containers[0].Env); diff != "" {
+				t.Errorf("gateway env (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.wantVolumes, gateway.Template.Spec.Volumes); diff != "" {
+				t.Errorf("gateway volumes (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.wantVolumeMounts, gateway.Template.Spec.Containers[0].VolumeMounts); diff != "" {
+				t.Errorf("gateway volume mounts (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.wantStrategy, gateway.Strategy); diff != "" {
+				t.Errorf("gateway strategy (-want, +got) = %v", diff)
+			}
+
+			_, provisionerEnv := providerChildrenEnv(t, c, streamingv1alpha1.InMemoryProviderGatewayLabelKey, streamingv1alpha1.InMemoryProviderProvisionerLabelKey)
+			if diff := cmp.Diff([]corev1.EnvVar{
+				{Name: "GATEWAY", Value: "my-provider-inmemory-gateway-00002.test-namespace:6565"},
+			}, provisionerEnv); diff != "" {
+				t.Errorf("provisioner env (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
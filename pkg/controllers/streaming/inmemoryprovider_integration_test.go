@@ -0,0 +1,142 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/gateway"
+)
+
+var _ = Describe("InMemoryProvider", func() {
+	ctx := context.Background()
+
+	It("carries messages of streams bound to processors", func() {
+		provider := &streamingv1alpha1.InMemoryProvider{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "memory"},
+		}
+		provider.Default()
+		Expect(k8sClient.Create(ctx, provider)).To(Succeed())
+
+		By("making the gateway and provisioner available")
+		eventually(func() int {
+			return markDeploymentsAvailable(ctx, streamingv1alpha1.InMemoryProviderLabelKey, "memory")
+		}).Should(Equal(2))
+		eventually(func() bool {
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: "memory"}, provider)).To(Succeed())
+			return provider.Status.IsReady()
+		}).Should(BeTrue())
+
+		By("provisioning a stream")
+		stream := &streamingv1alpha1.Stream{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "numbers"},
+			Spec: streamingv1alpha1.StreamSpec{
+				Provider:    streamingv1alpha1.StreamProviderReference{Kind: streamingv1alpha1.InMemoryProviderKind, Name: "memory"},
+				ContentType: "text/plain",
+			},
+		}
+		stream.Default()
+		Expect(k8sClient.Create(ctx, stream)).To(Succeed())
+		eventually(func() bool {
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: "numbers"}, stream)).To(Succeed())
+			return stream.Status.IsReady()
+		}).Should(BeTrue())
+		Expect(stream.Status.Address.Gateway).To(Equal(gatewayAddress))
+		var secret corev1.Secret
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: stream.Status.Binding.SecretRef.Name}, &secret)).To(Succeed())
+
+		By("publishing to the stream")
+		gatewayClient, err := gateway.NewClient(stream.Status.Address.Gateway)
+		Expect(err).ToNot(HaveOccurred())
+		defer gatewayClient.Close()
+		Expect(gatewayClient.Publish(ctx, stream.Status.Address.Topic, nil, []byte("1"))).To(Succeed())
+		assignments, err := gatewayClient.Subscribe(ctx, stream.Status.Address.Topic, "test", gateway.OffsetResetEarliest)
+		Expect(err).ToNot(HaveOccurred())
+		defer assignments.Close()
+		assignment, err := assignments.Next()
+		Expect(err).ToNot(HaveOccurred())
+		records, err := gatewayClient.Receive(ctx, assignment)
+		Expect(err).ToNot(HaveOccurred())
+		defer records.Close()
+		record, err := records.Next()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(record.Value)).To(Equal("1"))
+
+		By("binding a processor to the stream")
+		processor := &streamingv1alpha1.Processor{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "square"},
+			Spec: streamingv1alpha1.ProcessorSpec{
+				Inputs: []streamingv1alpha1.StreamBinding{{Stream: "numbers"}},
+				Template: &corev1.PodSpec{
+					Containers: []corev1.Container{{Image: "square"}},
+				},
+			},
+		}
+		processor.Default()
+		Expect(k8sClient.Create(ctx, processor)).To(Succeed())
+		var deployments appsv1.DeploymentList
+		eventually(func() int {
+			Expect(k8sClient.List(ctx, &deployments, client.InNamespace(testNamespace),
+				client.MatchingLabels{streamingv1alpha1.ProcessorLabelKey: "square"})).To(Succeed())
+			return len(deployments.Items)
+		}).Should(Equal(1))
+		var inputs *corev1.EnvVar
+		for _, container := range deployments.Items[0].Spec.Template.Spec.Containers {
+			if env := findEnv(container.Env, "INPUTS"); env != nil {
+				inputs = env
+			}
+		}
+		Expect(inputs).ToNot(BeNil())
+		Expect(inputs.Value).To(Equal(stream.Status.Address.String()))
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: "square"}, processor)).To(Succeed())
+		Expect(processor.Status.GetCondition(streamingv1alpha1.ProcessorConditionStreamsReady).IsTrue()).To(BeTrue())
+	})
+})
+
+// markDeploymentsAvailable stands in for the deployment controller, which
+// the test API server does not run. Returns the number of available
+// deployments of the provider, updates that conflict are retried on the next
+// poll.
+func markDeploymentsAvailable(ctx context.Context, labelKey, name string) int {
+	var deployments appsv1.DeploymentList
+	Expect(k8sClient.List(ctx, &deployments, client.InNamespace(testNamespace), client.MatchingLabels{labelKey: name})).To(Succeed())
+	available := 0
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		if len(deployment.Status.Conditions) == 0 {
+			deployment.Status.Conditions = []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+			}
+			if err := k8sClient.Status().Update(ctx, deployment); err != nil {
+				continue
+			}
+		}
+		available++
+	}
+	return available
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+)
+
+const testSystemNamespace = "riff-system"
+
+func TestKafkaProviderReconcile(t *testing.T) {
+	images := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testSystemNamespace, Name: kafkaProviderImages},
+		Data: map[string]string{
+			gatewayImageKey:     "gateway-image",
+			provisionerImageKey: "provisioner-image",
+		},
+	}
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "kafka-credentials"},
+		Data:       map[string][]byte{"username": []byte("riff"), "password": []byte("secret")},
+	}
+	ca := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "kafka-ca"},
+		Data:       map[string][]byte{"ca.crt": []byte("ca")},
+	}
+	client := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "kafka-client"},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")},
+	}
+	secretRef := func(name, key string) *corev1.EnvVarSource {
+		return &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+				Key:                  key,
+			},
+		}
+	}
+
+	tests := []struct {
+		name               string
+		spec               streamingv1alpha1.KafkaProviderSpec
+		objects            []runtime.Object
+		wantGatewayEnv     []corev1.EnvVar
+		wantProvisionerEnv []corev1.EnvVar
+	}{{
+		name: "plaintext",
+		spec: streamingv1alpha1.KafkaProviderSpec{
+			BootstrapServers: "kafka:9092",
+		},
+		objects: []runtime.Object{images},
+		wantGatewayEnv: []corev1.EnvVar{
+			{Name: "kafka_bootstrapServers", Value: "kafka:9092"},
+			{Name: "storage_records_type", Value: "KAFKA"},
+			{Name: "storage_positions_type", Value: "MEMORY"},
+		},
+		wantProvisionerEnv: []corev1.EnvVar{
+			{Name: "GATEWAY", Value: "my-provider-kafka-gateway-00002.test-namespace:6565"},
+			{Name: "BROKER", Value: "kafka:9092"},
+		},
+	}, {
+		name: "sasl and tls",
+		spec: streamingv1alpha1.KafkaProviderSpec{
+			BootstrapServers: "kafka:9093",
+			SASL: &streamingv1alpha1.KafkaSASL{
+				Mechanism: streamingv1alpha1.KafkaSASLMechanismScramSHA512,
+				SecretRef: corev1.LocalObjectReference{Name: "kafka-credentials"},
+			},
+			TLS: &streamingv1alpha1.ProviderTLS{
+				CASecretRef:   &corev1.LocalObjectReference{Name: "kafka-ca"},
+				CertSecretRef: &corev1.LocalObjectReference{Name: "kafka-client"},
+			},
+		},
+		objects: []runtime.Object{images, credentials, ca, client},
+		// the liiklus kafka plugin only binds the bootstrap servers
+		wantGatewayEnv: []corev1.EnvVar{
+			{Name: "kafka_bootstrapServers", Value: "kafka:9093"},
+			{Name: "storage_records_type", Value: "KAFKA"},
+			{Name: "storage_positions_type", Value: "MEMORY"},
+		},
+		wantProvisionerEnv: []corev1.EnvVar{
+			{Name: "GATEWAY", Value: "my-provider-kafka-gateway-00002.test-namespace:6565"},
+			{Name: "BROKER", Value: "kafka:9093"},
+			{Name: "SASL_MECHANISM", Value: "SCRAM-SHA-512"},
+			{Name: "SASL_USERNAME", ValueFrom: secretRef("kafka-credentials", "username")},
+			{Name: "SASL_PASSWORD", ValueFrom: secretRef("kafka-credentials", "password")},
+			{Name: "TLS_ENABLED", Value: "true"},
+			{Name: "TLS_CA", Value: "/var/riff/tls/ca/ca.crt"},
+			{Name: "TLS_CERT", Value: "/var/riff/tls/cert/tls.crt"},
+			{Name: "TLS_KEY", Value: "/var/riff/tls/cert/tls.key"},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider := &streamingv1alpha1.KafkaProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "my-provider"},
+				Spec:       test.spec,
+			}
+			c := newFakeClient(append(test.objects, provider)...)
+			r := &KafkaProviderReconciler{
+				Client:    c,
+				Log:       zap.Logger(true),
+				Scheme:    scheme.Scheme,
+				Tracker:   newTestTracker(),
+				Namespace: testSystemNamespace,
+			}
+			key := types.NamespacedName{Namespace: testNamespace, Name: "my-provider"}
+			if _, err := r.Reconcile(ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() unexpected error: %v", err)
+			}
+
+			gatewayEnv, provisionerEnv := providerChildrenEnv(t, c, streamingv1alpha1.KafkaProviderGatewayLabelKey, streamingv1alpha1.KafkaProviderProvisionerLabelKey)
+			if diff := cmp.Diff(test.wantGatewayEnv, gatewayEnv); diff != "" {
+				t.Errorf("gateway env (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.wantProvisionerEnv, provisionerEnv); diff != "" {
+				t.Errorf("provisioner env (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -20,11 +20,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -53,6 +55,7 @@ import (
 const (
 	processorDeploymentIndexField   = ".metadata.processorDeploymentController"
 	processorScaledObjectIndexField = ".metadata.processorScaledObjectController"
+	scaledObjectJobIndexField       = ".metadata.scaledObjectJobController"
 )
 
 // ProcessorReconciler reconciles a Processor object
@@ -62,6 +65,8 @@ type ProcessorReconciler struct {
 	Scheme    *runtime.Scheme
 	Tracker   tracker.Tracker
 	Namespace string
+
+	StreamProvisionerClient StreamProvisionerClient
 }
 
 // For
@@ -72,7 +77,9 @@ type ProcessorReconciler struct {
 // +kubebuilder:rbac:groups=keda.k8s.io,resources=scaledobjects,verbs=get;list;watch;create;update;patch;delete
 // Watches
 // +kubebuilder:rbac:groups=streaming.projectriff.io,resources=streams,verbs=get;watch
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=kafkaproviders;pulsarproviders;inmemoryproviders;providers,verbs=get;list;watch
 // +kubebuilder:rbac:groups=build.projectriff.io,resources=containers;functions,verbs=get;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;delete
 
 func (r *ProcessorReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -127,6 +134,10 @@ func (r *ProcessorReconciler) reconcile(ctx context.Context, logger logr.Logger,
 		logger.Error(err, "unable to lookup images configMap")
 		return ctrl.Result{}, err
 	}
+	processorImg := cm.Data[processorImageKey]
+	if processorImg == "" {
+		return ctrl.Result{}, fmt.Errorf("missing processor image configuration")
+	}
 
 	// resolve image
 	if processor.Spec.Build != nil {
@@ -188,17 +199,34 @@ func (r *ProcessorReconciler) reconcile(ctx context.Context, logger logr.Logger,
 	processor.Status.OutputAddresses = r.collectStreamAddresses(outputStreams)
 	processor.Status.OutputContentTypes = r.collectStreamContentTypes(outputStreams)
 
+	// Resolve dead-letter address
+	deadLetterStreams, err := r.resolveStreams(ctx, processorNSName, r.collectDeadLetterBindings(processor))
+	if err != nil {
+		return ctrl.Result{Requeue: true}, err
+	}
+	processor.Status.DeadLetterAddress = ""
+	if len(deadLetterStreams) != 0 {
+		processor.Status.DeadLetterAddress = r.collectStreamAddresses(deadLetterStreams)[0]
+	}
+
 	// Reconcile deployment for processor
-	deployment, err := r.reconcileProcessorDeployment(ctx, logger, processor, &cm)
+	deployment, err := r.reconcileProcessorDeployment(ctx, logger, processor, processorImg)
 	if err != nil {
 		logger.Error(err, "unable to reconcile deployment")
 		return ctrl.Result{}, err
 	}
-	processor.Status.DeploymentName = deployment.Name
-	processor.Status.PropagateDeploymentStatus(&deployment.Status)
+	if deployment == nil {
+		// batch processors run as jobs
+		processor.Status.DeploymentName = ""
+		processor.Status.MarkDeploymentNotRequired()
+	} else {
+		processor.Status.DeploymentName = deployment.Name
+		processor.Status.PropagateDeploymentStatus(&deployment.Status)
+	}
 
 	processor.Status.MarkStreamsReady()
-	for _, stream := range append(inputStreams, outputStreams...) {
+	allStreams := append(append(inputStreams, outputStreams...), deadLetterStreams...)
+	for _, stream := range allStreams {
 		ready := stream.Status.GetCondition(stream.Status.GetReadyConditionType())
 		if ready == nil {
 			ready = &apis.Condition{Message: "stream has no ready condition"}
@@ -209,21 +237,48 @@ func (r *ProcessorReconciler) reconcile(ctx context.Context, logger logr.Logger,
 		}
 	}
 
+	// Resolve the providers backing the inputs to replay them
+	inputProviders, err := r.resolveStreamProviders(ctx, processorNSName, inputStreams)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Reflect the outcome of the jobs run for batch processors
+	if processor.Spec.Batch == nil {
+		processor.Status.Batch = nil
+	} else {
+		if err := r.reconcileBatchJobs(ctx, logger, processor); err != nil {
+			logger.Error(err, "unable to resolve jobs")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Replay inputs once the processor has scaled down
+	if err := r.reconcileReplay(ctx, logger, processor, deployment, inputStreams, inputProviders); err != nil {
+		logger.Error(err, "unable to replay inputs")
+		return ctrl.Result{}, err
+	}
+
 	// Reconcile scaledObject for processor
-	scaledObject, err := r.reconcileProcessorScaledObject(ctx, logger, processor, deployment)
+	scaledObject, err := r.reconcileProcessorScaledObject(ctx, logger, processor, deployment, processorImg)
 	if err != nil {
 		logger.Error(err, "unable to reconcile scaledObject")
 		return ctrl.Result{}, err
 	}
 	processor.Status.ScaledObjectName = scaledObject.Name
 	processor.Status.PropagateScaledObjectStatus(&scaledObject.Status)
+	if processor.Spec.Suspend {
+		processor.Status.MarkSuspended()
+	} else {
+		processor.Status.MarkResumed()
+	}
 
 	processor.Status.ObservedGeneration = processor.Generation
 
 	return ctrl.Result{}, nil
 }
 
-func (r *ProcessorReconciler) reconcileProcessorScaledObject(ctx context.Context, log logr.Logger, processor *streamingv1alpha1.Processor, deployment *appsv1.Deployment) (*kedav1alpha1.ScaledObject, error) {
+func (r *ProcessorReconciler) reconcileProcessorScaledObject(ctx context.Context, log logr.Logger, processor *streamingv1alpha1.Processor, deployment *appsv1.Deployment, processorImg string) (*kedav1alpha1.ScaledObject, error) {
 	var actualScaledObject kedav1alpha1.ScaledObject
 	var childScaledObjects kedav1alpha1.ScaledObjectList
 	if err := r.List(ctx, &childScaledObjects, client.InNamespace(processor.Namespace), client.MatchingField(processorScaledObjectIndexField, processor.Name)); err != nil {
@@ -242,7 +297,7 @@ func (r *ProcessorReconciler) reconcileProcessorScaledObject(ctx context.Context
 		}
 	}
 
-	desiredScaledObject, err := r.constructScaledObjectForProcessor(processor, deployment)
+	desiredScaledObject, err := r.constructScaledObjectForProcessor(processor, deployment, processorImg)
 	if err != nil {
 		return nil, err
 	}
@@ -285,19 +340,21 @@ func (r *ProcessorReconciler) reconcileProcessorScaledObject(ctx context.Context
 	return scaledObject, nil
 }
 
-func (r *ProcessorReconciler) constructScaledObjectForProcessor(processor *streamingv1alpha1.Processor, deployment *appsv1.Deployment) (*kedav1alpha1.ScaledObject, error) {
+func (r *ProcessorReconciler) constructScaledObjectForProcessor(processor *streamingv1alpha1.Processor, deployment *appsv1.Deployment, processorImg string) (*kedav1alpha1.ScaledObject, error) {
 	labels := r.constructLabelsForProcessor(processor)
 
-	zero := int32(0)
-	one := int32(1)
-	thirty := int32(30)
-
-	labels["deploymentName"] = deployment.Name
-
-	maxReplicas := thirty
-	if processor.Status.GetCondition(streamingv1alpha1.ProcessorConditionStreamsReady).IsFalse() {
-		// scale to zero while dependencies are not ready
-		maxReplicas = zero
+	// the defaulter guarantees scaling bounds
+	scaling := processor.Spec.Scaling
+	minReplicas := *scaling.MinReplicas
+	maxReplicas := *scaling.MaxReplicas
+	pollingInterval := *scaling.PollingInterval
+	cooldownPeriod := *scaling.CooldownPeriod
+	if processor.Status.GetCondition(streamingv1alpha1.ProcessorConditionStreamsReady).IsFalse() || processor.Spec.Suspend || r.isReplaying(processor) {
+		// scale to zero while dependencies are not ready, the processor is
+		// suspended or its inputs are being replayed, the consumer group is
+		// kept to resume where it left off
+		minReplicas = 0
+		maxReplicas = 0
 	}
 
 	scaledObject := &kedav1alpha1.ScaledObject{
@@ -307,16 +364,27 @@ func (r *ProcessorReconciler) constructScaledObjectForProcessor(processor *strea
 			Labels:       labels,
 		},
 		Spec: kedav1alpha1.ScaledObjectSpec{
-			ScaleTargetRef: &kedav1alpha1.ObjectReference{
-				DeploymentName: deployment.Name,
-			},
-			PollingInterval: &one,
-			CooldownPeriod:  &thirty,
-			Triggers:        triggers(processor),
-			MinReplicaCount: &zero,
+			PollingInterval: &pollingInterval,
+			CooldownPeriod:  &cooldownPeriod,
+			Triggers:        scaleTriggers(r.collectGroups(processor), processor.Status.InputAddresses, r.collectLagThresholds(processor.Spec.Inputs)),
+			MinReplicaCount: &minReplicas,
 			MaxReplicaCount: &maxReplicas,
 		},
 	}
+	if processor.Spec.Batch == nil {
+		scaledObject.ObjectMeta.Labels["deploymentName"] = deployment.Name
+		scaledObject.Spec.ScaleTargetRef = &kedav1alpha1.ObjectReference{
+			DeploymentName: deployment.Name,
+		}
+	} else {
+		// jobs are started as messages are pending on the inputs
+		jobSpec, err := r.constructJobSpecForProcessor(processor, processorImg)
+		if err != nil {
+			return nil, err
+		}
+		scaledObject.Spec.ScaleType = kedav1alpha1.ScaleTypeJob
+		scaledObject.Spec.JobTargetRef = jobSpec
+	}
 
 	if err := ctrl.SetControllerReference(processor, scaledObject, r.Scheme); err != nil {
 		return nil, err
@@ -325,25 +393,47 @@ func (r *ProcessorReconciler) constructScaledObjectForProcessor(processor *strea
 	return scaledObject, nil
 }
 
-func triggers(proc *streamingv1alpha1.Processor) []kedav1alpha1.ScaleTriggers {
-	result := make([]kedav1alpha1.ScaleTriggers, len(proc.Status.InputAddresses))
-	for i, topic := range proc.Status.InputAddresses {
+// scaleTriggers asks the gateway for the lag of each input. The groups are the
+// consumer groups reading each input, liiklus keeps their positions so the
+// brokers' own committed offsets do not reflect them.
+func scaleTriggers(groups, addresses []string, lagThresholds []*int32) []kedav1alpha1.ScaleTriggers {
+	result := make([]kedav1alpha1.ScaleTriggers, len(addresses))
+	for i, address := range addresses {
 		result[i].Type = "liiklus"
 		result[i].Metadata = map[string]string{
-			"address": strings.SplitN(topic, "/", 2)[0],
-			"group":   proc.Name,
-			"topic":   strings.SplitN(topic, "/", 2)[1],
+			"address": strings.SplitN(address, "/", 2)[0],
+			"group":   groups[i],
+			"topic":   strings.SplitN(address, "/", 2)[1],
+		}
+		if lagThreshold := lagThresholds[i]; lagThreshold != nil {
+			result[i].Metadata["lagThreshold"] = fmt.Sprintf("%d", *lagThreshold)
 		}
 	}
 	return result
 }
 
+// resolveStreamProviders finds the provider referenced by each stream. Streams
+// whose provider does not exist resolve to an empty streamProvider.
+func (r *ProcessorReconciler) resolveStreamProviders(ctx context.Context, processorCoordinates types.NamespacedName, streams []streamingv1alpha1.Stream) ([]streamProvider, error) {
+	providers := make([]streamProvider, len(streams))
+	for i := range streams {
+		// track provider for broker changes
+		r.Tracker.Track(streamProviderKey(&streams[i]), processorCoordinates)
+		provider, err := getStreamProvider(ctx, r.Client, &streams[i])
+		if err != nil {
+			return nil, err
+		}
+		providers[i] = provider
+	}
+	return providers, nil
+}
+
 func (r *ProcessorReconciler) scaledObjectSemanticEquals(desiredDeployment, deployment *kedav1alpha1.ScaledObject) bool {
 	return equality.Semantic.DeepEqual(desiredDeployment.Spec, deployment.Spec) &&
 		equality.Semantic.DeepEqual(desiredDeployment.ObjectMeta.Labels, deployment.ObjectMeta.Labels)
 }
 
-func (r *ProcessorReconciler) reconcileProcessorDeployment(ctx context.Context, log logr.Logger, processor *streamingv1alpha1.Processor, cm *corev1.ConfigMap) (*appsv1.Deployment, error) {
+func (r *ProcessorReconciler) reconcileProcessorDeployment(ctx context.Context, log logr.Logger, processor *streamingv1alpha1.Processor, processorImg string) (*appsv1.Deployment, error) {
 	var actualDeployment appsv1.Deployment
 	var childDeployments appsv1.DeploymentList
 	if err := r.List(ctx, &childDeployments, client.InNamespace(processor.Namespace), client.MatchingField(processorDeploymentIndexField, processor.Name)); err != nil {
@@ -362,11 +452,6 @@ func (r *ProcessorReconciler) reconcileProcessorDeployment(ctx context.Context,
 		}
 	}
 
-	processorImg := cm.Data[processorImageKey]
-	if processorImg == "" {
-		return nil, fmt.Errorf("missing processor image configuration")
-	}
-
 	desiredDeployment, err := r.constructDeploymentForProcessor(processor, processorImg)
 	if err != nil {
 		return nil, err
@@ -374,6 +459,10 @@ func (r *ProcessorReconciler) reconcileProcessorDeployment(ctx context.Context,
 
 	// delete deployment if no longer needed
 	if desiredDeployment == nil {
+		if actualDeployment.Name == "" {
+			return nil, nil
+		}
+		log.Info("deleting processor deployment", "deployment", actualDeployment)
 		if err := r.Delete(ctx, &actualDeployment); err != nil {
 			log.Error(err, "unable to delete Deployment for Processor", "deployment", actualDeployment)
 			return nil, err
@@ -414,29 +503,19 @@ func (r *ProcessorReconciler) reconcileProcessorDeployment(ctx context.Context,
 }
 
 func (r *ProcessorReconciler) constructDeploymentForProcessor(processor *streamingv1alpha1.Processor, processorImg string) (*appsv1.Deployment, error) {
+	if processor.Spec.Batch != nil {
+		// batch processors run as jobs
+		return nil, nil
+	}
+
 	labels := r.constructLabelsForProcessor(processor)
 
 	zero := int32(0)
-	environmentVariables, err := r.computeEnvironmentVariables(processor)
+	podSpec, err := r.constructPodSpecForProcessor(processor, processorImg)
 	if err != nil {
 		return nil, err
 	}
 
-	// merge provided template with controlled values
-	podSpec := processor.Spec.Template.DeepCopy()
-	podSpec.Containers[0].Image = processor.Status.LatestImage
-	podSpec.Containers[0].Ports = []v1.ContainerPort{
-		{
-			ContainerPort: 8081,
-		},
-	}
-	podSpec.Containers = append(podSpec.Containers, v1.Container{
-		Name:            "processor",
-		Image:           processorImg,
-		ImagePullPolicy: v1.PullAlways,
-		Env:             environmentVariables,
-	})
-
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: fmt.Sprintf("%s-processor-", processor.Name),
@@ -467,6 +546,273 @@ func (r *ProcessorReconciler) constructDeploymentForProcessor(processor *streami
 	return deployment, nil
 }
 
+func (r *ProcessorReconciler) constructJobSpecForProcessor(processor *streamingv1alpha1.Processor, processorImg string) (*batchv1.JobSpec, error) {
+	podSpec, err := r.constructPodSpecForProcessor(processor, processorImg)
+	if err != nil {
+		return nil, err
+	}
+	// the processor stops the function once it drained its share of messages
+	shareProcessNamespace := true
+	podSpec.ShareProcessNamespace = &shareProcessNamespace
+	podSpec.RestartPolicy = v1.RestartPolicyNever
+
+	return &batchv1.JobSpec{
+		BackoffLimit:          processor.Spec.Batch.BackoffLimit,
+		ActiveDeadlineSeconds: processor.Spec.Batch.ActiveDeadlineSeconds,
+		Template: v1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					streamingv1alpha1.ProcessorLabelKey: processor.Name,
+				},
+			},
+			Spec: *podSpec,
+		},
+	}, nil
+}
+
+func (r *ProcessorReconciler) constructPodSpecForProcessor(processor *streamingv1alpha1.Processor, processorImg string) (*v1.PodSpec, error) {
+	environmentVariables, err := r.computeEnvironmentVariables(processor)
+	if err != nil {
+		return nil, err
+	}
+
+	// merge provided template with controlled values, the function image and
+	// port as well as the processor container are owned by riff
+	podSpec := processor.Spec.Template.DeepCopy()
+	podSpec.Containers[0].Image = processor.Status.LatestImage
+	podSpec.Containers[0].Ports = []v1.ContainerPort{
+		{
+			ContainerPort: 8081,
+		},
+	}
+	sidecar := v1.Container{
+		Name:            "processor",
+		Image:           processorImg,
+		ImagePullPolicy: v1.PullAlways,
+		Env:             environmentVariables,
+	}
+	if processor.Spec.Sidecar != nil {
+		sidecar.Resources = *processor.Spec.Sidecar.Resources.DeepCopy()
+	}
+	podSpec.Containers = append(podSpec.Containers, sidecar)
+
+	return podSpec, nil
+}
+
+// reconcileBatchJobs summarizes the jobs started for a batch processor and
+// prunes finished jobs beyond the history limits, oldest first. The latest
+// finish times are carried over so they survive the jobs being pruned.
+func (r *ProcessorReconciler) reconcileBatchJobs(ctx context.Context, log logr.Logger, processor *streamingv1alpha1.Processor) error {
+	// jobs are started by KEDA and controlled by the scaled object
+	var jobs batchv1.JobList
+	if processor.Status.ScaledObjectName != "" {
+		if err := r.List(ctx, &jobs, client.InNamespace(processor.Namespace), client.MatchingField(scaledObjectJobIndexField, processor.Status.ScaledObjectName)); err != nil {
+			return err
+		}
+	}
+
+	status := &streamingv1alpha1.BatchStatus{}
+	if previous := processor.Status.Batch; previous != nil {
+		status.LastSuccessfulTime = previous.LastSuccessfulTime
+		status.LastFailureTime = previous.LastFailureTime
+	}
+	var succeeded, failed []*batchv1.Job
+	var lastJob *batchv1.Job
+	var lastFinished *metav1.Time
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		finished := controllers.JobFinishedTime(job)
+		if finished == nil {
+			status.Active++
+			continue
+		}
+		if controllers.IsJobFailed(job) {
+			failed = append(failed, job)
+			if status.LastFailureTime == nil || status.LastFailureTime.Before(finished) {
+				status.LastFailureTime = finished
+			}
+		} else {
+			succeeded = append(succeeded, job)
+			if status.LastSuccessfulTime == nil || status.LastSuccessfulTime.Before(finished) {
+				status.LastSuccessfulTime = finished
+			}
+		}
+		if lastFinished == nil || lastFinished.Before(finished) {
+			lastJob = job
+			lastFinished = finished
+		}
+	}
+
+	retainedSucceeded, err := r.pruneJobs(ctx, log, succeeded, processor.Spec.Batch.SuccessfulJobsHistoryLimit)
+	if err != nil {
+		return err
+	}
+	retainedFailed, err := r.pruneJobs(ctx, log, failed, processor.Spec.Batch.FailedJobsHistoryLimit)
+	if err != nil {
+		return err
+	}
+	status.Succeeded = retainedSucceeded
+	status.Failed = retainedFailed
+	processor.Status.Batch = status
+
+	if lastJob == nil {
+		if status.LastSuccessfulTime == nil && status.LastFailureTime == nil {
+			processor.Status.MarkNoJobs()
+		}
+		return nil
+	}
+	processor.Status.PropagateLastJobStatus(&lastJob.Status)
+	return nil
+}
+
+// pruneJobs deletes the oldest finished jobs beyond the limit, returning the
+// number of jobs retained. A nil limit retains every job.
+func (r *ProcessorReconciler) pruneJobs(ctx context.Context, log logr.Logger, jobs []*batchv1.Job, limit *int32) (int32, error) {
+	if limit == nil || int32(len(jobs)) <= *limit {
+		return int32(len(jobs)), nil
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return controllers.JobFinishedTime(jobs[i]).Before(controllers.JobFinishedTime(jobs[j]))
+	})
+	propagation := metav1.DeletePropagationBackground
+	for _, job := range jobs[:int32(len(jobs))-*limit] {
+		log.Info("deleting job beyond history limit", "job", job.Name)
+		if err := r.Delete(ctx, job, client.PropagationPolicy(propagation)); err != nil && !errors.IsNotFound(err) {
+			return 0, err
+		}
+	}
+	return *limit, nil
+}
+
+// reconcileReplay resets the consumer group on each replayed input. Nothing
+// may be consuming from the group while it moves, so the reset waits for the
+// deployment to scale down, or for the active jobs of a batch processor to
+// finish. Each replay token is replayed once.
+func (r *ProcessorReconciler) reconcileReplay(ctx context.Context, log logr.Logger, processor *streamingv1alpha1.Processor, deployment *appsv1.Deployment, streams []streamingv1alpha1.Stream, providers []streamProvider) error {
+	processor.Status.ReplayedGroups = r.collectReplayedGroups(processor)
+
+	replay := processor.Spec.Replay
+	if replay == nil {
+		processor.Status.Replay = nil
+		return nil
+	}
+	inputs := r.collectReplayedInputs(processor)
+	if processor.Status.Replay == nil || processor.Status.Replay.Token != replay.Token {
+		processor.Status.Replay = &streamingv1alpha1.ReplayStatus{Token: replay.Token}
+		for _, i := range inputs {
+			if !providers[i].supportsReplay() {
+				provider := streams[i].Spec.Provider
+				processor.Status.MarkReplayNotSupported(fmt.Sprintf("%s %q of stream %q does not support replay", provider.Kind, provider.Name, streams[i].Name))
+				return nil
+			}
+		}
+		// scale down before touching the consumer groups
+		processor.Status.MarkReplayPending("scaling down processor")
+		return nil
+	}
+	if !r.isReplaying(processor) {
+		return nil
+	}
+
+	switch {
+	case deployment != nil && (deployment.Status.ObservedGeneration < deployment.Generation || deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 0):
+		processor.Status.MarkReplayPending("waiting for the deployment to scale down")
+		return nil
+	case deployment != nil && deployment.Status.Replicas != 0:
+		processor.Status.MarkReplayPending(fmt.Sprintf("waiting for %d replicas to terminate", deployment.Status.Replicas))
+		return nil
+	case processor.Status.Batch != nil && processor.Status.Batch.Active != 0:
+		processor.Status.MarkReplayPending(fmt.Sprintf("waiting for %d jobs to finish", processor.Status.Batch.Active))
+		return nil
+	}
+
+	groups := r.collectBindingGroups(processor)
+	for _, i := range inputs {
+		provisioner := providers[i].provisionerService()
+		if !providers[i].isReady() || provisioner.Name == "" {
+			processor.Status.MarkReplayPending(fmt.Sprintf("provider for stream %q is not ready", streams[i].Name))
+			return nil
+		}
+		log.Info("resetting consumer group", "stream", streams[i].Name, "group", groups[i], "position", replay.Position)
+		consumerGroup, err := r.StreamProvisionerClient.ResetConsumerGroup(&streams[i], provisioner, groups[i], replay.Position)
+		if err != nil {
+			processor.Status.MarkReplayFailed(fmt.Sprintf("unable to reset stream %q: %s", streams[i].Name, err))
+			return err
+		}
+		alias := processor.Spec.Inputs[i].Alias
+		replayedGroups := []streamingv1alpha1.ReplayedGroup{}
+		for _, replayedGroup := range processor.Status.ReplayedGroups {
+			if replayedGroup.Alias != alias {
+				replayedGroups = append(replayedGroups, replayedGroup)
+			}
+		}
+		if consumerGroup != groups[i] {
+			replayedGroups = append(replayedGroups, streamingv1alpha1.ReplayedGroup{
+				Alias:         alias,
+				Group:         groups[i],
+				ConsumerGroup: consumerGroup,
+				StartPosition: replay.Position,
+			})
+		}
+		processor.Status.ReplayedGroups = replayedGroups
+	}
+
+	now := metav1.Now()
+	processor.Status.Replay.CompletionTime = &now
+	processor.Status.MarkReplayed()
+	return nil
+}
+
+// isReplaying is true while the requested replay has not completed.
+func (r *ProcessorReconciler) isReplaying(processor *streamingv1alpha1.Processor) bool {
+	if processor.Spec.Replay == nil || processor.Status.Replay == nil {
+		return false
+	}
+	if replayed := processor.Status.GetCondition(streamingv1alpha1.ProcessorConditionReplayed); replayed != nil && replayed.Reason == "ReplayNotSupported" {
+		return false
+	}
+	return processor.Status.Replay.Token == processor.Spec.Replay.Token && processor.Status.Replay.CompletionTime == nil
+}
+
+// collectReplayedInputs returns the index of each input to replay, all inputs
+// unless the replay names them.
+func (r *ProcessorReconciler) collectReplayedInputs(processor *streamingv1alpha1.Processor) []int {
+	aliases := map[string]bool{}
+	for _, alias := range processor.Spec.Replay.Inputs {
+		aliases[alias] = true
+	}
+	inputs := []int{}
+	for i, input := range processor.Spec.Inputs {
+		if len(aliases) == 0 || aliases[input.Alias] {
+			inputs = append(inputs, i)
+		}
+	}
+	return inputs
+}
+
+// collectReplayedGroups drops the replayed groups of inputs that were removed
+// or now name another group, they consume with their own group again.
+func (r *ProcessorReconciler) collectReplayedGroups(processor *streamingv1alpha1.Processor) []streamingv1alpha1.ReplayedGroup {
+	var replayedGroups []streamingv1alpha1.ReplayedGroup
+	groups := r.collectBindingGroups(processor)
+	for i, input := range processor.Spec.Inputs {
+		if replayedGroup := r.replayedGroupFor(processor, input.Alias, groups[i]); replayedGroup != nil {
+			replayedGroups = append(replayedGroups, *replayedGroup)
+		}
+	}
+	return replayedGroups
+}
+
+func (r *ProcessorReconciler) replayedGroupFor(processor *streamingv1alpha1.Processor, alias, group string) *streamingv1alpha1.ReplayedGroup {
+	for i := range processor.Status.ReplayedGroups {
+		replayedGroup := &processor.Status.ReplayedGroups[i]
+		if replayedGroup.Alias == alias && replayedGroup.Group == group {
+			return replayedGroup
+		}
+	}
+	return nil
+}
+
 func (r *ProcessorReconciler) constructLabelsForProcessor(processor *streamingv1alpha1.Processor) map[string]string {
 	labels := make(map[string]string, len(processor.ObjectMeta.Labels)+1)
 	// pass through existing labels
@@ -520,6 +866,15 @@ func (r *ProcessorReconciler) collectStreamContentTypes(streams []streamingv1alp
 	return contentTypes
 }
 
+func (r *ProcessorReconciler) collectDeadLetterBindings(processor *streamingv1alpha1.Processor) []streamingv1alpha1.StreamBinding {
+	if processor.Spec.ErrorPolicy == nil || processor.Spec.ErrorPolicy.DeadLetterStream == "" {
+		return nil
+	}
+	return []streamingv1alpha1.StreamBinding{
+		{Stream: processor.Spec.ErrorPolicy.DeadLetterStream},
+	}
+}
+
 func (r *ProcessorReconciler) computeEnvironmentVariables(processor *streamingv1alpha1.Processor) ([]v1.EnvVar, error) {
 	contentTypesJson, err := json.Marshal(processor.Status.OutputContentTypes)
 	if err != nil {
@@ -527,7 +882,7 @@ func (r *ProcessorReconciler) computeEnvironmentVariables(processor *streamingv1
 	}
 	inputsNames := r.collectAliases(processor.Spec.Inputs)
 	outputsNames := r.collectAliases(processor.Spec.Outputs)
-	return []v1.EnvVar{
+	env := []v1.EnvVar{
 		{
 			Name:  "INPUTS",
 			Value: strings.Join(processor.Status.InputAddresses, ","),
@@ -548,6 +903,14 @@ func (r *ProcessorReconciler) computeEnvironmentVariables(processor *streamingv1
 			Name:  "GROUP",
 			Value: processor.Name,
 		},
+		{
+			Name:  "INPUT_GROUPS",
+			Value: strings.Join(r.collectGroups(processor), ","),
+		},
+		{
+			Name:  "INPUT_START_POSITIONS",
+			Value: strings.Join(r.collectStartPositions(processor), ","),
+		},
 		{
 			Name:  "FUNCTION",
 			Value: "localhost:8081",
@@ -556,7 +919,85 @@ func (r *ProcessorReconciler) computeEnvironmentVariables(processor *streamingv1
 			Name:  "OUTPUT_CONTENT_TYPES",
 			Value: string(contentTypesJson),
 		},
-	}, nil
+	}
+	if batch := processor.Spec.Batch; batch != nil {
+		env = append(env, v1.EnvVar{
+			Name:  "MAX_MESSAGES",
+			Value: fmt.Sprintf("%d", *batch.MaxMessages),
+		})
+	}
+	if policy := processor.Spec.ErrorPolicy; policy != nil {
+		env = append(env,
+			v1.EnvVar{
+				Name:  "ERROR_MAX_RETRIES",
+				Value: fmt.Sprintf("%d", *policy.MaxRetries),
+			},
+			v1.EnvVar{
+				Name:  "ERROR_BACKOFF_INITIAL_DELAY",
+				Value: policy.Backoff.InitialDelay.Duration.String(),
+			},
+			v1.EnvVar{
+				Name:  "ERROR_BACKOFF_MAX_DELAY",
+				Value: policy.Backoff.MaxDelay.Duration.String(),
+			},
+			v1.EnvVar{
+				Name:  "ERROR_BACKOFF_MULTIPLIER",
+				Value: fmt.Sprintf("%d", *policy.Backoff.Multiplier),
+			},
+			v1.EnvVar{
+				Name:  "DEAD_LETTER",
+				Value: processor.Status.DeadLetterAddress,
+			},
+		)
+	}
+	return env, nil
+}
+
+func (*ProcessorReconciler) collectLagThresholds(bindings []streamingv1alpha1.StreamBinding) []*int32 {
+	lagThresholds := make([]*int32, len(bindings))
+	for i := range bindings {
+		lagThresholds[i] = bindings[i].LagThreshold
+	}
+	return lagThresholds
+}
+
+// collectBindingGroups returns the consumer group of each input binding, the
+// processor name unless the input names a group.
+func (*ProcessorReconciler) collectBindingGroups(processor *streamingv1alpha1.Processor) []string {
+	groups := make([]string, len(processor.Spec.Inputs))
+	for i := range processor.Spec.Inputs {
+		groups[i] = processor.Spec.Inputs[i].Group
+		if groups[i] == "" {
+			groups[i] = processor.Name
+		}
+	}
+	return groups
+}
+
+// collectGroups returns the consumer group each input consumes with, the
+// group handed out by the provisioner for replayed inputs.
+func (r *ProcessorReconciler) collectGroups(processor *streamingv1alpha1.Processor) []string {
+	groups := r.collectBindingGroups(processor)
+	for i, input := range processor.Spec.Inputs {
+		if replayedGroup := r.replayedGroupFor(processor, input.Alias, groups[i]); replayedGroup != nil {
+			groups[i] = replayedGroup.ConsumerGroup
+		}
+	}
+	return groups
+}
+
+// collectStartPositions returns where each input starts when its consumer
+// group has no position yet.
+func (r *ProcessorReconciler) collectStartPositions(processor *streamingv1alpha1.Processor) []string {
+	groups := r.collectBindingGroups(processor)
+	positions := make([]string, len(processor.Spec.Inputs))
+	for i, input := range processor.Spec.Inputs {
+		positions[i] = input.StartPosition
+		if replayedGroup := r.replayedGroupFor(processor, input.Alias, groups[i]); replayedGroup != nil {
+			positions[i] = replayedGroup.StartPosition
+		}
+	}
+	return positions
 }
 
 func (*ProcessorReconciler) collectAliases(bindings []streamingv1alpha1.StreamBinding) []string {
@@ -591,12 +1032,51 @@ func (r *ProcessorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	scaledObjectAPIVersion := kedav1alpha1.GroupVersion.String()
+	if err := mgr.GetFieldIndexer().IndexField(&batchv1.Job{}, scaledObjectJobIndexField, func(rawObj runtime.Object) []string {
+		ownerRef := metav1.GetControllerOf(rawObj.(metav1.Object))
+		if ownerRef == nil || ownerRef.APIVersion != scaledObjectAPIVersion || ownerRef.Kind != "ScaledObject" {
+			return nil
+		}
+		return []string{ownerRef.Name}
+	}); err != nil {
+		return err
+	}
+
+	// jobs are controlled by the scaled object, which is controlled by the
+	// processor
+	enqueueProcessorForJob := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			ownerRef := metav1.GetControllerOf(a.Meta)
+			if ownerRef == nil || ownerRef.APIVersion != scaledObjectAPIVersion || ownerRef.Kind != "ScaledObject" {
+				return nil
+			}
+			var scaledObject kedav1alpha1.ScaledObject
+			if err := r.Get(context.Background(), types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: ownerRef.Name}, &scaledObject); err != nil {
+				return nil
+			}
+			processorRef := metav1.GetControllerOf(&scaledObject)
+			if processorRef == nil || processorRef.Kind != "Processor" {
+				return nil
+			}
+			return []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: processorRef.Name}},
+			}
+		}),
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&streamingv1alpha1.Processor{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&kedav1alpha1.ScaledObject{}).
+		// watch for jobs of batch processors to report their outcome
+		Watches(&source.Kind{Type: &batchv1.Job{}}, enqueueProcessorForJob).
 		Watches(&source.Kind{Type: &buildv1alpha1.Container{}}, enqueueTrackedResources(&buildv1alpha1.Container{})).
 		Watches(&source.Kind{Type: &buildv1alpha1.Function{}}, enqueueTrackedResources(&buildv1alpha1.Function{})).
 		Watches(&source.Kind{Type: &streamingv1alpha1.Stream{}}, enqueueTrackedResources(&streamingv1alpha1.Stream{})).
+		Watches(&source.Kind{Type: &streamingv1alpha1.KafkaProvider{}}, enqueueTrackedResources(&streamingv1alpha1.KafkaProvider{})).
+		Watches(&source.Kind{Type: &streamingv1alpha1.PulsarProvider{}}, enqueueTrackedResources(&streamingv1alpha1.PulsarProvider{})).
+		Watches(&source.Kind{Type: &streamingv1alpha1.InMemoryProvider{}}, enqueueTrackedResources(&streamingv1alpha1.InMemoryProvider{})).
+		Watches(&source.Kind{Type: &streamingv1alpha1.Provider{}}, enqueueTrackedResources(&streamingv1alpha1.Provider{})).
 		Complete(r)
 }
@@ -0,0 +1,429 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/projectriff/system/pkg/apis"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	kedav1alpha1 "github.com/projectriff/system/pkg/apis/thirdparty/keda/v1alpha1"
+)
+
+// processorImagesConfigMap names the processor sidecar image
+func processorImagesConfigMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testSystemNamespace, Name: processorImages},
+		Data:       map[string]string{processorImageKey: "processor-image"},
+	}
+}
+
+// readyStream is a provisioned stream of the in-memory provider "memory"
+func readyStream(name string) *streamingv1alpha1.Stream {
+	stream := &streamingv1alpha1.Stream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: name},
+		Spec: streamingv1alpha1.StreamSpec{
+			Provider:    streamingv1alpha1.StreamProviderReference{Kind: streamingv1alpha1.InMemoryProviderKind, Name: "memory"},
+			ContentType: "text/plain",
+		},
+	}
+	stream.Status.InitializeConditions()
+	stream.Status.MarkProviderReady()
+	stream.Status.MarkStreamProvisioned()
+	stream.Status.MarkBindingReady()
+	stream.Status.Address = streamingv1alpha1.StreamAddress{Gateway: "memory-gateway:6565", Topic: testNamespace + "_" + name}
+	return stream
+}
+
+// newTestProcessor consumes the "numbers" stream with the "my-function" image
+func newTestProcessor() *streamingv1alpha1.Processor {
+	return &streamingv1alpha1.Processor{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "my-processor"},
+		Spec: streamingv1alpha1.ProcessorSpec{
+			Inputs: []streamingv1alpha1.StreamBinding{{Stream: "numbers"}},
+			Template: &corev1.PodSpec{
+				Containers: []corev1.Container{{Image: "my-function"}},
+			},
+		},
+	}
+}
+
+// reconcileProcessor reconciles the processor once, returning the client
+// holding the outcome
+func reconcileProcessor(t *testing.T, r *ProcessorReconciler, objects ...runtime.Object) client.Client {
+	c := newFakeClient(objects...)
+	r.Client = c
+	r.Log = zap.Logger(true)
+	r.Scheme = scheme.Scheme
+	r.Tracker = newTestTracker()
+	r.Namespace = testSystemNamespace
+	key := types.NamespacedName{Namespace: testNamespace, Name: "my-processor"}
+	if _, err := r.Reconcile(ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() unexpected error: %v", err)
+	}
+	return c
+}
+
+func getProcessor(t *testing.T, c client.Client) *streamingv1alpha1.Processor {
+	var processor streamingv1alpha1.Processor
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: testNamespace, Name: "my-processor"}, &processor); err != nil {
+		t.Fatalf("unable to get processor: %v", err)
+	}
+	return &processor
+}
+
+func getScaledObject(t *testing.T, c client.Client) *kedav1alpha1.ScaledObject {
+	var scaledObjects kedav1alpha1.ScaledObjectList
+	if err := c.List(context.Background(), &scaledObjects, client.InNamespace(testNamespace)); err != nil {
+		t.Fatalf("unable to list scaled objects: %v", err)
+	}
+	if len(scaledObjects.Items) != 1 {
+		t.Fatalf("found %d scaled objects, want 1", len(scaledObjects.Items))
+	}
+	return &scaledObjects.Items[0]
+}
+
+func TestProcessorReconcileBatch(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	minutesAgo := func(minutes int) metav1.Time {
+		return metav1.NewTime(now.Add(-time.Duration(minutes) * time.Minute))
+	}
+	job := func(name string, condition batchv1.JobConditionType, finished metav1.Time) *batchv1.Job {
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: name},
+		}
+		if condition != "" {
+			job.Status.Conditions = []batchv1.JobCondition{
+				{Type: condition, Status: corev1.ConditionTrue, LastTransitionTime: finished, Reason: "Reason", Message: "message"},
+			}
+		}
+		return job
+	}
+	lastSuccessful := minutesAgo(10)
+	lastFailure := minutesAgo(5)
+
+	tests := []struct {
+		name              string
+		jobs              []runtime.Object
+		wantJobs          []string
+		wantBatch         *streamingv1alpha1.BatchStatus
+		wantLastJobStatus corev1.ConditionStatus
+	}{{
+		name:              "no jobs",
+		wantBatch:         &streamingv1alpha1.BatchStatus{},
+		wantLastJobStatus: corev1.ConditionUnknown,
+	}, {
+		name: "prunes jobs beyond the history limits",
+		jobs: []runtime.Object{
+			job("succeeded-1", batchv1.JobComplete, minutesAgo(30)),
+			job("succeeded-2", batchv1.JobComplete, minutesAgo(20)),
+			job("succeeded-3", batchv1.JobComplete, lastSuccessful),
+			job("failed-1", batchv1.JobFailed, minutesAgo(25)),
+			job("failed-2", batchv1.JobFailed, lastFailure),
+			job("active", "", metav1.Time{}),
+		},
+		wantJobs: []string{"active", "failed-2", "succeeded-2", "succeeded-3"},
+		wantBatch: &streamingv1alpha1.BatchStatus{
+			Active:             1,
+			Succeeded:          2,
+			Failed:             1,
+			LastSuccessfulTime: &lastSuccessful,
+			LastFailureTime:    &lastFailure,
+		},
+		wantLastJobStatus: corev1.ConditionFalse,
+	}, {
+		name: "last job succeeded",
+		jobs: []runtime.Object{
+			job("failed", batchv1.JobFailed, minutesAgo(25)),
+			job("succeeded", batchv1.JobComplete, lastSuccessful),
+		},
+		wantJobs: []string{"failed", "succeeded"},
+		wantBatch: &streamingv1alpha1.BatchStatus{
+			Succeeded:          1,
+			Failed:             1,
+			LastSuccessfulTime: &lastSuccessful,
+			LastFailureTime:    &[]metav1.Time{minutesAgo(25)}[0],
+		},
+		wantLastJobStatus: corev1.ConditionTrue,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			processor := newTestProcessor()
+			processor.Spec.Batch = &streamingv1alpha1.Batch{
+				MaxMessages:                &[]int32{10}[0],
+				SuccessfulJobsHistoryLimit: &[]int32{2}[0],
+			}
+			processor.Status.ScaledObjectName = "my-processor-processor-abcde"
+			objects := append([]runtime.Object{processorImagesConfigMap(), readyStream("numbers"), processor}, test.jobs...)
+			c := reconcileProcessor(t, &ProcessorReconciler{}, objects...)
+
+			var deployments appsv1.DeploymentList
+			if err := c.List(context.Background(), &deployments, client.InNamespace(testNamespace)); err != nil {
+				t.Fatalf("unable to list deployments: %v", err)
+			}
+			if len(deployments.Items) != 0 {
+				t.Errorf("batch processor created %d deployments", len(deployments.Items))
+			}
+
+			scaledObject := getScaledObject(t, c)
+			if scaledObject.Spec.ScaleType != kedav1alpha1.ScaleTypeJob || scaledObject.Spec.JobTargetRef == nil {
+				t.Fatalf("scaled object does not start jobs: %+v", scaledObject.Spec)
+			}
+			podSpec := scaledObject.Spec.JobTargetRef.Template.Spec
+			if podSpec.ShareProcessNamespace == nil || !*podSpec.ShareProcessNamespace {
+				t.Errorf("job pods must share their process namespace for the processor to stop the function")
+			}
+			if podSpec.RestartPolicy != corev1.RestartPolicyNever {
+				t.Errorf("job pods restart policy = %q, want %q", podSpec.RestartPolicy, corev1.RestartPolicyNever)
+			}
+			if diff := cmp.Diff(&corev1.EnvVar{Name: "MAX_MESSAGES", Value: "10"}, findEnv(podSpec.Containers[1].Env, "MAX_MESSAGES")); diff != "" {
+				t.Errorf("MAX_MESSAGES (-want, +got) = %v", diff)
+			}
+
+			var jobs batchv1.JobList
+			if err := c.List(context.Background(), &jobs, client.InNamespace(testNamespace)); err != nil {
+				t.Fatalf("unable to list jobs: %v", err)
+			}
+			var jobNames []string
+			for _, job := range jobs.Items {
+				jobNames = append(jobNames, job.Name)
+			}
+			sort.Strings(jobNames)
+			if diff := cmp.Diff(test.wantJobs, jobNames); diff != "" {
+				t.Errorf("retained jobs (-want, +got) = %v", diff)
+			}
+
+			processor = getProcessor(t, c)
+			if diff := cmp.Diff(test.wantBatch, processor.Status.Batch); diff != "" {
+				t.Errorf("batch status (-want, +got) = %v", diff)
+			}
+			if got := processor.Status.GetCondition(streamingv1alpha1.ProcessorConditionLastJobSucceeded).Status; got != test.wantLastJobStatus {
+				t.Errorf("LastJobSucceeded = %q, want %q", got, test.wantLastJobStatus)
+			}
+		})
+	}
+}
+
+func findEnv(env []corev1.EnvVar, name string) *corev1.EnvVar {
+	for i := range env {
+		if env[i].Name == name {
+			return &env[i]
+		}
+	}
+	return nil
+}
+
+func TestProcessorReconcileInputs(t *testing.T) {
+	tests := []struct {
+		name               string
+		inputs             []streamingv1alpha1.StreamBinding
+		wantGroups         string
+		wantStartPositions string
+		wantTriggerGroups  []string
+	}{{
+		name:               "defaults",
+		inputs:             []streamingv1alpha1.StreamBinding{{Stream: "numbers"}},
+		wantGroups:         "my-processor",
+		wantStartPositions: "",
+		wantTriggerGroups:  []string{"my-processor"},
+	}, {
+		name: "groups and start positions",
+		inputs: []streamingv1alpha1.StreamBinding{
+			{Stream: "numbers", Group: "renamed-processor", StartPosition: "latest"},
+			{Stream: "letters", StartPosition: "2019-11-04T12:00:00Z"},
+		},
+		wantGroups:         "renamed-processor,my-processor",
+		wantStartPositions: "latest,2019-11-04T12:00:00Z",
+		wantTriggerGroups:  []string{"renamed-processor", "my-processor"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			processor := newTestProcessor()
+			processor.Spec.Inputs = test.inputs
+			c := reconcileProcessor(t, &ProcessorReconciler{}, processorImagesConfigMap(), readyStream("numbers"), readyStream("letters"), processor)
+
+			var deployments appsv1.DeploymentList
+			if err := c.List(context.Background(), &deployments, client.InNamespace(testNamespace)); err != nil {
+				t.Fatalf("unable to list deployments: %v", err)
+			}
+			if len(deployments.Items) != 1 {
+				t.Fatalf("found %d deployments, want 1", len(deployments.Items))
+			}
+			env := deployments.Items[0].Spec.Template.Spec.Containers[1].Env
+			if diff := cmp.Diff(&corev1.EnvVar{Name: "INPUT_GROUPS", Value: test.wantGroups}, findEnv(env, "INPUT_GROUPS")); diff != "" {
+				t.Errorf("INPUT_GROUPS (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(&corev1.EnvVar{Name: "INPUT_START_POSITIONS", Value: test.wantStartPositions}, findEnv(env, "INPUT_START_POSITIONS")); diff != "" {
+				t.Errorf("INPUT_START_POSITIONS (-want, +got) = %v", diff)
+			}
+
+			var triggerGroups []string
+			for _, trigger := range getScaledObject(t, c).Spec.Triggers {
+				triggerGroups = append(triggerGroups, trigger.Metadata["group"])
+			}
+			if diff := cmp.Diff(test.wantTriggerGroups, triggerGroups); diff != "" {
+				t.Errorf("trigger groups (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+// fakeStreamProvisionerClient hands out a fresh group for each reset
+type fakeStreamProvisionerClient struct {
+	StreamProvisionerClient
+	resets []string
+}
+
+func (c *fakeStreamProvisionerClient) ResetConsumerGroup(stream *streamingv1alpha1.Stream, provisioner types.NamespacedName, group, position string) (string, error) {
+	c.resets = append(c.resets, fmt.Sprintf("%s/%s %s %s %s", provisioner.Namespace, provisioner.Name, stream.Name, group, position))
+	return group + "-replayed", nil
+}
+
+func TestProcessorReconcileReplay(t *testing.T) {
+	kafkaProvider := func(ready bool) *streamingv1alpha1.KafkaProvider {
+		provider := &streamingv1alpha1.KafkaProvider{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "kafka"},
+		}
+		provider.Status.ProvisionerServiceName = "kafka-provisioner"
+		provider.Status.Conditions = apis.Conditions{
+			{Type: streamingv1alpha1.ProviderConditionReady, Status: corev1.ConditionFalse},
+		}
+		if ready {
+			provider.Status.Conditions[0].Status = corev1.ConditionTrue
+		}
+		return provider
+	}
+	kafkaStream := func() *streamingv1alpha1.Stream {
+		stream := readyStream("numbers")
+		stream.Spec.Provider = streamingv1alpha1.StreamProviderReference{Kind: streamingv1alpha1.KafkaProviderKind, Name: "kafka"}
+		return stream
+	}
+	genericStream := func() *streamingv1alpha1.Stream {
+		stream := readyStream("numbers")
+		stream.Spec.Provider = streamingv1alpha1.StreamProviderReference{Kind: streamingv1alpha1.ProviderKind, Name: "generic"}
+		return stream
+	}
+	deployment := func(replicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "my-processor-processor-abcde", Generation: 1},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: replicas},
+		}
+	}
+	inProgress := &streamingv1alpha1.ReplayStatus{Token: "1"}
+
+	tests := []struct {
+		name              string
+		objects           []runtime.Object
+		status            *streamingv1alpha1.ReplayStatus
+		wantReplayed      corev1.ConditionStatus
+		wantReason        string
+		wantMessage       string
+		wantScaledToZero  bool
+		wantResets        []string
+		wantReplayedGroup []streamingv1alpha1.ReplayedGroup
+	}{{
+		name:             "scales down first",
+		objects:          []runtime.Object{kafkaStream(), kafkaProvider(true)},
+		wantReplayed:     corev1.ConditionUnknown,
+		wantMessage:      "scaling down processor",
+		wantScaledToZero: true,
+	}, {
+		name:             "waits for the deployment to scale down",
+		objects:          []runtime.Object{kafkaStream(), kafkaProvider(true), deployment(2)},
+		status:           inProgress,
+		wantReplayed:     corev1.ConditionUnknown,
+		wantMessage:      "waiting for the deployment to scale down",
+		wantScaledToZero: true,
+	}, {
+		name:             "waits for the provider",
+		objects:          []runtime.Object{kafkaStream(), kafkaProvider(false), deployment(0)},
+		status:           inProgress,
+		wantReplayed:     corev1.ConditionUnknown,
+		wantMessage:      `provider for stream "numbers" is not ready`,
+		wantScaledToZero: true,
+	}, {
+		name:         "resets the consumer groups",
+		objects:      []runtime.Object{kafkaStream(), kafkaProvider(true), deployment(0)},
+		status:       inProgress,
+		wantReplayed: corev1.ConditionTrue,
+		wantResets:   []string{"test-namespace/kafka-provisioner numbers my-processor earliest"},
+		wantReplayedGroup: []streamingv1alpha1.ReplayedGroup{
+			{Alias: "numbers", Group: "my-processor", ConsumerGroup: "my-processor-replayed", StartPosition: "earliest"},
+		},
+	}, {
+		name:         "generic providers do not support replay",
+		objects:      []runtime.Object{genericStream()},
+		wantReplayed: corev1.ConditionFalse,
+		wantReason:   "ReplayNotSupported",
+		wantMessage:  `Provider "generic" of stream "numbers" does not support replay`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			processor := newTestProcessor()
+			processor.Spec.Replay = &streamingv1alpha1.Replay{Token: "1", Position: "earliest"}
+			processor.Status.Replay = test.status.DeepCopy()
+			provisionerClient := &fakeStreamProvisionerClient{}
+			objects := append([]runtime.Object{processorImagesConfigMap(), processor}, test.objects...)
+			c := reconcileProcessor(t, &ProcessorReconciler{StreamProvisionerClient: provisionerClient}, objects...)
+
+			processor = getProcessor(t, c)
+			replayed := processor.Status.GetCondition(streamingv1alpha1.ProcessorConditionReplayed)
+			if replayed == nil {
+				t.Fatalf("processor has no Replayed condition")
+			}
+			if replayed.Status != test.wantReplayed || replayed.Message != test.wantMessage {
+				t.Errorf("Replayed = %s %q, want %s %q", replayed.Status, replayed.Message, test.wantReplayed, test.wantMessage)
+			}
+			if test.wantReason != "" && replayed.Reason != test.wantReason {
+				t.Errorf("Replayed reason = %q, want %q", replayed.Reason, test.wantReason)
+			}
+			if completed := processor.Status.Replay.CompletionTime != nil; completed != (test.wantReplayed == corev1.ConditionTrue) {
+				t.Errorf("replay completed = %v", completed)
+			}
+			if diff := cmp.Diff(test.wantResets, provisionerClient.resets); diff != "" {
+				t.Errorf("resets (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.wantReplayedGroup, processor.Status.ReplayedGroups); diff != "" {
+				t.Errorf("replayed groups (-want, +got) = %v", diff)
+			}
+			scaledObject := getScaledObject(t, c)
+			if scaledToZero := *scaledObject.Spec.MaxReplicaCount == 0; scaledToZero != test.wantScaledToZero {
+				t.Errorf("scaled to zero = %v, want %v", scaledToZero, test.wantScaledToZero)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"strings"
+	"text/template"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/tracker"
+)
+
+const (
+	providerDeploymentIndexField = ".metadata.providerDeploymentController"
+	providerServiceIndexField    = ".metadata.providerServiceController"
+)
+
+// ProviderReconciler reconciles a Provider object
+type ProviderReconciler struct {
+	client.Client
+	Log     logr.Logger
+	Scheme  *runtime.Scheme
+	Tracker tracker.Tracker
+}
+
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=providers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=providers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=providerclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+
+func (r *ProviderReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	var provider streamingv1alpha1.Provider
+	log := r.Log.WithValues("provider", req.NamespacedName)
+	return reconcileProvider(r.Client, log, req, &provider, &provider.Status, func(ctx context.Context, log logr.Logger) (ctrl.Result, error) {
+		return r.reconcile(ctx, log, &provider)
+	})
+}
+
+func (r *ProviderReconciler) reconcile(ctx context.Context, log logr.Logger, provider *streamingv1alpha1.Provider) (ctrl.Result, error) {
+
+	// Lookup and track the provider class to know what to run
+	var class streamingv1alpha1.ProviderClass
+	classKey := types.NamespacedName{Name: provider.Spec.Class}
+	r.Tracker.Track(
+		tracker.NewKey(streamingv1alpha1.GroupVersion.WithKind("ProviderClass"), classKey),
+		namespacedNamedFor(provider),
+	)
+	if err := r.Get(ctx, classKey, &class); err != nil {
+		if apierrs.IsNotFound(err) {
+			// the class is tracked, wait for it to be created
+			provider.Status.MarkClassNotFound(provider.Spec.Class)
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "unable to lookup ProviderClass", "class", provider.Spec.Class)
+		return ctrl.Result{}, err
+	}
+	provider.Status.MarkClassReady()
+
+	data := providerClassTemplateData{
+		Name:      provider.Name,
+		Namespace: provider.Namespace,
+		Config:    provider.Spec.Config,
+	}
+	gatewayEnv, err := renderProviderClassEnv(class.Spec.GatewayEnv, data)
+	if err != nil {
+		log.Error(err, "unable to render gateway environment", "class", class.Name)
+		return ctrl.Result{}, err
+	}
+	// the provisioner is rendered again once the gateway address is known
+	provisionerEnv, err := renderProviderClassEnv(class.Spec.ProvisionerEnv, data)
+	if err != nil {
+		log.Error(err, "unable to render provisioner environment", "class", class.Name)
+		return ctrl.Result{}, err
+	}
+
+	// Lookup and track referenced secrets to roll deployments when credentials change
+	secretNames := append(envSecretNames(gatewayEnv), envSecretNames(provisionerEnv)...)
+	secretsChecksum, err := secretsChecksum(ctx, r.Client, r.Tracker, namespacedNamedFor(provider), secretNames)
+	if err != nil {
+		log.Error(err, "unable to lookup referenced secrets")
+		return ctrl.Result{}, err
+	}
+
+	childTemplate := providerTemplate{
+		kind:                 class.Name,
+		labelKey:             streamingv1alpha1.ProviderLabelKey,
+		gatewayLabelKey:      streamingv1alpha1.ProviderGatewayLabelKey,
+		provisionerLabelKey:  streamingv1alpha1.ProviderProvisionerLabelKey,
+		provisioner:          class.Name + "-provisioner",
+		deploymentIndexField: providerDeploymentIndexField,
+		serviceIndexField:    providerServiceIndexField,
+
+		gatewayImage:    class.Spec.GatewayImage,
+		gatewayEnv:      append([]corev1.EnvVar{{Name: "storage_records_type", Value: class.Spec.RecordsStorageType}}, gatewayEnv...),
+		positionStorage: provider.Spec.PositionStorage,
+
+		provisionerImage: class.Spec.ProvisionerImage,
+		provisionerEnv: func(gateway string) ([]corev1.EnvVar, error) {
+			data.Gateway = gateway
+			return renderProviderClassEnv(class.Spec.ProvisionerEnv, data)
+		},
+
+		podAnnotations: secretsChecksumAnnotations(secretsChecksum),
+	}
+	if err := reconcileProviderChildren(ctx, log, r.Client, r.Scheme, provider, &provider.Status, childTemplate); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// providerClassTemplateData is available to the environment templates of a
+// ProviderClass
+type providerClassTemplateData struct {
+	Name      string
+	Namespace string
+	Config    map[string]string
+	// Gateway is the address of the gateway, only set for the provisioner
+	Gateway string
+}
+
+// renderProviderClassEnv executes the templates in the env var values and
+// secret names. Referencing a missing config key is an error.
+func renderProviderClassEnv(env []corev1.EnvVar, data providerClassTemplateData) ([]corev1.EnvVar, error) {
+	rendered := make([]corev1.EnvVar, len(env))
+	for i := range env {
+		e := env[i].DeepCopy()
+		value, err := renderProviderClassTemplate(e.Name, e.Value, data)
+		if err != nil {
+			return nil, err
+		}
+		e.Value = value
+		if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
+			name, err := renderProviderClassTemplate(e.Name, e.ValueFrom.SecretKeyRef.Name, data)
+			if err != nil {
+				return nil, err
+			}
+			e.ValueFrom.SecretKeyRef.Name = name
+		}
+		rendered[i] = *e
+	}
+	return rendered, nil
+}
+
+func renderProviderClassTemplate(name, text string, data providerClassTemplateData) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	t, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// envSecretNames lists the secrets referenced by the env vars
+func envSecretNames(env []corev1.EnvVar) []string {
+	names := []string{}
+	for _, e := range env {
+		if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
+			names = append(names, e.ValueFrom.SecretKeyRef.Name)
+		}
+	}
+	return names
+}
+
+func (r *ProviderReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	enqueueTrackedClasses := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			requests := []reconcile.Request{}
+			key := tracker.NewKey(
+				streamingv1alpha1.GroupVersion.WithKind("ProviderClass"),
+				types.NamespacedName{Name: a.Meta.GetName()},
+			)
+			for _, item := range r.Tracker.Lookup(key) {
+				requests = append(requests, reconcile.Request{NamespacedName: item})
+			}
+			return requests
+		}),
+	}
+
+	builder, err := newProviderControllerManagedBy(mgr, &streamingv1alpha1.Provider{}, providerDeploymentIndexField, providerServiceIndexField)
+	if err != nil {
+		return err
+	}
+
+	return builder.
+		Watches(&source.Kind{Type: &streamingv1alpha1.ProviderClass{}}, enqueueTrackedClasses).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, enqueueTrackedSecrets(r.Tracker)).
+		Complete(r)
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+)
+
+func TestProviderReconcile(t *testing.T) {
+	provider := &streamingv1alpha1.Provider{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "my-provider"},
+		Spec: streamingv1alpha1.ProviderSpec{
+			Class:  "pulsar",
+			Config: map[string]string{"serviceURL": "pulsar://pulsar:6650"},
+		},
+	}
+	class := &streamingv1alpha1.ProviderClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "pulsar"},
+		Spec: streamingv1alpha1.ProviderClassSpec{
+			GatewayImage:       "gateway-image",
+			ProvisionerImage:   "provisioner-image",
+			RecordsStorageType: "PULSAR",
+			GatewayEnv: []corev1.EnvVar{
+				{Name: "pulsar_serviceUrl", Value: "{{ .Config.serviceURL }}"},
+				{Name: "PULSAR_AUTH_TOKEN", ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "{{ .Name }}-token"},
+						Key:                  "token",
+					},
+				}},
+			},
+			ProvisionerEnv: []corev1.EnvVar{
+				{Name: "BROKER", Value: "{{ .Config.serviceURL }}"},
+				{Name: "NAMESPACE", Value: "{{ .Namespace }}"},
+			},
+		},
+	}
+	token := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "my-provider-token"},
+		Data:       map[string][]byte{"token": []byte("secret")},
+	}
+	tokenRef := &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "my-provider-token"},
+			Key:                  "token",
+		},
+	}
+
+	tests := []struct {
+		name               string
+		objects            []runtime.Object
+		wantErr            bool
+		wantClassReady     corev1.ConditionStatus
+		wantGatewayEnv     []corev1.EnvVar
+		wantProvisionerEnv []corev1.EnvVar
+	}{{
+		name:           "class not found",
+		objects:        []runtime.Object{provider},
+		wantClassReady: corev1.ConditionFalse,
+	}, {
+		name:           "renders the class templates",
+		objects:        []runtime.Object{provider, class, token},
+		wantClassReady: corev1.ConditionTrue,
+		wantGatewayEnv: []corev1.EnvVar{
+			{Name: "storage_records_type", Value: "PULSAR"},
+			{Name: "pulsar_serviceUrl", Value: "pulsar://pulsar:6650"},
+			{Name: "PULSAR_AUTH_TOKEN", ValueFrom: tokenRef},
+			{Name: "storage_positions_type", Value: "MEMORY"},
+		},
+		wantProvisionerEnv: []corev1.EnvVar{
+			{Name: "GATEWAY", Value: "my-provider-pulsar-gateway-00002.test-namespace:6565"},
+			{Name: "BROKER", Value: "pulsar://pulsar:6650"},
+			{Name: "NAMESPACE", Value: testNamespace},
+		},
+	}, {
+		name: "missing config key",
+		objects: []runtime.Object{
+			provider,
+			func() *streamingv1alpha1.ProviderClass {
+				class := class.DeepCopy()
+				class.Spec.GatewayEnv = []corev1.EnvVar{{Name: "pulsar_adminUrl", Value: "{{ .Config.adminURL }}"}}
+				return class
+			}(),
+		},
+		wantErr:        true,
+		wantClassReady: corev1.ConditionTrue,
+	}, {
+		name:    "missing secret",
+		objects: []runtime.Object{provider, class},
+		wantErr: true,
+		// the class was found before the secret lookup failed
+		wantClassReady: corev1.ConditionTrue,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newFakeClient(test.objects...)
+			r := &ProviderReconciler{
+				Client:  c,
+				Log:     zap.Logger(true),
+				Scheme:  scheme.Scheme,
+				Tracker: newTestTracker(),
+			}
+			key := types.NamespacedName{Namespace: testNamespace, Name: "my-provider"}
+			_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Reconcile() error = %v, wantErr %v", err, test.wantErr)
+			}
+
+			var actual streamingv1alpha1.Provider
+			if err := c.Get(context.Background(), key, &actual); err != nil {
+				t.Fatalf("Get() unexpected error: %v", err)
+			}
+			if got := actual.Status.GetCondition(streamingv1alpha1.ProviderConditionClassReady).Status; got != test.wantClassReady {
+				t.Errorf("ClassReady = %v, want %v", got, test.wantClassReady)
+			}
+
+			gatewayEnv, provisionerEnv := providerChildrenEnv(t, c, streamingv1alpha1.ProviderGatewayLabelKey, streamingv1alpha1.ProviderProvisionerLabelKey)
+			if diff := cmp.Diff(test.wantGatewayEnv, gatewayEnv); diff != "" {
+				t.Errorf("gateway env (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.wantProvisionerEnv, provisionerEnv); diff != "" {
+				t.Errorf("provisioner env (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+// providerChildrenEnv returns the environment of the gateway and provisioner
+// deployments, nil when a deployment was not created
+func providerChildrenEnv(t *testing.T, c client.Client, gatewayLabelKey, provisionerLabelKey string) ([]corev1.EnvVar, []corev1.EnvVar) {
+	var deployments appsv1.DeploymentList
+	if err := c.List(context.Background(), &deployments, client.InNamespace(testNamespace)); err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	var gatewayEnv, provisionerEnv []corev1.EnvVar
+	for _, deployment := range deployments.Items {
+		env := deployment.Spec.Template.Spec.Containers[0].Env
+		if _, ok := deployment.Labels[gatewayLabelKey]; ok {
+			gatewayEnv = env
+		}
+		if _, ok := deployment.Labels[provisionerLabelKey]; ok {
+			provisionerEnv = env
+		}
+	}
+	return gatewayEnv, provisionerEnv
+}
@@ -0,0 +1,574 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/projectriff/system/pkg/apis"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/controllers"
+	"github.com/projectriff/system/pkg/tracker"
+)
+
+// providerOwner is the provider resource owning the gateway and provisioner
+type providerOwner interface {
+	metav1.Object
+	runtime.Object
+}
+
+// providerResource is a provider of any kind, as fetched by reconcileProvider
+type providerResource interface {
+	providerOwner
+	apis.Resource
+	Default()
+}
+
+// reconcileProvider fetches the provider for the request, defaults it and
+// hands it to the kind specific reconcile. The status is updated when it
+// changed. The provider and status must point into the same resource.
+func reconcileProvider(c client.Client, log logr.Logger, req ctrl.Request, provider providerResource, status *streamingv1alpha1.ProviderStatus, reconcile func(ctx context.Context, log logr.Logger) (ctrl.Result, error)) (ctrl.Result, error) {
+	ctx := context.Background()
+	kind := provider.GetGroupVersionKind().Kind
+
+	if err := c.Get(ctx, req.NamespacedName, provider); err != nil {
+		log.Error(err, fmt.Sprintf("unable to fetch %s", kind))
+		// we'll ignore not-found errors, since they can't be fixed by an immediate
+		// requeue (we'll need to wait for a new notification), and we can get them
+		// on deleted requests.
+		return ctrl.Result{}, ignoreNotFound(err)
+	}
+
+	originalStatus := status.DeepCopy()
+	provider.Default()
+	status.InitializeConditions()
+
+	result, err := reconcile(ctx, log)
+
+	// check if status has changed before updating, unless requeued
+	if !result.Requeue && !equality.Semantic.DeepEqual(status, originalStatus) {
+		// update status
+		log.Info("updating provider status", "diff", cmp.Diff(originalStatus, status))
+		if updateErr := c.Status().Update(ctx, provider); updateErr != nil {
+			log.Error(updateErr, fmt.Sprintf("unable to update %s status", kind), strings.ToLower(kind), provider)
+			return ctrl.Result{Requeue: true}, updateErr
+		}
+	}
+
+	return result, err
+}
+
+// newProviderControllerManagedBy indexes the gateway and provisioner children
+// of a provider kind and watches them. The caller adds the watches for the
+// resources it tracks before completing the controller.
+func newProviderControllerManagedBy(mgr ctrl.Manager, provider providerResource, deploymentIndexField, serviceIndexField string) (*ctrl.Builder, error) {
+	if err := controllers.IndexControllersOfType(mgr, deploymentIndexField, provider, &appsv1.Deployment{}); err != nil {
+		return nil, err
+	}
+	if err := controllers.IndexControllersOfType(mgr, serviceIndexField, provider, &corev1.Service{}); err != nil {
+		return nil, err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(provider).
+		Owns(&appsv1.Deployment{}).
+		Owns(&corev1.Service{}), nil
+}
+
+// providerTemplate describes the gateway and provisioner run for a provider.
+// Each provider kind fills in the template from its own spec, the children
+// are then reconciled the same way for every kind.
+type providerTemplate struct {
+	// kind names the children, like kafka in <name>-kafka-gateway-
+	kind string
+	// labelKey, gatewayLabelKey and provisionerLabelKey label the children
+	// with the provider name
+	labelKey            string
+	gatewayLabelKey     string
+	provisionerLabelKey string
+	// provisioner is the value of the ProvisionerLabelKey label
+	provisioner string
+	// deploymentIndexField and serviceIndexField index the children by their
+	// controlling provider
+	deploymentIndexField string
+	serviceIndexField    string
+
+	gatewayImage string
+	// gatewayEnv configures the gateway's records storage, position storage
+	// is added from positionStorage, if any
+	gatewayEnv      []corev1.EnvVar
+	positionStorage *streamingv1alpha1.PositionStorage
+	// gatewayVolumes and gatewayVolumeMounts are only used by the gateway
+	gatewayVolumes      []corev1.Volume
+	gatewayVolumeMounts []corev1.VolumeMount
+	// gatewayStrategy replaces the gateway pods, defaults to a rolling update
+	gatewayStrategy appsv1.DeploymentStrategy
+
+	provisionerImage string
+	// provisionerEnv configures the provisioner for the gateway at the
+	// address, GATEWAY is always set
+	provisionerEnv func(gateway string) ([]corev1.EnvVar, error)
+
+	// volumes and volumeMounts are shared by the gateway and provisioner
+	volumes      []corev1.Volume
+	volumeMounts []corev1.VolumeMount
+	// podAnnotations roll the deployments when they change
+	podAnnotations map[string]string
+}
+
+// providerImages looks up and tracks the configMap holding the gateway and
+// provisioner images for a provider kind
+func providerImages(ctx context.Context, c client.Client, t tracker.Tracker, namespace, name string, owner types.NamespacedName) (string, string, error) {
+	cm := corev1.ConfigMap{}
+	cmKey := types.NamespacedName{Namespace: namespace, Name: name}
+	// track config map for new images
+	t.Track(tracker.NewKey(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, cmKey), owner)
+	if err := c.Get(ctx, cmKey, &cm); err != nil {
+		return "", "", err
+	}
+	gatewayImg := cm.Data[gatewayImageKey]
+	if gatewayImg == "" {
+		return "", "", fmt.Errorf("missing gateway image configuration")
+	}
+	provisionerImg := cm.Data[provisionerImageKey]
+	if provisionerImg == "" {
+		return "", "", fmt.Errorf("missing provisioner image configuration")
+	}
+	return gatewayImg, provisionerImg, nil
+}
+
+// reconcileProviderChildren creates or updates the gateway and provisioner of
+// a provider, reflecting their state in the provider's status
+func reconcileProviderChildren(ctx context.Context, log logr.Logger, c client.Client, scheme *runtime.Scheme, owner providerOwner, status *streamingv1alpha1.ProviderStatus, template providerTemplate) error {
+	// Reconcile deployment for gateway
+	gatewayDeployment, err := reconcileProviderDeployment(ctx, log.WithValues("component", "gateway"), c, template, template.gatewayLabelKey, owner, func() (*appsv1.Deployment, error) {
+		return constructProviderGatewayDeployment(scheme, owner, template)
+	})
+	if err != nil {
+		log.Error(err, "unable to reconcile gateway Deployment")
+		return err
+	}
+	status.GatewayDeploymentName = gatewayDeployment.Name
+	if template.positionStorage != nil {
+		status.PositionStorageType = template.positionStorage.Type
+	}
+	status.PropagateGatewayDeploymentStatus(&gatewayDeployment.Status)
+
+	// Reconcile service for gateway
+	gatewayService, err := reconcileProviderService(ctx, log.WithValues("component", "gateway"), c, template, template.gatewayLabelKey, owner, func() (*corev1.Service, error) {
+		return constructProviderGatewayService(scheme, owner, template)
+	})
+	if err != nil {
+		log.Error(err, "unable to reconcile gateway Service")
+		return err
+	}
+	status.GatewayServiceName = gatewayService.Name
+	status.PropagateGatewayServiceStatus(&gatewayService.Status)
+
+	// Reconcile deployment for provisioner
+	gateway := fmt.Sprintf("%s.%s:6565", gatewayService.Name, owner.GetNamespace()) // TODO get port number from svc lookup?
+	provisionerDeployment, err := reconcileProviderDeployment(ctx, log.WithValues("component", "provisioner"), c, template, template.provisionerLabelKey, owner, func() (*appsv1.Deployment, error) {
+		return constructProviderProvisionerDeployment(scheme, owner, template, gateway)
+	})
+	if err != nil {
+		log.Error(err, "unable to reconcile provisioner Deployment")
+		return err
+	}
+	status.ProvisionerDeploymentName = provisionerDeployment.Name
+	status.PropagateProvisionerDeploymentStatus(&provisionerDeployment.Status)
+
+	// Reconcile service for provisioner
+	provisionerService, err := reconcileProviderService(ctx, log.WithValues("component", "provisioner"), c, template, template.provisionerLabelKey, owner, func() (*corev1.Service, error) {
+		return constructProviderProvisionerService(scheme, owner, template)
+	})
+	if err != nil {
+		log.Error(err, "unable to reconcile provisioner Service")
+		return err
+	}
+	status.ProvisionerServiceName = provisionerService.Name
+	status.PropagateProvisionerServiceStatus(&provisionerService.Status)
+
+	return nil
+}
+
+func reconcileProviderDeployment(ctx context.Context, log logr.Logger, c client.Client, template providerTemplate, selectorLabelKey string, owner providerOwner, construct func() (*appsv1.Deployment, error)) (*appsv1.Deployment, error) {
+	var actualDeployment appsv1.Deployment
+	var childDeployments appsv1.DeploymentList
+	if err := c.List(ctx, &childDeployments,
+		client.InNamespace(owner.GetNamespace()),
+		client.MatchingLabels(map[string]string{selectorLabelKey: owner.GetName()}),
+		client.MatchingField(template.deploymentIndexField, owner.GetName())); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childDeployments.Items) == 1 {
+		actualDeployment = childDeployments.Items[0]
+	} else if len(childDeployments.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraDeployment := range childDeployments.Items {
+			log.Info("deleting extra deployment", "deployment", extraDeployment)
+			if err := c.Delete(ctx, &extraDeployment); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	desiredDeployment, err := construct()
+	if err != nil {
+		return nil, err
+	}
+
+	// create deployment if it doesn't exist
+	if actualDeployment.Name == "" {
+		log.Info("creating deployment", "spec", desiredDeployment.Spec)
+		if err := c.Create(ctx, desiredDeployment); err != nil {
+			log.Error(err, "unable to create Deployment for provider", "deployment", desiredDeployment)
+			return nil, err
+		}
+		return desiredDeployment, nil
+	}
+
+	// overwrite fields that should not be mutated
+	desiredDeployment.Spec.Replicas = actualDeployment.Spec.Replicas
+
+	if providerDeploymentSemanticEquals(desiredDeployment, &actualDeployment) {
+		// deployment is unchanged
+		return &actualDeployment, nil
+	}
+
+	// update deployment with desired changes
+
+	deployment := actualDeployment.DeepCopy()
+	deployment.ObjectMeta.Labels = desiredDeployment.ObjectMeta.Labels
+	deployment.Spec = desiredDeployment.Spec
+	log.Info("reconciling deployment", "diff", cmp.Diff(actualDeployment.Spec, deployment.Spec))
+	if err := c.Update(ctx, deployment); err != nil {
+		log.Error(err, "unable to update Deployment for provider", "deployment", deployment)
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+func providerDeploymentSemanticEquals(desiredDeployment, deployment *appsv1.Deployment) bool {
+	return equality.Semantic.DeepEqual(desiredDeployment.Spec, deployment.Spec) &&
+		equality.Semantic.DeepEqual(desiredDeployment.ObjectMeta.Labels, deployment.ObjectMeta.Labels)
+}
+
+func reconcileProviderService(ctx context.Context, log logr.Logger, c client.Client, template providerTemplate, selectorLabelKey string, owner providerOwner, construct func() (*corev1.Service, error)) (*corev1.Service, error) {
+	var actualService corev1.Service
+	var childServices corev1.ServiceList
+	if err := c.List(ctx, &childServices,
+		client.InNamespace(owner.GetNamespace()),
+		client.MatchingLabels(map[string]string{selectorLabelKey: owner.GetName()}),
+		client.MatchingField(template.serviceIndexField, owner.GetName())); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childServices.Items) == 1 {
+		actualService = childServices.Items[0]
+	} else if len(childServices.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraService := range childServices.Items {
+			log.Info("deleting extra service", "service", extraService)
+			if err := c.Delete(ctx, &extraService); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	desiredService, err := construct()
+	if err != nil {
+		return nil, err
+	}
+
+	// create service if it doesn't exist
+	if actualService.Name == "" {
+		log.Info("creating service", "spec", desiredService.Spec)
+		if err := c.Create(ctx, desiredService); err != nil {
+			log.Error(err, "unable to create Service for provider", "service", desiredService)
+			return nil, err
+		}
+		return desiredService, nil
+	}
+
+	// overwrite fields that should not be mutated
+	desiredService.Spec.ClusterIP = actualService.Spec.ClusterIP
+
+	if providerServiceSemanticEquals(desiredService, &actualService) {
+		// service is unchanged
+		return &actualService, nil
+	}
+
+	// update service with desired changes
+	service := actualService.DeepCopy()
+	service.ObjectMeta.Labels = desiredService.ObjectMeta.Labels
+	service.Spec = desiredService.Spec
+	log.Info("reconciling service", "diff", cmp.Diff(actualService.Spec, service.Spec))
+	if err := c.Update(ctx, service); err != nil {
+		log.Error(err, "unable to update Service for provider", "service", service)
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func providerServiceSemanticEquals(desiredService, service *corev1.Service) bool {
+	return equality.Semantic.DeepEqual(desiredService.Spec, service.Spec) &&
+		equality.Semantic.DeepEqual(desiredService.ObjectMeta.Labels, service.ObjectMeta.Labels)
+}
+
+func constructProviderGatewayDeployment(scheme *runtime.Scheme, owner providerOwner, template providerTemplate) (*appsv1.Deployment, error) {
+	labels := constructProviderGatewayLabels(owner, template)
+
+	env := append([]corev1.EnvVar{}, template.gatewayEnv...)
+	if template.positionStorage != nil {
+		env = append(env, positionStorageEnvironment(template.positionStorage)...)
+	}
+	volumes, volumeMounts := template.volumes, template.volumeMounts
+	if len(template.gatewayVolumes) != 0 {
+		volumes = append(append([]corev1.Volume{}, volumes...), template.gatewayVolumes...)
+		volumeMounts = append(append([]corev1.VolumeMount{}, volumeMounts...), template.gatewayVolumeMounts...)
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       labels,
+			Annotations:  make(map[string]string),
+			GenerateName: fmt.Sprintf("%s-%s-gateway-", owner.GetName(), template.kind),
+			Namespace:    owner.GetNamespace(),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					template.gatewayLabelKey: owner.GetName(),
+				},
+			},
+			Strategy: template.gatewayStrategy,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      labels,
+					Annotations: template.podAnnotations,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:            "gateway",
+							Image:           template.gatewayImage,
+							ImagePullPolicy: corev1.PullAlways,
+							Env:             env,
+							VolumeMounts:    volumeMounts,
+						},
+					},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(owner, deployment, scheme); err != nil {
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+func constructProviderGatewayService(scheme *runtime.Scheme, owner providerOwner, template providerTemplate) (*corev1.Service, error) {
+	labels := constructProviderGatewayLabels(owner, template)
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       labels,
+			Annotations:  make(map[string]string),
+			GenerateName: fmt.Sprintf("%s-%s-gateway-", owner.GetName(), template.kind),
+			Namespace:    owner.GetNamespace(),
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "gateway", Port: 6565},
+			},
+			Selector: map[string]string{
+				template.gatewayLabelKey: owner.GetName(),
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(owner, service, scheme); err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func constructProviderGatewayLabels(owner providerOwner, template providerTemplate) map[string]string {
+	labels := make(map[string]string, len(owner.GetLabels())+2)
+	// pass through existing labels
+	for k, v := range owner.GetLabels() {
+		labels[k] = v
+	}
+
+	labels[template.labelKey] = owner.GetName()
+	labels[template.gatewayLabelKey] = owner.GetName()
+
+	return labels
+}
+
+func constructProviderProvisionerDeployment(scheme *runtime.Scheme, owner providerOwner, template providerTemplate, gateway string) (*appsv1.Deployment, error) {
+	labels := constructProviderProvisionerLabels(owner, template)
+
+	env := []corev1.EnvVar{
+		{Name: "GATEWAY", Value: gateway},
+	}
+	if template.provisionerEnv != nil {
+		provisionerEnv, err := template.provisionerEnv(gateway)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, provisionerEnv...)
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       labels,
+			Annotations:  make(map[string]string),
+			GenerateName: fmt.Sprintf("%s-%s-provisioner-", owner.GetName(), template.kind),
+			Namespace:    owner.GetNamespace(),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					template.provisionerLabelKey: owner.GetName(),
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      labels,
+					Annotations: template.podAnnotations,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:            "main",
+							Image:           template.provisionerImage,
+							ImagePullPolicy: corev1.PullAlways,
+							Env:             env,
+							VolumeMounts:    template.volumeMounts,
+						},
+					},
+					Volumes: template.volumes,
+				},
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(owner, deployment, scheme); err != nil {
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+func constructProviderProvisionerService(scheme *runtime.Scheme, owner providerOwner, template providerTemplate) (*corev1.Service, error) {
+	labels := constructProviderProvisionerLabels(owner, template)
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      labels,
+			Annotations: make(map[string]string),
+			Name:        fmt.Sprintf("%s-%s-provisioner", owner.GetName(), template.kind),
+			Namespace:   owner.GetNamespace(),
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromInt(8080)},
+			},
+			Selector: map[string]string{
+				template.provisionerLabelKey: owner.GetName(),
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(owner, service, scheme); err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func constructProviderProvisionerLabels(owner providerOwner, template providerTemplate) map[string]string {
+	labels := make(map[string]string, len(owner.GetLabels())+3)
+	// pass through existing labels
+	for k, v := range owner.GetLabels() {
+		labels[k] = v
+	}
+
+	labels[template.labelKey] = owner.GetName()
+	labels[template.provisionerLabelKey] = owner.GetName()
+	labels[streamingv1alpha1.ProvisionerLabelKey] = template.provisioner
+
+	return labels
+}
+
+// enqueueTrackedConfigMap enqueues the providers tracking the images configMap
+func enqueueTrackedConfigMap(t tracker.Tracker, namespace, name string) handler.EventHandler {
+	return &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			requests := []reconcile.Request{}
+			if a.Meta.GetNamespace() == namespace && a.Meta.GetName() == name {
+				key := tracker.NewKey(
+					schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+					types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: a.Meta.GetName()},
+				)
+				for _, item := range t.Lookup(key) {
+					requests = append(requests, reconcile.Request{NamespacedName: item})
+				}
+			}
+			return requests
+		}),
+	}
+}
+
+// enqueueTrackedSecrets enqueues the providers tracking a secret for their
+// credentials
+func enqueueTrackedSecrets(t tracker.Tracker) handler.EventHandler {
+	return &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			requests := []reconcile.Request{}
+			key := tracker.NewKey(
+				schema.GroupVersionKind{Version: "v1", Kind: "Secret"},
+				types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: a.Meta.GetName()},
+			)
+			for _, item := range t.Lookup(key) {
+				requests = append(requests, reconcile.Request{NamespacedName: item})
+			}
+			return requests
+		}),
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+)
+
+func TestPulsarProviderReconcile(t *testing.T) {
+	images := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testSystemNamespace, Name: pulsarProviderImages},
+		Data: map[string]string{
+			gatewayImageKey:     "gateway-image",
+			provisionerImageKey: "provisioner-image",
+		},
+	}
+	token := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "pulsar-token"},
+		Data:       map[string][]byte{"token": []byte("secret")},
+	}
+	ca := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "pulsar-ca"},
+		Data:       map[string][]byte{"ca.crt": []byte("ca")},
+	}
+	client := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "pulsar-client"},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")},
+	}
+	tokenRef := &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "pulsar-token"},
+			Key:                  "token",
+		},
+	}
+
+	tests := []struct {
+		name               string
+		spec               streamingv1alpha1.PulsarProviderSpec
+		objects            []runtime.Object
+		wantGatewayEnv     []corev1.EnvVar
+		wantProvisionerEnv []corev1.EnvVar
+	}{{
+		name: "plaintext",
+		spec: streamingv1alpha1.PulsarProviderSpec{
+			ServiceURL: "pulsar://pulsar:6650",
+		},
+		objects: []runtime.Object{images},
+		wantGatewayEnv: []corev1.EnvVar{
+			{Name: "storage_records_type", Value: "PULSAR"},
+			{Name: "pulsar_serviceUrl", Value: "pulsar://pulsar:6650"},
+			{Name: "storage_positions_type", Value: "MEMORY"},
+		},
+		wantProvisionerEnv: []corev1.EnvVar{
+			{Name: "GATEWAY", Value: "my-provider-pulsar-gateway-00002.test-namespace:6565"},
+			{Name: "ADMIN_URL", Value: "http://pulsar:8080"},
+		},
+	}, {
+		name: "client certificate",
+		spec: streamingv1alpha1.PulsarProviderSpec{
+			ServiceURL: "pulsar+ssl://pulsar:6651",
+			TLS: &streamingv1alpha1.ProviderTLS{
+				CASecretRef:   &corev1.LocalObjectReference{Name: "pulsar-ca"},
+				CertSecretRef: &corev1.LocalObjectReference{Name: "pulsar-client"},
+			},
+		},
+		objects: []runtime.Object{images, ca, client},
+		wantGatewayEnv: []corev1.EnvVar{
+			{Name: "storage_records_type", Value: "PULSAR"},
+			{Name: "pulsar_serviceUrl", Value: "pulsar+ssl://pulsar:6651"},
+			{Name: "pulsar_tlsTrustCertsFilePath", Value: "/var/riff/tls/ca/ca.crt"},
+			{Name: "pulsar_authPluginClassName", Value: "org.apache.pulsar.client.impl.auth.AuthenticationTls"},
+			{Name: "SPRING_APPLICATION_JSON", Value: `{"pulsar":{"authPluginParams":{"tlsCertFile":"/var/riff/tls/cert/tls.crt","tlsKeyFile":"/var/riff/tls/cert/tls.key"}}}`},
+			{Name: "storage_positions_type", Value: "MEMORY"},
+		},
+		wantProvisionerEnv: []corev1.EnvVar{
+			{Name: "GATEWAY", Value: "my-provider-pulsar-gateway-00002.test-namespace:6565"},
+			{Name: "ADMIN_URL", Value: "https://pulsar:8443"},
+			{Name: "TLS_CA", Value: "/var/riff/tls/ca/ca.crt"},
+			{Name: "TLS_CERT", Value: "/var/riff/tls/cert/tls.crt"},
+			{Name: "TLS_KEY", Value: "/var/riff/tls/cert/tls.key"},
+		},
+	}, {
+		name: "token",
+		spec: streamingv1alpha1.PulsarProviderSpec{
+			ServiceURL:     "pulsar+ssl://pulsar:6651",
+			AdminURL:       "https://pulsar-admin:443",
+			TLS:            &streamingv1alpha1.ProviderTLS{CASecretRef: &corev1.LocalObjectReference{Name: "pulsar-ca"}},
+			TokenSecretRef: &corev1.LocalObjectReference{Name: "pulsar-token"},
+		},
+		objects: []runtime.Object{images, ca, token},
+		wantGatewayEnv: []corev1.EnvVar{
+			{Name: "storage_records_type", Value: "PULSAR"},
+			{Name: "pulsar_serviceUrl", Value: "pulsar+ssl://pulsar:6651"},
+			{Name: "pulsar_tlsTrustCertsFilePath", Value: "/var/riff/tls/ca/ca.crt"},
+			{Name: "PULSAR_AUTH_TOKEN", ValueFrom: tokenRef},
+			{Name: "pulsar_authPluginClassName", Value: "org.apache.pulsar.client.impl.auth.AuthenticationToken"},
+			{Name: "SPRING_APPLICATION_JSON", Value: `{"pulsar":{"authPluginParams":{"token":"$(PULSAR_AUTH_TOKEN)"}}}`},
+			{Name: "storage_positions_type", Value: "MEMORY"},
+		},
+		wantProvisionerEnv: []corev1.EnvVar{
+			{Name: "GATEWAY", Value: "my-provider-pulsar-gateway-00002.test-namespace:6565"},
+			{Name: "ADMIN_URL", Value: "https://pulsar-admin:443"},
+			{Name: "TLS_CA", Value: "/var/riff/tls/ca/ca.crt"},
+			{Name: "AUTH_TOKEN", ValueFrom: tokenRef},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider := &streamingv1alpha1.PulsarProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "my-provider"},
+				Spec:       test.spec,
+			}
+			c := newFakeClient(append(test.objects, provider)...)
+			r := &PulsarProviderReconciler{
+				Client:    c,
+				Log:       zap.Logger(true),
+				Scheme:    scheme.Scheme,
+				Tracker:   newTestTracker(),
+				Namespace: testSystemNamespace,
+			}
+			key := types.NamespacedName{Namespace: testNamespace, Name: "my-provider"}
+			if _, err := r.Reconcile(ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() unexpected error: %v", err)
+			}
+
+			gatewayEnv, provisionerEnv := providerChildrenEnv(t, c, streamingv1alpha1.PulsarProviderGatewayLabelKey, streamingv1alpha1.PulsarProviderProvisionerLabelKey)
+			if diff := cmp.Diff(test.wantGatewayEnv, gatewayEnv); diff != "" {
+				t.Errorf("gateway env (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.wantProvisionerEnv, provisionerEnv); diff != "" {
+				t.Errorf("provisioner env (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ package streaming
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
@@ -26,11 +27,18 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	"github.com/projectriff/system/pkg/apis"
 	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
 	"github.com/projectriff/system/pkg/controllers"
+	"github.com/projectriff/system/pkg/tracker"
 )
 
 const (
@@ -38,12 +46,20 @@ const (
 	bindingSecretIndexField   = ".metadata.bindingSecretController"
 )
 
+// streamFinalizer blocks the removal of a Stream until the provisioner has
+// deleted the backing topic
+var streamFinalizer = streamingv1alpha1.GroupVersion.Group + "/stream-provisioner"
+
 // StreamReconciler reconciles a Stream object
 type StreamReconciler struct {
 	client.Client
 	Log                     logr.Logger
 	Scheme                  *runtime.Scheme
+	Tracker                 tracker.Tracker
 	StreamProvisionerClient StreamProvisionerClient
+
+	// referrers are the installed kinds that may bind a stream
+	referrers []streamReferrer
 }
 
 // For
@@ -52,6 +68,12 @@ type StreamReconciler struct {
 // Owns
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// Watches
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=processors;subscriptions;httpsources,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core.projectriff.io,resources=deployers;schedules,verbs=get;list;watch
+// +kubebuilder:rbac:groups=knative.projectriff.io,resources=deployers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=kafkaproviders;pulsarproviders;inmemoryproviders;providers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
 
 func (r *StreamReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -83,7 +105,16 @@ func (r *StreamReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 
 func (r *StreamReconciler) reconcile(ctx context.Context, log logr.Logger, stream *streamingv1alpha1.Stream) (ctrl.Result, error) {
 	if stream.GetDeletionTimestamp() != nil {
-		return ctrl.Result{}, nil
+		return r.finalize(ctx, log, stream)
+	}
+
+	// make sure the topic is deprovisioned before the stream goes away
+	if !hasFinalizer(stream, streamFinalizer) {
+		stream.SetFinalizers(append(stream.GetFinalizers(), streamFinalizer))
+		if err := r.Update(ctx, stream); err != nil {
+			log.Error(err, "unable to add finalizer to Stream", "stream", stream)
+			return ctrl.Result{}, err
+		}
 	}
 
 	// We may be reading a version of the object that was stored at an older version
@@ -94,9 +125,42 @@ func (r *StreamReconciler) reconcile(ctx context.Context, log logr.Logger, strea
 
 	stream.Status.InitializeConditions()
 
+	// resolve the provisioner from the provider, we'll be notified as the
+	// provider changes
+	streamNSName := namespacedNamedFor(stream)
+	r.Tracker.Track(streamProviderKey(stream), streamNSName)
+	provider, err := getStreamProvider(ctx, r.Client, stream)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !provider.exists() {
+		stream.Status.MarkProviderNotFound(stream.Spec.Provider.Kind, stream.Spec.Provider.Name)
+		return ctrl.Result{}, nil
+	}
+	// shared providers select the namespaces they serve, track the namespace
+	// for label changes
+	r.Tracker.Track(
+		tracker.NewKey(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, types.NamespacedName{Name: stream.Namespace}),
+		streamNSName,
+	)
+	allowed, err := provider.allowsNamespace(ctx, r.Client, stream.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !allowed {
+		stream.Status.MarkProviderNotAllowed(stream.Spec.Provider.Kind, stream.Spec.Provider.Name, stream.Namespace)
+		return ctrl.Result{}, nil
+	}
+	provisioner := provider.provisionerService()
+	if !provider.isReady() || provisioner.Name == "" {
+		stream.Status.MarkProviderNotReady(fmt.Sprintf("%s %q is not ready", stream.Spec.Provider.Kind, stream.Spec.Provider.Name))
+		return ctrl.Result{}, nil
+	}
+	stream.Status.MarkProviderReady()
+
 	// delegate to the provider via its REST API
-	log.Info("calling provisioner for Stream", "provisioner", stream.Spec.Provider)
-	address, err := r.StreamProvisionerClient.ProvisionStream(stream)
+	log.Info("calling provisioner for Stream", "provisioner", provisioner)
+	address, err := r.StreamProvisionerClient.ProvisionStream(stream, provisioner)
 	if err != nil {
 		stream.Status.MarkStreamProvisionFailed(err.Error())
 		return ctrl.Result{Requeue: true}, err
@@ -139,6 +203,72 @@ func (r *StreamReconciler) reconcile(ctx context.Context, log logr.Logger, strea
 	return ctrl.Result{}, nil
 }
 
+func (r *StreamReconciler) finalize(ctx context.Context, log logr.Logger, stream *streamingv1alpha1.Stream) (ctrl.Result, error) {
+	if !hasFinalizer(stream, streamFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	// block deprovisioning while resources still bind the stream, we'll be
+	// notified as the resources are updated or deleted
+	references, err := referencesToStream(ctx, r.Client, r.referrers, stream)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(references) != 0 {
+		stream.Status.MarkStreamInUse(fmt.Sprintf("stream is referenced by: %s", strings.Join(references, ", ")))
+		return ctrl.Result{}, nil
+	}
+
+	r.Tracker.Track(streamProviderKey(stream), namespacedNamedFor(stream))
+	provider, err := getStreamProvider(ctx, r.Client, stream)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if stream.Status.IsDeprovisionUnsupported() {
+		// the topic is left for the operator to delete, the condition was
+		// recorded on a previous pass
+		log.Info("provisioner does not delete topics, releasing Stream without deprovisioning", "address", stream.Status.Address)
+	} else if provider.exists() {
+		provisioner := provider.provisionerService()
+		if provisioner.Name == "" {
+			// wait for the provider to expose its provisioner
+			stream.Status.MarkStreamDeprovisionFailed(fmt.Sprintf("%s %q has no provisioner", stream.Spec.Provider.Kind, stream.Spec.Provider.Name))
+			return ctrl.Result{}, nil
+		}
+		log.Info("calling provisioner to delete Stream", "provisioner", provisioner)
+		if err := r.StreamProvisionerClient.DeprovisionStream(stream, provisioner); err == ErrDeprovisionUnsupported {
+			// record the topic to clean up before releasing the stream, we'll
+			// be notified as the status is updated
+			stream.Status.MarkStreamDeprovisionUnsupported(stream.Status.Address.Topic)
+			return ctrl.Result{}, nil
+		} else if err != nil {
+			stream.Status.MarkStreamDeprovisionFailed(err.Error())
+			return ctrl.Result{}, err
+		}
+	} else {
+		// without a provider there is nothing left to deprovision the topic
+		log.Info("provider not found, releasing Stream without deprovisioning", "provider", stream.Spec.Provider)
+	}
+
+	removeFinalizer(stream, streamFinalizer)
+	if err := r.Update(ctx, stream); err != nil {
+		log.Error(err, "unable to remove finalizer from Stream", "stream", stream)
+		return ctrl.Result{}, ignoreNotFound(err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func streamBindingsForProcessor(processor *streamingv1alpha1.Processor) []streamingv1alpha1.StreamBinding {
+	bindings := make([]streamingv1alpha1.StreamBinding, 0, len(processor.Spec.Inputs)+len(processor.Spec.Outputs))
+	bindings = append(bindings, processor.Spec.Inputs...)
+	bindings = append(bindings, processor.Spec.Outputs...)
+	if policy := processor.Spec.ErrorPolicy; policy != nil && policy.DeadLetterStream != "" {
+		bindings = append(bindings, streamingv1alpha1.StreamBinding{Stream: policy.DeadLetterStream})
+	}
+	return bindings
+}
+
 func (r *StreamReconciler) reconcileChildBindingMetadata(ctx context.Context, log logr.Logger, stream *streamingv1alpha1.Stream) (*corev1.ConfigMap, error) {
 	var actualBindingMetadata corev1.ConfigMap
 	var childBindingMetadatas corev1.ConfigMapList
@@ -323,6 +453,22 @@ func (r *StreamReconciler) constructBindingSecret(stream *streamingv1alpha1.Stre
 }
 
 func (r *StreamReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	enqueueTrackedResources := func(t apis.Resource) handler.EventHandler {
+		return &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+				requests := []reconcile.Request{}
+				key := tracker.NewKey(
+					t.GetGroupVersionKind(),
+					types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: a.Meta.GetName()},
+				)
+				for _, item := range r.Tracker.Lookup(key) {
+					requests = append(requests, reconcile.Request{NamespacedName: item})
+				}
+				return requests
+			}),
+		}
+	}
+
 	if err := controllers.IndexControllersOfType(mgr, bindingMetadataIndexField, &streamingv1alpha1.Stream{}, &corev1.ConfigMap{}); err != nil {
 		return err
 	}
@@ -330,9 +476,40 @@ func (r *StreamReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	enqueueTrackedNamespace := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			requests := []reconcile.Request{}
+			key := tracker.NewKey(
+				schema.GroupVersionKind{Version: "v1", Kind: "Namespace"},
+				types.NamespacedName{Name: a.Meta.GetName()},
+			)
+			for _, item := range r.Tracker.Lookup(key) {
+				requests = append(requests, reconcile.Request{NamespacedName: item})
+			}
+			return requests
+		}),
+	}
+
+	referrers, err := installedStreamReferrers(mgr)
+	if err != nil {
+		return err
+	}
+	r.referrers = referrers
+
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&streamingv1alpha1.Stream{}).
 		Owns(&corev1.ConfigMap{}).
-		Owns(&corev1.Secret{}).
+		Owns(&corev1.Secret{})
+	for _, referrer := range referrers {
+		// watch for mutations of resources binding streams to release streams
+		// pending deletion
+		builder = builder.Watches(&source.Kind{Type: referrer.object.(runtime.Object)}, enqueueStreamsForReferrer(referrer))
+	}
+	return builder.
+		Watches(&source.Kind{Type: &streamingv1alpha1.KafkaProvider{}}, enqueueTrackedResources(&streamingv1alpha1.KafkaProvider{})).
+		Watches(&source.Kind{Type: &streamingv1alpha1.PulsarProvider{}}, enqueueTrackedResources(&streamingv1alpha1.PulsarProvider{})).
+		Watches(&source.Kind{Type: &streamingv1alpha1.InMemoryProvider{}}, enqueueTrackedResources(&streamingv1alpha1.InMemoryProvider{})).
+		Watches(&source.Kind{Type: &streamingv1alpha1.Provider{}}, enqueueTrackedResources(&streamingv1alpha1.Provider{})).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, enqueueTrackedNamespace).
 		Complete(r)
 }
@@ -0,0 +1,223 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/projectriff/system/pkg/apis"
+	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
+	knativev1alpha1 "github.com/projectriff/system/pkg/apis/knative/v1alpha1"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+)
+
+// recordingProvisionerClient records the streams provisioned and deleted
+type recordingProvisionerClient struct {
+	StreamProvisionerClient
+	deprovisionErr error
+	provisioned    []string
+	deprovisioned  []string
+}
+
+func (c *recordingProvisionerClient) ProvisionStream(stream *streamingv1alpha1.Stream, provisioner types.NamespacedName) (*streamingv1alpha1.StreamAddress, error) {
+	c.provisioned = append(c.provisioned, fmt.Sprintf("%s %s", provisioner, stream.Name))
+	return &streamingv1alpha1.StreamAddress{Gateway: "memory-gateway:6565", Topic: stream.Namespace + "_" + stream.Name}, nil
+}
+
+func (c *recordingProvisionerClient) DeprovisionStream(stream *streamingv1alpha1.Stream, provisioner types.NamespacedName) error {
+	c.deprovisioned = append(c.deprovisioned, fmt.Sprintf("%s %s", provisioner, stream.Name))
+	return c.deprovisionErr
+}
+
+// readyInMemoryProvider is the provider "memory" with its provisioner
+func readyInMemoryProvider() *streamingv1alpha1.InMemoryProvider {
+	provider := &streamingv1alpha1.InMemoryProvider{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "memory"},
+	}
+	provider.Status.ProvisionerServiceName = "memory-provisioner"
+	provider.Status.Conditions = apis.Conditions{
+		{Type: streamingv1alpha1.ProviderConditionReady, Status: corev1.ConditionTrue},
+	}
+	return provider
+}
+
+func TestStreamReconcileFinalizer(t *testing.T) {
+	deleted := metav1.Now()
+	deletingStream := func() *streamingv1alpha1.Stream {
+		stream := readyStream("numbers")
+		stream.DeletionTimestamp = &deleted
+		stream.Finalizers = []string{streamFinalizer}
+		return stream
+	}
+	binding := func(deleting bool) *streamingv1alpha1.Processor {
+		processor := newTestProcessor()
+		if deleting {
+			processor.DeletionTimestamp = &deleted
+		}
+		return processor
+	}
+	coreDeployer := &corev1alpha1.Deployer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "my-deployer"},
+		Spec: corev1alpha1.DeployerSpec{
+			Streams: []corev1alpha1.StreamBinding{{Stream: "numbers"}},
+		},
+	}
+	knativeDeployer := &knativev1alpha1.Deployer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "my-knative-deployer"},
+		Spec: knativev1alpha1.DeployerSpec{
+			Streams: []knativev1alpha1.StreamBinding{{Stream: "numbers", Mode: knativev1alpha1.StreamBindingModeSubscribe}},
+		},
+	}
+	provisioned := "test-namespace/memory-provisioner numbers"
+
+	tests := []struct {
+		name              string
+		stream            *streamingv1alpha1.Stream
+		objects           []runtime.Object
+		deprovisionErr    error
+		wantErr           bool
+		wantFinalizers    []string
+		wantProvisioned   []string
+		wantDeprovisioned []string
+		wantReady         corev1.ConditionStatus
+		wantReason        string
+		wantMessage       string
+	}{{
+		name:            "adds the finalizer",
+		stream:          &streamingv1alpha1.Stream{ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "numbers"}, Spec: readyStream("numbers").Spec},
+		objects:         []runtime.Object{readyInMemoryProvider()},
+		wantFinalizers:  []string{streamFinalizer},
+		wantProvisioned: []string{provisioned},
+		wantReady:       corev1.ConditionTrue,
+	}, {
+		name:              "deprovisions the topic",
+		stream:            deletingStream(),
+		objects:           []runtime.Object{readyInMemoryProvider()},
+		wantDeprovisioned: []string{provisioned},
+		wantReady:         corev1.ConditionTrue,
+	}, {
+		name:           "waits while resources bind the stream",
+		stream:         deletingStream(),
+		objects:        []runtime.Object{readyInMemoryProvider(), binding(false)},
+		wantFinalizers: []string{streamFinalizer},
+		wantReady:      corev1.ConditionFalse,
+		wantReason:     "StreamInUse",
+		wantMessage:    "stream is referenced by: Processor/my-processor",
+	}, {
+		name:           "waits while deployers of other runtimes bind the stream",
+		stream:         deletingStream(),
+		objects:        []runtime.Object{readyInMemoryProvider(), coreDeployer, knativeDeployer},
+		wantFinalizers: []string{streamFinalizer},
+		wantReady:      corev1.ConditionFalse,
+		wantReason:     "StreamInUse",
+		wantMessage:    "stream is referenced by: Deployer/my-deployer, Deployer/my-knative-deployer",
+	}, {
+		name:              "ignores resources pending deletion",
+		stream:            deletingStream(),
+		objects:           []runtime.Object{readyInMemoryProvider(), binding(true)},
+		wantDeprovisioned: []string{provisioned},
+		wantReady:         corev1.ConditionTrue,
+	}, {
+		name:              "records topics the provisioner cannot delete",
+		stream:            deletingStream(),
+		objects:           []runtime.Object{readyInMemoryProvider()},
+		deprovisionErr:    ErrDeprovisionUnsupported,
+		wantFinalizers:    []string{streamFinalizer},
+		wantDeprovisioned: []string{provisioned},
+		wantReady:         corev1.ConditionFalse,
+		wantReason:        "DeprovisionUnsupported",
+		wantMessage:       `the provisioner does not delete topics, topic "test-namespace_numbers" must be deleted manually`,
+	}, {
+		name: "releases streams once the topic to delete is recorded",
+		stream: func() *streamingv1alpha1.Stream {
+			stream := deletingStream()
+			stream.Status.MarkStreamDeprovisionUnsupported(stream.Status.Address.Topic)
+			return stream
+		}(),
+		objects:     []runtime.Object{readyInMemoryProvider()},
+		wantReady:   corev1.ConditionFalse,
+		wantReason:  "DeprovisionUnsupported",
+		wantMessage: `the provisioner does not delete topics, topic "test-namespace_numbers" must be deleted manually`,
+	}, {
+		name:              "keeps the finalizer when deprovisioning fails",
+		stream:            deletingStream(),
+		objects:           []runtime.Object{readyInMemoryProvider()},
+		deprovisionErr:    errors.New("broker unavailable"),
+		wantErr:           true,
+		wantFinalizers:    []string{streamFinalizer},
+		wantDeprovisioned: []string{provisioned},
+		wantReady:         corev1.ConditionFalse,
+		wantReason:        "DeprovisionFailed",
+		wantMessage:       "broker unavailable",
+	}, {
+		name:      "releases streams without a provider",
+		stream:    deletingStream(),
+		wantReady: corev1.ConditionTrue,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provisionerClient := &recordingProvisionerClient{deprovisionErr: test.deprovisionErr}
+			c := newFakeClient(append(test.objects, test.stream)...)
+			r := &StreamReconciler{
+				Client:                  c,
+				Log:                     zap.Logger(true),
+				Scheme:                  scheme.Scheme,
+				Tracker:                 newTestTracker(),
+				StreamProvisionerClient: provisionerClient,
+				referrers:               streamReferrers,
+			}
+			key := types.NamespacedName{Namespace: testNamespace, Name: "numbers"}
+			if _, err := r.Reconcile(ctrl.Request{NamespacedName: key}); (err != nil) != test.wantErr {
+				t.Fatalf("Reconcile() error = %v, want error %v", err, test.wantErr)
+			}
+
+			var stream streamingv1alpha1.Stream
+			if err := c.Get(context.Background(), key, &stream); err != nil {
+				t.Fatalf("unable to get stream: %v", err)
+			}
+			if diff := cmp.Diff(test.wantFinalizers, stream.Finalizers); diff != "" {
+				t.Errorf("finalizers (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.wantProvisioned, provisionerClient.provisioned); diff != "" {
+				t.Errorf("provisioned (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.wantDeprovisioned, provisionerClient.deprovisioned); diff != "" {
+				t.Errorf("deprovisioned (-want, +got) = %v", diff)
+			}
+			ready := stream.Status.GetCondition(streamingv1alpha1.StreamConditionReady)
+			if ready == nil {
+				t.Fatalf("stream has no Ready condition")
+			}
+			if ready.Status != test.wantReady || ready.Reason != test.wantReason || ready.Message != test.wantMessage {
+				t.Errorf("Ready = %s %s %q, want %s %s %q", ready.Status, ready.Reason, ready.Message, test.wantReady, test.wantReason, test.wantMessage)
+			}
+		})
+	}
+}
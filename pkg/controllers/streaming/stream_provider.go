@@ -0,0 +1,189 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/tracker"
+)
+
+// streamProvider is the provider backing a stream, at most one kind is set
+type streamProvider struct {
+	kafka    *streamingv1alpha1.KafkaProvider
+	pulsar   *streamingv1alpha1.PulsarProvider
+	inMemory *streamingv1alpha1.InMemoryProvider
+	generic  *streamingv1alpha1.Provider
+}
+
+// streamProviderNamespacedName locates the provider referenced by the stream,
+// the provider defaults to the stream's namespace
+func streamProviderNamespacedName(stream *streamingv1alpha1.Stream) types.NamespacedName {
+	namespace := stream.Spec.Provider.Namespace
+	if namespace == "" {
+		namespace = stream.Namespace
+	}
+	return types.NamespacedName{Namespace: namespace, Name: stream.Spec.Provider.Name}
+}
+
+// streamProviderKey identifies the provider referenced by the stream for the
+// tracker
+func streamProviderKey(stream *streamingv1alpha1.Stream) tracker.Key {
+	gvk := streamingv1alpha1.GroupVersion.WithKind(stream.Spec.Provider.Kind)
+	return tracker.NewKey(gvk, streamProviderNamespacedName(stream))
+}
+
+// getStreamProvider fetches the provider referenced by the stream. A provider
+// that does not exist resolves to an empty streamProvider.
+func getStreamProvider(ctx context.Context, c client.Client, stream *streamingv1alpha1.Stream) (streamProvider, error) {
+	provider := streamProvider{}
+	key := streamProviderNamespacedName(stream)
+	switch stream.Spec.Provider.Kind {
+	case streamingv1alpha1.KafkaProviderKind:
+		var kafkaProvider streamingv1alpha1.KafkaProvider
+		if err := c.Get(ctx, key, &kafkaProvider); err != nil {
+			return provider, ignoreNotFound(err)
+		}
+		provider.kafka = &kafkaProvider
+	case streamingv1alpha1.PulsarProviderKind:
+		var pulsarProvider streamingv1alpha1.PulsarProvider
+		if err := c.Get(ctx, key, &pulsarProvider); err != nil {
+			return provider, ignoreNotFound(err)
+		}
+		provider.pulsar = &pulsarProvider
+	case streamingv1alpha1.InMemoryProviderKind:
+		var inMemoryProvider streamingv1alpha1.InMemoryProvider
+		if err := c.Get(ctx, key, &inMemoryProvider); err != nil {
+			return provider, ignoreNotFound(err)
+		}
+		provider.inMemory = &inMemoryProvider
+	case streamingv1alpha1.ProviderKind:
+		var genericProvider streamingv1alpha1.Provider
+		if err := c.Get(ctx, key, &genericProvider); err != nil {
+			return provider, ignoreNotFound(err)
+		}
+		provider.generic = &genericProvider
+	default:
+		return provider, fmt.Errorf("unknown provider kind %q", stream.Spec.Provider.Kind)
+	}
+	return provider, nil
+}
+
+func (p streamProvider) exists() bool {
+	return p.kafka != nil || p.pulsar != nil || p.inMemory != nil || p.generic != nil
+}
+
+func (p streamProvider) isReady() bool {
+	switch {
+	case p.kafka != nil:
+		return p.kafka.Status.IsReady()
+	case p.pulsar != nil:
+		return p.pulsar.Status.IsReady()
+	case p.inMemory != nil:
+		return p.inMemory.Status.IsReady()
+	case p.generic != nil:
+		return p.generic.Status.IsReady()
+	}
+	return false
+}
+
+func (p streamProvider) namespace() string {
+	switch {
+	case p.kafka != nil:
+		return p.kafka.Namespace
+	case p.pulsar != nil:
+		return p.pulsar.Namespace
+	case p.inMemory != nil:
+		return p.inMemory.Namespace
+	case p.generic != nil:
+		return p.generic.Namespace
+	}
+	return ""
+}
+
+func (p streamProvider) allowedNamespaces() *streamingv1alpha1.AllowedNamespaces {
+	switch {
+	case p.kafka != nil:
+		return p.kafka.Spec.AllowedNamespaces
+	case p.pulsar != nil:
+		return p.pulsar.Spec.AllowedNamespaces
+	case p.inMemory != nil:
+		return p.inMemory.Spec.AllowedNamespaces
+	case p.generic != nil:
+		return p.generic.Spec.AllowedNamespaces
+	}
+	return nil
+}
+
+// allowsNamespace checks if streams in the namespace may use the provider.
+// Streams in the provider's own namespace are always allowed.
+func (p streamProvider) allowsNamespace(ctx context.Context, c client.Client, namespace string) (bool, error) {
+	if namespace == p.namespace() {
+		return true, nil
+	}
+	allowed := p.allowedNamespaces()
+	if allowed == nil {
+		return false, nil
+	}
+	for _, name := range allowed.Names {
+		if name == namespace {
+			return true, nil
+		}
+	}
+	if allowed.Selector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(allowed.Selector)
+	if err != nil {
+		return false, err
+	}
+	var ns corev1.Namespace
+	if err := c.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
+
+// provisionerService locates the service exposing the provider's provisioner
+// REST API, the name is empty until the provider has created it
+func (p streamProvider) provisionerService() types.NamespacedName {
+	switch {
+	case p.kafka != nil:
+		return types.NamespacedName{Namespace: p.kafka.Namespace, Name: p.kafka.Status.ProvisionerServiceName}
+	case p.pulsar != nil:
+		return types.NamespacedName{Namespace: p.pulsar.Namespace, Name: p.pulsar.Status.ProvisionerServiceName}
+	case p.inMemory != nil:
+		return types.NamespacedName{Namespace: p.inMemory.Namespace, Name: p.inMemory.Status.ProvisionerServiceName}
+	case p.generic != nil:
+		return types.NamespacedName{Namespace: p.generic.Namespace, Name: p.generic.Status.ProvisionerServiceName}
+	}
+	return types.NamespacedName{}
+}
+
+// supportsReplay is true when the provider's provisioner serves consumer group
+// resets. The provisioner of a generic provider is not known to serve it.
+func (p streamProvider) supportsReplay() bool {
+	return p.kafka != nil || p.pulsar != nil || p.inMemory != nil
+}
@@ -19,17 +19,29 @@ package streaming
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
 
 	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
 )
 
+// ErrDeprovisionUnsupported is returned by DeprovisionStream when the
+// provisioner does not delete topics
+var ErrDeprovisionUnsupported = errors.New("provisioner does not support deleting streams")
+
 type StreamProvisionerClient interface {
-	ProvisionStream(stream *streamingv1alpha1.Stream) (*streamingv1alpha1.StreamAddress, error)
+	ProvisionStream(stream *streamingv1alpha1.Stream, provisioner types.NamespacedName) (*streamingv1alpha1.StreamAddress, error)
+	DeprovisionStream(stream *streamingv1alpha1.Stream, provisioner types.NamespacedName) error
+	// ResetConsumerGroup moves the consumer group to the position on the
+	// stream, returning the group to consume with from then on. Provisioners
+	// that cannot move a group hand out a fresh group starting at the
+	// position.
+	ResetConsumerGroup(stream *streamingv1alpha1.Stream, provisioner types.NamespacedName, group, position string) (string, error)
 }
 
 type streamProvisionerRestClient struct {
@@ -37,6 +49,27 @@ type streamProvisionerRestClient struct {
 	logger     logr.Logger
 }
 
+// streamProvisionRequest is the body sent to the provisioner with the topic
+// settings. Unset values defer to the broker's defaults.
+type streamProvisionRequest struct {
+	Partitions        *int32 `json:"partitions,omitempty"`
+	ReplicationFactor *int32 `json:"replicationFactor,omitempty"`
+	RetentionMillis   *int64 `json:"retentionMillis,omitempty"`
+	RetentionBytes    *int64 `json:"retentionBytes,omitempty"`
+	Compaction        bool   `json:"compaction,omitempty"`
+}
+
+// consumerGroupResetRequest is the body sent to the provisioner to move a
+// consumer group to a new position on the stream's topic.
+type consumerGroupResetRequest struct {
+	Position string `json:"position"`
+}
+
+// consumerGroupResetResponse is the group to consume with after the reset
+type consumerGroupResetResponse struct {
+	Group string `json:"group"`
+}
+
 func NewStreamProvisionerClient(httpClient *http.Client, logger logr.Logger) StreamProvisionerClient {
 	return &streamProvisionerRestClient{
 		httpClient: httpClient,
@@ -44,13 +77,16 @@ func NewStreamProvisionerClient(httpClient *http.Client, logger logr.Logger) Str
 	}
 }
 
-func (s *streamProvisionerRestClient) ProvisionStream(stream *streamingv1alpha1.Stream) (*streamingv1alpha1.StreamAddress, error) {
-	url := fmt.Sprintf("http://%s.%s.svc.cluster.local/%s/%s", stream.Spec.Provider, stream.Namespace, stream.Namespace, stream.Name)
-	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader([]byte{}))
+func (s *streamProvisionerRestClient) ProvisionStream(stream *streamingv1alpha1.Stream, provisioner types.NamespacedName) (*streamingv1alpha1.StreamAddress, error) {
+	body, err := json.Marshal(newStreamProvisionRequest(stream))
 	if err != nil {
 		return nil, err
 	}
-	//req.Header.Add("content-type", "application/json")
+	req, err := http.NewRequest(http.MethodPut, provisionerURL(stream, provisioner), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("content-type", "application/json")
 	res, err := s.httpClient.Do(req)
 	if err != nil {
 		return nil, err
@@ -70,3 +106,89 @@ func (s *streamProvisionerRestClient) ProvisionStream(stream *streamingv1alpha1.
 	}
 	return address, nil
 }
+
+func (s *streamProvisionerRestClient) DeprovisionStream(stream *streamingv1alpha1.Stream, provisioner types.NamespacedName) error {
+	req, err := http.NewRequest(http.MethodDelete, provisionerURL(stream, provisioner), nil)
+	if err != nil {
+		return err
+	}
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			s.logger.Error(err, "Error closing stream deletion response body")
+		}
+	}()
+	if res.StatusCode == http.StatusNotFound {
+		// the topic is already gone
+		return nil
+	}
+	if res.StatusCode == http.StatusMethodNotAllowed || res.StatusCode == http.StatusNotImplemented {
+		return ErrDeprovisionUnsupported
+	}
+	if res.StatusCode >= 400 {
+		msg, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("status: %d, body: %q", res.StatusCode, string(msg))
+	}
+	return nil
+}
+
+func (s *streamProvisionerRestClient) ResetConsumerGroup(stream *streamingv1alpha1.Stream, provisioner types.NamespacedName, group, position string) (string, error) {
+	body, err := json.Marshal(&consumerGroupResetRequest{Position: position})
+	if err != nil {
+		return "", err
+	}
+	url := fmt.Sprintf("%s/groups/%s", provisionerURL(stream, provisioner), group)
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Add("content-type", "application/json")
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			s.logger.Error(err, "Error closing consumer group reset response body")
+		}
+	}()
+	if res.StatusCode >= 400 {
+		msg, _ := ioutil.ReadAll(res.Body)
+		return "", fmt.Errorf("status: %d, body: %q", res.StatusCode, string(msg))
+	}
+	response := &consumerGroupResetResponse{}
+	if err := json.NewDecoder(res.Body).Decode(response); err != nil {
+		return "", err
+	}
+	if response.Group == "" {
+		// the group was moved in place
+		return group, nil
+	}
+	return response.Group, nil
+}
+
+func newStreamProvisionRequest(stream *streamingv1alpha1.Stream) *streamProvisionRequest {
+	request := &streamProvisionRequest{
+		Partitions:        stream.Spec.Partitions,
+		ReplicationFactor: stream.Spec.ReplicationFactor,
+		Compaction:        stream.Spec.Compaction,
+	}
+	if retention := stream.Spec.Retention; retention != nil {
+		if retention.Time != nil {
+			millis := retention.Time.Duration.Milliseconds()
+			request.RetentionMillis = &millis
+		}
+		request.RetentionBytes = retention.Bytes
+	}
+	return request
+}
+
+// provisionerURL addresses the stream's topic on the provisioner. Topics are
+// keyed by the stream's namespace as well as its name, so tenants sharing a
+// provider cannot collide with, or reach, each other's topics.
+func provisionerURL(stream *streamingv1alpha1.Stream, provisioner types.NamespacedName) string {
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local/%s/%s", provisioner.Name, provisioner.Namespace, stream.Namespace, stream.Name)
+}
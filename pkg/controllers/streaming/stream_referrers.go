@@ -0,0 +1,186 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/projectriff/system/pkg/apis"
+	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
+	knativev1alpha1 "github.com/projectriff/system/pkg/apis/knative/v1alpha1"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/controllers"
+)
+
+// streamReferencesIndexField indexes resources by the name of each stream they
+// bind
+const streamReferencesIndexField = ".spec.streams"
+
+// streamReferrer is a kind of resource that binds streams from its namespace
+// by name
+type streamReferrer struct {
+	object  apis.Resource
+	newList func() runtime.Object
+	streams func(obj runtime.Object) []string
+}
+
+// streamReferrers are the kinds that may bind a stream. A stream stays in use,
+// and its topic provisioned, while a resource of any of these kinds binds it.
+var streamReferrers = []streamReferrer{
+	{
+		object:  &streamingv1alpha1.Processor{},
+		newList: func() runtime.Object { return &streamingv1alpha1.ProcessorList{} },
+		streams: func(obj runtime.Object) []string {
+			processor := obj.(*streamingv1alpha1.Processor)
+			names := []string{}
+			for _, binding := range streamBindingsForProcessor(processor) {
+				names = append(names, binding.Stream)
+			}
+			return names
+		},
+	},
+	{
+		object:  &streamingv1alpha1.Subscription{},
+		newList: func() runtime.Object { return &streamingv1alpha1.SubscriptionList{} },
+		streams: func(obj runtime.Object) []string {
+			subscription := obj.(*streamingv1alpha1.Subscription)
+			names := []string{subscription.Spec.Stream, subscription.Spec.Reply}
+			if policy := subscription.Spec.ErrorPolicy; policy != nil {
+				names = append(names, policy.DeadLetterStream)
+			}
+			return names
+		},
+	},
+	{
+		object:  &streamingv1alpha1.HTTPSource{},
+		newList: func() runtime.Object { return &streamingv1alpha1.HTTPSourceList{} },
+		streams: func(obj runtime.Object) []string {
+			return []string{obj.(*streamingv1alpha1.HTTPSource).Spec.Stream}
+		},
+	},
+	{
+		object:  &corev1alpha1.Deployer{},
+		newList: func() runtime.Object { return &corev1alpha1.DeployerList{} },
+		streams: func(obj runtime.Object) []string {
+			names := []string{}
+			for _, binding := range obj.(*corev1alpha1.Deployer).Spec.Streams {
+				names = append(names, binding.Stream)
+			}
+			return names
+		},
+	},
+	{
+		object:  &corev1alpha1.Schedule{},
+		newList: func() runtime.Object { return &corev1alpha1.ScheduleList{} },
+		streams: func(obj runtime.Object) []string {
+			return []string{obj.(*corev1alpha1.Schedule).Spec.Output}
+		},
+	},
+	{
+		object:  &knativev1alpha1.Deployer{},
+		newList: func() runtime.Object { return &knativev1alpha1.DeployerList{} },
+		streams: func(obj runtime.Object) []string {
+			names := []string{}
+			for _, binding := range obj.(*knativev1alpha1.Deployer).Spec.Streams {
+				names = append(names, binding.Stream)
+			}
+			return names
+		},
+	},
+}
+
+// installedStreamReferrers indexes the kinds served by the cluster, kinds of
+// runtimes that are not installed cannot bind streams
+func installedStreamReferrers(mgr ctrl.Manager) ([]streamReferrer, error) {
+	installed := []streamReferrer{}
+	for _, referrer := range streamReferrers {
+		if !controllers.IsKindInstalled(mgr, referrer.object.GetGroupVersionKind()) {
+			continue
+		}
+		streams := referrer.streams
+		if err := mgr.GetFieldIndexer().IndexField(referrer.object.(runtime.Object), streamReferencesIndexField, func(obj runtime.Object) []string {
+			return referencedStreams(streams, obj)
+		}); err != nil {
+			return nil, err
+		}
+		installed = append(installed, referrer)
+	}
+	return installed, nil
+}
+
+// referencedStreams lists each stream bound by the resource once
+func referencedStreams(streams func(obj runtime.Object) []string, obj runtime.Object) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, name := range streams(obj) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// referencesToStream describes the resources, not pending deletion, that bind
+// the stream, like `Processor/my-processor`
+func referencesToStream(ctx context.Context, c client.Client, referrers []streamReferrer, stream *streamingv1alpha1.Stream) ([]string, error) {
+	references := []string{}
+	for _, referrer := range referrers {
+		list := referrer.newList()
+		if err := c.List(ctx, list, client.InNamespace(stream.Namespace), client.MatchingField(streamReferencesIndexField, stream.Name)); err != nil {
+			return nil, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			obj := item.(metav1.Object)
+			if obj.GetDeletionTimestamp() != nil {
+				continue
+			}
+			references = append(references, fmt.Sprintf("%s/%s", referrer.object.GetGroupVersionKind().Kind, obj.GetName()))
+		}
+	}
+	return references, nil
+}
+
+// enqueueStreamsForReferrer requests reconciliation of the streams bound by a
+// resource, so streams pending deletion are released once no longer bound
+func enqueueStreamsForReferrer(referrer streamReferrer) handler.EventHandler {
+	return &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			requests := []reconcile.Request{}
+			for _, name := range referencedStreams(referrer.streams, a.Object) {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: name},
+				})
+			}
+			return requests
+		}),
+	}
+}
@@ -0,0 +1,549 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/projectriff/system/pkg/apis"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	kedav1alpha1 "github.com/projectriff/system/pkg/apis/thirdparty/keda/v1alpha1"
+	"github.com/projectriff/system/pkg/controllers"
+	"github.com/projectriff/system/pkg/tracker"
+)
+
+const (
+	subscriptionDeploymentIndexField   = ".metadata.subscriptionDeploymentController"
+	subscriptionScaledObjectIndexField = ".metadata.subscriptionScaledObjectController"
+)
+
+// addressableSubscriberKinds are watched, when installed, so subscriptions
+// follow changes to their subscriber's address. Subscribers of other kinds
+// are resolved as they are reconciled.
+var addressableSubscriberKinds = []schema.GroupVersionKind{
+	{Group: "core.projectriff.io", Version: "v1alpha1", Kind: "Deployer"},
+	{Group: "knative.projectriff.io", Version: "v1alpha1", Kind: "Deployer"},
+}
+
+// SubscriptionReconciler reconciles a Subscription object
+type SubscriptionReconciler struct {
+	client.Client
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Tracker   tracker.Tracker
+	Namespace string
+}
+
+// For
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=subscriptions,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=subscriptions/status,verbs=get;update;patch
+// Owns
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=keda.k8s.io,resources=scaledobjects,verbs=get;list;watch;create;update;patch;delete
+// Watches
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=streams,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core.projectriff.io;knative.projectriff.io,resources=deployers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+
+func (r *SubscriptionReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("subscription", req.NamespacedName)
+
+	var original streamingv1alpha1.Subscription
+	if err := r.Client.Get(ctx, req.NamespacedName, &original); err != nil {
+		return ctrl.Result{}, ignoreNotFound(err)
+	}
+
+	// Don't modify the informers copy
+	subscription := original.DeepCopy()
+
+	// Reconcile this copy of the subscription and then write back any status
+	// updates regardless of whether the reconciliation errored out.
+	result, err := r.reconcile(ctx, log, subscription)
+
+	// check if status has changed before updating, unless requeued
+	if !result.Requeue && !equality.Semantic.DeepEqual(subscription.Status, original.Status) {
+		// update status
+		log.Info("updating subscription status", "diff", cmp.Diff(original.Status, subscription.Status))
+		if updateErr := r.Status().Update(ctx, subscription); updateErr != nil {
+			log.Error(updateErr, "unable to update Subscription status", "subscription", subscription)
+			return ctrl.Result{Requeue: true}, updateErr
+		}
+	}
+	return result, err
+}
+
+func (r *SubscriptionReconciler) reconcile(ctx context.Context, log logr.Logger, subscription *streamingv1alpha1.Subscription) (ctrl.Result, error) {
+	if subscription.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	// We may be reading a version of the object that was stored at an older version
+	// and may not have had all of the assumed defaults specified.  This won't result
+	// in this getting written back to the API Server, but lets downstream logic make
+	// assumptions about defaulting.
+	subscription.Default()
+
+	subscription.Status.InitializeConditions()
+
+	// resolve the image for the dispatcher
+	image, err := r.resolveImage(ctx, subscription)
+	if err != nil {
+		log.Error(err, "unable to resolve image for Subscription", "subscription", subscription)
+		return ctrl.Result{}, err
+	}
+
+	// resolve the streams the dispatcher reads from and writes to
+	input, notReady, err := r.resolveStream(ctx, subscription, subscription.Spec.Stream)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	var reply, deadLetter *streamingv1alpha1.Stream
+	if notReady == "" && subscription.Spec.Reply != "" {
+		reply, notReady, err = r.resolveStream(ctx, subscription, subscription.Spec.Reply)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if notReady == "" && subscription.Spec.ErrorPolicy != nil && subscription.Spec.ErrorPolicy.DeadLetterStream != "" {
+		deadLetter, notReady, err = r.resolveStream(ctx, subscription, subscription.Spec.ErrorPolicy.DeadLetterStream)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if notReady != "" {
+		subscription.Status.MarkStreamsNotReady(notReady)
+		return ctrl.Result{}, nil
+	}
+	subscription.Status.MarkStreamsReady()
+	subscription.Status.InputAddress = input.Status.Address.String()
+	subscription.Status.ReplyAddress = ""
+	if reply != nil {
+		subscription.Status.ReplyAddress = reply.Status.Address.String()
+	}
+	subscription.Status.DeadLetterAddress = ""
+	if deadLetter != nil {
+		subscription.Status.DeadLetterAddress = deadLetter.Status.Address.String()
+	}
+
+	// resolve the subscriber
+	subscriberURL, notReady, err := r.resolveSubscriberURL(ctx, subscription)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if notReady != "" {
+		subscription.Status.MarkSubscriberNotReady(notReady)
+		return ctrl.Result{}, nil
+	}
+	subscription.Status.MarkSubscriberReady()
+	subscription.Status.SubscriberURL = subscriberURL
+
+	// reconcile deployment
+	deployment, err := r.reconcileChildDeployment(ctx, log, subscription, reply, image)
+	if err != nil {
+		log.Error(err, "unable to reconcile child Deployment", "subscription", subscription)
+		return ctrl.Result{}, err
+	}
+	subscription.Status.DeploymentName = deployment.Name
+	subscription.Status.PropagateDeploymentStatus(&deployment.Status)
+
+	// reconcile scaledObject
+	scaledObject, err := r.reconcileChildScaledObject(ctx, log, subscription, deployment)
+	if err != nil {
+		log.Error(err, "unable to reconcile child ScaledObject", "subscription", subscription)
+		return ctrl.Result{}, err
+	}
+	subscription.Status.ScaledObjectName = scaledObject.Name
+	subscription.Status.PropagateScaledObjectStatus(&scaledObject.Status)
+
+	subscription.Status.ObservedGeneration = subscription.Generation
+	return ctrl.Result{}, nil
+}
+
+func (r *SubscriptionReconciler) resolveImage(ctx context.Context, subscription *streamingv1alpha1.Subscription) (string, error) {
+	var config corev1.ConfigMap
+	key := types.NamespacedName{Namespace: r.Namespace, Name: subscriptionImages}
+	// track config map for new images
+	r.Tracker.Track(
+		tracker.NewKey(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, key),
+		namespacedNamedFor(subscription),
+	)
+	if err := r.Get(ctx, key, &config); err != nil {
+		return "", err
+	}
+	image := config.Data[dispatcherImageKey]
+	if image == "" {
+		return "", fmt.Errorf("missing dispatcher image configuration")
+	}
+	return image, nil
+}
+
+// resolveStream returns the named stream, or a message explaining why the
+// stream is not yet usable.
+func (r *SubscriptionReconciler) resolveStream(ctx context.Context, subscription *streamingv1alpha1.Subscription, name string) (*streamingv1alpha1.Stream, string, error) {
+	var stream streamingv1alpha1.Stream
+	key := types.NamespacedName{Namespace: subscription.Namespace, Name: name}
+	// track stream for new coordinates
+	r.Tracker.Track(
+		tracker.NewKey(stream.GetGroupVersionKind(), key),
+		namespacedNamedFor(subscription),
+	)
+	if err := r.Get(ctx, key, &stream); err != nil {
+		if apierrs.IsNotFound(err) {
+			return nil, fmt.Sprintf("stream %q not found", name), nil
+		}
+		return nil, "", err
+	}
+	ready := stream.Status.GetCondition(stream.Status.GetReadyConditionType())
+	if ready == nil {
+		ready = &apis.Condition{Message: "stream has no ready condition"}
+	}
+	if !ready.IsTrue() || stream.Status.Address.Gateway == "" {
+		return nil, fmt.Sprintf("stream %q is not ready: %s", name, ready.Message), nil
+	}
+	return &stream, "", nil
+}
+
+// resolveSubscriberURL returns the URL messages are delivered to, or a message
+// explaining why the subscriber is not yet addressable.
+func (r *SubscriptionReconciler) resolveSubscriberURL(ctx context.Context, subscription *streamingv1alpha1.Subscription) (string, string, error) {
+	ref := subscription.Spec.Subscriber.Ref
+	if ref == nil {
+		return subscription.Spec.Subscriber.URI, "", nil
+	}
+
+	subscriber := &unstructured.Unstructured{}
+	subscriber.SetAPIVersion(ref.APIVersion)
+	subscriber.SetKind(ref.Kind)
+	key := types.NamespacedName{Namespace: subscription.Namespace, Name: ref.Name}
+	// track subscriber for new addresses
+	r.Tracker.Track(
+		tracker.NewKey(subscriber.GroupVersionKind(), key),
+		namespacedNamedFor(subscription),
+	)
+	if err := r.Get(ctx, key, subscriber); err != nil {
+		if apierrs.IsNotFound(err) {
+			return "", fmt.Sprintf("%s %q not found", ref.Kind, ref.Name), nil
+		}
+		return "", "", err
+	}
+	url, _, err := unstructured.NestedString(subscriber.Object, "status", "address", "url")
+	if err != nil {
+		return "", "", err
+	}
+	if url == "" {
+		return "", fmt.Sprintf("%s %q does not have an address", ref.Kind, ref.Name), nil
+	}
+	return url, "", nil
+}
+
+func (r *SubscriptionReconciler) reconcileChildDeployment(ctx context.Context, log logr.Logger, subscription *streamingv1alpha1.Subscription, reply *streamingv1alpha1.Stream, image string) (*appsv1.Deployment, error) {
+	var actualDeployment appsv1.Deployment
+	var childDeployments appsv1.DeploymentList
+	if err := r.List(ctx, &childDeployments, client.InNamespace(subscription.Namespace), client.MatchingField(subscriptionDeploymentIndexField, subscription.Name)); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childDeployments.Items) == 1 {
+		actualDeployment = childDeployments.Items[0]
+	} else if len(childDeployments.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraDeployment := range childDeployments.Items {
+			log.Info("deleting extra deployment", "deployment", extraDeployment)
+			if err := r.Delete(ctx, &extraDeployment); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	desiredDeployment, err := r.constructDeploymentForSubscription(subscription, reply, image)
+	if err != nil {
+		return nil, err
+	}
+
+	// create deployment if it doesn't exist
+	if actualDeployment.Name == "" {
+		log.Info("creating deployment", "spec", desiredDeployment.Spec)
+		if err := r.Create(ctx, desiredDeployment); err != nil {
+			log.Error(err, "unable to create Deployment for Subscription", "deployment", desiredDeployment)
+			return nil, err
+		}
+		return desiredDeployment, nil
+	}
+
+	// overwrite fields that should not be mutated
+	desiredDeployment.Spec.Replicas = actualDeployment.Spec.Replicas
+
+	if r.deploymentSemanticEquals(desiredDeployment, &actualDeployment) {
+		// deployment is unchanged
+		return &actualDeployment, nil
+	}
+
+	// update deployment with desired changes
+	deployment := actualDeployment.DeepCopy()
+	deployment.ObjectMeta.Labels = desiredDeployment.ObjectMeta.Labels
+	deployment.Spec = desiredDeployment.Spec
+	log.Info("reconciling deployment", "diff", cmp.Diff(actualDeployment.Spec, deployment.Spec))
+	if err := r.Update(ctx, deployment); err != nil {
+		log.Error(err, "unable to update Deployment for Subscription", "deployment", deployment)
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+func (r *SubscriptionReconciler) deploymentSemanticEquals(desiredDeployment, deployment *appsv1.Deployment) bool {
+	return equality.Semantic.DeepEqual(desiredDeployment.Spec, deployment.Spec) &&
+		equality.Semantic.DeepEqual(desiredDeployment.ObjectMeta.Labels, deployment.ObjectMeta.Labels)
+}
+
+func (r *SubscriptionReconciler) constructDeploymentForSubscription(subscription *streamingv1alpha1.Subscription, reply *streamingv1alpha1.Stream, image string) (*appsv1.Deployment, error) {
+	labels := r.constructLabelsForSubscription(subscription)
+
+	// start at zero, the scaled object manages replicas from here
+	zero := int32(0)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       labels,
+			GenerateName: fmt.Sprintf("%s-subscription-", subscription.Name),
+			Namespace:    subscription.Namespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &zero,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					streamingv1alpha1.SubscriptionLabelKey: subscription.Name,
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:            "dispatcher",
+							Image:           image,
+							ImagePullPolicy: corev1.PullAlways,
+							Env:             r.computeEnvironmentVariables(subscription, reply),
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(subscription, deployment, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+func (r *SubscriptionReconciler) computeEnvironmentVariables(subscription *streamingv1alpha1.Subscription, reply *streamingv1alpha1.Stream) []corev1.EnvVar {
+	env := []corev1.EnvVar{
+		{Name: "INPUT", Value: subscription.Status.InputAddress},
+		{Name: "GROUP", Value: subscriptionGroup(subscription)},
+		{Name: "SUBSCRIBER", Value: subscription.Status.SubscriberURL},
+		{Name: "DELIVERY", Value: string(subscription.Spec.Delivery)},
+	}
+	if reply != nil {
+		env = append(env,
+			corev1.EnvVar{Name: "REPLY", Value: subscription.Status.ReplyAddress},
+			corev1.EnvVar{Name: "REPLY_CONTENT_TYPE", Value: reply.Spec.ContentType},
+		)
+	}
+	if policy := subscription.Spec.ErrorPolicy; policy != nil {
+		env = append(env,
+			corev1.EnvVar{Name: "ERROR_MAX_RETRIES", Value: fmt.Sprintf("%d", *policy.MaxRetries)},
+			corev1.EnvVar{Name: "ERROR_BACKOFF_INITIAL_DELAY", Value: policy.Backoff.InitialDelay.Duration.String()},
+			corev1.EnvVar{Name: "ERROR_BACKOFF_MAX_DELAY", Value: policy.Backoff.MaxDelay.Duration.String()},
+			corev1.EnvVar{Name: "ERROR_BACKOFF_MULTIPLIER", Value: fmt.Sprintf("%d", *policy.Backoff.Multiplier)},
+			corev1.EnvVar{Name: "DEAD_LETTER", Value: subscription.Status.DeadLetterAddress},
+		)
+	}
+	return env
+}
+
+func (r *SubscriptionReconciler) reconcileChildScaledObject(ctx context.Context, log logr.Logger, subscription *streamingv1alpha1.Subscription, deployment *appsv1.Deployment) (*kedav1alpha1.ScaledObject, error) {
+	var actualScaledObject kedav1alpha1.ScaledObject
+	var childScaledObjects kedav1alpha1.ScaledObjectList
+	if err := r.List(ctx, &childScaledObjects, client.InNamespace(subscription.Namespace), client.MatchingField(subscriptionScaledObjectIndexField, subscription.Name)); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childScaledObjects.Items) == 1 {
+		actualScaledObject = childScaledObjects.Items[0]
+	} else if len(childScaledObjects.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraScaledObject := range childScaledObjects.Items {
+			log.Info("deleting extra scaled object", "scaledObject", extraScaledObject)
+			if err := r.Delete(ctx, &extraScaledObject); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	desiredScaledObject, err := r.constructScaledObjectForSubscription(subscription, deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	// create scaledObject if it doesn't exist
+	if actualScaledObject.Name == "" {
+		log.Info("creating scaled object", "spec", desiredScaledObject.Spec)
+		if err := r.Create(ctx, desiredScaledObject); err != nil {
+			log.Error(err, "unable to create ScaledObject for Subscription", "scaledObject", desiredScaledObject)
+			return nil, err
+		}
+		return desiredScaledObject, nil
+	}
+
+	if r.scaledObjectSemanticEquals(desiredScaledObject, &actualScaledObject) {
+		// scaledObject is unchanged
+		return &actualScaledObject, nil
+	}
+
+	// update scaledObject with desired changes
+	scaledObject := actualScaledObject.DeepCopy()
+	scaledObject.ObjectMeta.Labels = desiredScaledObject.ObjectMeta.Labels
+	scaledObject.Spec = desiredScaledObject.Spec
+	log.Info("reconciling scaled object", "diff", cmp.Diff(actualScaledObject.Spec, scaledObject.Spec))
+	if err := r.Update(ctx, scaledObject); err != nil {
+		log.Error(err, "unable to update ScaledObject for Subscription", "scaledObject", scaledObject)
+		return nil, err
+	}
+
+	return scaledObject, nil
+}
+
+func (r *SubscriptionReconciler) scaledObjectSemanticEquals(desiredScaledObject, scaledObject *kedav1alpha1.ScaledObject) bool {
+	return equality.Semantic.DeepEqual(desiredScaledObject.Spec, scaledObject.Spec) &&
+		equality.Semantic.DeepEqual(desiredScaledObject.ObjectMeta.Labels, scaledObject.ObjectMeta.Labels)
+}
+
+func (r *SubscriptionReconciler) constructScaledObjectForSubscription(subscription *streamingv1alpha1.Subscription, deployment *appsv1.Deployment) (*kedav1alpha1.ScaledObject, error) {
+	labels := r.constructLabelsForSubscription(subscription)
+
+	labels["deploymentName"] = deployment.Name
+
+	// the defaulter guarantees scaling bounds
+	scaling := subscription.Spec.Scaling
+	minReplicas := *scaling.MinReplicas
+	maxReplicas := *scaling.MaxReplicas
+	pollingInterval := *scaling.PollingInterval
+	cooldownPeriod := *scaling.CooldownPeriod
+
+	scaledObject := &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-subscription-", subscription.Name),
+			Namespace:    subscription.Namespace,
+			Labels:       labels,
+		},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ObjectReference{
+				DeploymentName: deployment.Name,
+			},
+			PollingInterval: &pollingInterval,
+			CooldownPeriod:  &cooldownPeriod,
+			Triggers:        scaleTriggers([]string{subscriptionGroup(subscription)}, []string{subscription.Status.InputAddress}, []*int32{subscription.Spec.LagThreshold}),
+			MinReplicaCount: &minReplicas,
+			MaxReplicaCount: &maxReplicas,
+		},
+	}
+	if err := ctrl.SetControllerReference(subscription, scaledObject, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return scaledObject, nil
+}
+
+func (r *SubscriptionReconciler) constructLabelsForSubscription(subscription *streamingv1alpha1.Subscription) map[string]string {
+	labels := make(map[string]string, len(subscription.ObjectMeta.Labels)+1)
+	// pass through existing labels
+	for k, v := range subscription.ObjectMeta.Labels {
+		labels[k] = v
+	}
+
+	labels[streamingv1alpha1.SubscriptionLabelKey] = subscription.Name
+	return labels
+}
+
+// subscriptionGroup is the consumer group the dispatcher reads the stream with
+func subscriptionGroup(subscription *streamingv1alpha1.Subscription) string {
+	return controllers.ConsumerGroup("subscription", subscription.Name)
+}
+
+func (r *SubscriptionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	enqueueTrackedResources := func(gvk schema.GroupVersionKind) handler.EventHandler {
+		return &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+				requests := []reconcile.Request{}
+				key := tracker.NewKey(
+					gvk,
+					types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: a.Meta.GetName()},
+				)
+				for _, item := range r.Tracker.Lookup(key) {
+					requests = append(requests, reconcile.Request{NamespacedName: item})
+				}
+				return requests
+			}),
+		}
+	}
+
+	if err := controllers.IndexControllersOfType(mgr, subscriptionDeploymentIndexField, &streamingv1alpha1.Subscription{}, &appsv1.Deployment{}); err != nil {
+		return err
+	}
+	if err := controllers.IndexControllersOfType(mgr, subscriptionScaledObjectIndexField, &streamingv1alpha1.Subscription{}, &kedav1alpha1.ScaledObject{}); err != nil {
+		return err
+	}
+
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&streamingv1alpha1.Subscription{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&kedav1alpha1.ScaledObject{}).
+		Watches(&source.Kind{Type: &streamingv1alpha1.Stream{}}, enqueueTrackedResources((&streamingv1alpha1.Stream{}).GetGroupVersionKind())).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueTrackedConfigMap(r.Tracker, r.Namespace, subscriptionImages))
+	for _, gvk := range addressableSubscriberKinds {
+		if !controllers.IsKindInstalled(mgr, gvk) {
+			continue
+		}
+		// watch for subscriber mutations to follow address changes
+		subscriber := &unstructured.Unstructured{}
+		subscriber.SetGroupVersionKind(gvk)
+		builder = builder.Watches(&source.Kind{Type: subscriber}, enqueueTrackedResources(gvk))
+	}
+	return builder.Complete(r)
+}
@@ -17,29 +17,53 @@ limitations under the License.
 package streaming
 
 import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"google.golang.org/grpc"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
 	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	kedav1alpha1 "github.com/projectriff/system/pkg/apis/thirdparty/keda/v1alpha1"
+	"github.com/projectriff/system/pkg/gateway/server"
+	"github.com/projectriff/system/pkg/liiklus"
+	"github.com/projectriff/system/pkg/provisioner"
 	// +kubebuilder:scaffold:imports
 )
 
 // These tests use Ginkgo (BDD-style Go testing framework). Refer to
 // http://onsi.github.io/ginkgo/ to learn more about Ginkgo.
+//
+// The suite runs the InMemoryProvider, Stream and Processor controllers
+// against a test API server. Nothing runs the pods of the provider, so the
+// gateway and provisioner are served from the test process instead.
 
 var cfg *rest.Config
 var k8sClient client.Client
 var testEnv *envtest.Environment
+var stopManager chan struct{}
+var gatewayServer *grpc.Server
+
+// gatewayAddress is where the in-process gateway listens
+var gatewayAddress string
 
 func TestAPIs(t *testing.T) {
 	RegisterFailHandler(Fail)
@@ -54,7 +78,12 @@ var _ = BeforeSuite(func(done Done) {
 
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
-		CRDDirectoryPaths: []string{filepath.Join("..", "config", "crd", "bases")},
+		CRDDirectoryPaths: []string{
+			filepath.Join("..", "..", "..", "config", "streaming", "crd", "bases"),
+			// processors watch the functions and containers they run
+			filepath.Join("..", "..", "..", "config", "build", "crd", "bases"),
+		},
+		CRDs: []*apiextensionsv1beta1.CustomResourceDefinition{scaledObjectCRD()},
 	}
 
 	var err error
@@ -65,7 +94,10 @@ var _ = BeforeSuite(func(done Done) {
 	err = streamingv1alpha1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
-	err = streamingv1alpha1.AddToScheme(scheme.Scheme)
+	err = buildv1alpha1.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = kedav1alpha1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
 	// +kubebuilder:scaffold:scheme
@@ -74,11 +106,135 @@ var _ = BeforeSuite(func(done Done) {
 	Expect(err).ToNot(HaveOccurred())
 	Expect(k8sClient).ToNot(BeNil())
 
+	By("starting the gateway")
+	gatewayStorage, err := server.New("")
+	Expect(err).ToNot(HaveOccurred())
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+	gatewayAddress = listener.Addr().String()
+	gatewayServer = grpc.NewServer()
+	liiklus.RegisterLiiklusServiceServer(gatewayServer, gatewayStorage)
+	go gatewayServer.Serve(listener)
+
+	By("starting the controllers")
+	for _, namespace := range []string{testNamespace, testSystemNamespace} {
+		err = k8sClient.Create(context.Background(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})
+		Expect(err).ToNot(HaveOccurred())
+	}
+	for name, data := range map[string]map[string]string{
+		inMemoryProviderImages: {gatewayImageKey: "gateway-image", provisionerImageKey: "provisioner-image"},
+		processorImages:        {processorImageKey: "processor-image"},
+	} {
+		err = k8sClient.Create(context.Background(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testSystemNamespace, Name: name},
+			Data:       data,
+		})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:             scheme.Scheme,
+		MetricsBindAddress: "0",
+	})
+	Expect(err).ToNot(HaveOccurred())
+	provisionerClient := NewStreamProvisionerClient(&http.Client{
+		Transport: inProcessTransport{&provisioner.Handler{
+			Gateway: gatewayAddress,
+			Topics:  gatewayTopics{},
+			Log:     ctrl.Log.WithName("provisioner"),
+		}},
+	}, ctrl.Log.WithName("provisioner-client"))
+	err = (&InMemoryProviderReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("InMemoryProvider"),
+		Scheme:    mgr.GetScheme(),
+		Tracker:   newTestTracker(),
+		Namespace: testSystemNamespace,
+	}).SetupWithManager(mgr)
+	Expect(err).ToNot(HaveOccurred())
+	err = (&StreamReconciler{
+		Client:                  mgr.GetClient(),
+		Log:                     ctrl.Log.WithName("controllers").WithName("Stream"),
+		Scheme:                  mgr.GetScheme(),
+		Tracker:                 newTestTracker(),
+		StreamProvisionerClient: provisionerClient,
+	}).SetupWithManager(mgr)
+	Expect(err).ToNot(HaveOccurred())
+	err = (&ProcessorReconciler{
+		Client:                  mgr.GetClient(),
+		Log:                     ctrl.Log.WithName("controllers").WithName("Processor"),
+		Scheme:                  mgr.GetScheme(),
+		Tracker:                 newTestTracker(),
+		Namespace:               testSystemNamespace,
+		StreamProvisionerClient: provisionerClient,
+	}).SetupWithManager(mgr)
+	Expect(err).ToNot(HaveOccurred())
+
+	stopManager = make(chan struct{})
+	go func() {
+		defer GinkgoRecover()
+		Expect(mgr.Start(stopManager)).To(Succeed())
+	}()
+
 	close(done)
 }, 60)
 
 var _ = AfterSuite(func() {
 	By("tearing down the test environment")
+	if stopManager != nil {
+		close(stopManager)
+	}
+	if gatewayServer != nil {
+		gatewayServer.Stop()
+	}
 	err := testEnv.Stop()
 	Expect(err).ToNot(HaveOccurred())
 })
+
+// scaledObjectCRD installs the KEDA kind processors scale with
+func scaledObjectCRD() *apiextensionsv1beta1.CustomResourceDefinition {
+	return &apiextensionsv1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "scaledobjects." + kedav1alpha1.GroupVersion.Group},
+		Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+			Group:   kedav1alpha1.GroupVersion.Group,
+			Version: kedav1alpha1.GroupVersion.Version,
+			Scope:   apiextensionsv1beta1.NamespaceScoped,
+			Names: apiextensionsv1beta1.CustomResourceDefinitionNames{
+				Plural:   "scaledobjects",
+				Singular: "scaledobject",
+				Kind:     "ScaledObject",
+				ListKind: "ScaledObjectList",
+			},
+			Subresources: &apiextensionsv1beta1.CustomResourceSubresources{
+				Status: &apiextensionsv1beta1.CustomResourceSubresourceStatus{},
+			},
+		},
+	}
+}
+
+// inProcessTransport serves requests for any host with the handler
+type inProcessTransport struct {
+	handler http.Handler
+}
+
+func (t inProcessTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	t.handler.ServeHTTP(recorder, req)
+	return recorder.Result(), nil
+}
+
+// gatewayTopics leaves topics to the gateway, like the in-memory provisioner
+type gatewayTopics struct{}
+
+func (gatewayTopics) Apply(ctx context.Context, topic string, settings provisioner.TopicSettings) error {
+	return nil
+}
+
+func (gatewayTopics) Delete(ctx context.Context, topic string) error {
+	return nil
+}
+
+// eventually polls for the outcome of the controllers
+func eventually(actual interface{}) AsyncAssertion {
+	return Eventually(actual, 20*time.Second, 100*time.Millisecond)
+}
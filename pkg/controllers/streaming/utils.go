@@ -17,11 +17,32 @@
 package streaming
 
 import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/tracker"
+)
+
+const (
+	tlsCAMountPath   = "/var/riff/tls/ca"
+	tlsCertMountPath = "/var/riff/tls/cert"
 )
 
+// secretsChecksumAnnotationKey is set on pod templates so deployments roll
+// when a referenced secret changes
+var secretsChecksumAnnotationKey = streamingv1alpha1.GroupVersion.Group + "/secrets-checksum"
+
 /*
 We generally want to ignore (not requeue) NotFound errors, since we'll get a
 reconciliation request once the object exists, and requeuing in the meantime
@@ -37,3 +58,161 @@ func ignoreNotFound(err error) error {
 func namespacedNamedFor(ref metav1.ObjectMetaAccessor) types.NamespacedName {
 	return types.NamespacedName{Namespace: ref.GetObjectMeta().GetNamespace(), Name: ref.GetObjectMeta().GetName()}
 }
+
+func hasFinalizer(obj metav1.Object, finalizer string) bool {
+	for _, f := range obj.GetFinalizers() {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+func removeFinalizer(obj metav1.Object, finalizer string) {
+	finalizers := []string{}
+	for _, f := range obj.GetFinalizers() {
+		if f != finalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	obj.SetFinalizers(finalizers)
+}
+
+// positionStorageEnvironment configures where a gateway persists consumer
+// positions.
+func positionStorageEnvironment(storage *streamingv1alpha1.PositionStorage) []corev1.EnvVar {
+	switch storage.Type {
+	case streamingv1alpha1.PositionStorageTypeRedis:
+		return []corev1.EnvVar{
+			{Name: "storage_positions_type", Value: "REDIS"},
+			{Name: "redis_host", Value: storage.Redis.Host},
+			{Name: "redis_port", Value: fmt.Sprintf("%d", storage.Redis.Port)},
+		}
+	}
+	return []corev1.EnvVar{
+		{Name: "storage_positions_type", Value: "MEMORY"},
+	}
+}
+
+// providerTLSVolumes mounts the secrets referenced by a provider's TLS settings
+func providerTLSVolumes(tls *streamingv1alpha1.ProviderTLS) ([]corev1.Volume, []corev1.VolumeMount) {
+	volumes := []corev1.Volume{}
+	mounts := []corev1.VolumeMount{}
+	if tls == nil {
+		return volumes, mounts
+	}
+	if tls.CASecretRef != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: "tls-ca",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: tls.CASecretRef.Name},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{Name: "tls-ca", MountPath: tlsCAMountPath, ReadOnly: true})
+	}
+	if tls.CertSecretRef != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: "tls-cert",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: tls.CertSecretRef.Name},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{Name: "tls-cert", MountPath: tlsCertMountPath, ReadOnly: true})
+	}
+	return volumes, mounts
+}
+
+// providerTLSFiles locates the mounted CA bundle, client certificate and key,
+// paths are empty when the secret is not referenced
+func providerTLSFiles(tls *streamingv1alpha1.ProviderTLS) (ca, cert, key string) {
+	if tls == nil {
+		return "", "", ""
+	}
+	if tls.CASecretRef != nil {
+		ca = path.Join(tlsCAMountPath, "ca.crt")
+	}
+	if tls.CertSecretRef != nil {
+		cert = path.Join(tlsCertMountPath, corev1.TLSCertKey)
+		key = path.Join(tlsCertMountPath, corev1.TLSPrivateKeyKey)
+	}
+	return ca, cert, key
+}
+
+// provisionerTLSEnvironment points the provisioner at the mounted TLS files
+func provisionerTLSEnvironment(tls *streamingv1alpha1.ProviderTLS) []corev1.EnvVar {
+	env := []corev1.EnvVar{}
+	ca, cert, key := providerTLSFiles(tls)
+	if ca != "" {
+		env = append(env, corev1.EnvVar{Name: "TLS_CA", Value: ca})
+	}
+	if cert != "" {
+		env = append(env,
+			corev1.EnvVar{Name: "TLS_CERT", Value: cert},
+			corev1.EnvVar{Name: "TLS_KEY", Value: key},
+		)
+	}
+	return env
+}
+
+func secretsChecksumAnnotations(secretsChecksum string) map[string]string {
+	if secretsChecksum == "" {
+		return nil
+	}
+	return map[string]string{secretsChecksumAnnotationKey: secretsChecksum}
+}
+
+func secretKeyEnvVar(name string, ref corev1.LocalObjectReference, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: ref, Key: key},
+		},
+	}
+}
+
+// providerTLSSecretNames lists the secrets referenced by a provider's TLS
+// settings
+func providerTLSSecretNames(tls *streamingv1alpha1.ProviderTLS) []string {
+	names := []string{}
+	if tls == nil {
+		return names
+	}
+	if tls.CASecretRef != nil {
+		names = append(names, tls.CASecretRef.Name)
+	}
+	if tls.CertSecretRef != nil {
+		names = append(names, tls.CertSecretRef.Name)
+	}
+	return names
+}
+
+// secretsChecksum fingerprints the content of the named secrets, tracking each
+// secret for the owner. The checksum is empty when no secrets are named.
+func secretsChecksum(ctx context.Context, c client.Client, t tracker.Tracker, owner types.NamespacedName, names []string) (string, error) {
+	if len(names) == 0 {
+		return "", nil
+	}
+	sort.Strings(names)
+	hash := sha256.New()
+	for _, name := range names {
+		secretKey := types.NamespacedName{Namespace: owner.Namespace, Name: name}
+		t.Track(
+			tracker.NewKey(schema.GroupVersionKind{Version: "v1", Kind: "Secret"}, secretKey),
+			owner,
+		)
+		var secret corev1.Secret
+		if err := c.Get(ctx, secretKey, &secret); err != nil {
+			return "", err
+		}
+		keys := make([]string, 0, len(secret.Data))
+		for k := range secret.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(hash, "%s\n", name)
+		for _, k := range keys {
+			fmt.Fprintf(hash, "%s=%x\n", k, secret.Data[k])
+		}
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gateway is a client for the liiklus gateway that fronts the topic of
+// every stream. Only the calls riff's own workloads need are exposed:
+// publishing, joining a consumer group, receiving records, acknowledging
+// them and reading the positions of a group.
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/projectriff/system/pkg/liiklus"
+)
+
+// OffsetReset is where a consumer group without a position starts
+// consuming a partition.
+type OffsetReset = liiklus.SubscribeRequest_AutoOffsetReset
+
+const (
+	OffsetResetEarliest = liiklus.SubscribeRequest_EARLIEST
+	OffsetResetLatest   = liiklus.SubscribeRequest_LATEST
+)
+
+// ParseAddress splits a stream address of the form <gateway>/<topic>, as
+// exposed in a stream's status, into the gateway and the topic.
+func ParseAddress(address string) (string, string, error) {
+	i := strings.Index(address, "/")
+	if i <= 0 || i == len(address)-1 {
+		return "", "", fmt.Errorf("stream address %q must be of the form <gateway>/<topic>", address)
+	}
+	return address[:i], address[i+1:], nil
+}
+
+// Client calls a liiklus gateway
+type Client struct {
+	conn    *grpc.ClientConn
+	liiklus liiklus.LiiklusServiceClient
+}
+
+// NewClient creates a client for the gateway at host:port. The gateway does
+// not terminate tls, calls are made in the clear unless the options say
+// otherwise. The connection is established lazily.
+func NewClient(gateway string, opts ...grpc.DialOption) (*Client, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	conn, err := grpc.Dial(gateway, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		conn:    conn,
+		liiklus: liiklus.NewLiiklusServiceClient(conn),
+	}, nil
+}
+
+// Close releases the connection to the gateway
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Publish appends a value to the topic
+func (c *Client) Publish(ctx context.Context, topic string, key, value []byte) error {
+	_, err := c.liiklus.Publish(ctx, &liiklus.PublishRequest{
+		Topic: topic,
+		Key:   key,
+		Value: value,
+	})
+	return err
+}
+
+// Assignment is a partition of a topic assigned to a member of a consumer
+// group
+type Assignment struct {
+	SessionID string
+	Partition uint32
+}
+
+// Subscribe joins the consumer group of a topic. Assignments are received
+// from the returned stream as partitions are assigned to this member. The
+// subscription ends when the stream is closed or the context is cancelled.
+func (c *Client) Subscribe(ctx context.Context, topic, group string, reset OffsetReset) (*AssignmentStream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := c.liiklus.Subscribe(ctx, &liiklus.SubscribeRequest{
+		Topic:           topic,
+		Group:           group,
+		AutoOffsetReset: reset,
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &AssignmentStream{stream: stream, cancel: cancel}, nil
+}
+
+// AssignmentStream receives the assignments of a subscription
+type AssignmentStream struct {
+	stream liiklus.LiiklusService_SubscribeClient
+	cancel context.CancelFunc
+}
+
+// Next blocks until the next assignment. It returns io.EOF once the gateway
+// ends the subscription.
+func (s *AssignmentStream) Next() (Assignment, error) {
+	for {
+		reply, err := s.stream.Recv()
+		if err != nil {
+			return Assignment{}, err
+		}
+		if assignment := reply.GetAssignment(); assignment != nil {
+			return Assignment{
+				SessionID: assignment.SessionId,
+				Partition: assignment.Partition,
+			}, nil
+		}
+	}
+}
+
+// Close ends the subscription
+func (s *AssignmentStream) Close() {
+	s.cancel()
+}
+
+// Record is a value on a partition
+type Record struct {
+	Offset uint64
+	Key    []byte
+	Value  []byte
+	// Timestamp the broker recorded for the value, zero when unknown
+	Timestamp time.Time
+}
+
+// Receive streams the records of an assigned partition, starting after the
+// last acknowledged position of the consumer group. Receiving ends when the
+// stream is closed or the context is cancelled.
+func (c *Client) Receive(ctx context.Context, assignment Assignment) (*RecordStream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := c.liiklus.Receive(ctx, &liiklus.ReceiveRequest{
+		Assignment: &liiklus.Assignment{
+			SessionId: assignment.SessionID,
+			Partition: assignment.Partition,
+		},
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &RecordStream{stream: stream, cancel: cancel}, nil
+}
+
+// RecordStream receives the records of a partition
+type RecordStream struct {
+	stream liiklus.LiiklusService_ReceiveClient
+	cancel context.CancelFunc
+}
+
+// Next blocks until the next record. It returns io.EOF once the partition
+// is revoked from this member.
+func (s *RecordStream) Next() (Record, error) {
+	for {
+		reply, err := s.stream.Recv()
+		if err != nil {
+			return Record{}, err
+		}
+		if record := reply.GetRecord(); record != nil {
+			r := Record{
+				Offset: record.Offset,
+				Key:    record.Key,
+				Value:  record.Value,
+			}
+			if record.Timestamp != nil {
+				r.Timestamp = record.Timestamp.AsTime()
+			}
+			return r, nil
+		}
+	}
+}
+
+// Close stops receiving records
+func (s *RecordStream) Close() {
+	s.cancel()
+}
+
+// Ack moves the position of the consumer group on the assigned partition
+// past the offset
+func (c *Client) Ack(ctx context.Context, topic, group string, assignment Assignment, offset uint64) error {
+	_, err := c.liiklus.Ack(ctx, &liiklus.AckRequest{
+		Topic:     topic,
+		Group:     group,
+		Partition: assignment.Partition,
+		Offset:    offset,
+	})
+	return err
+}
+
+// Offsets returns the last offset the consumer group acknowledged on each
+// partition of the topic. Partitions the group has no position on are
+// missing.
+func (c *Client) Offsets(ctx context.Context, topic, group string) (map[uint32]uint64, error) {
+	reply, err := c.liiklus.GetOffsets(ctx, &liiklus.GetOffsetsRequest{
+		Topic: topic,
+		Group: group,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply.Offsets, nil
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/projectriff/system/pkg/gateway"
+	"github.com/projectriff/system/pkg/gateway/gatewaytest"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		gateway string
+		topic   string
+		wantErr bool
+	}{{
+		name:    "valid",
+		address: "gateway.riff-system.svc.cluster.local:6565/default_orders",
+		gateway: "gateway.riff-system.svc.cluster.local:6565",
+		topic:   "default_orders",
+	}, {
+		name:    "missing topic",
+		address: "gateway:6565/",
+		wantErr: true,
+	}, {
+		name:    "missing gateway",
+		address: "/default_orders",
+		wantErr: true,
+	}, {
+		name:    "empty",
+		address: "",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gatewayAddress, topic, err := gateway.ParseAddress(test.address)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseAddress() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if gatewayAddress != test.gateway || topic != test.topic {
+				t.Errorf("ParseAddress() = %q, %q, want %q, %q", gatewayAddress, topic, test.gateway, test.topic)
+			}
+		})
+	}
+}
+
+func TestClient(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	server, client := gatewaytest.NewServer(t)
+
+	for _, value := range []string{"one", "two"} {
+		if err := client.Publish(ctx, "default_numbers", nil, []byte(value)); err != nil {
+			t.Fatalf("Publish() unexpected error: %v", err)
+		}
+	}
+	if diff := cmp.Diff([][]byte{[]byte("one"), []byte("two")}, server.Records("default_numbers")); diff != "" {
+		t.Errorf("Publish() (-want, +got) = %v", diff)
+	}
+
+	assignments, err := client.Subscribe(ctx, "default_numbers", "test", gateway.OffsetResetEarliest)
+	if err != nil {
+		t.Fatalf("Subscribe() unexpected error: %v", err)
+	}
+	defer assignments.Close()
+	assignment, err := assignments.Next()
+	if err != nil {
+		t.Fatalf("Next() unexpected error: %v", err)
+	}
+
+	records, err := client.Receive(ctx, assignment)
+	if err != nil {
+		t.Fatalf("Receive() unexpected error: %v", err)
+	}
+	defer records.Close()
+	var got []gateway.Record
+	for len(got) < 2 {
+		record, err := records.Next()
+		if err != nil {
+			t.Fatalf("Next() unexpected error: %v", err)
+		}
+		got = append(got, record)
+		if err := client.Ack(ctx, "default_numbers", "test", assignment, record.Offset); err != nil {
+			t.Fatalf("Ack() unexpected error: %v", err)
+		}
+	}
+	want := []gateway.Record{
+		{Offset: 0, Value: []byte("one")},
+		{Offset: 1, Value: []byte("two")},
+	}
+	// published records are stamped by the gateway
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(gateway.Record{}, "Timestamp")); diff != "" {
+		t.Errorf("Receive() (-want, +got) = %v", diff)
+	}
+	for _, record := range got {
+		if record.Timestamp.IsZero() {
+			t.Errorf("Receive() record %d has no timestamp", record.Offset)
+		}
+	}
+	if diff := cmp.Diff([]uint64{0, 1}, server.Acks("default_numbers", "test")); diff != "" {
+		t.Errorf("Ack() (-want, +got) = %v", diff)
+	}
+
+	offsets, err := client.Offsets(ctx, "default_numbers", "test")
+	if err != nil {
+		t.Fatalf("Offsets() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[uint32]uint64{0: 1}, offsets); diff != "" {
+		t.Errorf("Offsets() (-want, +got) = %v", diff)
+	}
+	offsets, err = client.Offsets(ctx, "default_numbers", "other")
+	if err != nil {
+		t.Fatalf("Offsets() unexpected error: %v", err)
+	}
+	if len(offsets) != 0 {
+		t.Errorf("Offsets() = %v for a group without positions, want none", offsets)
+	}
+}
+
+func TestClientSubscribeLatest(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	server, client := gatewaytest.NewServer(t)
+	server.Append("default_numbers", []byte("old"))
+
+	assignments, err := client.Subscribe(ctx, "default_numbers", "test", gateway.OffsetResetLatest)
+	if err != nil {
+		t.Fatalf("Subscribe() unexpected error: %v", err)
+	}
+	defer assignments.Close()
+	assignment, err := assignments.Next()
+	if err != nil {
+		t.Fatalf("Next() unexpected error: %v", err)
+	}
+	records, err := client.Receive(ctx, assignment)
+	if err != nil {
+		t.Fatalf("Receive() unexpected error: %v", err)
+	}
+	defer records.Close()
+
+	published := time.Date(2019, time.November, 4, 13, 54, 1, 0, time.UTC)
+	server.AppendAt("default_numbers", []byte("new"), published)
+	record, err := records.Next()
+	if err != nil {
+		t.Fatalf("Next() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(gateway.Record{Offset: 1, Value: []byte("new"), Timestamp: published}, record); diff != "" {
+		t.Errorf("Next() (-want, +got) = %v", diff)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gatewaytest serves an in-memory liiklus gateway for tests. Each
+// topic has a single partition, consumer groups start from the earliest or
+// latest record and move as records are acknowledged. Records are stamped
+// with the time they were published.
+package gatewaytest
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/projectriff/system/pkg/gateway"
+	"github.com/projectriff/system/pkg/gateway/server"
+	"github.com/projectriff/system/pkg/liiklus"
+)
+
+// Server is a liiklus gateway holding records in memory
+type Server struct {
+	*server.Server
+
+	// PublishErr, when set, fails every publish
+	PublishErr error
+
+	mu sync.Mutex
+	// acks are the offsets acknowledged by each group of a topic
+	acks map[string]map[string][]uint64
+}
+
+// NewServer starts a gateway, returning a client connected to it. The server
+// stops when the test ends.
+func NewServer(t *testing.T) (*Server, *gateway.Client) {
+	memory, err := server.New("")
+	if err != nil {
+		t.Fatalf("unable to create gateway: %v", err)
+	}
+	s := &Server{
+		Server: memory,
+		acks:   map[string]map[string][]uint64{},
+	}
+
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	liiklus.RegisterLiiklusServiceServer(grpcServer, s)
+	go grpcServer.Serve(listener)
+
+	client, err := gateway.NewClient("bufnet", grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	if err != nil {
+		t.Fatalf("unable to create gateway client: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		grpcServer.Stop()
+	})
+	return s, client
+}
+
+// Records returns the values published to the topic
+func (s *Server) Records(topic string) [][]byte {
+	var values [][]byte
+	for _, record := range s.Server.Records(topic) {
+		values = append(values, record.Value)
+	}
+	return values
+}
+
+// Append adds a value to the topic as if it was published
+func (s *Server) Append(topic string, value []byte) {
+	s.AppendAt(topic, value, time.Now())
+}
+
+// AppendAt adds a value to the topic as if it was published at the timestamp
+func (s *Server) AppendAt(topic string, value []byte, timestamp time.Time) {
+	// in-memory topics do not fail
+	s.Server.Append(topic, server.Record{Value: value, Timestamp: timestamp})
+}
+
+// Acks returns the offsets acknowledged by the group
+func (s *Server) Acks(topic, group string) []uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]uint64(nil), s.acks[topic][group]...)
+}
+
+func (s *Server) Publish(ctx context.Context, req *liiklus.PublishRequest) (*liiklus.PublishReply, error) {
+	if s.PublishErr != nil {
+		return nil, s.PublishErr
+	}
+	return s.Server.Publish(ctx, req)
+}
+
+func (s *Server) Ack(ctx context.Context, req *liiklus.AckRequest) (*emptypb.Empty, error) {
+	reply, err := s.Server.Ack(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.acks[req.Topic] == nil {
+		s.acks[req.Topic] = map[string][]uint64{}
+	}
+	s.acks[req.Topic][req.Group] = append(s.acks[req.Topic][req.Group], req.Offset)
+	return reply, nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"mime"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/projectriff/system/pkg/message"
+)
+
+// Message is the value of a record on a riff stream. The payload travels
+// with its content type and headers, so consumers can interpret it without
+// knowing the producer.
+type Message struct {
+	Payload     []byte
+	ContentType string
+	Headers     map[string]string
+}
+
+// Marshal encodes the message as a riff streaming.Message
+func (m *Message) Marshal() ([]byte, error) {
+	// deterministic for a stable encoding of the headers
+	return proto.MarshalOptions{Deterministic: true}.Marshal(&message.Message{
+		Payload:     m.Payload,
+		ContentType: m.ContentType,
+		Headers:     m.Headers,
+	})
+}
+
+// UnmarshalMessage decodes a message encoded by Marshal
+func UnmarshalMessage(b []byte) (*Message, error) {
+	msg := &message.Message{}
+	if err := proto.Unmarshal(b, msg); err != nil {
+		return nil, err
+	}
+	return &Message{
+		Payload:     msg.Payload,
+		ContentType: msg.ContentType,
+		Headers:     msg.Headers,
+	}, nil
+}
+
+// ContentTypeAccepted is true when a payload of the content type may be
+// published to a stream of the accepted content type. The accepted type may
+// be a wildcard, like `text/*` or `*/*`, and parameters are ignored.
+func ContentTypeAccepted(accepted, contentType string) bool {
+	acceptedType, _, err := mime.ParseMediaType(accepted)
+	if err != nil {
+		return false
+	}
+	actualType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if acceptedType == "*/*" || acceptedType == actualType {
+		return true
+	}
+	if strings.HasSuffix(acceptedType, "/*") {
+		return strings.HasPrefix(actualType, strings.TrimSuffix(acceptedType, "*"))
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *Message
+	}{{
+		name: "empty",
+		in:   &Message{},
+	}, {
+		name: "payload",
+		in: &Message{
+			Payload:     []byte(`{"hello":"world"}`),
+			ContentType: "application/json",
+		},
+	}, {
+		name: "headers",
+		in: &Message{
+			Payload:     []byte("hello"),
+			ContentType: "text/plain",
+			Headers: map[string]string{
+				"X-Request-Id": "1234",
+				"X-Source":     "test",
+			},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := test.in.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal() unexpected error: %v", err)
+			}
+			got, err := UnmarshalMessage(b)
+			if err != nil {
+				t.Fatalf("UnmarshalMessage() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.in, got); diff != "" {
+				t.Errorf("UnmarshalMessage() (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestContentTypeAccepted(t *testing.T) {
+	tests := []struct {
+		name        string
+		accepted    string
+		contentType string
+		want        bool
+	}{{
+		name:        "same",
+		accepted:    "application/json",
+		contentType: "application/json",
+		want:        true,
+	}, {
+		name:        "parameters",
+		accepted:    "text/plain",
+		contentType: "text/plain; charset=utf-8",
+		want:        true,
+	}, {
+		name:        "different",
+		accepted:    "application/json",
+		contentType: "text/plain",
+		want:        false,
+	}, {
+		name:        "subtype wildcard",
+		accepted:    "text/*",
+		contentType: "text/csv",
+		want:        true,
+	}, {
+		name:        "subtype wildcard, different type",
+		accepted:    "text/*",
+		contentType: "application/json",
+		want:        false,
+	}, {
+		name:        "wildcard",
+		accepted:    "*/*",
+		contentType: "image/png",
+		want:        true,
+	}, {
+		name:        "missing",
+		accepted:    "application/json",
+		contentType: "",
+		want:        false,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ContentTypeAccepted(test.accepted, test.contentType); got != test.want {
+				t.Errorf("ContentTypeAccepted(%q, %q) = %v, want %v", test.accepted, test.contentType, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,436 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package server is a liiklus gateway that keeps the records of each topic in
+// memory, for streams that do not need a broker. Each topic has a single
+// partition, assigned to one member of a consumer group at a time. Given a
+// directory, records and consumer positions are written through to it and
+// loaded again when the gateway restarts.
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/projectriff/system/pkg/liiklus"
+)
+
+const (
+	recordsSuffix   = ".records"
+	positionsSuffix = ".positions"
+)
+
+// Record is a value on a topic
+type Record struct {
+	Key       []byte
+	Value     []byte
+	Timestamp time.Time
+}
+
+// Server is a liiklus gateway holding records in memory
+type Server struct {
+	liiklus.UnimplementedLiiklusServiceServer
+
+	// dir persists the topics, empty to keep them in memory only
+	dir string
+
+	mu       sync.Mutex
+	changed  *sync.Cond
+	topics   map[string]*topic
+	sessions map[string]*session
+	sequence int
+}
+
+type topic struct {
+	records []Record
+	// positions are the last offset acknowledged by each group
+	positions map[string]uint64
+	// members are the session holding the partition for each group
+	members map[string]string
+	file    *os.File
+}
+
+type session struct {
+	topic string
+	group string
+	// start is where a group without a position receives from
+	start uint64
+}
+
+// New creates a gateway. Topics are loaded from and written through to the
+// directory, unless it is empty.
+func New(dir string) (*Server, error) {
+	s := &Server{
+		dir:      dir,
+		topics:   map[string]*topic{},
+		sessions: map[string]*session{},
+	}
+	s.changed = sync.NewCond(&s.mu)
+	if dir == "" {
+		return s, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*"+recordsSuffix))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		name, err := url.PathUnescape(strings.TrimSuffix(filepath.Base(file), recordsSuffix))
+		if err != nil {
+			return nil, fmt.Errorf("unexpected file %q: %v", file, err)
+		}
+		if _, err := s.topic(name); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Close releases the files of the topics
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.topics {
+		if t.file != nil {
+			if err := t.file.Close(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Records returns the records of the topic
+func (s *Server) Records(topic string) []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.topics[topic]; ok {
+		return append([]Record(nil), t.records...)
+	}
+	return nil
+}
+
+// Append adds a record to the topic, returning its offset
+func (s *Server) Append(topic string, record Record) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := s.topic(topic)
+	if err != nil {
+		return 0, err
+	}
+	if t.file != nil {
+		if _, err := t.file.Write(encodeRecord(record)); err != nil {
+			return 0, err
+		}
+	}
+	t.records = append(t.records, record)
+	s.changed.Broadcast()
+	return uint64(len(t.records) - 1), nil
+}
+
+func (s *Server) Publish(ctx context.Context, req *liiklus.PublishRequest) (*liiklus.PublishReply, error) {
+	offset, err := s.Append(req.Topic, Record{Key: req.Key, Value: req.Value, Timestamp: time.Now()})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to store record: %v", err)
+	}
+	return &liiklus.PublishReply{Topic: req.Topic, Offset: offset}, nil
+}
+
+// Subscribe assigns the partition of the topic once no other member of the
+// group holds it. The partition is released when the subscription ends.
+func (s *Server) Subscribe(req *liiklus.SubscribeRequest, stream liiklus.LiiklusService_SubscribeServer) error {
+	ctx := stream.Context()
+	stop := s.wakeOnDone(ctx)
+	defer stop()
+
+	s.mu.Lock()
+	t, err := s.topic(req.Topic)
+	if err != nil {
+		s.mu.Unlock()
+		return status.Errorf(codes.Internal, "unable to load topic: %v", err)
+	}
+	for ctx.Err() == nil && t.members[req.Group] != "" {
+		s.changed.Wait()
+	}
+	if ctx.Err() != nil {
+		s.mu.Unlock()
+		return nil
+	}
+	s.sequence++
+	id := strconv.Itoa(s.sequence)
+	member := &session{topic: req.Topic, group: req.Group}
+	if req.AutoOffsetReset == liiklus.SubscribeRequest_LATEST {
+		member.start = uint64(len(t.records))
+	}
+	s.sessions[id] = member
+	t.members[req.Group] = id
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.sessions, id)
+		delete(t.members, req.Group)
+		s.changed.Broadcast()
+	}()
+	if err := stream.Send(&liiklus.SubscribeReply{
+		Reply: &liiklus.SubscribeReply_Assignment{
+			Assignment: &liiklus.Assignment{SessionId: id},
+		},
+	}); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// Receive streams the records of an assigned partition after the position of
+// the group, until the partition is released
+func (s *Server) Receive(req *liiklus.ReceiveRequest, stream liiklus.LiiklusService_ReceiveServer) error {
+	ctx := stream.Context()
+	stop := s.wakeOnDone(ctx)
+	defer stop()
+
+	id := req.Assignment.GetSessionId()
+	s.mu.Lock()
+	member, ok := s.sessions[id]
+	if !ok {
+		s.mu.Unlock()
+		return status.Errorf(codes.NotFound, "unknown session %q", id)
+	}
+	t := s.topics[member.topic]
+	offset := member.start
+	if position, ok := t.positions[member.group]; ok {
+		offset = position + 1
+	}
+	s.mu.Unlock()
+
+	for {
+		s.mu.Lock()
+		for ctx.Err() == nil && s.sessions[id] != nil && offset >= uint64(len(t.records)) {
+			s.changed.Wait()
+		}
+		if ctx.Err() != nil || s.sessions[id] == nil {
+			s.mu.Unlock()
+			return nil
+		}
+		record := t.records[offset]
+		s.mu.Unlock()
+
+		if err := stream.Send(&liiklus.ReceiveReply{
+			Reply: &liiklus.ReceiveReply_Record_{
+				Record: &liiklus.ReceiveReply_Record{
+					Offset:    offset,
+					Key:       record.Key,
+					Value:     record.Value,
+					Timestamp: timestamppb.New(record.Timestamp),
+				},
+			},
+		}); err != nil {
+			return err
+		}
+		offset++
+	}
+}
+
+// Ack moves the position of the group
+func (s *Server) Ack(ctx context.Context, req *liiklus.AckRequest) (*emptypb.Empty, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := s.topic(req.Topic)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to load topic: %v", err)
+	}
+	t.positions[req.Group] = req.Offset
+	if s.dir != "" {
+		if err := writePositions(s.path(req.Topic, positionsSuffix), t.positions); err != nil {
+			return nil, status.Errorf(codes.Internal, "unable to store position: %v", err)
+		}
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// GetOffsets returns the last offset acknowledged by the group, if any
+func (s *Server) GetOffsets(ctx context.Context, req *liiklus.GetOffsetsRequest) (*liiklus.GetOffsetsReply, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reply := &liiklus.GetOffsetsReply{Offsets: map[uint32]uint64{}}
+	if t, ok := s.topics[req.Topic]; ok {
+		if position, ok := t.positions[req.Group]; ok {
+			reply.Offsets[0] = position
+		}
+	}
+	return reply, nil
+}
+
+// GetEndOffsets returns the offset of the last record, if any
+func (s *Server) GetEndOffsets(ctx context.Context, req *liiklus.GetEndOffsetsRequest) (*liiklus.GetEndOffsetsReply, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reply := &liiklus.GetEndOffsetsReply{Offsets: map[uint32]uint64{}}
+	if t, ok := s.topics[req.Topic]; ok && len(t.records) != 0 {
+		reply.Offsets[0] = uint64(len(t.records) - 1)
+	}
+	return reply, nil
+}
+
+// wakeOnDone wakes the waiters once the context is done, so they notice
+func (s *Server) wakeOnDone(ctx context.Context) func() {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.changed.Broadcast()
+	}()
+	return func() { close(done) }
+}
+
+// topic returns the topic, loading or creating it on first use. The lock must
+// be held.
+func (s *Server) topic(name string) (*topic, error) {
+	if t, ok := s.topics[name]; ok {
+		return t, nil
+	}
+	t := &topic{
+		positions: map[string]uint64{},
+		members:   map[string]string{},
+	}
+	if s.dir != "" {
+		var err error
+		if t.file, t.records, err = openRecords(s.path(name, recordsSuffix)); err != nil {
+			return nil, err
+		}
+		if t.positions, err = readPositions(s.path(name, positionsSuffix)); err != nil {
+			t.file.Close()
+			return nil, err
+		}
+	}
+	s.topics[name] = t
+	return t, nil
+}
+
+func (s *Server) path(topic, suffix string) string {
+	return filepath.Join(s.dir, url.PathEscape(topic)+suffix)
+}
+
+// encodeRecord lays out the timestamp in nanoseconds, followed by the key and
+// value, each prefixed with its length
+func encodeRecord(record Record) []byte {
+	b := make([]byte, 16, 16+len(record.Key)+len(record.Value))
+	binary.BigEndian.PutUint64(b[0:8], uint64(record.Timestamp.UnixNano()))
+	binary.BigEndian.PutUint32(b[8:12], uint32(len(record.Key)))
+	b = append(b[:12], record.Key...)
+	b = append(b, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(b[len(b)-4:], uint32(len(record.Value)))
+	return append(b, record.Value...)
+}
+
+// openRecords reads the records of a topic and opens the file to append more.
+// A record cut short, by a crash while it was written, is dropped.
+func openRecords(path string) (*os.File, []Record, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+	var records []Record
+	var size int64
+	r := bufio.NewReader(file)
+	for {
+		var header [12]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			break
+		}
+		key := make([]byte, binary.BigEndian.Uint32(header[8:12]))
+		if _, err := io.ReadFull(r, key); err != nil {
+			break
+		}
+		var length [4]byte
+		if _, err := io.ReadFull(r, length[:]); err != nil {
+			break
+		}
+		value := make([]byte, binary.BigEndian.Uint32(length[:]))
+		if _, err := io.ReadFull(r, value); err != nil {
+			break
+		}
+		records = append(records, Record{
+			Key:       key,
+			Value:     value,
+			Timestamp: time.Unix(0, int64(binary.BigEndian.Uint64(header[0:8]))),
+		})
+		size += int64(16 + len(key) + len(value))
+	}
+	if err := file.Truncate(size); err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	if _, err := file.Seek(size, io.SeekStart); err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return file, records, nil
+}
+
+func readPositions(path string) (map[string]uint64, error) {
+	positions := map[string]uint64{}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return positions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &positions); err != nil {
+		return nil, fmt.Errorf("invalid positions in %q: %v", path, err)
+	}
+	return positions, nil
+}
+
+// writePositions replaces the positions of a topic, so they are never seen
+// half written
+func writePositions(path string, positions map[string]uint64) error {
+	b, err := json.Marshal(positions)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path+".tmp", b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
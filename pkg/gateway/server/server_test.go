@@ -0,0 +1,210 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/projectriff/system/pkg/gateway"
+	"github.com/projectriff/system/pkg/gateway/server"
+	"github.com/projectriff/system/pkg/liiklus"
+)
+
+// serve exposes the gateway to a client until the test ends
+func serve(t *testing.T, s *server.Server) *gateway.Client {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	liiklus.RegisterLiiklusServiceServer(grpcServer, s)
+	go grpcServer.Serve(listener)
+
+	client, err := gateway.NewClient("bufnet", grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	if err != nil {
+		t.Fatalf("unable to create gateway client: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		grpcServer.Stop()
+	})
+	return client
+}
+
+// receive joins the group and returns the next count values
+func receive(ctx context.Context, t *testing.T, client *gateway.Client, topic, group string, count int) []string {
+	assignments, err := client.Subscribe(ctx, topic, group, gateway.OffsetResetEarliest)
+	if err != nil {
+		t.Fatalf("Subscribe() unexpected error: %v", err)
+	}
+	defer assignments.Close()
+	assignment, err := assignments.Next()
+	if err != nil {
+		t.Fatalf("Next() unexpected error: %v", err)
+	}
+	records, err := client.Receive(ctx, assignment)
+	if err != nil {
+		t.Fatalf("Receive() unexpected error: %v", err)
+	}
+	defer records.Close()
+	var values []string
+	for len(values) < count {
+		record, err := records.Next()
+		if err != nil {
+			t.Fatalf("Next() unexpected error: %v", err)
+		}
+		values = append(values, string(record.Value))
+		if err := client.Ack(ctx, topic, group, assignment, record.Offset); err != nil {
+			t.Fatalf("Ack() unexpected error: %v", err)
+		}
+	}
+	return values
+}
+
+func TestServerDisk(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	dir, err := ioutil.TempDir("", "gateway")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := server.New(dir)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	client := serve(t, s)
+	for _, value := range []string{"one", "two", "three"} {
+		if err := client.Publish(ctx, "default/numbers", []byte("key"), []byte(value)); err != nil {
+			t.Fatalf("Publish() unexpected error: %v", err)
+		}
+	}
+	if diff := cmp.Diff([]string{"one"}, receive(ctx, t, client, "default/numbers", "processor", 1)); diff != "" {
+		t.Errorf("receive() (-want, +got) = %v", diff)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() unexpected error: %v", err)
+	}
+
+	// a record cut short by a crash is dropped
+	file, err := os.OpenFile(filepath.Join(dir, "default%2Fnumbers.records"), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("unable to open records: %v", err)
+	}
+	file.Write([]byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0})
+	file.Close()
+
+	restarted, err := server.New(dir)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	defer restarted.Close()
+	client = serve(t, restarted)
+	if err := client.Publish(ctx, "default/numbers", nil, []byte("four")); err != nil {
+		t.Fatalf("Publish() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"two", "three", "four"}, receive(ctx, t, client, "default/numbers", "processor", 3)); diff != "" {
+		t.Errorf("receive() after restart (-want, +got) = %v", diff)
+	}
+	records := restarted.Records("default/numbers")
+	if len(records) != 4 || string(records[0].Key) != "key" || records[0].Timestamp.IsZero() {
+		t.Errorf("Records() = %+v, want the keys and timestamps of four records", records)
+	}
+}
+
+func TestServerMemory(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	s, err := server.New("")
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	client := serve(t, s)
+	for _, value := range []string{"one", "two"} {
+		if err := client.Publish(ctx, "default_numbers", nil, []byte(value)); err != nil {
+			t.Fatalf("Publish() unexpected error: %v", err)
+		}
+	}
+
+	if diff := cmp.Diff([]string{"one", "two"}, receive(ctx, t, client, "default_numbers", "processor", 2)); diff != "" {
+		t.Errorf("receive() (-want, +got) = %v", diff)
+	}
+	offsets, err := client.Offsets(ctx, "default_numbers", "processor")
+	if err != nil {
+		t.Fatalf("Offsets() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[uint32]uint64{0: 1}, offsets); diff != "" {
+		t.Errorf("Offsets() (-want, +got) = %v", diff)
+	}
+	// other groups start over
+	if diff := cmp.Diff([]string{"one"}, receive(ctx, t, client, "default_numbers", "other", 1)); diff != "" {
+		t.Errorf("receive() other group (-want, +got) = %v", diff)
+	}
+}
+
+func TestServerGroupMembers(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	s, err := server.New("")
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	client := serve(t, s)
+
+	first, err := client.Subscribe(ctx, "default_numbers", "processor", gateway.OffsetResetEarliest)
+	if err != nil {
+		t.Fatalf("Subscribe() unexpected error: %v", err)
+	}
+	if _, err := first.Next(); err != nil {
+		t.Fatalf("Next() unexpected error: %v", err)
+	}
+	second, err := client.Subscribe(ctx, "default_numbers", "processor", gateway.OffsetResetEarliest)
+	if err != nil {
+		t.Fatalf("Subscribe() unexpected error: %v", err)
+	}
+	defer second.Close()
+	assigned := make(chan error)
+	go func() {
+		_, err := second.Next()
+		assigned <- err
+	}()
+
+	select {
+	case <-assigned:
+		t.Fatalf("the partition was assigned to two members of the group")
+	case <-time.After(100 * time.Millisecond):
+	}
+	first.Close()
+	select {
+	case err := <-assigned:
+		if err != nil {
+			t.Fatalf("Next() unexpected error: %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatalf("the partition was not assigned once released")
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package httpsource publishes the body of each POST request to a stream.
+// Requests must declare a content type the stream accepts, the payload is
+// published with that content type.
+package httpsource
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/projectriff/system/pkg/gateway"
+)
+
+// Handler publishes requests to the topic
+type Handler struct {
+	Client *gateway.Client
+	Topic  string
+	// ContentType is accepted by the stream, it may be a wildcard
+	ContentType string
+	// Timeout bounds each publish, defaults to 30 seconds
+	Timeout time.Duration
+	Log     logr.Logger
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST requests are published", http.StatusMethodNotAllowed)
+		return
+	}
+	contentType := r.Header.Get("Content-Type")
+	if !gateway.ContentTypeAccepted(h.ContentType, contentType) {
+		http.Error(w, "the stream accepts content of type "+h.ContentType, http.StatusUnsupportedMediaType)
+		return
+	}
+	payload, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	message, err := (&gateway.Message{Payload: payload, ContentType: contentType}).Marshal()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	timeout := h.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	if err := h.Client.Publish(ctx, h.Topic, nil, message); err != nil {
+		h.Log.Error(err, "unable to publish", "topic", h.Topic)
+		http.Error(w, "unable to publish to the stream", http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpsource_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/projectriff/system/pkg/gateway"
+	"github.com/projectriff/system/pkg/gateway/gatewaytest"
+	"github.com/projectriff/system/pkg/httpsource"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		accepted    string
+		method      string
+		contentType string
+		body        string
+		publishErr  error
+		wantStatus  int
+		want        []*gateway.Message
+	}{{
+		name:        "publishes the body",
+		accepted:    "application/json",
+		method:      http.MethodPost,
+		contentType: "application/json",
+		body:        `{"hello":"world"}`,
+		wantStatus:  http.StatusAccepted,
+		want: []*gateway.Message{
+			{Payload: []byte(`{"hello":"world"}`), ContentType: "application/json"},
+		},
+	}, {
+		name:        "keeps the declared content type",
+		accepted:    "text/*",
+		method:      http.MethodPost,
+		contentType: "text/plain; charset=utf-8",
+		body:        "hello",
+		wantStatus:  http.StatusAccepted,
+		want: []*gateway.Message{
+			{Payload: []byte("hello"), ContentType: "text/plain; charset=utf-8"},
+		},
+	}, {
+		name:        "rejects other methods",
+		accepted:    "*/*",
+		method:      http.MethodGet,
+		contentType: "text/plain",
+		wantStatus:  http.StatusMethodNotAllowed,
+	}, {
+		name:        "rejects content the stream does not accept",
+		accepted:    "application/json",
+		method:      http.MethodPost,
+		contentType: "text/plain",
+		body:        "hello",
+		wantStatus:  http.StatusUnsupportedMediaType,
+	}, {
+		name:        "reports gateway failures",
+		accepted:    "text/plain",
+		method:      http.MethodPost,
+		contentType: "text/plain",
+		body:        "hello",
+		publishErr:  fmt.Errorf("gateway unavailable"),
+		wantStatus:  http.StatusBadGateway,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, client := gatewaytest.NewServer(t)
+			server.PublishErr = test.publishErr
+			handler := &httpsource.Handler{
+				Client:      client,
+				Topic:       "default_orders",
+				ContentType: test.accepted,
+				Log:         zap.Logger(true),
+			}
+
+			req := httptest.NewRequest(test.method, "/", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", test.contentType)
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			if res.Code != test.wantStatus {
+				t.Errorf("ServeHTTP() status = %d, want %d", res.Code, test.wantStatus)
+			}
+			var got []*gateway.Message
+			for _, record := range server.Records("default_orders") {
+				message, err := gateway.UnmarshalMessage(record)
+				if err != nil {
+					t.Fatalf("UnmarshalMessage() unexpected error: %v", err)
+				}
+				got = append(got, message)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ServeHTTP() published (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,432 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package processor runs the function of a streaming Processor. Messages are
+// consumed from the input streams through their gateways, each message is an
+// invocation of the function and the results are published to the output
+// streams before the message is acknowledged.
+package processor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+
+	"github.com/projectriff/system/pkg/gateway"
+	"github.com/projectriff/system/pkg/rpc"
+)
+
+// Stream is a topic on a gateway
+type Stream struct {
+	Client *gateway.Client
+	Topic  string
+}
+
+func (s *Stream) publish(ctx context.Context, message *gateway.Message) error {
+	value, err := message.Marshal()
+	if err != nil {
+		return err
+	}
+	return s.Client.Publish(ctx, s.Topic, nil, value)
+}
+
+// Input is a stream the function consumes
+type Input struct {
+	Stream
+	// Name the function knows the input by
+	Name string
+	// Group is the consumer group reading the stream
+	Group string
+	// Reset is where the group starts on partitions it has no position on
+	Reset gateway.OffsetReset
+	// Since, when set, starts the group at the first record published at or
+	// after it on partitions the group has no position on. Those partitions
+	// are read from the earliest record, acknowledging older records
+	// without processing them.
+	Since time.Time
+}
+
+// Output is a stream the function produces
+type Output struct {
+	Stream
+	// Name the function knows the output by
+	Name string
+	// ContentType the stream accepts
+	ContentType string
+}
+
+// ErrorPolicy decides what happens to messages the function fails to process
+type ErrorPolicy struct {
+	// MaxRetries is negative to retry forever
+	MaxRetries   int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   int
+	// DeadLetter receives the messages whose retries are exhausted, they are
+	// dropped when nil
+	DeadLetter *Stream
+}
+
+// DefaultErrorPolicy retries forever, the behavior of processors without an
+// error policy
+var DefaultErrorPolicy = ErrorPolicy{
+	MaxRetries:   -1,
+	InitialDelay: time.Second,
+	MaxDelay:     time.Minute,
+	Multiplier:   2,
+}
+
+// Processor feeds the messages of its inputs to the function
+type Processor struct {
+	Inputs      []Input
+	Outputs     []Output
+	Function    rpc.RiffClient
+	ErrorPolicy ErrorPolicy
+	// MaxMessages the processor acknowledges before Run returns, zero for no
+	// limit
+	MaxMessages int
+	Log         logr.Logger
+
+	limit *limit
+}
+
+// limit hands out a slot for each message to process, a slot taken by a
+// message that is not acknowledged is handed out again
+type limit struct {
+	slots     chan struct{}
+	mu        sync.Mutex
+	remaining int
+	done      context.CancelFunc
+}
+
+func newLimit(max int, done context.CancelFunc) *limit {
+	l := &limit{
+		slots:     make(chan struct{}, max),
+		remaining: max,
+		done:      done,
+	}
+	for i := 0; i < max; i++ {
+		l.slots <- struct{}{}
+	}
+	return l
+}
+
+// take waits for a free slot, false once the context is done
+func (l *limit) take(ctx context.Context) bool {
+	if l == nil {
+		return true
+	}
+	select {
+	case <-l.slots:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// release frees the slot of a message that was not acknowledged
+func (l *limit) release() {
+	if l != nil {
+		l.slots <- struct{}{}
+	}
+}
+
+// acked consumes the slot of an acknowledged message, the last one ends the
+// run
+func (l *limit) acked() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.remaining--; l.remaining == 0 {
+		l.done()
+	}
+}
+
+// Run consumes the inputs until the context is cancelled or MaxMessages
+// messages are acknowledged
+func (p *Processor) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if p.MaxMessages > 0 {
+		p.limit = newLimit(p.MaxMessages, cancel)
+	}
+
+	var wg sync.WaitGroup
+	for i := range p.Inputs {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			p.runInput(ctx, index)
+		}(i)
+	}
+	wg.Wait()
+	return nil
+}
+
+// runInput consumes an input, joining its consumer group again whenever the
+// subscription ends
+func (p *Processor) runInput(ctx context.Context, index int) {
+	input := p.Inputs[index]
+	log := p.Log.WithValues("input", input.Name, "topic", input.Topic, "group", input.Group)
+
+	// the partitions the group had a position on when the processor started
+	// are not moved, later positions may be records skipped for Since
+	var positions map[uint32]uint64
+	for !input.Since.IsZero() && ctx.Err() == nil {
+		var err error
+		if positions, err = input.Client.Offsets(ctx, input.Topic, input.Group); err == nil {
+			break
+		}
+		log.Error(err, "unable to read the positions of the consumer group")
+		sleep(ctx, time.Second)
+	}
+
+	for ctx.Err() == nil {
+		if err := p.subscribe(ctx, log, index, positions); err != nil && ctx.Err() == nil {
+			log.Error(err, "subscription to the input stream failed")
+		}
+		sleep(ctx, time.Second)
+	}
+}
+
+// subscribe consumes each partition assigned to this member concurrently
+func (p *Processor) subscribe(ctx context.Context, log logr.Logger, index int, positions map[uint32]uint64) error {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	input := p.Inputs[index]
+	reset := input.Reset
+	if !input.Since.IsZero() {
+		reset = gateway.OffsetResetEarliest
+	}
+	assignments, err := input.Client.Subscribe(ctx, input.Topic, input.Group, reset)
+	if err != nil {
+		return err
+	}
+	defer assignments.Close()
+	for {
+		assignment, err := assignments.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		log := log.WithValues("partition", assignment.Partition)
+		log.Info("consuming partition")
+		var since time.Time
+		if _, ok := positions[assignment.Partition]; !ok {
+			since = input.Since
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.consume(ctx, log, index, assignment, since); err != nil && ctx.Err() == nil {
+				log.Error(err, "consuming partition failed")
+				// join again for a fresh assignment
+				cancel()
+			}
+		}()
+	}
+}
+
+// consume processes the records of a partition in order until it is revoked.
+// Records published before since are acknowledged without processing them,
+// until the first record published at or after it.
+func (p *Processor) consume(ctx context.Context, log logr.Logger, index int, assignment gateway.Assignment, since time.Time) error {
+	input := p.Inputs[index]
+	records, err := input.Client.Receive(ctx, assignment)
+	if err != nil {
+		return err
+	}
+	defer records.Close()
+	for {
+		record, err := records.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if !record.Timestamp.IsZero() && record.Timestamp.Before(since) {
+			if err := input.Client.Ack(ctx, input.Topic, input.Group, assignment, record.Offset); err != nil {
+				return err
+			}
+			continue
+		}
+		since = time.Time{}
+		if !p.limit.take(ctx) {
+			return ctx.Err()
+		}
+		if err := p.process(ctx, log.WithValues("offset", record.Offset), index, record); err != nil {
+			p.limit.release()
+			return err
+		}
+		if err := input.Client.Ack(ctx, input.Topic, input.Group, assignment, record.Offset); err != nil {
+			p.limit.release()
+			return err
+		}
+		p.limit.acked()
+	}
+}
+
+// process invokes the function with the record, retrying failed invocations
+// as the error policy says. The record may be acknowledged once process
+// returns without an error.
+func (p *Processor) process(ctx context.Context, log logr.Logger, index int, record gateway.Record) error {
+	message, err := gateway.UnmarshalMessage(record.Value)
+	if err != nil {
+		log.Error(err, "skipping malformed message")
+		return nil
+	}
+
+	policy := p.ErrorPolicy
+	delay := policy.InitialDelay
+	for retries := 0; ; retries++ {
+		results, err := p.invoke(ctx, index, message)
+		if err == nil {
+			return p.publish(ctx, results)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if policy.MaxRetries >= 0 && retries >= policy.MaxRetries {
+			log.Error(err, "retries exhausted")
+			if policy.DeadLetter != nil {
+				return policy.DeadLetter.publish(ctx, message)
+			}
+			return nil
+		}
+		log.Info("retrying message", "delay", delay.String(), "error", err.Error())
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+		delay *= time.Duration(policy.Multiplier)
+		if delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+	}
+}
+
+// result is a message destined for an output
+type result struct {
+	output  int
+	message *gateway.Message
+}
+
+// invoke calls the function with a single message on the input, returning
+// the messages it produced once the invocation completes
+func (p *Processor) invoke(ctx context.Context, index int, message *gateway.Message) ([]result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// wait for the function to start rather than failing the message
+	invocation, err := p.Function.Invoke(ctx, grpc.WaitForReady(true))
+	if err != nil {
+		return nil, err
+	}
+	start := &rpc.StartFrame{
+		ExpectedContentTypes: make([]string, len(p.Outputs)),
+		InputNames:           make([]string, len(p.Inputs)),
+		OutputNames:          make([]string, len(p.Outputs)),
+	}
+	for i, input := range p.Inputs {
+		start.InputNames[i] = input.Name
+	}
+	for i, output := range p.Outputs {
+		start.ExpectedContentTypes[i] = output.ContentType
+		start.OutputNames[i] = output.Name
+	}
+	if err := invocation.Send(&rpc.InputSignal{Frame: &rpc.InputSignal_Start{Start: start}}); err != nil {
+		return nil, err
+	}
+	if err := invocation.Send(&rpc.InputSignal{Frame: &rpc.InputSignal_Data{Data: &rpc.InputFrame{
+		Payload:     message.Payload,
+		ContentType: message.ContentType,
+		Headers:     message.Headers,
+		ArgIndex:    int32(index),
+	}}}); err != nil {
+		return nil, err
+	}
+	if err := invocation.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	var results []result
+	for {
+		signal, err := invocation.Recv()
+		if err == io.EOF {
+			return results, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		frame := signal.GetData()
+		if frame == nil {
+			continue
+		}
+		i := int(frame.ResultIndex)
+		if i < 0 || i >= len(p.Outputs) {
+			return nil, fmt.Errorf("function produced result %d, the processor has %d outputs", i, len(p.Outputs))
+		}
+		contentType := frame.ContentType
+		if contentType == "" {
+			contentType = p.Outputs[i].ContentType
+		}
+		if p.Outputs[i].ContentType != "" && !gateway.ContentTypeAccepted(p.Outputs[i].ContentType, contentType) {
+			return nil, fmt.Errorf("function produced content type %q, output %q accepts %q", contentType, p.Outputs[i].Name, p.Outputs[i].ContentType)
+		}
+		results = append(results, result{
+			output: i,
+			message: &gateway.Message{
+				Payload:     frame.Payload,
+				ContentType: contentType,
+				Headers:     frame.Headers,
+			},
+		})
+	}
+}
+
+func (p *Processor) publish(ctx context.Context, results []result) error {
+	for _, result := range results {
+		if err := p.Outputs[result.output].publish(ctx, result.message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,437 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processor_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/projectriff/system/pkg/gateway"
+	"github.com/projectriff/system/pkg/gateway/gatewaytest"
+	"github.com/projectriff/system/pkg/processor"
+	"github.com/projectriff/system/pkg/rpc"
+)
+
+// fakeFunction answers each input frame of an invocation with the frames
+// returned by handle, failing the invocation when handle errors
+type fakeFunction struct {
+	rpc.UnimplementedRiffServer
+	handle func(start *rpc.StartFrame, in *rpc.InputFrame) ([]*rpc.OutputFrame, error)
+
+	mu          sync.Mutex
+	invocations int
+}
+
+func (f *fakeFunction) Invoke(stream rpc.Riff_InvokeServer) error {
+	f.mu.Lock()
+	f.invocations++
+	f.mu.Unlock()
+
+	signal, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	start := signal.GetStart()
+	if start == nil {
+		return errors.New("expected a start frame")
+	}
+	for {
+		signal, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		outputs, err := f.handle(start, signal.GetData())
+		if err != nil {
+			return err
+		}
+		for _, output := range outputs {
+			if err := stream.Send(&rpc.OutputSignal{Frame: &rpc.OutputSignal_Data{Data: output}}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (f *fakeFunction) Invocations() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.invocations
+}
+
+func newFakeFunction(t *testing.T, f *fakeFunction) rpc.RiffClient {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	rpc.RegisterRiffServer(server, f)
+	go server.Serve(listener)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("unable to dial function: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+	return rpc.NewRiffClient(conn)
+}
+
+func marshal(t *testing.T, message *gateway.Message) []byte {
+	value, err := message.Marshal()
+	if err != nil {
+		t.Fatalf("unable to marshal message: %v", err)
+	}
+	return value
+}
+
+func unmarshalAll(t *testing.T, values [][]byte) []*gateway.Message {
+	var messages []*gateway.Message
+	for _, value := range values {
+		message, err := gateway.UnmarshalMessage(value)
+		if err != nil {
+			t.Fatalf("unable to unmarshal message: %v", err)
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// upper echoes the payload upper cased to the first output
+func upper(start *rpc.StartFrame, in *rpc.InputFrame) ([]*rpc.OutputFrame, error) {
+	return []*rpc.OutputFrame{{Payload: bytes.ToUpper(in.Payload)}}, nil
+}
+
+// failing fails the first failures invocations, then behaves like handle
+func failing(failures int, handle func(*rpc.StartFrame, *rpc.InputFrame) ([]*rpc.OutputFrame, error)) func(*rpc.StartFrame, *rpc.InputFrame) ([]*rpc.OutputFrame, error) {
+	var mu sync.Mutex
+	return func(start *rpc.StartFrame, in *rpc.InputFrame) ([]*rpc.OutputFrame, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures != 0 {
+			failures--
+			return nil, errors.New("function failed")
+		}
+		return handle(start, in)
+	}
+}
+
+func TestProcessor(t *testing.T) {
+	hello := &gateway.Message{Payload: []byte("hello"), ContentType: "text/plain", Headers: map[string]string{"k": "v"}}
+	world := &gateway.Message{Payload: []byte("world"), ContentType: "text/plain"}
+	retries := func(maxRetries int) processor.ErrorPolicy {
+		return processor.ErrorPolicy{
+			MaxRetries:   maxRetries,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+			Multiplier:   2,
+		}
+	}
+	withDeadLetter := func(policy processor.ErrorPolicy) processor.ErrorPolicy {
+		policy.DeadLetter = &processor.Stream{Topic: "dead-letter"}
+		return policy
+	}
+
+	tests := []struct {
+		name            string
+		inputs          map[string][][]byte
+		handle          func(*rpc.StartFrame, *rpc.InputFrame) ([]*rpc.OutputFrame, error)
+		policy          processor.ErrorPolicy
+		wantOutput      []*gateway.Message
+		wantDeadLetter  []*gateway.Message
+		wantInvocations int
+	}{{
+		name:   "publishes results",
+		inputs: map[string][][]byte{"numbers": {marshal(t, hello), marshal(t, world)}},
+		handle: upper,
+		policy: processor.DefaultErrorPolicy,
+		wantOutput: []*gateway.Message{
+			{Payload: []byte("HELLO"), ContentType: "text/plain"},
+			{Payload: []byte("WORLD"), ContentType: "text/plain"},
+		},
+		wantInvocations: 2,
+	}, {
+		name:   "passes input names and indexes",
+		inputs: map[string][][]byte{"letters": {marshal(t, hello)}},
+		handle: func(start *rpc.StartFrame, in *rpc.InputFrame) ([]*rpc.OutputFrame, error) {
+			return []*rpc.OutputFrame{{
+				Payload:     []byte(fmt.Sprintf("%s %s %v", start.InputNames[in.ArgIndex], in.Payload, in.Headers)),
+				ContentType: "text/plain; charset=utf-8",
+				Headers:     map[string]string{"from": start.OutputNames[0]},
+			}}, nil
+		},
+		policy: processor.DefaultErrorPolicy,
+		wantOutput: []*gateway.Message{
+			{Payload: []byte("letters hello map[k:v]"), ContentType: "text/plain; charset=utf-8", Headers: map[string]string{"from": "out"}},
+		},
+		wantInvocations: 1,
+	}, {
+		name:   "retries failed invocations",
+		inputs: map[string][][]byte{"numbers": {marshal(t, hello)}},
+		handle: failing(2, upper),
+		policy: retries(3),
+		wantOutput: []*gateway.Message{
+			{Payload: []byte("HELLO"), ContentType: "text/plain"},
+		},
+		wantInvocations: 3,
+	}, {
+		name:            "dead letters exhausted messages",
+		inputs:          map[string][][]byte{"numbers": {marshal(t, hello)}},
+		handle:          failing(-1, upper),
+		policy:          withDeadLetter(retries(1)),
+		wantDeadLetter:  []*gateway.Message{hello},
+		wantInvocations: 2,
+	}, {
+		name:            "drops exhausted messages without a dead letter stream",
+		inputs:          map[string][][]byte{"numbers": {marshal(t, hello)}},
+		handle:          failing(-1, upper),
+		policy:          retries(0),
+		wantInvocations: 1,
+	}, {
+		name:   "fails results the output does not accept",
+		inputs: map[string][][]byte{"numbers": {marshal(t, hello)}},
+		handle: func(start *rpc.StartFrame, in *rpc.InputFrame) ([]*rpc.OutputFrame, error) {
+			return []*rpc.OutputFrame{{Payload: []byte("{}"), ContentType: "application/json"}}, nil
+		},
+		policy:          withDeadLetter(retries(0)),
+		wantDeadLetter:  []*gateway.Message{hello},
+		wantInvocations: 1,
+	}, {
+		name:   "fails results for unknown outputs",
+		inputs: map[string][][]byte{"numbers": {marshal(t, hello)}},
+		handle: func(start *rpc.StartFrame, in *rpc.InputFrame) ([]*rpc.OutputFrame, error) {
+			return []*rpc.OutputFrame{{Payload: []byte("hello"), ResultIndex: 1}}, nil
+		},
+		policy:          withDeadLetter(retries(0)),
+		wantDeadLetter:  []*gateway.Message{hello},
+		wantInvocations: 1,
+	}, {
+		name:            "skips malformed messages",
+		inputs:          map[string][][]byte{"numbers": {{0xff}}},
+		handle:          upper,
+		policy:          processor.DefaultErrorPolicy,
+		wantInvocations: 0,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, client := gatewaytest.NewServer(t)
+			function := &fakeFunction{handle: test.handle}
+			policy := test.policy
+			if policy.DeadLetter != nil {
+				policy.DeadLetter = &processor.Stream{Client: client, Topic: policy.DeadLetter.Topic}
+			}
+			p := &processor.Processor{
+				Outputs: []processor.Output{{
+					Stream:      processor.Stream{Client: client, Topic: "out"},
+					Name:        "out",
+					ContentType: "text/plain",
+				}},
+				Function:    newFakeFunction(t, function),
+				ErrorPolicy: policy,
+				Log:         zap.Logger(true),
+			}
+			want := 0
+			for _, name := range []string{"numbers", "letters"} {
+				p.Inputs = append(p.Inputs, processor.Input{
+					Stream: processor.Stream{Client: client, Topic: name},
+					Name:   name,
+					Group:  "my-processor",
+				})
+				for _, value := range test.inputs[name] {
+					server.Append(name, value)
+					want++
+				}
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error)
+			go func() { done <- p.Run(ctx) }()
+			acked := func() int {
+				return len(server.Acks("numbers", "my-processor")) + len(server.Acks("letters", "my-processor"))
+			}
+			for deadline := time.Now().Add(10 * time.Second); acked() < want && time.Now().Before(deadline); {
+				time.Sleep(10 * time.Millisecond)
+			}
+			cancel()
+			if err := <-done; err != nil {
+				t.Errorf("Run() unexpected error: %v", err)
+			}
+
+			if got := acked(); got != want {
+				t.Errorf("acknowledged %d messages, want %d", got, want)
+			}
+			if diff := cmp.Diff(test.wantOutput, unmarshalAll(t, server.Records("out"))); diff != "" {
+				t.Errorf("output (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.wantDeadLetter, unmarshalAll(t, server.Records("dead-letter"))); diff != "" {
+				t.Errorf("dead letter (-want, +got) = %v", diff)
+			}
+			if got := function.Invocations(); got != test.wantInvocations {
+				t.Errorf("invocations = %d, want %d", got, test.wantInvocations)
+			}
+		})
+	}
+}
+
+func TestProcessorMaxMessages(t *testing.T) {
+	server, client := gatewaytest.NewServer(t)
+	function := &fakeFunction{handle: failing(1, upper)}
+	p := &processor.Processor{
+		Inputs: []processor.Input{{
+			Stream: processor.Stream{Client: client, Topic: "numbers"},
+			Name:   "numbers",
+			Group:  "my-processor",
+		}},
+		Outputs: []processor.Output{{
+			Stream: processor.Stream{Client: client, Topic: "out"},
+			Name:   "out",
+		}},
+		Function: newFakeFunction(t, function),
+		ErrorPolicy: processor.ErrorPolicy{
+			MaxRetries:   -1,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+			Multiplier:   2,
+		},
+		MaxMessages: 2,
+		Log:         zap.Logger(true),
+	}
+	for _, payload := range []string{"one", "two", "three"} {
+		server.Append("numbers", marshal(t, &gateway.Message{Payload: []byte(payload)}))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := p.Run(ctx); err != nil {
+		t.Fatalf("Run() unexpected error: %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("Run() did not return after %d messages", p.MaxMessages)
+	}
+
+	if got, want := len(server.Acks("numbers", "my-processor")), 2; got != want {
+		t.Errorf("acknowledged %d messages, want %d", got, want)
+	}
+	want := []*gateway.Message{
+		{Payload: []byte("ONE")},
+		{Payload: []byte("TWO")},
+	}
+	if diff := cmp.Diff(want, unmarshalAll(t, server.Records("out"))); diff != "" {
+		t.Errorf("output (-want, +got) = %v", diff)
+	}
+	if got, want := function.Invocations(), 3; got != want {
+		t.Errorf("invocations = %d, want %d", got, want)
+	}
+}
+
+func TestProcessorStartPosition(t *testing.T) {
+	since := time.Date(2019, time.November, 4, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		position   bool
+		wantOutput []*gateway.Message
+	}{{
+		name: "skips records before the timestamp",
+		wantOutput: []*gateway.Message{
+			{Payload: []byte("NEW")},
+		},
+	}, {
+		name:     "keeps the position of the group",
+		position: true,
+		wantOutput: []*gateway.Message{
+			{Payload: []byte("OLDER")},
+			{Payload: []byte("NEW")},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			server, client := gatewaytest.NewServer(t)
+			server.AppendAt("numbers", marshal(t, &gateway.Message{Payload: []byte("old")}), since.Add(-2*time.Hour))
+			server.AppendAt("numbers", marshal(t, &gateway.Message{Payload: []byte("older")}), since.Add(-time.Hour))
+			server.AppendAt("numbers", marshal(t, &gateway.Message{Payload: []byte("new")}), since)
+			if test.position {
+				// the group acknowledged the first record before the processor started
+				assignments, err := client.Subscribe(ctx, "numbers", "my-processor", gateway.OffsetResetEarliest)
+				if err != nil {
+					t.Fatalf("Subscribe() unexpected error: %v", err)
+				}
+				assignment, err := assignments.Next()
+				if err != nil {
+					t.Fatalf("Next() unexpected error: %v", err)
+				}
+				if err := client.Ack(ctx, "numbers", "my-processor", assignment, 0); err != nil {
+					t.Fatalf("Ack() unexpected error: %v", err)
+				}
+				assignments.Close()
+			}
+
+			p := &processor.Processor{
+				Inputs: []processor.Input{{
+					Stream: processor.Stream{Client: client, Topic: "numbers"},
+					Name:   "numbers",
+					Group:  "my-processor",
+					Since:  since,
+				}},
+				Outputs: []processor.Output{{
+					Stream: processor.Stream{Client: client, Topic: "out"},
+					Name:   "out",
+				}},
+				Function:    newFakeFunction(t, &fakeFunction{handle: upper}),
+				ErrorPolicy: processor.DefaultErrorPolicy,
+				MaxMessages: len(test.wantOutput),
+				Log:         zap.Logger(true),
+			}
+			if err := p.Run(ctx); err != nil {
+				t.Fatalf("Run() unexpected error: %v", err)
+			}
+			if ctx.Err() != nil {
+				t.Fatalf("Run() did not return after %d messages", p.MaxMessages)
+			}
+
+			if diff := cmp.Diff([]uint64{0, 1, 2}, server.Acks("numbers", "my-processor")); diff != "" {
+				t.Errorf("acks (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.wantOutput, unmarshalAll(t, server.Records("out"))); diff != "" {
+				t.Errorf("output (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
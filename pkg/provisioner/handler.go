@@ -0,0 +1,200 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package provisioner serves the REST API the stream controller calls to
+// provision the topic of each stream on a broker:
+//
+//	PUT    /<namespace>/<name>                  create or update the topic
+//	DELETE /<namespace>/<name>                  delete the topic
+//	PUT    /<namespace>/<name>/groups/<group>   reset a consumer group
+//
+// The broker specific work is left to a Topics implementation.
+package provisioner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+)
+
+// ErrDeleteUnsupported is returned by Topics that cannot delete topics. The
+// stream controller then leaves the topic behind for an operator to clean up.
+var ErrDeleteUnsupported = errors.New("deleting topics is not supported")
+
+// TopicSettings are the settings a stream asks for its topic. Unset values
+// defer to the broker's defaults.
+type TopicSettings struct {
+	Partitions        *int32 `json:"partitions,omitempty"`
+	ReplicationFactor *int32 `json:"replicationFactor,omitempty"`
+	RetentionMillis   *int64 `json:"retentionMillis,omitempty"`
+	RetentionBytes    *int64 `json:"retentionBytes,omitempty"`
+	Compaction        bool   `json:"compaction,omitempty"`
+}
+
+// Topics manages topics on a broker
+type Topics interface {
+	// Apply creates the topic with the settings. The settings of an existing
+	// topic are updated where the broker allows it in place, the stream
+	// webhook rejects the other changes.
+	Apply(ctx context.Context, topic string, settings TopicSettings) error
+	// Delete deletes the topic, a missing topic is not an error. Returns
+	// ErrDeleteUnsupported when the broker cannot delete topics.
+	Delete(ctx context.Context, topic string) error
+}
+
+// Handler serves the provisioner API for the topics behind a gateway
+type Handler struct {
+	// Gateway is the address of the liiklus gateway serving the topics
+	Gateway string
+	Topics  Topics
+	// Timeout bounds each call to the broker
+	Timeout time.Duration
+	Log     logr.Logger
+}
+
+// ServeHTTP handles requests for /<namespace>/<name> and
+// /<namespace>/<name>/groups/<group>
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	for _, part := range parts {
+		if part == "" {
+			parts = nil
+			break
+		}
+	}
+	switch {
+	case len(parts) == 2:
+		h.serveStream(w, r, parts[0], parts[1])
+	case len(parts) == 4 && parts[2] == "groups":
+		h.serveConsumerGroup(w, r, parts[0], parts[1], parts[3])
+	default:
+		http.Error(w, "expected a path of the form /<namespace>/<name> or /<namespace>/<name>/groups/<group>", http.StatusNotFound)
+	}
+}
+
+// TopicName qualifies the stream's topic by namespace to keep tenants apart
+func TopicName(namespace, name string) string {
+	return fmt.Sprintf("%s_%s", namespace, name)
+}
+
+func (h *Handler) serveStream(w http.ResponseWriter, r *http.Request, namespace, name string) {
+	ctx, cancel := h.context(r)
+	defer cancel()
+	topic := TopicName(namespace, name)
+	log := h.Log.WithValues("namespace", namespace, "name", name, "topic", topic)
+
+	switch r.Method {
+	case http.MethodPut:
+		settings := TopicSettings{}
+		// older controllers send no body, the broker defaults apply
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil && err != io.EOF {
+			http.Error(w, fmt.Sprintf("invalid request body: %s", err), http.StatusBadRequest)
+			return
+		}
+		if err := h.Topics.Apply(ctx, topic, settings); err != nil {
+			log.Error(err, "unable to provision stream")
+			http.Error(w, fmt.Sprintf("unable to provision topic %q: %s", topic, err), http.StatusBadGateway)
+			return
+		}
+		log.Info("provisioned stream")
+		writeJSON(w, log, streamingv1alpha1.StreamAddress{
+			Gateway: h.Gateway,
+			Topic:   topic,
+		})
+	case http.MethodDelete:
+		if err := h.Topics.Delete(ctx, topic); err != nil {
+			if errors.Is(err, ErrDeleteUnsupported) {
+				w.Header().Set("allow", "PUT")
+				http.Error(w, err.Error(), http.StatusMethodNotAllowed)
+				return
+			}
+			log.Error(err, "unable to deprovision stream")
+			http.Error(w, fmt.Sprintf("unable to delete topic %q: %s", topic, err), http.StatusBadGateway)
+			return
+		}
+		log.Info("deprovisioned stream")
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.Header().Set("allow", "PUT, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+type consumerGroupResetRequest struct {
+	Position string `json:"position"`
+}
+
+type consumerGroupResetResponse struct {
+	Group string `json:"group"`
+}
+
+// serveConsumerGroup resets a consumer group by handing out a fresh group.
+// The gateway offers no way to move the position of a group, and a group
+// without positions starts from the earliest or latest message, or the first
+// message after a timestamp, as the consumer asks, whatever the broker.
+func (h *Handler) serveConsumerGroup(w http.ResponseWriter, r *http.Request, namespace, name, group string) {
+	if r.Method != http.MethodPut {
+		w.Header().Set("allow", "PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	request := consumerGroupResetRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %s", err), http.StatusBadRequest)
+		return
+	}
+	if !isStartPosition(request.Position) {
+		http.Error(w, fmt.Sprintf("position %q must be %q, %q or an RFC 3339 timestamp", request.Position, streamingv1alpha1.StartPositionEarliest, streamingv1alpha1.StartPositionLatest), http.StatusBadRequest)
+		return
+	}
+	response := consumerGroupResetResponse{
+		Group: fmt.Sprintf("%s-%s", group, strconv.FormatInt(time.Now().UnixNano(), 36)),
+	}
+	h.Log.Info("reset consumer group", "namespace", namespace, "name", name, "group", group, "position", request.Position, "replacement", response.Group)
+	writeJSON(w, h.Log, response)
+}
+
+func isStartPosition(position string) bool {
+	if position == streamingv1alpha1.StartPositionEarliest || position == streamingv1alpha1.StartPositionLatest {
+		return true
+	}
+	_, err := time.Parse(time.RFC3339, position)
+	return err == nil
+}
+
+func (h *Handler) context(r *http.Request) (context.Context, context.CancelFunc) {
+	if h.Timeout == 0 {
+		return context.WithCancel(r.Context())
+	}
+	return context.WithTimeout(r.Context(), h.Timeout)
+}
+
+func writeJSON(w http.ResponseWriter, log logr.Logger, body interface{}) {
+	w.Header().Set("content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Error(err, "unable to write response")
+	}
+}
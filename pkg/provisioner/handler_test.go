@@ -0,0 +1,231 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/provisioner"
+)
+
+// recordingTopics records the calls made by the handler
+type recordingTopics struct {
+	applied   map[string]provisioner.TopicSettings
+	deleted   []string
+	applyErr  error
+	deleteErr error
+}
+
+func (t *recordingTopics) Apply(ctx context.Context, topic string, settings provisioner.TopicSettings) error {
+	if t.applyErr != nil {
+		return t.applyErr
+	}
+	if t.applied == nil {
+		t.applied = map[string]provisioner.TopicSettings{}
+	}
+	t.applied[topic] = settings
+	return nil
+}
+
+func (t *recordingTopics) Delete(ctx context.Context, topic string) error {
+	if t.deleteErr != nil {
+		return t.deleteErr
+	}
+	t.deleted = append(t.deleted, topic)
+	return nil
+}
+
+func TestHandlerStream(t *testing.T) {
+	partitions := int32(3)
+	retention := int64(60000)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		topics      *recordingTopics
+		wantStatus  int
+		wantAddress *streamingv1alpha1.StreamAddress
+		wantApplied map[string]provisioner.TopicSettings
+		wantDeleted []string
+	}{{
+		name:        "provision with settings",
+		method:      http.MethodPut,
+		path:        "/default/orders",
+		body:        `{"partitions":3,"retentionMillis":60000,"compaction":true}`,
+		topics:      &recordingTopics{},
+		wantStatus:  http.StatusOK,
+		wantAddress: &streamingv1alpha1.StreamAddress{Gateway: "gateway:6565", Topic: "default_orders"},
+		wantApplied: map[string]provisioner.TopicSettings{
+			"default_orders": {Partitions: &partitions, RetentionMillis: &retention, Compaction: true},
+		},
+	}, {
+		name:        "provision without a body",
+		method:      http.MethodPut,
+		path:        "/default/orders",
+		topics:      &recordingTopics{},
+		wantStatus:  http.StatusOK,
+		wantAddress: &streamingv1alpha1.StreamAddress{Gateway: "gateway:6565", Topic: "default_orders"},
+		wantApplied: map[string]provisioner.TopicSettings{
+			"default_orders": {},
+		},
+	}, {
+		name:       "invalid body",
+		method:     http.MethodPut,
+		path:       "/default/orders",
+		body:       `{"partitions":"three"}`,
+		topics:     &recordingTopics{},
+		wantStatus: http.StatusBadRequest,
+	}, {
+		name:       "broker failure",
+		method:     http.MethodPut,
+		path:       "/default/orders",
+		topics:     &recordingTopics{applyErr: fmt.Errorf("broker unavailable")},
+		wantStatus: http.StatusBadGateway,
+	}, {
+		name:        "deprovision",
+		method:      http.MethodDelete,
+		path:        "/default/orders",
+		topics:      &recordingTopics{},
+		wantStatus:  http.StatusOK,
+		wantDeleted: []string{"default_orders"},
+	}, {
+		name:       "deprovision unsupported",
+		method:     http.MethodDelete,
+		path:       "/default/orders",
+		topics:     &recordingTopics{deleteErr: provisioner.ErrDeleteUnsupported},
+		wantStatus: http.StatusMethodNotAllowed,
+	}, {
+		name:       "other methods",
+		method:     http.MethodPost,
+		path:       "/default/orders",
+		topics:     &recordingTopics{},
+		wantStatus: http.StatusMethodNotAllowed,
+	}, {
+		name:       "unknown path",
+		method:     http.MethodPut,
+		path:       "/default",
+		topics:     &recordingTopics{},
+		wantStatus: http.StatusNotFound,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := &provisioner.Handler{
+				Gateway: "gateway:6565",
+				Topics:  test.topics,
+				Log:     zap.Logger(true),
+			}
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			if res.Code != test.wantStatus {
+				t.Fatalf("ServeHTTP() status = %d, want %d: %s", res.Code, test.wantStatus, res.Body.String())
+			}
+			if test.wantAddress != nil {
+				address := &streamingv1alpha1.StreamAddress{}
+				if err := json.NewDecoder(res.Body).Decode(address); err != nil {
+					t.Fatalf("unable to decode address: %v", err)
+				}
+				if diff := cmp.Diff(test.wantAddress, address); diff != "" {
+					t.Errorf("ServeHTTP() address (-want, +got) = %v", diff)
+				}
+			}
+			if diff := cmp.Diff(test.wantApplied, test.topics.applied); diff != "" {
+				t.Errorf("ServeHTTP() applied (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.wantDeleted, test.topics.deleted); diff != "" {
+				t.Errorf("ServeHTTP() deleted (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestHandlerConsumerGroup(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+	}{{
+		name:       "reset to earliest",
+		method:     http.MethodPut,
+		body:       `{"position":"earliest"}`,
+		wantStatus: http.StatusOK,
+	}, {
+		name:       "reset to latest",
+		method:     http.MethodPut,
+		body:       `{"position":"latest"}`,
+		wantStatus: http.StatusOK,
+	}, {
+		name:       "reset to timestamp",
+		method:     http.MethodPut,
+		body:       `{"position":"2019-11-04T00:00:00Z"}`,
+		wantStatus: http.StatusOK,
+	}, {
+		name:       "invalid position",
+		method:     http.MethodPut,
+		body:       `{"position":"yesterday"}`,
+		wantStatus: http.StatusBadRequest,
+	}, {
+		name:       "other methods",
+		method:     http.MethodGet,
+		wantStatus: http.StatusMethodNotAllowed,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := &provisioner.Handler{
+				Gateway: "gateway:6565",
+				Topics:  &recordingTopics{},
+				Log:     zap.Logger(true),
+			}
+			req := httptest.NewRequest(test.method, "/default/orders/groups/my-processor", strings.NewReader(test.body))
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			if res.Code != test.wantStatus {
+				t.Fatalf("ServeHTTP() status = %d, want %d: %s", res.Code, test.wantStatus, res.Body.String())
+			}
+			if res.Code != http.StatusOK {
+				return
+			}
+			response := struct {
+				Group string `json:"group"`
+			}{}
+			if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+				t.Fatalf("unable to decode response: %v", err)
+			}
+			// a fresh group has no positions, so it starts over
+			if !strings.HasPrefix(response.Group, "my-processor-") {
+				t.Errorf("ServeHTTP() group = %q, want a fresh group for my-processor", response.Group)
+			}
+		})
+	}
+}
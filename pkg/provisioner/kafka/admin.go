@@ -0,0 +1,342 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kafka provisions the topics of streams on a Kafka cluster. It
+// speaks just enough of the Kafka protocol to create, update and delete
+// topics.
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/projectriff/system/pkg/provisioner"
+)
+
+// defaultTimeout bounds the broker side of requests without a deadline
+const defaultTimeout = 30 * time.Second
+
+// Config locates and identifies the provisioner to the cluster
+type Config struct {
+	// Brokers to bootstrap from, in the form host:port
+	Brokers []string
+	// ClientID identifies the provisioner in the broker logs
+	ClientID string
+	// TLS encrypts the connections to the brokers when set
+	TLS *tls.Config
+	// SASL authenticates the connections to the brokers when set
+	SASL *SASL
+}
+
+// Admin manages topics on a Kafka cluster. Each call opens its own
+// connections, provisioning is too infrequent to keep them around.
+type Admin struct {
+	config Config
+	dialer net.Dialer
+}
+
+var _ provisioner.Topics = (*Admin)(nil)
+
+func NewAdmin(config Config) *Admin {
+	return &Admin{config: config}
+}
+
+// Apply creates the topic, or grows its partitions and updates its configs
+// when it exists. Brokers before Kafka 2.4 cannot default the partitions and
+// replication factor of a new topic, a single partition and replica are
+// used when the stream does not set them.
+func (a *Admin) Apply(ctx context.Context, topic string, settings provisioner.TopicSettings) error {
+	controller, metadata, err := a.connectController(ctx, topic)
+	if err != nil {
+		return err
+	}
+	defer controller.Close()
+
+	switch metadata.ErrorCode {
+	case errUnknownTopicOrPartition:
+		created, err := a.createTopic(ctx, controller, topic, settings)
+		if err != nil || created {
+			return err
+		}
+		// created concurrently, apply the settings to the existing topic
+		_, metadata, err = a.connectController(ctx, topic)
+		if err != nil {
+			return err
+		}
+	case 0:
+	default:
+		return errorForCode(metadata.ErrorCode, nil)
+	}
+
+	if settings.Partitions != nil && *settings.Partitions > int32(len(metadata.Partitions)) {
+		if err := a.createPartitions(ctx, controller, topic, *settings.Partitions); err != nil {
+			return err
+		}
+	}
+	return a.alterConfigs(ctx, controller, topic, settings)
+}
+
+// Delete deletes the topic. Clusters with topic deletion disabled return
+// ErrDeleteUnsupported.
+func (a *Admin) Delete(ctx context.Context, topic string) error {
+	controller, metadata, err := a.connectController(ctx, topic)
+	if err != nil {
+		return err
+	}
+	defer controller.Close()
+
+	switch metadata.ErrorCode {
+	case errUnknownTopicOrPartition:
+		return nil
+	case 0:
+	default:
+		return errorForCode(metadata.ErrorCode, nil)
+	}
+
+	req := kmsg.NewPtrDeleteTopicsRequest()
+	req.TimeoutMillis = timeoutMillis(ctx)
+	req.TopicNames = []string{topic}
+	requestTopic := kmsg.NewDeleteTopicsRequestTopic()
+	requestTopic.Topic = kmsg.StringPtr(topic)
+	req.Topics = []kmsg.DeleteTopicsRequestTopic{requestTopic}
+	res, err := controller.request(ctx, req)
+	if err != nil {
+		return err
+	}
+	for _, t := range res.(*kmsg.DeleteTopicsResponse).Topics {
+		switch t.ErrorCode {
+		case 0, errUnknownTopicOrPartition:
+		case errTopicDeletionDisabled:
+			return provisioner.ErrDeleteUnsupported
+		default:
+			return errorForCode(t.ErrorCode, t.ErrorMessage)
+		}
+	}
+	return nil
+}
+
+func (a *Admin) createTopic(ctx context.Context, controller *conn, topic string, settings provisioner.TopicSettings) (bool, error) {
+	req := kmsg.NewPtrCreateTopicsRequest()
+	requestTopic := kmsg.NewCreateTopicsRequestTopic()
+	requestTopic.Topic = topic
+	// -1 defers to the broker defaults, since v4
+	requestTopic.NumPartitions = -1
+	requestTopic.ReplicationFactor = -1
+	if controller.versions[req.Key()] < 4 {
+		requestTopic.NumPartitions = 1
+		requestTopic.ReplicationFactor = 1
+	}
+	if settings.Partitions != nil {
+		requestTopic.NumPartitions = *settings.Partitions
+	}
+	if settings.ReplicationFactor != nil {
+		requestTopic.ReplicationFactor = int16(*settings.ReplicationFactor)
+	}
+	for name, value := range topicConfigs(settings) {
+		if value == nil {
+			// the broker default applies to new topics
+			continue
+		}
+		config := kmsg.NewCreateTopicsRequestTopicConfig()
+		config.Name = name
+		config.Value = value
+		requestTopic.Configs = append(requestTopic.Configs, config)
+	}
+
+	req.TimeoutMillis = timeoutMillis(ctx)
+	req.Topics = []kmsg.CreateTopicsRequestTopic{requestTopic}
+	res, err := controller.request(ctx, req)
+	if err != nil {
+		return false, err
+	}
+	for _, t := range res.(*kmsg.CreateTopicsResponse).Topics {
+		switch t.ErrorCode {
+		case 0:
+		case errTopicAlreadyExists:
+			return false, nil
+		default:
+			return false, errorForCode(t.ErrorCode, t.ErrorMessage)
+		}
+	}
+	return true, nil
+}
+
+func (a *Admin) createPartitions(ctx context.Context, controller *conn, topic string, count int32) error {
+	requestTopic := kmsg.NewCreatePartitionsRequestTopic()
+	requestTopic.Topic = topic
+	requestTopic.Count = count
+
+	req := kmsg.NewPtrCreatePartitionsRequest()
+	req.TimeoutMillis = timeoutMillis(ctx)
+	req.Topics = []kmsg.CreatePartitionsRequestTopic{requestTopic}
+	res, err := controller.request(ctx, req)
+	if err != nil {
+		return err
+	}
+	for _, t := range res.(*kmsg.CreatePartitionsResponse).Topics {
+		if t.ErrorCode != 0 {
+			return errorForCode(t.ErrorCode, t.ErrorMessage)
+		}
+	}
+	return nil
+}
+
+// alterConfigs sets the topic configs the stream asks for and reverts the
+// others to the broker defaults
+func (a *Admin) alterConfigs(ctx context.Context, c *conn, topic string, settings provisioner.TopicSettings) error {
+	resource := kmsg.NewIncrementalAlterConfigsRequestResource()
+	resource.ResourceType = kmsg.ConfigResourceTypeTopic
+	resource.ResourceName = topic
+	for name, value := range topicConfigs(settings) {
+		config := kmsg.NewIncrementalAlterConfigsRequestResourceConfig()
+		config.Name = name
+		config.Op = kmsg.IncrementalAlterConfigOpSet
+		config.Value = value
+		if value == nil {
+			config.Op = kmsg.IncrementalAlterConfigOpDelete
+		}
+		resource.Configs = append(resource.Configs, config)
+	}
+
+	req := kmsg.NewPtrIncrementalAlterConfigsRequest()
+	if !c.supports(req) {
+		return fmt.Errorf("updating the configs of topic %q requires Kafka 2.3 or later", topic)
+	}
+	req.Resources = []kmsg.IncrementalAlterConfigsRequestResource{resource}
+	res, err := c.request(ctx, req)
+	if err != nil {
+		return err
+	}
+	for _, r := range res.(*kmsg.IncrementalAlterConfigsResponse).Resources {
+		if r.ErrorCode != 0 {
+			return errorForCode(r.ErrorCode, r.ErrorMessage)
+		}
+	}
+	return nil
+}
+
+// topicConfigs maps the settings to topic configs, nil for the configs left
+// to the broker defaults
+func topicConfigs(settings provisioner.TopicSettings) map[string]*string {
+	configs := map[string]*string{
+		"retention.ms":    nil,
+		"retention.bytes": nil,
+		"cleanup.policy":  nil,
+	}
+	if settings.RetentionMillis != nil {
+		configs["retention.ms"] = kmsg.StringPtr(strconv.FormatInt(*settings.RetentionMillis, 10))
+	}
+	if settings.RetentionBytes != nil {
+		configs["retention.bytes"] = kmsg.StringPtr(strconv.FormatInt(*settings.RetentionBytes, 10))
+	}
+	if settings.Compaction {
+		configs["cleanup.policy"] = kmsg.StringPtr("compact")
+	}
+	return configs
+}
+
+// connectController looks up the topic and connects to the controller,
+// topics are created and deleted through the controller
+func (a *Admin) connectController(ctx context.Context, topic string) (*conn, *kmsg.MetadataResponseTopic, error) {
+	bootstrap, err := a.connectAny(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer bootstrap.Close()
+
+	req := kmsg.NewPtrMetadataRequest()
+	requestTopic := kmsg.NewMetadataRequestTopic()
+	requestTopic.Topic = kmsg.StringPtr(topic)
+	req.Topics = []kmsg.MetadataRequestTopic{requestTopic}
+	req.AllowAutoTopicCreation = false
+	res, err := bootstrap.request(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata := res.(*kmsg.MetadataResponse)
+	if len(metadata.Topics) != 1 {
+		return nil, nil, fmt.Errorf("expected metadata for topic %q, got %d topics", topic, len(metadata.Topics))
+	}
+
+	for _, broker := range metadata.Brokers {
+		if broker.NodeID == metadata.ControllerID {
+			controller, err := a.connect(ctx, net.JoinHostPort(broker.Host, strconv.Itoa(int(broker.Port))))
+			if err != nil {
+				return nil, nil, err
+			}
+			return controller, &metadata.Topics[0], nil
+		}
+	}
+	return nil, nil, fmt.Errorf("controller %d is not a known broker", metadata.ControllerID)
+}
+
+// connectAny connects to the first bootstrap broker reachable
+func (a *Admin) connectAny(ctx context.Context) (*conn, error) {
+	if len(a.config.Brokers) == 0 {
+		return nil, errors.New("no brokers configured")
+	}
+	var err error
+	for _, address := range a.config.Brokers {
+		var c *conn
+		if c, err = a.connect(ctx, address); err == nil {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("unable to connect to any of brokers %v, last error: %w", a.config.Brokers, err)
+}
+
+func (a *Admin) connect(ctx context.Context, address string) (*conn, error) {
+	nc, err := a.dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	if a.config.TLS != nil {
+		config := a.config.TLS.Clone()
+		if config.ServerName == "" {
+			config.ServerName, _, _ = net.SplitHostPort(address)
+		}
+		tlsConn := tls.Client(nc, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			nc.Close()
+			return nil, fmt.Errorf("unable to connect to broker %s: %w", address, err)
+		}
+		nc = tlsConn
+	}
+	c, err := newConn(ctx, nc, a.config.ClientID)
+	if err == nil && a.config.SASL != nil {
+		err = c.authenticate(ctx, a.config.SASL)
+	}
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("unable to connect to broker %s: %w", address, err)
+	}
+	return c, nil
+}
+
+func timeoutMillis(ctx context.Context) int32 {
+	timeout := defaultTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	return int32(timeout / time.Millisecond)
+}
@@ -0,0 +1,577 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"github.com/xdg-go/scram"
+
+	"github.com/projectriff/system/pkg/provisioner"
+)
+
+// fakeTopic is a topic held by the fake broker
+type fakeTopic struct {
+	Partitions        int32
+	ReplicationFactor int16
+	Configs           map[string]string
+}
+
+// fakeBroker is a single broker cluster answering the requests used by Admin
+type fakeBroker struct {
+	t        *testing.T
+	listener net.Listener
+
+	mu     sync.Mutex
+	topics map[string]*fakeTopic
+	// maxVersions overrides the versions advertised for a key
+	maxVersions map[int16]int16
+	// deleteDisabled mimics delete.topic.enable=false
+	deleteDisabled bool
+	// sasl is the mechanism and credentials required from clients when set
+	sasl *SASL
+}
+
+// fakeSession is the authentication state of a connection to the fake broker
+type fakeSession struct {
+	mechanism     string
+	scram         *scram.ServerConversation
+	authenticated bool
+}
+
+func newFakeBroker(t *testing.T) *fakeBroker {
+	return newFakeBrokerWithTLS(t, nil)
+}
+
+// newFakeBrokerWithTLS serves TLS connections when the config is set
+func newFakeBrokerWithTLS(t *testing.T, config *tls.Config) *fakeBroker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	if config != nil {
+		listener = tls.NewListener(listener, config)
+	}
+	b := &fakeBroker{
+		t:           t,
+		listener:    listener,
+		topics:      map[string]*fakeTopic{},
+		maxVersions: map[int16]int16{},
+	}
+	t.Cleanup(func() { listener.Close() })
+	go b.serve()
+	return b
+}
+
+func (b *fakeBroker) address() string {
+	return b.listener.Addr().String()
+}
+
+func (b *fakeBroker) serve() {
+	for {
+		c, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.serveConn(c)
+	}
+}
+
+func (b *fakeBroker) serveConn(c net.Conn) {
+	defer c.Close()
+	session := &fakeSession{}
+	for {
+		var size int32
+		if err := binary.Read(c, binary.BigEndian, &size); err != nil {
+			return
+		}
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(c, buf); err != nil {
+			return
+		}
+		key := int16(binary.BigEndian.Uint16(buf[0:]))
+		version := int16(binary.BigEndian.Uint16(buf[2:]))
+		correlationID := buf[4:8]
+		clientIDLength := int16(binary.BigEndian.Uint16(buf[8:]))
+		body := buf[10:]
+		if clientIDLength > 0 {
+			body = body[clientIDLength:]
+		}
+
+		req := kmsg.RequestForKey(key)
+		req.SetVersion(version)
+		if req.IsFlexible() {
+			var err error
+			if body, err = skipTags(body); err != nil {
+				b.t.Errorf("invalid request header: %v", err)
+				return
+			}
+		}
+		if err := req.ReadFrom(body); err != nil {
+			b.t.Errorf("unable to read %T: %v", req, err)
+			return
+		}
+
+		res := b.handle(session, req)
+		if res == nil {
+			// brokers drop unauthenticated connections
+			return
+		}
+		res.SetVersion(version)
+		out := append([]byte{0, 0, 0, 0}, correlationID...)
+		if res.IsFlexible() && key != apiVersionsKey {
+			out = append(out, 0)
+		}
+		out = res.AppendTo(out)
+		binary.BigEndian.PutUint32(out, uint32(len(out)-4))
+		if _, err := c.Write(out); err != nil {
+			return
+		}
+	}
+}
+
+func (b *fakeBroker) handle(session *fakeSession, req kmsg.Request) kmsg.Response {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch req.(type) {
+	case *kmsg.ApiVersionsRequest, *kmsg.SASLHandshakeRequest, *kmsg.SASLAuthenticateRequest:
+	default:
+		if b.sasl != nil && !session.authenticated {
+			return nil
+		}
+	}
+
+	switch req := req.(type) {
+	case *kmsg.ApiVersionsRequest:
+		res := kmsg.NewPtrApiVersionsResponse()
+		for _, r := range []kmsg.Request{
+			kmsg.NewPtrApiVersionsRequest(),
+			kmsg.NewPtrMetadataRequest(),
+			kmsg.NewPtrCreateTopicsRequest(),
+			kmsg.NewPtrDeleteTopicsRequest(),
+			kmsg.NewPtrCreatePartitionsRequest(),
+			kmsg.NewPtrIncrementalAlterConfigsRequest(),
+			kmsg.NewPtrSASLHandshakeRequest(),
+			kmsg.NewPtrSASLAuthenticateRequest(),
+		} {
+			max, ok := b.maxVersions[r.Key()]
+			if !ok {
+				max = r.MaxVersion()
+			}
+			if max < 0 {
+				// not supported at all
+				continue
+			}
+			key := kmsg.NewApiVersionsResponseApiKey()
+			key.ApiKey = r.Key()
+			key.MaxVersion = max
+			res.ApiKeys = append(res.ApiKeys, key)
+		}
+		return res
+
+	case *kmsg.SASLHandshakeRequest:
+		res := kmsg.NewPtrSASLHandshakeResponse()
+		if b.sasl == nil || req.Mechanism != b.sasl.Mechanism {
+			res.ErrorCode = 33
+			return res
+		}
+		session.mechanism = req.Mechanism
+		if req.Mechanism != SASLMechanismPlain {
+			session.scram = b.scramServer().NewConversation()
+		}
+		return res
+
+	case *kmsg.SASLAuthenticateRequest:
+		res := kmsg.NewPtrSASLAuthenticateResponse()
+		switch {
+		case session.mechanism == "":
+			res.ErrorCode = 34
+		case session.scram == nil:
+			if string(req.SASLAuthBytes) != "\x00"+b.sasl.Username+"\x00"+b.sasl.Password {
+				res.ErrorCode = 58
+				return res
+			}
+			session.authenticated = true
+		default:
+			challenge, err := session.scram.Step(string(req.SASLAuthBytes))
+			if err != nil {
+				res.ErrorCode = 58
+				res.ErrorMessage = kmsg.StringPtr(err.Error())
+				return res
+			}
+			res.SASLAuthBytes = []byte(challenge)
+			session.authenticated = session.scram.Valid()
+		}
+		return res
+
+	case *kmsg.MetadataRequest:
+		host, port, _ := net.SplitHostPort(b.address())
+		portNumber, _ := strconv.Atoi(port)
+		broker := kmsg.NewMetadataResponseBroker()
+		broker.NodeID = 1
+		broker.Host = host
+		broker.Port = int32(portNumber)
+		res := kmsg.NewPtrMetadataResponse()
+		res.Brokers = []kmsg.MetadataResponseBroker{broker}
+		res.ControllerID = 1
+		for _, requestTopic := range req.Topics {
+			t := kmsg.NewMetadataResponseTopic()
+			t.Topic = requestTopic.Topic
+			if topic, ok := b.topics[*requestTopic.Topic]; ok {
+				t.Partitions = make([]kmsg.MetadataResponseTopicPartition, topic.Partitions)
+			} else {
+				t.ErrorCode = errUnknownTopicOrPartition
+			}
+			res.Topics = append(res.Topics, t)
+		}
+		return res
+
+	case *kmsg.CreateTopicsRequest:
+		res := kmsg.NewPtrCreateTopicsResponse()
+		for _, requestTopic := range req.Topics {
+			t := kmsg.NewCreateTopicsResponseTopic()
+			t.Topic = requestTopic.Topic
+			if _, ok := b.topics[requestTopic.Topic]; ok {
+				t.ErrorCode = errTopicAlreadyExists
+			} else {
+				topic := &fakeTopic{
+					Partitions:        requestTopic.NumPartitions,
+					ReplicationFactor: requestTopic.ReplicationFactor,
+					Configs:           map[string]string{},
+				}
+				// the broker defaults
+				if topic.Partitions == -1 {
+					topic.Partitions = 6
+				}
+				if topic.ReplicationFactor == -1 {
+					topic.ReplicationFactor = 3
+				}
+				for _, config := range requestTopic.Configs {
+					topic.Configs[config.Name] = *config.Value
+				}
+				b.topics[requestTopic.Topic] = topic
+			}
+			res.Topics = append(res.Topics, t)
+		}
+		return res
+
+	case *kmsg.CreatePartitionsRequest:
+		res := kmsg.NewPtrCreatePartitionsResponse()
+		for _, requestTopic := range req.Topics {
+			t := kmsg.NewCreatePartitionsResponseTopic()
+			t.Topic = requestTopic.Topic
+			if topic, ok := b.topics[requestTopic.Topic]; !ok {
+				t.ErrorCode = errUnknownTopicOrPartition
+			} else if requestTopic.Count <= topic.Partitions {
+				t.ErrorCode = 37
+			} else {
+				topic.Partitions = requestTopic.Count
+			}
+			res.Topics = append(res.Topics, t)
+		}
+		return res
+
+	case *kmsg.IncrementalAlterConfigsRequest:
+		res := kmsg.NewPtrIncrementalAlterConfigsResponse()
+		for _, resource := range req.Resources {
+			r := kmsg.NewIncrementalAlterConfigsResponseResource()
+			r.ResourceType = resource.ResourceType
+			r.ResourceName = resource.ResourceName
+			if topic, ok := b.topics[resource.ResourceName]; !ok {
+				r.ErrorCode = errUnknownTopicOrPartition
+			} else {
+				for _, config := range resource.Configs {
+					switch config.Op {
+					case kmsg.IncrementalAlterConfigOpSet:
+						topic.Configs[config.Name] = *config.Value
+					case kmsg.IncrementalAlterConfigOpDelete:
+						delete(topic.Configs, config.Name)
+					}
+				}
+			}
+			res.Resources = append(res.Resources, r)
+		}
+		return res
+
+	case *kmsg.DeleteTopicsRequest:
+		names := req.TopicNames
+		if req.GetVersion() >= 6 {
+			names = nil
+			for _, t := range req.Topics {
+				names = append(names, *t.Topic)
+			}
+		}
+		res := kmsg.NewPtrDeleteTopicsResponse()
+		for _, name := range names {
+			t := kmsg.NewDeleteTopicsResponseTopic()
+			t.Topic = kmsg.StringPtr(name)
+			if b.deleteDisabled {
+				t.ErrorCode = errTopicDeletionDisabled
+			} else if _, ok := b.topics[name]; !ok {
+				t.ErrorCode = errUnknownTopicOrPartition
+			} else {
+				delete(b.topics, name)
+			}
+			res.Topics = append(res.Topics, t)
+		}
+		return res
+	}
+
+	b.t.Errorf("unexpected request %T", req)
+	return req.ResponseKind()
+}
+
+// scramServer verifies the credentials of the broker's SASL config
+func (b *fakeBroker) scramServer() *scram.Server {
+	hash := scram.SHA256
+	if b.sasl.Mechanism == SASLMechanismScramSHA512 {
+		hash = scram.SHA512
+	}
+	client, err := hash.NewClient(b.sasl.Username, b.sasl.Password, "")
+	if err != nil {
+		b.t.Fatalf("unable to create scram client: %v", err)
+	}
+	credentials := client.GetStoredCredentials(scram.KeyFactors{Salt: "salt", Iters: 4096})
+	server, err := hash.NewServer(func(username string) (scram.StoredCredentials, error) {
+		if username != b.sasl.Username {
+			return scram.StoredCredentials{}, fmt.Errorf("unknown user %q", username)
+		}
+		return credentials, nil
+	})
+	if err != nil {
+		b.t.Fatalf("unable to create scram server: %v", err)
+	}
+	return server
+}
+
+func TestAdminApply(t *testing.T) {
+	partitions := func(p int32) *int32 { return &p }
+	millis := int64(3600000)
+	bytes := int64(1048576)
+
+	tests := []struct {
+		name        string
+		existing    *fakeTopic
+		maxVersions map[int16]int16
+		settings    provisioner.TopicSettings
+		want        *fakeTopic
+		wantErr     bool
+	}{{
+		name: "create with broker defaults",
+		want: &fakeTopic{Partitions: 6, ReplicationFactor: 3, Configs: map[string]string{}},
+	}, {
+		name: "create with settings",
+		settings: provisioner.TopicSettings{
+			Partitions:        partitions(4),
+			ReplicationFactor: partitions(2),
+			RetentionMillis:   &millis,
+			RetentionBytes:    &bytes,
+			Compaction:        true,
+		},
+		want: &fakeTopic{Partitions: 4, ReplicationFactor: 2, Configs: map[string]string{
+			"retention.ms":    "3600000",
+			"retention.bytes": "1048576",
+			"cleanup.policy":  "compact",
+		}},
+	}, {
+		name:        "create on a broker without defaults",
+		maxVersions: map[int16]int16{kmsg.NewPtrCreateTopicsRequest().Key(): 3},
+		want:        &fakeTopic{Partitions: 1, ReplicationFactor: 1, Configs: map[string]string{}},
+	}, {
+		name:     "grow partitions and update configs",
+		existing: &fakeTopic{Partitions: 2, ReplicationFactor: 1, Configs: map[string]string{"cleanup.policy": "compact"}},
+		settings: provisioner.TopicSettings{
+			Partitions:      partitions(4),
+			RetentionMillis: &millis,
+		},
+		want: &fakeTopic{Partitions: 4, ReplicationFactor: 1, Configs: map[string]string{
+			"retention.ms": "3600000",
+		}},
+	}, {
+		name:     "keep partitions the stream does not set",
+		existing: &fakeTopic{Partitions: 8, ReplicationFactor: 1, Configs: map[string]string{}},
+		settings: provisioner.TopicSettings{Partitions: partitions(4)},
+		want:     &fakeTopic{Partitions: 8, ReplicationFactor: 1, Configs: map[string]string{}},
+	}, {
+		name:        "update on a broker without incremental configs",
+		existing:    &fakeTopic{Partitions: 1, ReplicationFactor: 1, Configs: map[string]string{}},
+		maxVersions: map[int16]int16{kmsg.NewPtrIncrementalAlterConfigsRequest().Key(): -1},
+		wantErr:     true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			broker := newFakeBroker(t)
+			broker.maxVersions = test.maxVersions
+			if broker.maxVersions == nil {
+				broker.maxVersions = map[int16]int16{}
+			}
+			if test.existing != nil {
+				broker.topics["default_orders"] = test.existing
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			admin := NewAdmin(Config{Brokers: []string{broker.address()}, ClientID: "test"})
+			err := admin.Apply(ctx, "default_orders", test.settings)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if diff := cmp.Diff(test.want, broker.topics["default_orders"]); diff != "" {
+				t.Errorf("Apply() topic (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestAdminDelete(t *testing.T) {
+	tests := []struct {
+		name           string
+		existing       bool
+		deleteDisabled bool
+		wantErr        error
+	}{{
+		name:     "delete",
+		existing: true,
+	}, {
+		name: "already deleted",
+	}, {
+		name:           "deletion disabled",
+		existing:       true,
+		deleteDisabled: true,
+		wantErr:        provisioner.ErrDeleteUnsupported,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			broker := newFakeBroker(t)
+			broker.deleteDisabled = test.deleteDisabled
+			if test.existing {
+				broker.topics["default_orders"] = &fakeTopic{Partitions: 1, ReplicationFactor: 1}
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			admin := NewAdmin(Config{Brokers: []string{broker.address()}, ClientID: "test"})
+			if err := admin.Delete(ctx, "default_orders"); err != test.wantErr {
+				t.Fatalf("Delete() error = %v, want %v", err, test.wantErr)
+			}
+			if _, ok := broker.topics["default_orders"]; ok && test.wantErr == nil {
+				t.Errorf("Delete() left the topic behind")
+			}
+		})
+	}
+}
+
+func TestAdminAuthentication(t *testing.T) {
+	server := httptest.NewUnstartedServer(nil)
+	server.StartTLS()
+	defer server.Close()
+	serverTLS := &tls.Config{Certificates: server.TLS.Certificates}
+	clientTLS := server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	tests := []struct {
+		name      string
+		serverTLS *tls.Config
+		clientTLS *tls.Config
+		broker    *SASL
+		client    *SASL
+		wantErr   bool
+	}{{
+		name:      "tls",
+		serverTLS: serverTLS,
+		clientTLS: clientTLS,
+	}, {
+		name:      "untrusted broker",
+		serverTLS: serverTLS,
+		clientTLS: &tls.Config{},
+		wantErr:   true,
+	}, {
+		name:   "plain",
+		broker: &SASL{Mechanism: SASLMechanismPlain, Username: "riff", Password: "secret"},
+		client: &SASL{Mechanism: SASLMechanismPlain, Username: "riff", Password: "secret"},
+	}, {
+		name:    "plain wrong password",
+		broker:  &SASL{Mechanism: SASLMechanismPlain, Username: "riff", Password: "secret"},
+		client:  &SASL{Mechanism: SASLMechanismPlain, Username: "riff", Password: "guess"},
+		wantErr: true,
+	}, {
+		name:   "scram-sha-256",
+		broker: &SASL{Mechanism: SASLMechanismScramSHA256, Username: "riff", Password: "secret"},
+		client: &SASL{Mechanism: SASLMechanismScramSHA256, Username: "riff", Password: "secret"},
+	}, {
+		name:      "scram-sha-512 over tls",
+		serverTLS: serverTLS,
+		clientTLS: clientTLS,
+		broker:    &SASL{Mechanism: SASLMechanismScramSHA512, Username: "riff", Password: "secret"},
+		client:    &SASL{Mechanism: SASLMechanismScramSHA512, Username: "riff", Password: "secret"},
+	}, {
+		name:    "scram wrong password",
+		broker:  &SASL{Mechanism: SASLMechanismScramSHA512, Username: "riff", Password: "secret"},
+		client:  &SASL{Mechanism: SASLMechanismScramSHA512, Username: "riff", Password: "guess"},
+		wantErr: true,
+	}, {
+		name:    "unsupported mechanism",
+		broker:  &SASL{Mechanism: SASLMechanismScramSHA512, Username: "riff", Password: "secret"},
+		client:  &SASL{Mechanism: SASLMechanismPlain, Username: "riff", Password: "secret"},
+		wantErr: true,
+	}, {
+		name:    "missing credentials",
+		broker:  &SASL{Mechanism: SASLMechanismPlain, Username: "riff", Password: "secret"},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			broker := newFakeBrokerWithTLS(t, test.serverTLS)
+			broker.sasl = test.broker
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			admin := NewAdmin(Config{
+				Brokers:  []string{broker.address()},
+				ClientID: "test",
+				TLS:      test.clientTLS,
+				SASL:     test.client,
+			})
+			err := admin.Apply(ctx, "default_orders", provisioner.TopicSettings{})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if _, ok := broker.topics["default_orders"]; ok == test.wantErr {
+				t.Errorf("Apply() created topic = %v, want %v", ok, !test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// apiVersionsKey is answered with a non-flexible response header, whatever
+// the request version
+const apiVersionsKey = 18
+
+// conn is a connection to a single broker. Requests are issued one at a time
+// at the highest version both sides support.
+type conn struct {
+	net.Conn
+	formatter     *kmsg.RequestFormatter
+	correlationID int32
+	// versions holds the highest version the broker supports for each key
+	versions map[int16]int16
+}
+
+// newConn negotiates the api versions with the broker on the connection
+func newConn(ctx context.Context, c net.Conn, clientID string) (*conn, error) {
+	bc := &conn{
+		Conn:      c,
+		formatter: kmsg.NewRequestFormatter(kmsg.FormatterClientID(clientID)),
+	}
+	// v0 is understood by every broker
+	req := kmsg.NewPtrApiVersionsRequest()
+	req.SetVersion(0)
+	res, err := bc.roundTrip(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	versions := res.(*kmsg.ApiVersionsResponse)
+	if err := errorForCode(versions.ErrorCode, nil); err != nil {
+		return nil, fmt.Errorf("unable to negotiate api versions: %w", err)
+	}
+	bc.versions = make(map[int16]int16, len(versions.ApiKeys))
+	for _, key := range versions.ApiKeys {
+		bc.versions[key.ApiKey] = key.MaxVersion
+	}
+	return bc, nil
+}
+
+// supports is true when the broker supports the request at any version
+func (c *conn) supports(req kmsg.Request) bool {
+	_, ok := c.versions[req.Key()]
+	return ok
+}
+
+// request issues the request at the highest version supported by both the
+// broker and this client
+func (c *conn) request(ctx context.Context, req kmsg.Request) (kmsg.Response, error) {
+	max, ok := c.versions[req.Key()]
+	if !ok {
+		return nil, fmt.Errorf("broker does not support %T", req)
+	}
+	if req.MaxVersion() < max {
+		max = req.MaxVersion()
+	}
+	req.SetVersion(max)
+	return c.roundTrip(ctx, req)
+}
+
+func (c *conn) roundTrip(ctx context.Context, req kmsg.Request) (kmsg.Response, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := c.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+	c.correlationID++
+	if _, err := c.Write(c.formatter.AppendRequest(nil, req, c.correlationID)); err != nil {
+		return nil, err
+	}
+
+	var size int32
+	if err := binary.Read(c, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+	if size < 4 {
+		return nil, fmt.Errorf("invalid response size %d", size)
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(c, buf); err != nil {
+		return nil, err
+	}
+	if correlationID := int32(binary.BigEndian.Uint32(buf)); correlationID != c.correlationID {
+		return nil, fmt.Errorf("response correlation id %d does not match request %d", correlationID, c.correlationID)
+	}
+	buf = buf[4:]
+
+	res := req.ResponseKind()
+	res.SetVersion(req.GetVersion())
+	if res.IsFlexible() && req.Key() != apiVersionsKey {
+		var err error
+		if buf, err = skipTags(buf); err != nil {
+			return nil, err
+		}
+	}
+	if err := res.ReadFrom(buf); err != nil {
+		return nil, fmt.Errorf("unable to read %T: %w", res, err)
+	}
+	return res, nil
+}
+
+// skipTags skips the tagged fields ending a flexible response header
+func skipTags(buf []byte) ([]byte, error) {
+	count, n := binary.Uvarint(buf)
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid tagged fields")
+	}
+	buf = buf[n:]
+	for i := uint64(0); i < count; i++ {
+		// the tag itself, then the size of its value
+		if _, n = binary.Uvarint(buf); n <= 0 {
+			return nil, fmt.Errorf("invalid tagged field")
+		}
+		buf = buf[n:]
+		size, n := binary.Uvarint(buf)
+		if n <= 0 || uint64(len(buf)-n) < size {
+			return nil, fmt.Errorf("invalid tagged field")
+		}
+		buf = buf[n+int(size):]
+	}
+	return buf, nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import "fmt"
+
+// error codes of the Kafka protocol handled by the provisioner, see
+// https://kafka.apache.org/protocol#protocol_error_codes
+const (
+	errUnknownTopicOrPartition int16 = 3
+	errTopicAlreadyExists      int16 = 36
+	errTopicDeletionDisabled   int16 = 73
+)
+
+// errorNames names the codes likely when managing topics
+var errorNames = map[int16]string{
+	errUnknownTopicOrPartition: "UNKNOWN_TOPIC_OR_PARTITION",
+	7:                          "REQUEST_TIMED_OUT",
+	29:                         "TOPIC_AUTHORIZATION_FAILED",
+	31:                         "CLUSTER_AUTHORIZATION_FAILED",
+	33:                         "UNSUPPORTED_SASL_MECHANISM",
+	34:                         "ILLEGAL_SASL_STATE",
+	errTopicAlreadyExists:      "TOPIC_ALREADY_EXISTS",
+	37:                         "INVALID_PARTITIONS",
+	38:                         "INVALID_REPLICATION_FACTOR",
+	40:                         "INVALID_CONFIG",
+	41:                         "NOT_CONTROLLER",
+	42:                         "INVALID_REQUEST",
+	58:                         "SASL_AUTHENTICATION_FAILED",
+	errTopicDeletionDisabled:   "TOPIC_DELETION_DISABLED",
+}
+
+// Error is an error code returned by a broker
+type Error struct {
+	Code    int16
+	Message string
+}
+
+func (e *Error) Error() string {
+	name, ok := errorNames[e.Code]
+	if !ok {
+		name = fmt.Sprintf("error code %d", e.Code)
+	}
+	if e.Message == "" {
+		return name
+	}
+	return fmt.Sprintf("%s: %s", name, e.Message)
+}
+
+// errorForCode returns nil for the code of a successful response
+func errorForCode(code int16, message *string) error {
+	if code == 0 {
+		return nil
+	}
+	err := &Error{Code: code}
+	if message != nil {
+		err.Message = *message
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"github.com/xdg-go/scram"
+)
+
+// SASL mechanisms supported by the provisioner
+const (
+	SASLMechanismPlain       = "PLAIN"
+	SASLMechanismScramSHA256 = "SCRAM-SHA-256"
+	SASLMechanismScramSHA512 = "SCRAM-SHA-512"
+)
+
+// SASL authenticates the provisioner with the brokers
+type SASL struct {
+	// Mechanism is one of PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512
+	Mechanism string
+	Username  string
+	Password  string
+}
+
+// authenticate runs the SASL exchange for the mechanism. The exchange is
+// framed in SaslAuthenticate requests, which brokers support since Kafka 1.0.
+func (c *conn) authenticate(ctx context.Context, sasl *SASL) error {
+	handshake := kmsg.NewPtrSASLHandshakeRequest()
+	if max, ok := c.versions[handshake.Key()]; !ok || max < 1 {
+		return errors.New("broker does not support framed SASL authentication")
+	}
+	handshake.Mechanism = sasl.Mechanism
+	res, err := c.request(ctx, handshake)
+	if err != nil {
+		return err
+	}
+	if handshakeRes := res.(*kmsg.SASLHandshakeResponse); handshakeRes.ErrorCode != 0 {
+		return fmt.Errorf("unable to use SASL mechanism %s, the broker supports %v: %w", sasl.Mechanism, handshakeRes.SupportedMechanisms, errorForCode(handshakeRes.ErrorCode, nil))
+	}
+
+	switch sasl.Mechanism {
+	case SASLMechanismPlain:
+		_, err := c.saslAuthenticate(ctx, []byte("\x00"+sasl.Username+"\x00"+sasl.Password))
+		return err
+	case SASLMechanismScramSHA256, SASLMechanismScramSHA512:
+		hash := scram.SHA256
+		if sasl.Mechanism == SASLMechanismScramSHA512 {
+			hash = scram.SHA512
+		}
+		client, err := hash.NewClient(sasl.Username, sasl.Password, "")
+		if err != nil {
+			return err
+		}
+		conversation := client.NewConversation()
+		message, err := conversation.Step("")
+		for err == nil && !conversation.Done() {
+			var challenge []byte
+			if challenge, err = c.saslAuthenticate(ctx, []byte(message)); err != nil {
+				return err
+			}
+			message, err = conversation.Step(string(challenge))
+		}
+		if err != nil {
+			return fmt.Errorf("SASL authentication failed: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported SASL mechanism %q", sasl.Mechanism)
+	}
+}
+
+// saslAuthenticate sends one message of the exchange, returning the broker's
+// challenge
+func (c *conn) saslAuthenticate(ctx context.Context, message []byte) ([]byte, error) {
+	req := kmsg.NewPtrSASLAuthenticateRequest()
+	req.SASLAuthBytes = message
+	res, err := c.request(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	authenticateRes := res.(*kmsg.SASLAuthenticateResponse)
+	if err := errorForCode(authenticateRes.ErrorCode, authenticateRes.ErrorMessage); err != nil {
+		return nil, fmt.Errorf("SASL authentication failed: %w", err)
+	}
+	return authenticateRes.SASLAuthBytes, nil
+}
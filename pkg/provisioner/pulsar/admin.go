@@ -0,0 +1,260 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pulsar provisions the topics of streams on a Pulsar cluster
+// through the admin REST API. Topics are partitioned, as the gateway reads
+// every partition of a topic.
+package pulsar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/projectriff/system/pkg/provisioner"
+)
+
+// DefaultCompactionThreshold is the backlog, in bytes, that triggers the
+// compaction of a compacted stream
+const DefaultCompactionThreshold = 100 * 1024 * 1024
+
+// Config locates the admin API of the cluster
+type Config struct {
+	// AdminURL is the admin REST API, in the form http(s)://host:port
+	AdminURL string
+	// Tenant and Namespace hold the topics, the gateway resolves short topic
+	// names in public/default
+	Tenant    string
+	Namespace string
+	// CompactionThreshold is the backlog, in bytes, that triggers compaction
+	// for compacted streams
+	CompactionThreshold int64
+	// Token authenticates the requests when set
+	Token string
+}
+
+// Admin manages topics on a Pulsar cluster. Retention, compaction and
+// replication are topic policies, which need topicLevelPoliciesEnabled on
+// the brokers (Pulsar 2.6 or later).
+type Admin struct {
+	config Config
+	client *http.Client
+}
+
+var _ provisioner.Topics = (*Admin)(nil)
+
+func NewAdmin(config Config, client *http.Client) *Admin {
+	if config.Tenant == "" {
+		config.Tenant = "public"
+	}
+	if config.Namespace == "" {
+		config.Namespace = "default"
+	}
+	if config.CompactionThreshold == 0 {
+		config.CompactionThreshold = DefaultCompactionThreshold
+	}
+	return &Admin{config: config, client: client}
+}
+
+type partitionedTopicMetadata struct {
+	Partitions int32 `json:"partitions"`
+}
+
+type retentionPolicies struct {
+	RetentionTimeInMinutes int32 `json:"retentionTimeInMinutes"`
+	RetentionSizeInMB      int64 `json:"retentionSizeInMB"`
+}
+
+type persistencePolicies struct {
+	BookkeeperEnsemble    int32 `json:"bookkeeperEnsemble"`
+	BookkeeperWriteQuorum int32 `json:"bookkeeperWriteQuorum"`
+	BookkeeperAckQuorum   int32 `json:"bookkeeperAckQuorum"`
+}
+
+// Apply creates the partitioned topic, or grows its partitions when it
+// exists, then applies the topic policies. The replication factor only
+// applies to new topics.
+func (a *Admin) Apply(ctx context.Context, topic string, settings provisioner.TopicSettings) error {
+	partitions := int32(1)
+	if settings.Partitions != nil {
+		partitions = *settings.Partitions
+	}
+	status, err := a.do(ctx, http.MethodPut, a.topicPath(topic, "partitions"), partitions)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case http.StatusNoContent, http.StatusOK:
+		if settings.ReplicationFactor != nil {
+			r := *settings.ReplicationFactor
+			if err := a.setPolicy(ctx, topic, "persistence", &persistencePolicies{
+				BookkeeperEnsemble:    r,
+				BookkeeperWriteQuorum: r,
+				BookkeeperAckQuorum:   r,
+			}); err != nil {
+				return err
+			}
+		}
+	case http.StatusConflict:
+		if err := a.growPartitions(ctx, topic, settings.Partitions); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unable to create topic %q: %s", topic, http.StatusText(status))
+	}
+
+	if settings.RetentionMillis != nil || settings.RetentionBytes != nil {
+		// -1 is unlimited for either bound
+		retention := &retentionPolicies{RetentionTimeInMinutes: -1, RetentionSizeInMB: -1}
+		if settings.RetentionMillis != nil {
+			retention.RetentionTimeInMinutes = int32(ceilDiv(*settings.RetentionMillis, 60*1000))
+		}
+		if settings.RetentionBytes != nil {
+			retention.RetentionSizeInMB = ceilDiv(*settings.RetentionBytes, 1024*1024)
+		}
+		err = a.setPolicy(ctx, topic, "retention", retention)
+	} else {
+		err = a.removePolicy(ctx, topic, "retention")
+	}
+	if err != nil {
+		return err
+	}
+	if settings.Compaction {
+		return a.setPolicy(ctx, topic, "compactionThreshold", a.config.CompactionThreshold)
+	}
+	return a.removePolicy(ctx, topic, "compactionThreshold")
+}
+
+// Delete force deletes the topic, disconnecting the gateway's consumers and
+// producers
+func (a *Admin) Delete(ctx context.Context, topic string) error {
+	status, err := a.do(ctx, http.MethodDelete, a.topicPath(topic, "partitions")+"?force=true", nil)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case http.StatusNoContent, http.StatusOK, http.StatusNotFound:
+		return nil
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return provisioner.ErrDeleteUnsupported
+	}
+	return fmt.Errorf("unable to delete topic %q: %s", topic, http.StatusText(status))
+}
+
+func (a *Admin) growPartitions(ctx context.Context, topic string, partitions *int32) error {
+	if partitions == nil {
+		return nil
+	}
+	metadata := &partitionedTopicMetadata{}
+	if err := a.get(ctx, a.topicPath(topic, "partitions"), metadata); err != nil {
+		return err
+	}
+	if *partitions <= metadata.Partitions {
+		return nil
+	}
+	status, err := a.do(ctx, http.MethodPost, a.topicPath(topic, "partitions"), *partitions)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusNoContent && status != http.StatusOK {
+		return fmt.Errorf("unable to grow topic %q to %d partitions: %s", topic, *partitions, http.StatusText(status))
+	}
+	return nil
+}
+
+// setPolicy sets a policy of the topic
+func (a *Admin) setPolicy(ctx context.Context, topic, policy string, value interface{}) error {
+	return a.updatePolicy(ctx, http.MethodPost, topic, policy, value)
+}
+
+// removePolicy removes a policy of the topic, the namespace policy applies
+func (a *Admin) removePolicy(ctx context.Context, topic, policy string) error {
+	return a.updatePolicy(ctx, http.MethodDelete, topic, policy, nil)
+}
+
+func (a *Admin) updatePolicy(ctx context.Context, method, topic, policy string, value interface{}) error {
+	status, err := a.do(ctx, method, a.topicPath(topic, policy), value)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusNoContent && status != http.StatusOK {
+		return fmt.Errorf("unable to update the %s policy of topic %q: %s", policy, topic, http.StatusText(status))
+	}
+	return nil
+}
+
+func (a *Admin) topicPath(topic, resource string) string {
+	return fmt.Sprintf("/admin/v2/persistent/%s/%s/%s/%s", a.config.Tenant, a.config.Namespace, topic, resource)
+}
+
+func (a *Admin) get(ctx context.Context, path string, into interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(a.config.AdminURL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	res, err := a.send(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to get %s: %s", path, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(into)
+}
+
+// do sends the request with the body as JSON, returning the response status
+func (a *Admin) do(ctx context.Context, method, path string, body interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(a.config.AdminURL, "/")+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	if body != nil {
+		req.Header.Set("content-type", "application/json")
+	}
+	res, err := a.send(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	// drain the body so the connection is reused
+	_, _ = io.Copy(io.Discard, res.Body)
+	return res.StatusCode, nil
+}
+
+// send issues the request with the credentials of the provisioner
+func (a *Admin) send(req *http.Request) (*http.Response, error) {
+	if a.config.Token != "" {
+		req.Header.Set("authorization", "Bearer "+a.config.Token)
+	}
+	return a.client.Do(req)
+}
+
+func ceilDiv(n, d int64) int64 {
+	return (n + d - 1) / d
+}
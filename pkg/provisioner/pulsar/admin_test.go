@@ -0,0 +1,250 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pulsar
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/projectriff/system/pkg/provisioner"
+)
+
+// fakeTopic is a partitioned topic held by the fake admin API
+type fakeTopic struct {
+	Partitions int32
+	// Policies holds the JSON of each topic policy set
+	Policies map[string]string
+}
+
+// fakeAdmin serves the parts of the admin API used by Admin for the
+// public/default namespace
+type fakeAdmin struct {
+	mu     sync.Mutex
+	topics map[string]*fakeTopic
+	// token is required from clients when set
+	token string
+}
+
+func (f *fakeAdmin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.token != "" && r.Header.Get("authorization") != "Bearer "+f.token {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/admin/v2/persistent/public/default/"), "/")
+	if len(parts) != 2 {
+		http.Error(w, "unexpected path", http.StatusNotFound)
+		return
+	}
+	name, resource := parts[0], parts[1]
+	topic, exists := f.topics[name]
+	var body json.RawMessage
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	switch {
+	case resource == "partitions" && r.Method == http.MethodPut:
+		if exists {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		topic = &fakeTopic{Policies: map[string]string{}}
+		_ = json.Unmarshal(body, &topic.Partitions)
+		f.topics[name] = topic
+	case !exists:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	case resource == "partitions" && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(partitionedTopicMetadata{Partitions: topic.Partitions})
+		return
+	case resource == "partitions" && r.Method == http.MethodPost:
+		var partitions int32
+		_ = json.Unmarshal(body, &partitions)
+		if partitions <= topic.Partitions {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		topic.Partitions = partitions
+	case resource == "partitions" && r.Method == http.MethodDelete:
+		if r.URL.Query().Get("force") != "true" {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		delete(f.topics, name)
+	case r.Method == http.MethodPost:
+		topic.Policies[resource] = string(body)
+	case r.Method == http.MethodDelete:
+		delete(topic.Policies, resource)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestAdminApply(t *testing.T) {
+	int32Ptr := func(i int32) *int32 { return &i }
+	int64Ptr := func(i int64) *int64 { return &i }
+
+	tests := []struct {
+		name     string
+		existing *fakeTopic
+		settings provisioner.TopicSettings
+		want     *fakeTopic
+	}{{
+		name: "create with defaults",
+		want: &fakeTopic{Partitions: 1, Policies: map[string]string{}},
+	}, {
+		name: "create with settings",
+		settings: provisioner.TopicSettings{
+			Partitions:        int32Ptr(4),
+			ReplicationFactor: int32Ptr(2),
+			RetentionMillis:   int64Ptr(90 * 1000),
+			RetentionBytes:    int64Ptr(3 * 1024 * 1024),
+			Compaction:        true,
+		},
+		want: &fakeTopic{Partitions: 4, Policies: map[string]string{
+			"persistence":         `{"bookkeeperEnsemble":2,"bookkeeperWriteQuorum":2,"bookkeeperAckQuorum":2}`,
+			"retention":           `{"retentionTimeInMinutes":2,"retentionSizeInMB":3}`,
+			"compactionThreshold": `104857600`,
+		}},
+	}, {
+		name: "retention by time only",
+		settings: provisioner.TopicSettings{
+			RetentionMillis: int64Ptr(60 * 60 * 1000),
+		},
+		want: &fakeTopic{Partitions: 1, Policies: map[string]string{
+			"retention": `{"retentionTimeInMinutes":60,"retentionSizeInMB":-1}`,
+		}},
+	}, {
+		name: "grow partitions and update policies",
+		existing: &fakeTopic{Partitions: 2, Policies: map[string]string{
+			"persistence":         `{"bookkeeperEnsemble":3,"bookkeeperWriteQuorum":3,"bookkeeperAckQuorum":3}`,
+			"compactionThreshold": `104857600`,
+		}},
+		settings: provisioner.TopicSettings{
+			Partitions:        int32Ptr(3),
+			ReplicationFactor: int32Ptr(3),
+			RetentionBytes:    int64Ptr(1024 * 1024),
+		},
+		want: &fakeTopic{Partitions: 3, Policies: map[string]string{
+			"persistence": `{"bookkeeperEnsemble":3,"bookkeeperWriteQuorum":3,"bookkeeperAckQuorum":3}`,
+			"retention":   `{"retentionTimeInMinutes":-1,"retentionSizeInMB":1}`,
+		}},
+	}, {
+		name:     "keep partitions the stream does not set",
+		existing: &fakeTopic{Partitions: 8, Policies: map[string]string{}},
+		settings: provisioner.TopicSettings{Partitions: int32Ptr(4)},
+		want:     &fakeTopic{Partitions: 8, Policies: map[string]string{}},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeAdmin{topics: map[string]*fakeTopic{}}
+			if test.existing != nil {
+				fake.topics["default_orders"] = test.existing
+			}
+			server := httptest.NewServer(fake)
+			defer server.Close()
+
+			admin := NewAdmin(Config{AdminURL: server.URL}, server.Client())
+			if err := admin.Apply(context.Background(), "default_orders", test.settings); err != nil {
+				t.Fatalf("Apply() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, fake.topics["default_orders"]); diff != "" {
+				t.Errorf("Apply() topic (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestAdminDelete(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing bool
+	}{{
+		name:     "delete",
+		existing: true,
+	}, {
+		name: "already deleted",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeAdmin{topics: map[string]*fakeTopic{}}
+			if test.existing {
+				fake.topics["default_orders"] = &fakeTopic{Partitions: 1}
+			}
+			server := httptest.NewServer(fake)
+			defer server.Close()
+
+			admin := NewAdmin(Config{AdminURL: server.URL}, server.Client())
+			if err := admin.Delete(context.Background(), "default_orders"); err != nil {
+				t.Fatalf("Delete() unexpected error: %v", err)
+			}
+			if _, ok := fake.topics["default_orders"]; ok {
+				t.Errorf("Delete() left the topic behind")
+			}
+		})
+	}
+}
+
+func TestAdminAuthentication(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{{
+		name:  "token",
+		token: "secret",
+	}, {
+		name:    "wrong token",
+		token:   "guess",
+		wantErr: true,
+	}, {
+		name:    "missing token",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeAdmin{topics: map[string]*fakeTopic{}, token: "secret"}
+			server := httptest.NewTLSServer(fake)
+			defer server.Close()
+
+			admin := NewAdmin(Config{AdminURL: server.URL, Token: test.token}, server.Client())
+			err := admin.Apply(context.Background(), "default_orders", provisioner.TopicSettings{})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if _, ok := fake.topics["default_orders"]; ok == test.wantErr {
+				t.Errorf("Apply() created topic = %v, want %v", ok, !test.wantErr)
+			}
+		})
+	}
+}
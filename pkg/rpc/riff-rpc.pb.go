@@ -0,0 +1,583 @@
+// The invocation API between the riff streaming processor and the function
+// it runs, as served by the riff streaming invokers. Only the go_package
+// option is added to the upstream definition.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        (unknown)
+// source: rpc/riff-rpc.proto
+
+package rpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Represents data flowing in when invoking a riff function. A special StartFrame is sent first to specify metadata
+// about the invocation.
+type InputSignal struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Frame:
+	//	*InputSignal_Start
+	//	*InputSignal_Data
+	Frame isInputSignal_Frame `protobuf_oneof:"frame"`
+}
+
+func (x *InputSignal) Reset() {
+	*x = InputSignal{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_riff_rpc_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InputSignal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InputSignal) ProtoMessage() {}
+
+func (x *InputSignal) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_riff_rpc_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InputSignal.ProtoReflect.Descriptor instead.
+func (*InputSignal) Descriptor() ([]byte, []int) {
+	return file_rpc_riff_rpc_proto_rawDescGZIP(), []int{0}
+}
+
+func (m *InputSignal) GetFrame() isInputSignal_Frame {
+	if m != nil {
+		return m.Frame
+	}
+	return nil
+}
+
+func (x *InputSignal) GetStart() *StartFrame {
+	if x, ok := x.GetFrame().(*InputSignal_Start); ok {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *InputSignal) GetData() *InputFrame {
+	if x, ok := x.GetFrame().(*InputSignal_Data); ok {
+		return x.Data
+	}
+	return nil
+}
+
+type isInputSignal_Frame interface {
+	isInputSignal_Frame()
+}
+
+type InputSignal_Start struct {
+	Start *StartFrame `protobuf:"bytes,1,opt,name=start,proto3,oneof"`
+}
+
+type InputSignal_Data struct {
+	Data *InputFrame `protobuf:"bytes,2,opt,name=data,proto3,oneof"`
+}
+
+func (*InputSignal_Start) isInputSignal_Frame() {}
+
+func (*InputSignal_Data) isInputSignal_Frame() {}
+
+// Represents data flowing out when invoking a riff function. Represented as a oneof with a single case to allow for
+// future extensions.
+type OutputSignal struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Frame:
+	//	*OutputSignal_Data
+	Frame isOutputSignal_Frame `protobuf_oneof:"frame"`
+}
+
+func (x *OutputSignal) Reset() {
+	*x = OutputSignal{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_riff_rpc_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OutputSignal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutputSignal) ProtoMessage() {}
+
+func (x *OutputSignal) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_riff_rpc_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutputSignal.ProtoReflect.Descriptor instead.
+func (*OutputSignal) Descriptor() ([]byte, []int) {
+	return file_rpc_riff_rpc_proto_rawDescGZIP(), []int{1}
+}
+
+func (m *OutputSignal) GetFrame() isOutputSignal_Frame {
+	if m != nil {
+		return m.Frame
+	}
+	return nil
+}
+
+func (x *OutputSignal) GetData() *OutputFrame {
+	if x, ok := x.GetFrame().(*OutputSignal_Data); ok {
+		return x.Data
+	}
+	return nil
+}
+
+type isOutputSignal_Frame interface {
+	isOutputSignal_Frame()
+}
+
+type OutputSignal_Data struct {
+	Data *OutputFrame `protobuf:"bytes,1,opt,name=data,proto3,oneof"`
+}
+
+func (*OutputSignal_Data) isOutputSignal_Frame() {}
+
+// Sent as the first message when invoking a function. Contains metadata about the invocation.
+type StartFrame struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The content types the invoker would like to receive for each output.
+	ExpectedContentTypes []string `protobuf:"bytes,1,rep,name=expectedContentTypes,proto3" json:"expectedContentTypes,omitempty"`
+	InputNames           []string `protobuf:"bytes,2,rep,name=inputNames,proto3" json:"inputNames,omitempty"`
+	OutputNames          []string `protobuf:"bytes,3,rep,name=outputNames,proto3" json:"outputNames,omitempty"`
+}
+
+func (x *StartFrame) Reset() {
+	*x = StartFrame{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_riff_rpc_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StartFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartFrame) ProtoMessage() {}
+
+func (x *StartFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_riff_rpc_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartFrame.ProtoReflect.Descriptor instead.
+func (*StartFrame) Descriptor() ([]byte, []int) {
+	return file_rpc_riff_rpc_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StartFrame) GetExpectedContentTypes() []string {
+	if x != nil {
+		return x.ExpectedContentTypes
+	}
+	return nil
+}
+
+func (x *StartFrame) GetInputNames() []string {
+	if x != nil {
+		return x.InputNames
+	}
+	return nil
+}
+
+func (x *StartFrame) GetOutputNames() []string {
+	if x != nil {
+		return x.OutputNames
+	}
+	return nil
+}
+
+// Contains actual invocation data, as input events.
+type InputFrame struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Payload     []byte            `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	ContentType string            `protobuf:"bytes,2,opt,name=contentType,proto3" json:"contentType,omitempty"`
+	Headers     map[string]string `protobuf:"bytes,3,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The input argument index this data frame relates to.
+	ArgIndex int32 `protobuf:"varint,4,opt,name=argIndex,proto3" json:"argIndex,omitempty"`
+}
+
+func (x *InputFrame) Reset() {
+	*x = InputFrame{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_riff_rpc_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InputFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InputFrame) ProtoMessage() {}
+
+func (x *InputFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_riff_rpc_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InputFrame.ProtoReflect.Descriptor instead.
+func (*InputFrame) Descriptor() ([]byte, []int) {
+	return file_rpc_riff_rpc_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *InputFrame) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *InputFrame) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *InputFrame) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *InputFrame) GetArgIndex() int32 {
+	if x != nil {
+		return x.ArgIndex
+	}
+	return 0
+}
+
+// Contains actual function invocation result data, as output events.
+type OutputFrame struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Payload     []byte            `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	ContentType string            `protobuf:"bytes,2,opt,name=contentType,proto3" json:"contentType,omitempty"`
+	Headers     map[string]string `protobuf:"bytes,3,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The index of the result this frame relates to.
+	ResultIndex int32 `protobuf:"varint,4,opt,name=resultIndex,proto3" json:"resultIndex,omitempty"`
+}
+
+func (x *OutputFrame) Reset() {
+	*x = OutputFrame{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_riff_rpc_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OutputFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutputFrame) ProtoMessage() {}
+
+func (x *OutputFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_riff_rpc_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutputFrame.ProtoReflect.Descriptor instead.
+func (*OutputFrame) Descriptor() ([]byte, []int) {
+	return file_rpc_riff_rpc_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *OutputFrame) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *OutputFrame) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *OutputFrame) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *OutputFrame) GetResultIndex() int32 {
+	if x != nil {
+		return x.ResultIndex
+	}
+	return 0
+}
+
+var File_rpc_riff_rpc_proto protoreflect.FileDescriptor
+
+var file_rpc_riff_rpc_proto_rawDesc = []byte{
+	0x0a, 0x12, 0x72, 0x70, 0x63, 0x2f, 0x72, 0x69, 0x66, 0x66, 0x2d, 0x72, 0x70, 0x63, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x22,
+	0x72, 0x0a, 0x0b, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x12, 0x2d,
+	0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e,
+	0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x46,
+	0x72, 0x61, 0x6d, 0x65, 0x48, 0x00, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x2b, 0x0a,
+	0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x46, 0x72, 0x61,
+	0x6d, 0x65, 0x48, 0x00, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x42, 0x07, 0x0a, 0x05, 0x66, 0x72,
+	0x61, 0x6d, 0x65, 0x22, 0x45, 0x0a, 0x0c, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x53, 0x69, 0x67,
+	0x6e, 0x61, 0x6c, 0x12, 0x2c, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x16, 0x2e, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x4f, 0x75,
+	0x74, 0x70, 0x75, 0x74, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x48, 0x00, 0x52, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x42, 0x07, 0x0a, 0x05, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x22, 0x82, 0x01, 0x0a, 0x0a, 0x53,
+	0x74, 0x61, 0x72, 0x74, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x12, 0x32, 0x0a, 0x14, 0x65, 0x78, 0x70,
+	0x65, 0x63, 0x74, 0x65, 0x64, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x14, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65,
+	0x64, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x73, 0x12, 0x1e, 0x0a,
+	0x0a, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x0a, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x20, 0x0a,
+	0x0b, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0b, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x22,
+	0xde, 0x01, 0x0a, 0x0a, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74,
+	0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63,
+	0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x3c, 0x0a, 0x07, 0x68, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x73, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x46, 0x72, 0x61,
+	0x6d, 0x65, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x72, 0x67, 0x49,
+	0x6e, 0x64, 0x65, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x61, 0x72, 0x67, 0x49,
+	0x6e, 0x64, 0x65, 0x78, 0x1a, 0x3a, 0x0a, 0x0c, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x22, 0xe6, 0x01, 0x0a, 0x0b, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x46, 0x72, 0x61, 0x6d, 0x65,
+	0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f,
+	0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x3d, 0x0a, 0x07,
+	0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e,
+	0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74,
+	0x46, 0x72, 0x61, 0x6d, 0x65, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x72,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0b, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x1a, 0x3a, 0x0a,
+	0x0c, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x32, 0x47, 0x0a, 0x04, 0x52, 0x69, 0x66,
+	0x66, 0x12, 0x3f, 0x0a, 0x06, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x12, 0x16, 0x2e, 0x73, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x53, 0x69, 0x67,
+	0x6e, 0x61, 0x6c, 0x1a, 0x17, 0x2e, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x2e,
+	0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x22, 0x00, 0x28, 0x01,
+	0x30, 0x01, 0x42, 0x45, 0x0a, 0x1a, 0x69, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74,
+	0x72, 0x69, 0x66, 0x66, 0x2e, 0x69, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x72, 0x2e, 0x72, 0x70, 0x63,
+	0x50, 0x01, 0x5a, 0x25, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x70,
+	0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x72, 0x69, 0x66, 0x66, 0x2f, 0x73, 0x79, 0x73, 0x74, 0x65,
+	0x6d, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
+}
+
+var (
+	file_rpc_riff_rpc_proto_rawDescOnce sync.Once
+	file_rpc_riff_rpc_proto_rawDescData = file_rpc_riff_rpc_proto_rawDesc
+)
+
+func file_rpc_riff_rpc_proto_rawDescGZIP() []byte {
+	file_rpc_riff_rpc_proto_rawDescOnce.Do(func() {
+		file_rpc_riff_rpc_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_riff_rpc_proto_rawDescData)
+	})
+	return file_rpc_riff_rpc_proto_rawDescData
+}
+
+var file_rpc_riff_rpc_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_rpc_riff_rpc_proto_goTypes = []interface{}{
+	(*InputSignal)(nil),  // 0: streaming.InputSignal
+	(*OutputSignal)(nil), // 1: streaming.OutputSignal
+	(*StartFrame)(nil),   // 2: streaming.StartFrame
+	(*InputFrame)(nil),   // 3: streaming.InputFrame
+	(*OutputFrame)(nil),  // 4: streaming.OutputFrame
+	nil,                  // 5: streaming.InputFrame.HeadersEntry
+	nil,                  // 6: streaming.OutputFrame.HeadersEntry
+}
+var file_rpc_riff_rpc_proto_depIdxs = []int32{
+	2, // 0: streaming.InputSignal.start:type_name -> streaming.StartFrame
+	3, // 1: streaming.InputSignal.data:type_name -> streaming.InputFrame
+	4, // 2: streaming.OutputSignal.data:type_name -> streaming.OutputFrame
+	5, // 3: streaming.InputFrame.headers:type_name -> streaming.InputFrame.HeadersEntry
+	6, // 4: streaming.OutputFrame.headers:type_name -> streaming.OutputFrame.HeadersEntry
+	0, // 5: streaming.Riff.Invoke:input_type -> streaming.InputSignal
+	1, // 6: streaming.Riff.Invoke:output_type -> streaming.OutputSignal
+	6, // [6:7] is the sub-list for method output_type
+	5, // [5:6] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_rpc_riff_rpc_proto_init() }
+func file_rpc_riff_rpc_proto_init() {
+	if File_rpc_riff_rpc_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_riff_rpc_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InputSignal); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_riff_rpc_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OutputSignal); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_riff_rpc_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StartFrame); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_riff_rpc_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InputFrame); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_riff_rpc_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OutputFrame); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_rpc_riff_rpc_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*InputSignal_Start)(nil),
+		(*InputSignal_Data)(nil),
+	}
+	file_rpc_riff_rpc_proto_msgTypes[1].OneofWrappers = []interface{}{
+		(*OutputSignal_Data)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_riff_rpc_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_rpc_riff_rpc_proto_goTypes,
+		DependencyIndexes: file_rpc_riff_rpc_proto_depIdxs,
+		MessageInfos:      file_rpc_riff_rpc_proto_msgTypes,
+	}.Build()
+	File_rpc_riff_rpc_proto = out.File
+	file_rpc_riff_rpc_proto_rawDesc = nil
+	file_rpc_riff_rpc_proto_goTypes = nil
+	file_rpc_riff_rpc_proto_depIdxs = nil
+}
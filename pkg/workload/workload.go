@@ -0,0 +1,91 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package workload stops the other containers of a pod that shares its
+// process namespace, so sidecars can complete the pod of a job once their
+// work is done.
+package workload
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// Stop terminates every other process in the pod's shared process namespace
+// with SIGTERM, and kills those still running after the grace period. The
+// first process is the pod's sandbox and is left alone.
+func Stop(log logr.Logger, gracePeriod time.Duration) error {
+	pids, err := processes()
+	if err != nil {
+		return err
+	}
+	for _, pid := range pids {
+		if err := syscall.Kill(pid, syscall.SIGTERM); err != nil && err != syscall.ESRCH {
+			return fmt.Errorf("unable to stop process %d: %v", pid, err)
+		}
+	}
+
+	deadline := time.Now().Add(gracePeriod)
+	for time.Now().Before(deadline) {
+		if pids, err = processes(); err != nil || len(pids) == 0 {
+			return err
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	for _, pid := range pids {
+		log.Info("killing process that did not stop in time", "pid", pid)
+		if err := syscall.Kill(pid, syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+			return fmt.Errorf("unable to kill process %d: %v", pid, err)
+		}
+	}
+	return nil
+}
+
+func processes() ([]int, error) {
+	entries, err := filepath.Glob("/proc/[0-9]*")
+	if err != nil {
+		return nil, err
+	}
+	self := os.Getpid()
+	pids := []int{}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(filepath.Base(entry))
+		if err != nil || pid == 1 || pid == self {
+			continue
+		}
+		if stat, err := ioutil.ReadFile(filepath.Join(entry, "stat")); err != nil || isZombie(stat) {
+			// the process exited
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
+
+// isZombie is true for a process that exited but was not reaped yet, the
+// state follows the parenthesized command name in /proc/<pid>/stat
+func isZombie(stat []byte) bool {
+	i := bytes.LastIndexByte(stat, ')')
+	return i >= 0 && i+2 < len(stat) && stat[i+2] == 'Z'
+}